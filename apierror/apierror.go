@@ -0,0 +1,82 @@
+// Package apierror gives every error response the same JSON shape.
+package apierror
+
+import (
+	"food-delivery-api/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes returned in ErrorResponse.Code
+const (
+	ErrBadRequest        = "BAD_REQUEST"
+	ErrValidation        = "VALIDATION_FAILED"
+	ErrUnauthorized      = "UNAUTHORIZED"
+	ErrForbidden         = "FORBIDDEN"
+	ErrNotFound          = "NOT_FOUND"
+	ErrConflict          = "CONFLICT"
+	ErrInvalidTransition = "INVALID_TRANSITION"
+	ErrUnprocessable     = "UNPROCESSABLE"
+	ErrInternal          = "INTERNAL_ERROR"
+	ErrUnavailable       = "SERVICE_UNAVAILABLE"
+	ErrRateLimited       = "RATE_LIMITED"
+	ErrNotImplemented    = "NOT_IMPLEMENTED"
+)
+
+// RequestIDKey is the gin context key holding the current request ID
+const RequestIDKey = "requestID"
+
+// ErrorResponse is the body of every non-2xx response
+type ErrorResponse struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+// Error is an error that knows how it should be rendered to the client.
+// Message is an i18n message key, translated with Args when the response
+// is written.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]interface{}
+	Args    []interface{}
+}
+
+// Error returns the message in the default locale, for logs
+func (e *Error) Error() string {
+	return i18n.Default.T(i18n.DefaultLocale, e.Message, e.Args...)
+}
+
+// New builds an Error for returning through code paths that can't write the response directly
+func New(status int, code, key string, details map[string]interface{}, args ...interface{}) *Error {
+	return &Error{Status: status, Code: code, Message: key, Details: details, Args: args}
+}
+
+func build(c *gin.Context, code, key string, details map[string]interface{}, args []interface{}) ErrorResponse {
+	return ErrorResponse{
+		Code:      code,
+		Message:   i18n.T(c, key, args...),
+		Details:   details,
+		RequestID: c.GetString(RequestIDKey),
+	}
+}
+
+// Respond writes a structured error response. key is an i18n message key,
+// translated into the request's locale and formatted with args; text that
+// isn't a key, like a binding error, is sent as is.
+func Respond(c *gin.Context, status int, code, key string, details map[string]interface{}, args ...interface{}) {
+	c.JSON(status, build(c, code, key, details, args))
+}
+
+// Abort writes a structured error response and stops the handler chain
+func Abort(c *gin.Context, status int, code, key string, details map[string]interface{}, args ...interface{}) {
+	c.AbortWithStatusJSON(status, build(c, code, key, details, args))
+}
+
+// RespondError writes an *Error as a structured error response
+func RespondError(c *gin.Context, err *Error) {
+	Respond(c, err.Status, err.Code, err.Message, err.Details, err.Args...)
+}
@@ -0,0 +1,38 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// ErrTimeout is returned when a request's deadline passed mid-query
+const ErrTimeout = "TIMEOUT"
+
+// KnownError maps a sentinel error to the response a handler should give
+// when it passes that error (or one wrapping it) to c.Error
+type KnownError struct {
+	Err     error
+	Status  int
+	Code    string
+	Message string // i18n key, used when the handler doesn't supply one
+}
+
+// KnownErrors is checked in order with errors.Is
+var KnownErrors = []KnownError{
+	{Err: gorm.ErrRecordNotFound, Status: http.StatusNotFound, Code: ErrNotFound, Message: "errors.resource_not_found"},
+	{Err: gorm.ErrDuplicatedKey, Status: http.StatusConflict, Code: ErrConflict, Message: "errors.resource_already_exists"},
+	{Err: context.DeadlineExceeded, Status: http.StatusServiceUnavailable, Code: ErrTimeout, Message: "errors.request_timed_out"},
+}
+
+// Lookup finds the KnownError matching err
+func Lookup(err error) (KnownError, bool) {
+	for _, known := range KnownErrors {
+		if errors.Is(err, known.Err) {
+			return known, true
+		}
+	}
+	return KnownError{}, false
+}
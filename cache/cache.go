@@ -0,0 +1,108 @@
+// Package cache is a small byte cache with per-entry expiry. Handlers go
+// through Default, which main picks from CACHE_BACKEND.
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores bytes under string keys for a limited time
+type Cache interface {
+	// Get returns the cached bytes for key if present and not yet expired
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for ttl
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes a single key
+	Delete(key string)
+	// DeletePrefix removes every key starting with prefix
+	DeletePrefix(prefix string)
+}
+
+type entry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// sweepInterval is the least time between two sweeps for expired entries
+const sweepInterval = time.Minute
+
+// MemoryCache keeps entries in process memory. An expired entry is dropped
+// the next time it is read, and Set sweeps out the rest now and then, so keys
+// that are never read again don't pile up. The zero value is ready to use.
+type MemoryCache struct {
+	entries   sync.Map     // key → entry
+	lastSweep atomic.Int64 // unix nanoseconds
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	v, ok := m.entries.Load(key)
+	if !ok {
+		return nil, false
+	}
+	e := v.(entry)
+	if time.Now().After(e.expiresAt) {
+		m.entries.Delete(key)
+		return nil, false
+	}
+	return e.data, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	m.entries.Store(key, entry{data: value, expiresAt: now.Add(ttl)})
+	m.sweep(now)
+}
+
+// sweep drops every expired entry, at most once per sweepInterval
+func (m *MemoryCache) sweep(now time.Time) {
+	last := m.lastSweep.Load()
+	if now.UnixNano()-last < int64(sweepInterval) || !m.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	m.entries.Range(func(k, v interface{}) bool {
+		if now.After(v.(entry).expiresAt) {
+			m.entries.Delete(k)
+		}
+		return true
+	})
+}
+
+func (m *MemoryCache) Delete(key string) {
+	m.entries.Delete(key)
+}
+
+func (m *MemoryCache) DeletePrefix(prefix string) {
+	m.entries.Range(func(k, _ interface{}) bool {
+		if strings.HasPrefix(k.(string), prefix) {
+			m.entries.Delete(k)
+		}
+		return true
+	})
+}
+
+// NoopCache stores nothing, so every Get misses and each request recomputes
+type NoopCache struct{}
+
+func (NoopCache) Get(string) ([]byte, bool)         { return nil, false }
+func (NoopCache) Set(string, []byte, time.Duration) {}
+func (NoopCache) Delete(string)                     {}
+func (NoopCache) DeletePrefix(string)               {}
+
+// New returns the cache for a CACHE_BACKEND value: "memory" (also the
+// default when empty) or "noop"
+func New(backend string) (Cache, error) {
+	switch backend {
+	case "", "memory":
+		return &MemoryCache{}, nil
+	case "noop":
+		return NoopCache{}, nil
+	}
+	return nil, fmt.Errorf("unknown cache backend %q", backend)
+}
+
+// Default is the cache used across the application
+var Default Cache = &MemoryCache{}
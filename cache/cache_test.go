@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func (m *MemoryCache) size() int {
+	n := 0
+	m.entries.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func TestMemoryCacheGetSet(t *testing.T) {
+	var m MemoryCache
+	m.Set("fresh", []byte("a"), time.Minute)
+	m.Set("stale", []byte("b"), -time.Second)
+
+	if got, ok := m.Get("fresh"); !ok || string(got) != "a" {
+		t.Errorf("Get(fresh) = %q, %v; want a, true", got, ok)
+	}
+	if _, ok := m.Get("stale"); ok {
+		t.Error("Get(stale) hit an expired entry")
+	}
+	if _, ok := m.Get("missing"); ok {
+		t.Error("Get(missing) hit")
+	}
+}
+
+func TestMemoryCacheDelete(t *testing.T) {
+	var m MemoryCache
+	for _, key := range []string{"heatmap:1", "heatmap:4", "leaderboard:week"} {
+		m.Set(key, []byte("x"), time.Minute)
+	}
+	m.DeletePrefix("heatmap:")
+	if m.size() != 1 {
+		t.Errorf("%d entries after DeletePrefix, want only leaderboard:week", m.size())
+	}
+	m.Delete("leaderboard:week")
+	if m.size() != 0 {
+		t.Errorf("%d entries after Delete, want none", m.size())
+	}
+}
+
+func TestMemoryCacheSweepsExpiredEntries(t *testing.T) {
+	var m MemoryCache
+	m.Set("old-1", []byte("x"), time.Millisecond)
+	m.Set("old-2", []byte("x"), time.Millisecond)
+	m.Set("live", []byte("x"), time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	// Within sweepInterval of the last sweep, expired entries stay until read
+	m.Set("other", []byte("x"), time.Hour)
+	if m.size() != 4 {
+		t.Fatalf("%d entries, want 4 before the next sweep is due", m.size())
+	}
+
+	m.lastSweep.Store(time.Now().Add(-sweepInterval).UnixNano())
+	m.Set("other", []byte("y"), time.Hour)
+	if m.size() != 2 {
+		t.Errorf("%d entries after the sweep, want live and other", m.size())
+	}
+	if _, ok := m.Get("live"); !ok {
+		t.Error("sweep dropped an entry that hadn't expired")
+	}
+}
+
+func TestNew(t *testing.T) {
+	for backend, want := range map[string]string{"": "*cache.MemoryCache", "memory": "*cache.MemoryCache", "noop": "cache.NoopCache"} {
+		c, err := New(backend)
+		if err != nil || fmt.Sprintf("%T", c) != want {
+			t.Errorf("New(%q) = %T, %v; want %s", backend, c, err, want)
+		}
+	}
+	if _, err := New("redis"); err == nil {
+		t.Error("New(redis) succeeded, want an error")
+	}
+}
@@ -0,0 +1,70 @@
+// Command migrate applies, rolls back and reports schema migrations.
+//
+//	go run ./cmd/migrate up
+//	go run ./cmd/migrate down [N]
+//	go run ./cmd/migrate status
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"food-delivery-api/config"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up | down [N] | status")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	db := config.OpenDB()
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := config.MigrateUp(db)
+		for _, v := range applied {
+			fmt.Println("applied", v)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("nothing to apply")
+		}
+	case "down":
+		n := 1
+		if len(os.Args) > 2 {
+			var err error
+			if n, err = strconv.Atoi(os.Args[2]); err != nil || n < 1 {
+				usage()
+			}
+		}
+		rolledBack, err := config.MigrateDown(db, n)
+		for _, v := range rolledBack {
+			fmt.Println("rolled back", v)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		states, err := config.MigrationStatus(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range states {
+			if s.Applied {
+				fmt.Printf("applied  %s  (%s)\n", s.Version, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("pending  %s\n", s.Version)
+			}
+		}
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var bcryptCostOnce sync.Once
+var bcryptCost int
+
+// BCryptCost is the bcrypt work factor for new password hashes, read once from
+// BCRYPT_COST. Values outside [4, 31] fall back to bcrypt.DefaultCost.
+func BCryptCost() int {
+	bcryptCostOnce.Do(func() {
+		bcryptCost = bcrypt.DefaultCost
+		if s := os.Getenv("BCRYPT_COST"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < bcrypt.MinCost || n > bcrypt.MaxCost {
+				log.Printf("⚠️  BCRYPT_COST=%q is not a number in [%d, %d], using %d", s, bcrypt.MinCost, bcrypt.MaxCost, bcrypt.DefaultCost)
+			} else {
+				bcryptCost = n
+			}
+		}
+		if bcryptCost < bcrypt.DefaultCost && os.Getenv("GIN_MODE") == gin.ReleaseMode {
+			log.Printf("⚠️  BCRYPT_COST=%d is below %d in release mode — password hashes are weaker than recommended", bcryptCost, bcrypt.DefaultCost)
+		}
+	})
+	return bcryptCost
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptCostFor reads BCryptCost with BCRYPT_COST set to value, forgetting
+// whatever an earlier call cached
+func bcryptCostFor(t *testing.T, value string) int {
+	t.Helper()
+	t.Setenv("BCRYPT_COST", value)
+	bcryptCostOnce = sync.Once{}
+	t.Cleanup(func() { bcryptCostOnce = sync.Once{} })
+	return BCryptCost()
+}
+
+func TestBCryptCost(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"", bcrypt.DefaultCost},
+		{"4", 4},
+		{"12", 12},
+		{"31", 31},
+		{"3", bcrypt.DefaultCost},
+		{"32", bcrypt.DefaultCost},
+		{"ten", bcrypt.DefaultCost},
+	}
+	for _, tt := range tests {
+		if got := bcryptCostFor(t, tt.value); got != tt.want {
+			t.Errorf("BCRYPT_COST=%q: BCryptCost() = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLowBCryptCostHashesFaster(t *testing.T) {
+	hashTime := func(cost int) time.Duration {
+		start := time.Now()
+		hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := bcrypt.CompareHashAndPassword(hash, []byte("secret123")); err != nil {
+			t.Fatalf("cost %d hash does not verify: %v", cost, err)
+		}
+		return time.Since(start)
+	}
+	low := hashTime(bcryptCostFor(t, "4"))
+	def := hashTime(bcryptCostFor(t, ""))
+	if low >= def {
+		t.Errorf("cost 4 took %v, not faster than the default cost's %v", low, def)
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultDashboardCacheTTL applies when DASHBOARD_CACHE_TTL is unset
+const DefaultDashboardCacheTTL = 60 * time.Second
+
+// DashboardCacheTTL is how long the admin dashboard metrics are cached, read
+// from DASHBOARD_CACHE_TTL in seconds
+func DashboardCacheTTL() time.Duration {
+	s := os.Getenv("DASHBOARD_CACHE_TTL")
+	if s == "" {
+		return DefaultDashboardCacheTTL
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		log.Printf("⚠️  DASHBOARD_CACHE_TTL=%q is not a positive number of seconds, ignoring", s)
+		return DefaultDashboardCacheTTL
+	}
+	return time.Duration(n) * time.Second
+}
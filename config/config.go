@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	"food-delivery-api/models"
 
@@ -13,8 +14,55 @@ import (
 
 var DB *gorm.DB
 
+// DefaultJWTSecret is the JWT_SECRET fallback for local development. It is
+// public, so the server refuses to start with it in release mode.
+const DefaultJWTSecret = "food_delivery_super_secret_2024"
+
 // JWTSecret used to sign tokens — read from env or fallback
-var JWTSecret = []byte(getEnv("JWT_SECRET", "food_delivery_super_secret_2024"))
+var JWTSecret = []byte(getEnv("JWT_SECRET", DefaultJWTSecret))
+
+// FrontendURL is where links in emails point, e.g. magic login links
+var FrontendURL = getEnv("FRONTEND_URL", "http://localhost:3000")
+
+// CurrencyRatesFile is a JSON file of fixed exchange rates; unset treats every
+// currency as equal
+var CurrencyRatesFile = os.Getenv("CURRENCY_RATES_FILE")
+
+// PaymentWebhookSecret signs the payment gateway's callbacks; unset rejects them all
+var PaymentWebhookSecret = os.Getenv("PAYMENT_WEBHOOK_SECRET")
+
+// GeocoderURL is a Nominatim-compatible geocoding API; unset saves addresses
+// without coordinates
+var GeocoderURL = os.Getenv("GEOCODER_URL")
+
+// CacheBackend picks the response cache: "memory" (the default) or "noop",
+// which turns caching off
+var CacheBackend = os.Getenv("CACHE_BACKEND")
+
+// GeocoderStrict rejects addresses that can't be geocoded instead of saving
+// them without coordinates
+var GeocoderStrict = os.Getenv("GEOCODER_STRICT") == "true"
+
+// TrustedProxies are the proxies whose X-Forwarded-For header is believed when
+// working out a client's IP, read from the comma-separated TRUSTED_PROXIES.
+// Empty trusts none, so clients can't spoof their IP past the admin allowlist.
+func TrustedProxies() []string {
+	s := os.Getenv("TRUSTED_PROXIES")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// AdminAllowedCIDRs are the networks admin routes accept requests from, read
+// from the comma-separated ADMIN_ALLOWED_CIDRS. Empty means no restriction.
+func AdminAllowedCIDRs() []string {
+	s := os.Getenv("ADMIN_ALLOWED_CIDRS")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
 
 func getEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
@@ -23,27 +71,54 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
-func InitDB() {
-	var err error
-	DB, err = gorm.Open(sqlite.Open("food_delivery.db"), &gorm.Config{
+// OpenDB connects to the SQLite database without touching the schema
+func OpenDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("food_delivery.db"), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Warn),
+		// Report unique violations as gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	return db
+}
 
-	// Auto-migrate all models
-	err = DB.AutoMigrate(
-		&models.User{},
-		&models.Restaurant{},
-		&models.MenuItem{},
-		&models.Order{},
-		&models.OrderItem{},
-		&models.OrderStatusHistory{},
-	)
+func InitDB() {
+	DB = OpenDB()
+	if err := DB.Use(NewLazyLoadChecker()); err != nil {
+		log.Fatal("Failed to register lazy-load checker:", err)
+	}
+	Queries = NewQueryMonitor()
+	if err := DB.Use(Queries); err != nil {
+		log.Fatal("Failed to register query monitor:", err)
+	}
+
+	if err := LoadJWTKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+	if JWTPublicKey != nil {
+		log.Println("🔑 Signing tokens with RS256, key", JWTKeyID)
+	}
+
+	// Apply pending schema migrations (see migrations/ and cmd/migrate)
+	applied, err := MigrateUp(DB)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	for _, v := range applied {
+		log.Println("📦 Applied migration", v)
+	}
+
+	// Seed the fixed allergen list
+	for _, name := range models.AllergenNames {
+		DB.Where(models.Allergen{Name: name}).FirstOrCreate(&models.Allergen{})
+	}
+
+	// Seed feature flags; existing rows keep whatever an admin set
+	for _, flag := range models.DefaultFeatureFlags {
+		DB.Where(models.FeatureFlag{Name: flag.Name}).Attrs(flag).FirstOrCreate(&models.FeatureFlag{})
+	}
 
 	log.Println("✅ Database connected and migrated successfully")
 }
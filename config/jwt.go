@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTPrivateKey and JWTPublicKey are set when JWT_PRIVATE_KEY_PATH and
+// JWT_PUBLIC_KEY_PATH point at a PEM RSA key pair. Tokens are then signed with
+// RS256; otherwise they are signed with HS256 and JWTSecret.
+var (
+	JWTPrivateKey *rsa.PrivateKey
+	JWTPublicKey  *rsa.PublicKey
+	// JWTKeyID identifies JWTPublicKey in token headers and the JWKS
+	JWTKeyID string
+	// JWTHS256AcceptUntil is how long HS256 tokens are still accepted once
+	// RS256 is configured, read from JWT_HS256_ACCEPT_UNTIL (RFC 3339). Unset
+	// rejects them straight away.
+	JWTHS256AcceptUntil time.Time
+)
+
+// maxHS256Transition bounds JWT_HS256_ACCEPT_UNTIL: a window longer than a
+// week is a forgotten setting, not a key rollover
+const maxHS256Transition = 7 * 24 * time.Hour
+
+// AcceptHS256 reports whether HS256 tokens are valid at now: always without
+// RS256 keys, and only inside the transition window with them
+func AcceptHS256(now time.Time) bool {
+	return JWTPublicKey == nil || now.Before(JWTHS256AcceptUntil)
+}
+
+// LoadJWTKeys reads the RSA key pair named by the environment. Setting only
+// one of the two paths is an error rather than a silent fallback to HS256, and
+// so is relying on DefaultJWTSecret in release mode.
+func LoadJWTKeys() error {
+	if err := loadRSAKeys(); err != nil {
+		return err
+	}
+	if s := os.Getenv("JWT_HS256_ACCEPT_UNTIL"); s != "" {
+		until, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("JWT_HS256_ACCEPT_UNTIL: %w", err)
+		}
+		if until.After(time.Now().Add(maxHS256Transition)) {
+			return fmt.Errorf("JWT_HS256_ACCEPT_UNTIL is more than %s away", maxHS256Transition)
+		}
+		JWTHS256AcceptUntil = until
+	}
+	if string(JWTSecret) == DefaultJWTSecret && AcceptHS256(time.Now()) && os.Getenv("GIN_MODE") == gin.ReleaseMode {
+		return fmt.Errorf("JWT_SECRET is unset; the default secret can't sign or verify tokens in release mode")
+	}
+	return nil
+}
+
+func loadRSAKeys() error {
+	privPath, pubPath := os.Getenv("JWT_PRIVATE_KEY_PATH"), os.Getenv("JWT_PUBLIC_KEY_PATH")
+	if privPath == "" && pubPath == "" {
+		return nil
+	}
+	if privPath == "" || pubPath == "" {
+		return fmt.Errorf("JWT_PRIVATE_KEY_PATH and JWT_PUBLIC_KEY_PATH must be set together")
+	}
+	privPEM, err := os.ReadFile(privPath)
+	if err != nil {
+		return err
+	}
+	priv, err := jwt.ParseRSAPrivateKeyFromPEM(privPEM)
+	if err != nil {
+		return fmt.Errorf("%s: %w", privPath, err)
+	}
+	pubPEM, err := os.ReadFile(pubPath)
+	if err != nil {
+		return err
+	}
+	pub, err := jwt.ParseRSAPublicKeyFromPEM(pubPEM)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pubPath, err)
+	}
+	if !priv.PublicKey.Equal(pub) {
+		return fmt.Errorf("%s is not the public key of %s", pubPath, privPath)
+	}
+	JWTPrivateKey, JWTPublicKey = priv, pub
+	// Stable across restarts, changes only when the key does
+	sum := sha256.Sum256(pub.N.Bytes())
+	JWTKeyID = base64.RawURLEncoding.EncodeToString(sum[:12])
+	return nil
+}
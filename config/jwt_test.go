@@ -0,0 +1,113 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeKeyPair writes a fresh RSA key pair as PEM files and points the
+// JWT_*_KEY_PATH variables at them
+func writeKeyPair(t *testing.T) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	priv := filepath.Join(dir, "jwt.key")
+	pub := filepath.Join(dir, "jwt.pub")
+	if err := os.WriteFile(priv, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pub, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_PRIVATE_KEY_PATH", priv)
+	t.Setenv("JWT_PUBLIC_KEY_PATH", pub)
+}
+
+// resetJWT restores the package's key state when the test ends
+func resetJWT(t *testing.T) {
+	t.Helper()
+	secret, priv, pub, kid, until := JWTSecret, JWTPrivateKey, JWTPublicKey, JWTKeyID, JWTHS256AcceptUntil
+	t.Cleanup(func() {
+		JWTSecret, JWTPrivateKey, JWTPublicKey, JWTKeyID, JWTHS256AcceptUntil = secret, priv, pub, kid, until
+	})
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	soon := time.Now().Add(time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name    string
+		rsa     bool
+		secret  string
+		mode    string
+		until   string
+		wantErr string
+		// wantHS256 is whether HS256 tokens are accepted after loading
+		wantHS256 bool
+	}{
+		{"default secret in debug mode", false, DefaultJWTSecret, "debug", "", "", true},
+		{"default secret in release mode", false, DefaultJWTSecret, "release", "", "JWT_SECRET is unset", true},
+		{"own secret in release mode", false, "s3cret", "release", "", "", true},
+		{"rsa rejects hs256", true, DefaultJWTSecret, "release", "", "", false},
+		{"rsa with transition window", true, "s3cret", "release", soon, "", true},
+		{"transition window with default secret", true, DefaultJWTSecret, "release", soon, "JWT_SECRET is unset", true},
+		{"transition window too long", true, "s3cret", "release", time.Now().Add(30 * 24 * time.Hour).Format(time.RFC3339), "more than", false},
+		{"transition window not a time", true, "s3cret", "release", "tomorrow", "JWT_HS256_ACCEPT_UNTIL", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetJWT(t)
+			JWTSecret, JWTPrivateKey, JWTPublicKey, JWTHS256AcceptUntil = []byte(tt.secret), nil, nil, time.Time{}
+			t.Setenv("JWT_PRIVATE_KEY_PATH", "")
+			t.Setenv("JWT_PUBLIC_KEY_PATH", "")
+			if tt.rsa {
+				writeKeyPair(t)
+			}
+			t.Setenv("GIN_MODE", tt.mode)
+			t.Setenv("JWT_HS256_ACCEPT_UNTIL", tt.until)
+
+			err := LoadJWTKeys()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadJWTKeys() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadJWTKeys() = %v", err)
+			}
+			if got := AcceptHS256(time.Now()); got != tt.wantHS256 {
+				t.Errorf("AcceptHS256 = %v, want %v", got, tt.wantHS256)
+			}
+		})
+	}
+}
+
+func TestAcceptHS256EndsWithTransitionWindow(t *testing.T) {
+	resetJWT(t)
+	writeKeyPair(t)
+	t.Setenv("JWT_HS256_ACCEPT_UNTIL", time.Now().Add(time.Hour).Format(time.RFC3339))
+	t.Setenv("GIN_MODE", "debug")
+	if err := LoadJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+	if !AcceptHS256(time.Now()) {
+		t.Error("HS256 rejected inside the transition window")
+	}
+	if AcceptHS256(time.Now().Add(2 * time.Hour)) {
+		t.Error("HS256 accepted after the transition window")
+	}
+}
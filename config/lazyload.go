@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"food-delivery-api/apierror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultLazyLoadThreshold applies when LAZY_LOAD_THRESHOLD is unset
+const DefaultLazyLoadThreshold = 10
+
+// LazyLoadChecker is a GORM plugin that catches N+1 queries. GORM never loads
+// associations on access, so forgetting a Preload shows up as a handler
+// fetching the relation itself, one row at a time. Within a traced request
+// (see WithQueryTrace) the checker counts identical queries against a table
+// that is a relation of a model the request already loaded; once Threshold is
+// reached it logs a warning naming the relation and the handler. Strict also
+// panics with a 500 so the regression can't be missed; tests turn it on.
+// Queries run with a WithBatchQueries context are not counted.
+type LazyLoadChecker struct {
+	Threshold int
+	Strict    bool
+}
+
+// NewLazyLoadChecker reads the threshold from LAZY_LOAD_THRESHOLD and strict
+// mode from LAZY_LOAD_STRICT
+func NewLazyLoadChecker() *LazyLoadChecker {
+	threshold := DefaultLazyLoadThreshold
+	if s := os.Getenv("LAZY_LOAD_THRESHOLD"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 2 {
+			log.Printf("⚠️  LAZY_LOAD_THRESHOLD=%q is not a number of at least 2, using %d", s, threshold)
+		} else {
+			threshold = n
+		}
+	}
+	return &LazyLoadChecker{Threshold: threshold, Strict: os.Getenv("LAZY_LOAD_STRICT") == "true"}
+}
+
+func (*LazyLoadChecker) Name() string {
+	return "lazy_load_checker"
+}
+
+func (p *LazyLoadChecker) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().After("gorm:query").Register("lazy_load_checker:query", p.afterQuery)
+}
+
+type queryTraceKey struct{}
+
+type batchQueriesKey struct{}
+
+// WithBatchQueries returns a context whose queries the LazyLoadChecker
+// ignores. Use it for work done in batches, e.g. with FindInBatches, where
+// running the same query once per batch is the point.
+func WithBatchQueries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchQueriesKey{}, true)
+}
+
+// queryTrace is what one request has queried so far
+type queryTrace struct {
+	mu        sync.Mutex
+	requestID string
+	handler   string
+	loaded    []*schema.Schema
+	counts    map[string]int
+	reported  map[string]bool
+}
+
+// WithQueryTrace returns a context whose queries the LazyLoadChecker watches.
+// requestID and handler only label the warnings.
+func WithQueryTrace(ctx context.Context, requestID, handler string) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, &queryTrace{
+		requestID: requestID,
+		handler:   handler,
+		counts:    map[string]int{},
+		reported:  map[string]bool{},
+	})
+}
+
+func (p *LazyLoadChecker) afterQuery(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.Context == nil {
+		return
+	}
+	trace, ok := stmt.Context.Value(queryTraceKey{}).(*queryTrace)
+	if !ok || stmt.Context.Value(batchQueriesKey{}) != nil {
+		return
+	}
+
+	trace.mu.Lock()
+	var relations []string
+	for _, parent := range trace.loaded {
+		relations = append(relations, relationsTo(parent, stmt.Schema.Table)...)
+	}
+	seen := false
+	for _, s := range trace.loaded {
+		seen = seen || s == stmt.Schema
+	}
+	if !seen {
+		trace.loaded = append(trace.loaded, stmt.Schema)
+	}
+	if len(relations) == 0 {
+		trace.mu.Unlock()
+		return
+	}
+	sql := stmt.SQL.String()
+	trace.counts[sql]++
+	detected := trace.counts[sql] >= p.Threshold && !trace.reported[sql]
+	if detected {
+		trace.reported[sql] = true
+	}
+	count := trace.counts[sql]
+	trace.mu.Unlock()
+
+	if !detected {
+		return
+	}
+	loaded := strings.Join(relations, " or ")
+	log.Printf("⚠️  N+1 query detected: %s loaded lazily in %s (request %s): %d identical queries, add a Preload", loaded, trace.handler, trace.requestID, count)
+	if p.Strict {
+		panic(apierror.New(http.StatusInternalServerError, apierror.ErrInternal, "errors.n_plus_one_query", nil, loaded))
+	}
+}
+
+// relationsTo names parent's associations stored in table, in declaration order
+func relationsTo(parent *schema.Schema, table string) []string {
+	var names []string
+	rels := &parent.Relationships
+	for _, group := range [][]*schema.Relationship{rels.BelongsTo, rels.HasOne, rels.HasMany, rels.Many2Many} {
+		for _, rel := range group {
+			if rel.FieldSchema != nil && rel.FieldSchema.Table == table {
+				names = append(names, parent.Name+"."+rel.Name)
+			}
+		}
+	}
+	return names
+}
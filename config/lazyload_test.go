@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// lazyLoadDB opens an in-memory database with the checker installed and a
+// dozen orders, each from its own customer
+func lazyLoadDB(t *testing.T, strict bool) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 12; i++ {
+		customer := models.User{Name: "Customer", Email: fmt.Sprintf("c%d@example.com", i), PasswordHash: "x"}
+		db.Create(&customer)
+		db.Create(&models.Order{InvoiceNumber: fmt.Sprint(i), CustomerID: customer.ID, RestaurantID: 1, DeliveryAddress: "a"})
+	}
+	if err := db.Use(&LazyLoadChecker{Threshold: DefaultLazyLoadThreshold, Strict: strict}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// loadCustomers fetches each order's customer, with a Preload or one by one
+func loadCustomers(db *gorm.DB, preload bool) {
+	db = db.WithContext(WithQueryTrace(context.Background(), "req-1", "handlers.GetOrders"))
+	var orders []models.Order
+	if preload {
+		db.Preload("Customer").Find(&orders)
+		return
+	}
+	db.Find(&orders)
+	for i := range orders {
+		db.First(&orders[i].Customer, orders[i].CustomerID)
+	}
+}
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	return &buf
+}
+
+func TestLazyLoadCheckerWarnsOnLazyRelation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := lazyLoadDB(t, false)
+	logs := captureLog(t)
+
+	loadCustomers(db, false)
+
+	got := logs.String()
+	if strings.Count(got, "N+1 query detected") != 1 {
+		t.Fatalf("want exactly one warning, log:\n%s", got)
+	}
+	if !strings.Contains(got, "Order.Customer") || !strings.Contains(got, "handlers.GetOrders") {
+		t.Errorf("warning doesn't name the relation and handler:\n%s", got)
+	}
+}
+
+func TestLazyLoadCheckerAcceptsPreload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := lazyLoadDB(t, false)
+	logs := captureLog(t)
+
+	loadCustomers(db, true)
+
+	if strings.Contains(logs.String(), "N+1 query detected") {
+		t.Errorf("preloaded query reported as lazy:\n%s", logs.String())
+	}
+}
+
+func TestLazyLoadCheckerOnlyWarnsInDebugMode(t *testing.T) {
+	gin.SetMode(gin.DebugMode)
+	defer gin.SetMode(gin.TestMode)
+	db := lazyLoadDB(t, false)
+	logs := captureLog(t)
+
+	loadCustomers(db, false)
+
+	if !strings.Contains(logs.String(), "N+1 query detected") {
+		t.Errorf("no warning logged:\n%s", logs.String())
+	}
+}
+
+func TestLazyLoadCheckerPanicsWhenStrict(t *testing.T) {
+	db := lazyLoadDB(t, true)
+	captureLog(t)
+
+	defer func() {
+		apiErr, ok := recover().(*apierror.Error)
+		if !ok || apiErr.Status != http.StatusInternalServerError {
+			t.Fatalf("recovered %v, want a 500 *apierror.Error", apiErr)
+		}
+		if msg := apiErr.Error(); msg != "N+1 query detected: Order.Customer or Order.Driver loaded lazily" {
+			t.Errorf("message = %q", msg)
+		}
+	}()
+	loadCustomers(db, false)
+}
+
+func TestLazyLoadCheckerIgnoresBatchQueries(t *testing.T) {
+	db := lazyLoadDB(t, true)
+	logs := captureLog(t)
+
+	ctx := WithBatchQueries(WithQueryTrace(context.Background(), "req-1", "handlers.Recalculate"))
+	db = db.WithContext(ctx)
+	var orders []models.Order
+	err := db.FindInBatches(&orders, 1, func(_ *gorm.DB, _ int) error {
+		var customer models.User
+		return db.First(&customer, orders[0].CustomerID).Error
+	}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(logs.String(), "N+1 query detected") {
+		t.Errorf("batch queries reported as lazy:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"food-delivery-api/migrations"
+
+	"gorm.io/gorm"
+)
+
+// BaselineVersion is the migration holding the schema AutoMigrate used to build
+const BaselineVersion = "0_baseline"
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// MigrationState is a migration and whether it has been applied
+type MigrationState struct {
+	Version   string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// LoadMigrations reads every <version>.up.sql / .down.sql pair, sorted by version
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[string]*Migration{}
+	for _, name := range files {
+		var version, direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			version, direction = strings.TrimSuffix(name, ".up.sql"), "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			version, direction = strings.TrimSuffix(name, ".down.sql"), "down"
+		default:
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", name)
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s: both .up.sql and .down.sql are required", m.Version)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// prepareMigrations creates schema_migrations. A database built by AutoMigrate
+// (tables present, no schema_migrations) is marked as already at the baseline.
+func prepareMigrations(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasTable(&SchemaMigration{}) {
+		return nil
+	}
+	legacy := migrator.HasTable("users")
+	if err := migrator.CreateTable(&SchemaMigration{}); err != nil {
+		return err
+	}
+	if legacy {
+		return db.Create(&SchemaMigration{Version: BaselineVersion, AppliedAt: time.Now()}).Error
+	}
+	return nil
+}
+
+func appliedVersions(db *gorm.DB) (map[string]time.Time, error) {
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r.AppliedAt
+	}
+	return applied, nil
+}
+
+// execScript runs each ;-terminated statement of a migration file
+func execScript(tx *gorm.DB, script string) error {
+	for _, stmt := range strings.Split(script, ";") {
+		if isBlankSQL(stmt) {
+			continue
+		}
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isBlankSQL reports whether stmt holds only whitespace and -- comments
+func isBlankSQL(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// MigrateUp applies every pending migration in order, each in its own transaction
+func MigrateUp(db *gorm.DB) ([]string, error) {
+	list, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareMigrations(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, m := range list {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, m.Up); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s: %w", m.Version, err)
+		}
+		done = append(done, m.Version)
+	}
+	return done, nil
+}
+
+// MigrateDown rolls back the most recent n applied migrations
+func MigrateDown(db *gorm.DB, n int) ([]string, error) {
+	list, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareMigrations(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for i := len(list) - 1; i >= 0 && len(done) < n; i-- {
+		m := list[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, m.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback %s: %w", m.Version, err)
+		}
+		done = append(done, m.Version)
+	}
+	return done, nil
+}
+
+// MigrationStatus lists every known migration and when it was applied
+func MigrationStatus(db *gorm.DB) ([]MigrationState, error) {
+	list, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareMigrations(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]MigrationState, len(list))
+	for i, m := range list {
+		states[i] = MigrationState{Version: m.Version}
+		if at, ok := applied[m.Version]; ok {
+			at := at
+			states[i].Applied = true
+			states[i].AppliedAt = &at
+		}
+	}
+	return states, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func migratedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// columnDefault is the default of table.column as SQLite stores it
+func columnDefault(t *testing.T, db *gorm.DB, table, column string) string {
+	t.Helper()
+	var def *string
+	if err := db.Raw("SELECT dflt_value FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&def).Error; err != nil || def == nil {
+		t.Fatalf("%s.%s: no default (err %v)", table, column, err)
+	}
+	return *def
+}
+
+var requotedColumns = []struct{ table, column, def string }{
+	{"orders", "payment_method", "'prepaid'"},
+	{"driver_documents", "status", "'pending'"},
+	{"restaurants", "currency", "'USD'"},
+	{"orders", "currency", "'USD'"},
+	{"orders", "payment_status", "'pending'"},
+	{"webhook_delivery_logs", "status", "'pending'"},
+	{"status_labels", "locale", "'en'"},
+}
+
+func TestTextDefaultsAreSingleQuoted(t *testing.T) {
+	db := migratedDB(t)
+	for _, c := range requotedColumns {
+		if got := columnDefault(t, db, c.table, c.column); got != c.def {
+			t.Errorf("%s.%s default = %s, want %s", c.table, c.column, got, c.def)
+		}
+	}
+}
+
+func TestRequotingKeepsDataAndIndexes(t *testing.T) {
+	db := migratedDB(t)
+	if _, err := MigrateDown(db, 2); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("INSERT INTO status_labels (status, locale, display_label) VALUES ('PLACED', 'es', 'Recibido')")
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var label string
+	db.Raw("SELECT display_label FROM status_labels WHERE status = 'PLACED' AND locale = 'es'").Scan(&label)
+	if label != "Recibido" {
+		t.Errorf("label after requoting = %q, want Recibido", label)
+	}
+	if err := db.Exec("INSERT INTO status_labels (status, locale, display_label) VALUES ('PLACED', 'es', 'Otro')").Error; err == nil {
+		t.Error("unique (status, locale) index lost")
+	}
+	if err := db.Exec("INSERT INTO status_labels (status, display_label) VALUES ('PLACED', 'Placed')").Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Raw("SELECT locale FROM status_labels WHERE display_label = 'Placed'").Scan(&label)
+	if label != "en" {
+		t.Errorf("default locale = %q, want en", label)
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultSlowQueryThreshold applies when DB_SLOW_QUERY_THRESHOLD_MS is unset
+const DefaultSlowQueryThreshold = time.Second
+
+// Queries is the monitor registered on DB by InitDB
+var Queries *QueryMonitor
+
+// QueryMonitor is a GORM plugin that keeps every in-flight statement in a
+// registry so an admin can see what is running and cancel it. Each statement
+// gets a UUID and a cancellable context; cancelling aborts the SQL. Statements
+// slower than SlowThreshold are logged with their full SQL.
+//
+// The SQLite driver interrupts writes as soon as their context is cancelled.
+// It steps a SELECT only after it has stopped watching the context, so a
+// cancelled SELECT runs until SQLite yields its next row and the request then
+// fails with context.Canceled. Row, Rows and Scan hand back open rows, and
+// SQLite does much of the work while they are read, after the statement has
+// left the registry. Those statements are listed only until their rows are
+// opened.
+type QueryMonitor struct {
+	SlowThreshold time.Duration
+	queries       sync.Map // query ID -> *activeQuery
+}
+
+// ActiveQuery is one in-flight statement as reported to admins
+type ActiveQuery struct {
+	ID        string    `json:"id"`
+	SQL       string    `json:"sql"` // empty until the statement reaches the database
+	StartedAt time.Time `json:"started_at"`
+	RunningMs int64     `json:"running_ms"`
+}
+
+type activeQuery struct {
+	id        string
+	startedAt time.Time
+	cancel    context.CancelFunc
+	ctx       context.Context
+	pool      gorm.ConnPool
+
+	mu   sync.Mutex
+	sql  string
+	vars []interface{}
+}
+
+func (q *activeQuery) setSQL(query string, args []interface{}) {
+	q.mu.Lock()
+	q.sql, q.vars = query, args
+	q.mu.Unlock()
+}
+
+func (q *activeQuery) statement() (string, []interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.sql, q.vars
+}
+
+// NewQueryMonitor reads the slow query threshold from DB_SLOW_QUERY_THRESHOLD_MS
+func NewQueryMonitor() *QueryMonitor {
+	threshold := DefaultSlowQueryThreshold
+	if s := os.Getenv("DB_SLOW_QUERY_THRESHOLD_MS"); s != "" {
+		ms, err := strconv.Atoi(s)
+		if err != nil || ms < 1 {
+			log.Printf("⚠️  DB_SLOW_QUERY_THRESHOLD_MS=%q is not a positive number, using %s", s, threshold)
+		} else {
+			threshold = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return &QueryMonitor{SlowThreshold: threshold}
+}
+
+func (*QueryMonitor) Name() string {
+	return "query_monitor"
+}
+
+type queryIDKey struct{}
+
+// QueryID returns the monitor's ID for the statement running with ctx
+func QueryID(ctx context.Context) string {
+	id, _ := ctx.Value(queryIDKey{}).(string)
+	return id
+}
+
+// Initialize wraps the callback that runs the SQL of every processor, so a
+// statement is registered only while it is at the database. Wrapping that
+// callback in place keeps the monitor inside the default transaction, which
+// gorm begins and commits through the statement's pool, swapped while the
+// statement is monitored. The statement is unregistered in a defer, so SQL
+// that panics doesn't leave it in the registry.
+func (m *QueryMonitor) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		m.wrap(cb.Create(), "gorm:create", false),
+		m.wrap(cb.Query(), "gorm:query", false),
+		m.wrap(cb.Update(), "gorm:update", false),
+		m.wrap(cb.Delete(), "gorm:delete", false),
+		m.wrap(cb.Row(), "gorm:row", true),
+		m.wrap(cb.Raw(), "gorm:raw", false),
+	)
+}
+
+// callbackProcessor is the part of a gorm callback processor wrap uses
+type callbackProcessor interface {
+	Get(name string) func(*gorm.DB)
+	Replace(name string, fn func(*gorm.DB)) error
+}
+
+// wrap replaces the named callback with one that monitors it
+func (m *QueryMonitor) wrap(p callbackProcessor, name string, rows bool) error {
+	run := p.Get(name)
+	if run == nil {
+		return fmt.Errorf("query monitor: no %s callback to wrap", name)
+	}
+	after := m.after(rows)
+	return p.Replace(name, func(db *gorm.DB) {
+		if m.before(db) {
+			defer after(db)
+		}
+		run(db)
+	})
+}
+
+// before registers the statement and reports whether it did
+func (m *QueryMonitor) before(db *gorm.DB) bool {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Context == nil {
+		return false
+	}
+	id := uuid.NewString()
+	ctx, cancel := context.WithCancel(context.WithValue(stmt.Context, queryIDKey{}, id))
+	q := &activeQuery{id: id, startedAt: time.Now(), cancel: cancel, ctx: stmt.Context, pool: stmt.ConnPool}
+	m.queries.Store(id, q)
+	stmt.Context = ctx
+	stmt.ConnPool = &monitoredPool{ConnPool: stmt.ConnPool, query: q}
+	return true
+}
+
+// after unregisters the statement and restores its context and pool, which a
+// reused statement would otherwise carry into its next operation. Open rows
+// still need their context, so it is only cancelled when rows is false.
+func (m *QueryMonitor) after(rows bool) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if stmt.Context == nil {
+			return
+		}
+		v, ok := m.queries.LoadAndDelete(QueryID(stmt.Context))
+		if !ok {
+			return
+		}
+		q := v.(*activeQuery)
+		stmt.Context, stmt.ConnPool = q.ctx, q.pool
+		if !rows {
+			q.cancel()
+		}
+
+		elapsed := time.Since(q.startedAt)
+		if elapsed < m.SlowThreshold {
+			return
+		}
+		query, vars := q.statement()
+		if query == "" {
+			return
+		}
+		log.Printf("⚠️  Slow query %s took %dms (threshold %dms): %s",
+			q.id, elapsed.Milliseconds(), m.SlowThreshold.Milliseconds(), db.Dialector.Explain(query, vars...))
+	}
+}
+
+// Active lists the statements that have been running for at least minAge, longest first
+func (m *QueryMonitor) Active(minAge time.Duration) []ActiveQuery {
+	now := time.Now()
+	active := []ActiveQuery{}
+	m.queries.Range(func(_, v any) bool {
+		q := v.(*activeQuery)
+		if running := now.Sub(q.startedAt); running >= minAge {
+			query, _ := q.statement()
+			active = append(active, ActiveQuery{ID: q.id, SQL: query, StartedAt: q.startedAt, RunningMs: running.Milliseconds()})
+		}
+		return true
+	})
+	sort.Slice(active, func(i, j int) bool { return active[i].StartedAt.Before(active[j].StartedAt) })
+	return active
+}
+
+// Cancel aborts an in-flight statement. It reports false when no statement
+// with that ID is running.
+func (m *QueryMonitor) Cancel(id string) bool {
+	v, ok := m.queries.Load(id)
+	if !ok {
+		return false
+	}
+	v.(*activeQuery).cancel()
+	return true
+}
+
+// monitoredPool records the SQL a monitored statement sends to the database
+type monitoredPool struct {
+	gorm.ConnPool
+	query *activeQuery
+}
+
+func (p *monitoredPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	p.query.setSQL(query, nil)
+	return p.ConnPool.PrepareContext(ctx, query)
+}
+
+func (p *monitoredPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.query.setSQL(query, args)
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p *monitoredPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	p.query.setSQL(query, args)
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (p *monitoredPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.query.setSQL(query, args)
+	return p.ConnPool.QueryRowContext(ctx, query, args...)
+}
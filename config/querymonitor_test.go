@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Returns rows forever; only a cancellation ends the read
+const endlessQuery = "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT COUNT(*) FROM n"
+
+// monitoredDB opens an in-memory database with a query monitor installed
+func monitoredDB(t *testing.T, threshold time.Duration) (*gorm.DB, *QueryMonitor) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.Allergen{}); err != nil {
+		t.Fatal(err)
+	}
+	m := &QueryMonitor{SlowThreshold: threshold}
+	if err := db.Use(m); err != nil {
+		t.Fatal(err)
+	}
+	return db, m
+}
+
+// waitForActive polls until one statement is running and returns it
+func waitForActive(t *testing.T, m *QueryMonitor) ActiveQuery {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if active := m.Active(0); len(active) == 1 && active[0].SQL != "" {
+			return active[0]
+		}
+	}
+	t.Fatalf("no statement showed up as active: %+v", m.Active(0))
+	return ActiveQuery{}
+}
+
+func TestQueryMonitorCancelsRunningStatement(t *testing.T) {
+	db, m := monitoredDB(t, time.Hour)
+	done := make(chan error, 1)
+	// Through Exec, so the statement stays registered while SQLite works
+	go func() { done <- db.Exec(endlessQuery).Error }()
+
+	q := waitForActive(t, m)
+	if !strings.Contains(q.SQL, "WITH RECURSIVE") {
+		t.Errorf("active SQL = %q, want the running statement", q.SQL)
+	}
+	if !m.Cancel(q.ID) {
+		t.Fatalf("Cancel(%s) = false for a running statement", q.ID)
+	}
+	select {
+	case err := <-done:
+		// SQLite reports the interrupt rather than context.Canceled for writes
+		if err == nil {
+			t.Error("a cancelled statement succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("statement still running 5s after it was cancelled")
+	}
+	if m.Cancel(q.ID) {
+		t.Error("Cancel succeeded for a statement that already finished")
+	}
+}
+
+func TestQueryMonitorForgetsFinishedStatements(t *testing.T) {
+	db, m := monitoredDB(t, time.Hour)
+	db.Create(&models.Allergen{Name: "nuts"})
+	var allergens []models.Allergen
+	db.Find(&allergens)
+	db.Model(&models.Allergen{}).Where("name = ?", "nuts").Update("name", "peanuts")
+	var count int64
+	db.Raw("SELECT COUNT(*) FROM allergens").Scan(&count)
+	db.Where("name = ?", "peanuts").Delete(&models.Allergen{})
+
+	if active := m.Active(0); len(active) != 0 {
+		t.Errorf("%d statement(s) still registered after they finished: %+v", len(active), active)
+	}
+}
+
+// panickingPool fails every query the hard way
+type panickingPool struct {
+	gorm.ConnPool
+}
+
+func (panickingPool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	panic("driver exploded")
+}
+
+func TestQueryMonitorForgetsPanickedStatements(t *testing.T) {
+	db, m := monitoredDB(t, time.Hour)
+	tx := db.Session(&gorm.Session{NewDB: true})
+	tx.Statement.ConnPool = panickingPool{ConnPool: db.ConnPool}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the query didn't panic")
+			}
+		}()
+		var allergens []models.Allergen
+		tx.Find(&allergens)
+	}()
+	if active := m.Active(0); len(active) != 0 {
+		t.Errorf("a panicked statement is still registered: %+v", active)
+	}
+}
+
+func TestQueryMonitorLogsSlowStatements(t *testing.T) {
+	logs := captureLog(t)
+	db, _ := monitoredDB(t, time.Nanosecond)
+	var allergens []models.Allergen
+	db.Where("name = ?", "sesame").Find(&allergens)
+
+	if !strings.Contains(logs.String(), "Slow query") || !strings.Contains(logs.String(), `name = "sesame"`) {
+		t.Errorf("log = %q, want the slow query with its arguments", logs.String())
+	}
+}
+
+func TestNewQueryMonitorThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		env  string
+		want time.Duration
+	}{
+		{"", DefaultSlowQueryThreshold},
+		{"250", 250 * time.Millisecond},
+		{"0", DefaultSlowQueryThreshold},
+		{"fast", DefaultSlowQueryThreshold},
+	} {
+		t.Setenv("DB_SLOW_QUERY_THRESHOLD_MS", tc.env)
+		if got := NewQueryMonitor().SlowThreshold; got != tc.want {
+			t.Errorf("DB_SLOW_QUERY_THRESHOLD_MS=%q: threshold %s, want %s", tc.env, got, tc.want)
+		}
+	}
+}
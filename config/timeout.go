@@ -0,0 +1,33 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHandlerTimeout applies when HANDLER_TIMEOUT_SECONDS is unset
+const DefaultHandlerTimeout = 10 * time.Second
+
+// HandlerTimeout is how long a request in the given route group may run.
+// HANDLER_TIMEOUT_SECONDS sets the default for every group, and
+// HANDLER_TIMEOUT_SECONDS_<GROUP> (e.g. HANDLER_TIMEOUT_SECONDS_ADMIN)
+// overrides it for one group.
+func HandlerTimeout(group string) time.Duration {
+	d := DefaultHandlerTimeout
+	for _, key := range []string{"HANDLER_TIMEOUT_SECONDS", "HANDLER_TIMEOUT_SECONDS_" + strings.ToUpper(group)} {
+		s := os.Getenv(key)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			log.Printf("⚠️  %s=%q is not a positive number of seconds, ignoring", key, s)
+			continue
+		}
+		d = time.Duration(n) * time.Second
+	}
+	return d
+}
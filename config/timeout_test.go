@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHandlerTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		global, admin string
+		// wantAdmin is the admin group's timeout; wantOther any other group's
+		wantAdmin, wantOther time.Duration
+	}{
+		{"unset", "", "", DefaultHandlerTimeout, DefaultHandlerTimeout},
+		{"global", "30", "", 30 * time.Second, 30 * time.Second},
+		{"group override", "30", "120", 120 * time.Second, 30 * time.Second},
+		{"invalid global", "soon", "", DefaultHandlerTimeout, DefaultHandlerTimeout},
+		{"invalid override", "30", "-5", 30 * time.Second, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HANDLER_TIMEOUT_SECONDS", tt.global)
+			t.Setenv("HANDLER_TIMEOUT_SECONDS_ADMIN", tt.admin)
+			if got := HandlerTimeout("admin"); got != tt.wantAdmin {
+				t.Errorf("HandlerTimeout(admin) = %v, want %v", got, tt.wantAdmin)
+			}
+			if got := HandlerTimeout("customer"); got != tt.wantOther {
+				t.Errorf("HandlerTimeout(customer) = %v, want %v", got, tt.wantOther)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package currency converts order amounts between a restaurant's currency and
+// the platform's base currency.
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// Converter turns an amount in one ISO 4217 currency into another, returning
+// the converted amount and the rate used (units of to per unit of from)
+type Converter interface {
+	Convert(amount float64, from, to string) (converted float64, rate float64, err error)
+}
+
+// NoopConverter treats every currency as equal
+type NoopConverter struct{}
+
+func (NoopConverter) Convert(amount float64, _, _ string) (float64, float64, error) {
+	return amount, 1, nil
+}
+
+// FixedConverter converts with static rates, each the value of one unit of a
+// common reference currency, e.g. {"USD": 1, "EUR": 0.92, "INR": 83.2}
+type FixedConverter struct {
+	Rates map[string]float64
+}
+
+// LoadFixed reads FixedConverter rates from a JSON file
+func LoadFixed(path string) (FixedConverter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FixedConverter{}, err
+	}
+	var rates map[string]float64
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return FixedConverter{}, fmt.Errorf("%s: %w", path, err)
+	}
+	fixed := FixedConverter{Rates: make(map[string]float64, len(rates))}
+	for code, rate := range rates {
+		if rate <= 0 {
+			return FixedConverter{}, fmt.Errorf("%s: rate for %s must be positive", path, code)
+		}
+		fixed.Rates[strings.ToUpper(code)] = rate
+	}
+	return fixed, nil
+}
+
+func (f FixedConverter) Convert(amount float64, from, to string) (float64, float64, error) {
+	if from == to {
+		return amount, 1, nil
+	}
+	fromRate, ok := f.Rates[from]
+	if !ok {
+		return 0, 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := f.Rates[to]
+	if !ok {
+		return 0, 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	rate := toRate / fromRate
+	return math.Round(amount*rate*100) / 100, rate, nil
+}
+
+// Default is the converter used across the application
+var Default Converter = NoopConverter{}
+
+// ValidCode reports whether code looks like an ISO 4217 code, e.g. "USD"
+func ValidCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+// Package eventbus is a small in-process publish/subscribe bus that keeps the
+// side effects of order events out of the handlers that cause them.
+package eventbus
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"food-delivery-api/models"
+)
+
+// Order events
+const (
+	OrderPlaced        = "order.placed"
+	OrderStatusChanged = "order.status_changed"
+	OrderDelivered     = "order.delivered"
+)
+
+// Menu events
+const (
+	ItemEightySixed = "item.eightysixed"
+)
+
+// Alerts for admins
+const (
+	AlertDriverOutOfZone   = "alert.driver_out_of_zone"
+	AlertNoDriverAvailable = "alert.no_driver_available"
+)
+
+// DriverOutOfZoneEvent is published once a delivery has collected enough
+// geofence violations; Violation is the latest one
+type DriverOutOfZoneEvent struct {
+	Violation  models.GeofenceViolation
+	Violations int64
+}
+
+// NoDriverAvailableEvent is published when auto-assignment gives up on an
+// order after Attempts tries
+type NoDriverAvailableEvent struct {
+	Order    models.Order
+	Attempts int
+}
+
+// ItemEightySixedEvent is published when a restaurant takes an item off the
+// menu for the rest of service
+type ItemEightySixedEvent struct {
+	Item   models.MenuItem
+	Reason string
+	At     time.Time
+}
+
+// OrderEvent is the payload of every order event. From is empty for OrderPlaced.
+type OrderEvent struct {
+	Order models.Order
+	From  models.OrderStatus
+	To    models.OrderStatus
+}
+
+// Bus delivers each published event to the functions subscribed to it.
+// Subscribers run one after another before Publish returns, unless Async is
+// set, in which case each one runs in its own goroutine.
+type Bus struct {
+	Async bool
+
+	mu   sync.RWMutex
+	subs map[string][]func(interface{})
+}
+
+func New() *Bus {
+	return &Bus{subs: map[string][]func(interface{}){}}
+}
+
+// Default is the bus shared by the whole application
+var Default = New()
+
+// Subscribe registers fn to be called with the payload of every event of that name
+func (b *Bus) Subscribe(event string, fn func(interface{})) {
+	b.mu.Lock()
+	b.subs[event] = append(b.subs[event], fn)
+	b.mu.Unlock()
+}
+
+// Publish hands payload to every subscriber of event. A subscriber that
+// panics is logged and doesn't stop the others.
+func (b *Bus) Publish(event string, payload interface{}) {
+	b.mu.RLock()
+	subs := b.subs[event]
+	b.mu.RUnlock()
+	for _, fn := range subs {
+		if b.Async {
+			go run(event, fn, payload)
+		} else {
+			run(event, fn, payload)
+		}
+	}
+}
+
+func run(event string, fn func(interface{}), payload interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("eventbus: subscriber to %q panicked: %v", event, r)
+		}
+	}()
+	fn(payload)
+}
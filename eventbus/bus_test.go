@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPublishRunsSubscribersInOrder(t *testing.T) {
+	b := New()
+	var got []string
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		b.Subscribe(OrderPlaced, func(payload interface{}) {
+			got = append(got, name+":"+payload.(string))
+		})
+	}
+	b.Subscribe(OrderDelivered, func(interface{}) { got = append(got, "other event") })
+
+	b.Publish(OrderPlaced, "a")
+	b.Publish(OrderPlaced, "b")
+
+	want := []string{"first:a", "second:a", "third:a", "first:b", "second:b", "third:b"}
+	if len(got) != len(want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	New().Publish(OrderPlaced, nil)
+}
+
+func TestPublishSurvivesAPanickingSubscriber(t *testing.T) {
+	b := New()
+	ran := false
+	b.Subscribe(OrderPlaced, func(interface{}) { panic("boom") })
+	b.Subscribe(OrderPlaced, func(interface{}) { ran = true })
+
+	b.Publish(OrderPlaced, nil)
+
+	if !ran {
+		t.Error("the subscriber after the panicking one didn't run")
+	}
+}
+
+func TestAsyncPublishReturnsBeforeSubscribersFinish(t *testing.T) {
+	b := New()
+	b.Async = true
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for i := 0; i < 2; i++ {
+		b.Subscribe(OrderPlaced, func(interface{}) {
+			defer wg.Done()
+			<-release
+		})
+	}
+
+	returned := make(chan struct{})
+	go func() {
+		b.Publish(OrderPlaced, nil)
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on its subscribers")
+	}
+	close(release)
+	wg.Wait()
+}
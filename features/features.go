@@ -0,0 +1,75 @@
+// Package features answers whether a feature flag is on from an in-memory
+// cache that is reloaded from the database periodically.
+package features
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+)
+
+// RefreshInterval is how often the cache is reloaded from the database
+const RefreshInterval = 30 * time.Second
+
+var (
+	mu      sync.RWMutex
+	enabled = map[string]bool{}
+)
+
+// Refresh reloads every flag into the cache
+func Refresh() error {
+	var flags []models.FeatureFlag
+	if err := config.DB.Find(&flags).Error; err != nil {
+		return err
+	}
+	fresh := make(map[string]bool, len(flags))
+	for _, f := range flags {
+		fresh[f.Name] = f.Enabled
+	}
+	mu.Lock()
+	enabled = fresh
+	mu.Unlock()
+	return nil
+}
+
+// StartRefresher loads the cache and keeps it fresh in the background
+func StartRefresher() {
+	if err := Refresh(); err != nil {
+		log.Printf("features: initial load failed: %v", err)
+	}
+	go func() {
+		for range time.Tick(RefreshInterval) {
+			if err := Refresh(); err != nil {
+				log.Printf("features: refresh failed: %v", err)
+			}
+		}
+	}()
+}
+
+// IsEnabled reports whether the named feature is on. Unknown flags are off.
+func IsEnabled(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enabled[name]
+}
+
+// Set switches a flag and updates this process's cache immediately
+func Set(name string, on bool, updatedBy uint) (models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+	if err := config.DB.Where("name = ?", name).First(&flag).Error; err != nil {
+		return flag, err
+	}
+	err := config.DB.Model(&flag).Updates(map[string]interface{}{"enabled": on, "updated_by": updatedBy}).Error
+	if err != nil {
+		return flag, err
+	}
+	flag.Enabled = on
+	flag.UpdatedBy = &updatedBy
+	mu.Lock()
+	enabled[name] = on
+	mu.Unlock()
+	return flag, nil
+}
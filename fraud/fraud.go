@@ -0,0 +1,79 @@
+// Package fraud holds the rules used to flag suspicious orders. Each rule is a
+// pure function of Facts, so it can be checked without a database.
+package fraud
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	NewAccountWindow      = time.Hour
+	RapidRepeatWindow     = 60 * time.Minute
+	RapidRepeatMaxOrders  = 3
+	AddressWindow         = 7 * 24 * time.Hour
+	AddressMaxOrders      = 10
+	DefaultTotalThreshold = 200.0
+)
+
+// Facts is everything the rules need to know about one order
+type Facts struct {
+	TotalPrice       float64
+	TotalThreshold   float64
+	PlacedAt         time.Time
+	AccountCreatedAt time.Time
+	// Orders by the same customer in the RapidRepeatWindow ending at PlacedAt, this one included
+	CustomerRecentOrders int
+	// Orders to the same address in the AddressWindow ending at PlacedAt, and how many customers placed them
+	AddressOrders    int
+	AddressCustomers int
+}
+
+// Rule reports whether an order is suspicious and why
+type Rule func(Facts) (bool, string)
+
+// Rules is every check run by the suspicious-orders report
+var Rules = []Rule{LargeTotal, NewAccount, RapidRepeat, AddressHotspot}
+
+// LargeTotal flags orders above the configured threshold
+func LargeTotal(f Facts) (bool, string) {
+	if f.TotalPrice > f.TotalThreshold {
+		return true, fmt.Sprintf("total %.2f exceeds %.2f", f.TotalPrice, f.TotalThreshold)
+	}
+	return false, ""
+}
+
+// NewAccount flags orders placed within an hour of the account being created
+func NewAccount(f Facts) (bool, string) {
+	if age := f.PlacedAt.Sub(f.AccountCreatedAt); age < NewAccountWindow {
+		return true, fmt.Sprintf("account was %d minutes old", int(age.Minutes()))
+	}
+	return false, ""
+}
+
+// RapidRepeat flags a customer placing more than three orders within an hour
+func RapidRepeat(f Facts) (bool, string) {
+	if f.CustomerRecentOrders > RapidRepeatMaxOrders {
+		return true, fmt.Sprintf("%d orders by this customer within 60 minutes", f.CustomerRecentOrders)
+	}
+	return false, ""
+}
+
+// AddressHotspot flags an address receiving more than ten orders in a week from different customers
+func AddressHotspot(f Facts) (bool, string) {
+	if f.AddressOrders > AddressMaxOrders && f.AddressCustomers > 1 {
+		return true, fmt.Sprintf("%d orders from %d customers to this address in 7 days", f.AddressOrders, f.AddressCustomers)
+	}
+	return false, ""
+}
+
+// Check runs every rule and returns the reasons for those that fired
+func Check(f Facts) []string {
+	var reasons []string
+	for _, rule := range Rules {
+		if flagged, reason := rule(f); flagged {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,85 @@
+package fraud
+
+import (
+	"testing"
+	"time"
+)
+
+var placedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// facts is an order that trips no rule, for each test to change one thing in
+func facts(change func(*Facts)) Facts {
+	f := Facts{
+		TotalPrice:           50,
+		TotalThreshold:       DefaultTotalThreshold,
+		PlacedAt:             placedAt,
+		AccountCreatedAt:     placedAt.AddDate(0, -1, 0),
+		CustomerRecentOrders: 1,
+		AddressOrders:        1,
+		AddressCustomers:     1,
+	}
+	change(&f)
+	return f
+}
+
+func TestRuleThresholds(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		f    Facts
+		want bool
+	}{
+		{"total below threshold", LargeTotal, facts(func(f *Facts) { f.TotalPrice = 199.99 }), false},
+		{"total at threshold", LargeTotal, facts(func(f *Facts) { f.TotalPrice = 200 }), false},
+		{"total above threshold", LargeTotal, facts(func(f *Facts) { f.TotalPrice = 200.01 }), true},
+		{"total above a custom threshold", LargeTotal, facts(func(f *Facts) { f.TotalPrice, f.TotalThreshold = 60, 50 }), true},
+
+		{"account 59 minutes old", NewAccount, facts(func(f *Facts) { f.AccountCreatedAt = placedAt.Add(-59 * time.Minute) }), true},
+		{"account an hour old", NewAccount, facts(func(f *Facts) { f.AccountCreatedAt = placedAt.Add(-NewAccountWindow) }), false},
+		{"account a day old", NewAccount, facts(func(f *Facts) { f.AccountCreatedAt = placedAt.AddDate(0, 0, -1) }), false},
+
+		{"3 orders in an hour", RapidRepeat, facts(func(f *Facts) { f.CustomerRecentOrders = 3 }), false},
+		{"4 orders in an hour", RapidRepeat, facts(func(f *Facts) { f.CustomerRecentOrders = 4 }), true},
+
+		{"10 orders to an address", AddressHotspot, facts(func(f *Facts) { f.AddressOrders, f.AddressCustomers = 10, 5 }), false},
+		{"11 orders from several customers", AddressHotspot, facts(func(f *Facts) { f.AddressOrders, f.AddressCustomers = 11, 2 }), true},
+		{"11 orders from one customer", AddressHotspot, facts(func(f *Facts) { f.AddressOrders, f.AddressCustomers = 11, 1 }), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagged, reason := tt.rule(tt.f)
+			if flagged != tt.want {
+				t.Errorf("flagged = %v (%q), want %v", flagged, reason, tt.want)
+			}
+			if flagged == (reason == "") {
+				t.Errorf("flagged = %v with reason %q; a reason goes with every flag", flagged, reason)
+			}
+		})
+	}
+}
+
+func TestCheckCollectsEveryReason(t *testing.T) {
+	if reasons := Check(facts(func(*Facts) {})); len(reasons) != 0 {
+		t.Errorf("clean order flagged: %v", reasons)
+	}
+	reasons := Check(facts(func(f *Facts) {
+		f.TotalPrice = 500
+		f.AccountCreatedAt = placedAt.Add(-10 * time.Minute)
+		f.CustomerRecentOrders = 4
+		f.AddressOrders, f.AddressCustomers = 12, 3
+	}))
+	want := []string{
+		"total 500.00 exceeds 200.00",
+		"account was 10 minutes old",
+		"4 orders by this customer within 60 minutes",
+		"12 orders from 3 customers to this address in 7 days",
+	}
+	if len(reasons) != len(want) {
+		t.Fatalf("reasons = %q, want %q", reasons, want)
+	}
+	for i := range want {
+		if reasons[i] != want[i] {
+			t.Errorf("reason %d = %q, want %q", i, reasons[i], want[i])
+		}
+	}
+}
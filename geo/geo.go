@@ -0,0 +1,46 @@
+// Package geo has the small amount of geometry the delivery flow needs.
+package geo
+
+import "math"
+
+// Point is a WGS 84 coordinate
+type Point struct {
+	Lat float64 `json:"lat" binding:"min=-90,max=90"`
+	Lng float64 `json:"lng" binding:"min=-180,max=180"`
+}
+
+// InPolygon reports whether p lies inside the polygon given by its vertices,
+// using ray casting. Coordinates are treated as planar, which is fine at the
+// scale of a city delivery zone.
+func InPolygon(p Point, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// earthRadiusKm is the mean radius used for great-circle distances
+const earthRadiusKm = 6371.0
+
+// DistanceKm is the great-circle (haversine) distance between a and b
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// PathLengthKm sums the haversine distances along a polyline
+func PathLengthKm(path []Point) float64 {
+	var km float64
+	for i := 1; i < len(path); i++ {
+		km += DistanceKm(path[i-1], path[i])
+	}
+	return km
+}
@@ -0,0 +1,107 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoGeocoder is returned when no geocoding provider is configured
+	ErrNoGeocoder = errors.New("no geocoder configured")
+	// ErrAddressNotFound is returned when the provider has no match for an address
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+// Geocoder turns a free-text address into coordinates
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (Point, error)
+}
+
+// Prober is implemented by geocoders that can check their provider is
+// reachable without geocoding anything
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+// NoopGeocoder geocodes nothing
+type NoopGeocoder struct{}
+
+func (NoopGeocoder) Geocode(context.Context, string) (Point, error) {
+	return Point{}, ErrNoGeocoder
+}
+
+// Nominatim geocodes with a Nominatim-compatible search API, e.g.
+// https://nominatim.openstreetmap.org
+type Nominatim struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// get requests path from the API
+func (n Nominatim) get(ctx context.Context, path string) (*http.Response, error) {
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(n.BaseURL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Nominatim's usage policy requires an identifying user agent
+	req.Header.Set("User-Agent", "food-delivery-api")
+	return client.Do(req)
+}
+
+// Probe asks the API's /status endpoint whether it is up
+func (n Nominatim) Probe(ctx context.Context) error {
+	resp, err := n.get(ctx, "/status")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("geocoder status responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n Nominatim) Geocode(ctx context.Context, address string) (Point, error) {
+	q := url.Values{"q": {address}, "format": {"json"}, "limit": {"1"}}
+	resp, err := n.get(ctx, "/search?"+q.Encode())
+	if err != nil {
+		return Point{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Point{}, fmt.Errorf("geocoder responded %d", resp.StatusCode)
+	}
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Point{}, err
+	}
+	if len(results) == 0 {
+		return Point{}, ErrAddressNotFound
+	}
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return Point{}, err
+	}
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{Lat: lat, Lng: lng}, nil
+}
+
+// Default is the geocoder used across the application
+var Default Geocoder = NoopGeocoder{}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/geo"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAddressRequest struct {
+	Label   string `json:"label" binding:"max=50"`
+	Address string `json:"address" binding:"required,max=300"`
+}
+
+// geocodeAddress fills in the address's coordinates. On failure the address
+// keeps whatever coordinates it had.
+func geocodeAddress(ctx context.Context, address *models.CustomerAddress) error {
+	point, err := geo.Default.Geocode(ctx, address.Address)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	address.Latitude, address.Longitude, address.GeocodedAt = point.Lat, point.Lng, &now
+	return nil
+}
+
+// CreateAddress saves a delivery address to the customer's address book,
+// geocoding it first
+//
+// @Summary     Save an address
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       body  body  CreateAddressRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/addresses [post]
+func CreateAddress(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var req CreateAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	address := models.CustomerAddress{CustomerID: customerID, Label: req.Label, Address: req.Address}
+	geoErr := geocodeAddress(c.Request.Context(), &address)
+	if geoErr != nil && config.GeocoderStrict {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.address_could_not_be_geocoded",
+			gin.H{"reason": geoErr.Error()})
+		return
+	}
+	if err := requestDB(c).Create(&address).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_address", nil)
+		return
+	}
+	resp := gin.H{"message": "Address saved", "address": address, "geocoded": geoErr == nil}
+	if geoErr != nil {
+		resp["geocode_error"] = geoErr.Error()
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetAddresses lists the customer's saved addresses
+//
+// @Summary     List my saved addresses
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /customer/addresses [get]
+func GetAddresses(c *gin.Context) {
+	addresses := []models.CustomerAddress{}
+	requestDB(c).Where("customer_id = ?", middleware.GetUserID(c)).Order("id").Find(&addresses)
+	c.JSON(http.StatusOK, gin.H{"count": len(addresses), "addresses": addresses})
+}
+
+// RegeocodeAddress retries geocoding a saved address, e.g. after it was saved
+// without coordinates
+//
+// @Summary     Geocode a saved address again
+// @Tags        customer
+// @Produce     json
+// @Param       id  path  int  true  "Address ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/addresses/{id}/regeocode [put]
+func RegeocodeAddress(c *gin.Context) {
+	var address models.CustomerAddress
+	if err := requestDB(c).Where("id = ? AND customer_id = ?", c.Param("id"), middleware.GetUserID(c)).First(&address).Error; err != nil {
+		c.Error(err).SetMeta("errors.address_not_found")
+		c.Abort()
+		return
+	}
+	if err := geocodeAddress(c.Request.Context(), &address); err != nil {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.address_could_not_be_geocoded",
+			gin.H{"reason": err.Error()})
+		return
+	}
+	if err := requestDB(c).Save(&address).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_address", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Address geocoded", "address": address})
+}
+
+// DeleteAddress removes a saved address. Orders placed with it keep their copy.
+//
+// @Summary     Delete a saved address
+// @Tags        customer
+// @Produce     json
+// @Param       id  path  int  true  "Address ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/addresses/{id} [delete]
+func DeleteAddress(c *gin.Context) {
+	res := requestDB(c).Where("id = ? AND customer_id = ?", c.Param("id"), middleware.GetUserID(c)).
+		Delete(&models.CustomerAddress{})
+	if res.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.address_not_found", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Address deleted"})
+}
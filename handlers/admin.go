@@ -2,17 +2,36 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
-	"food-delivery-api/config"
+	"food-delivery-api/apierror"
 	"food-delivery-api/models"
+	"food-delivery-api/ratelimit"
+	"food-delivery-api/sysconfig"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// AdminGetAllOrders returns all orders with full detail — admin only
+// AdminGetAllOrders returns all orders with full detail — admin only.
+// Soft-deleted orders are left out unless include_deleted=true.
+//
+// @Summary     List all orders with revenue summary
+// @Tags        admin
+// @Produce     json
+// @Param       status  query  string  false  "Filter by status"
+// @Param       customer_id  query  int  false  "Filter by customer"
+// @Param       restaurant_id  query  int  false  "Filter by restaurant"
+// @Param       auto_cancelled  query  bool  false  "Only orders cancelled by the auto-cancel worker"
+// @Param       include_deleted  query  bool  false  "Also list soft-deleted orders"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders [get]
 func AdminGetAllOrders(c *gin.Context) {
 	var orders []models.Order
-	query := config.DB.Preload("Items.MenuItem").
+	query := requestDB(c).Preload("Items.MenuItem").
 		Preload("Customer").Preload("Restaurant").Preload("Driver").Preload("StatusHistory")
 
 	if status := c.Query("status"); status != "" {
@@ -24,31 +43,55 @@ func AdminGetAllOrders(c *gin.Context) {
 	if restaurantID := c.Query("restaurant_id"); restaurantID != "" {
 		query = query.Where("restaurant_id = ?", restaurantID)
 	}
+	if c.Query("auto_cancelled") == "true" {
+		query = query.Where("auto_cancelled = ?", true)
+	}
+	if c.Query("include_deleted") == "true" {
+		query = query.Unscoped()
+	}
 
 	query.Order("created_at desc").Find(&orders)
+	setOrderLinks(c, orders)
 
-	// Admin dashboard: aggregate by status
+	// Admin dashboard: aggregate by status, with money in the base currency
 	summary := map[string]int{}
-	var totalRevenue float64
+	var totalRevenue, serviceFeeIncome, platformTipIncome, restaurantRevenue float64
 	for _, o := range orders {
 		summary[string(o.Status)]++
 		if o.Status == models.StatusDelivered {
-			totalRevenue += o.TotalPrice
+			totalRevenue += o.TotalPriceBase
+			serviceFeeIncome += toBase(o.ServiceFee, o.ExchangeRate)
+			platformTipIncome += toBase(o.PlatformTipIncome, o.ExchangeRate)
+			restaurantRevenue += toBase(o.Subtotal(), o.ExchangeRate)
 		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"order_summary": summary,
-		"total_revenue": totalRevenue,
-		"count":         len(orders),
-		"orders":        orders,
+		"order_summary":       summary,
+		"base_currency":       sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"total_revenue":       totalRevenue,
+		"service_fee_income":  serviceFeeIncome,
+		"platform_tip_income": platformTipIncome,
+		"restaurant_revenue":  restaurantRevenue,
+		"count":               len(orders),
+		"orders":              orders,
 	})
 }
 
 // AdminGetAllUsers returns all users — admin only
+//
+// @Summary     List all users
+// @Tags        admin
+// @Produce     json
+// @Param       role  query  string  false  "Filter by role"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/users [get]
 func AdminGetAllUsers(c *gin.Context) {
 	var users []models.User
-	query := config.DB
+	query := requestDB(c)
 	if role := c.Query("role"); role != "" {
 		query = query.Where("role = ?", role)
 	}
@@ -57,43 +100,134 @@ func AdminGetAllUsers(c *gin.Context) {
 }
 
 // AdminGetAllRestaurants returns all restaurants — admin only
+//
+// @Summary     List all restaurants
+// @Tags        admin
+// @Produce     json
+// @Param       featured  query  bool  false  "Only currently featured restaurants, open or not"
+// @Param       active  query  bool  false  "Only active (true) or deactivated (false) restaurants"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants [get]
 func AdminGetAllRestaurants(c *gin.Context) {
+	now := time.Now()
 	var restaurants []models.Restaurant
-	config.DB.Preload("Owner").Preload("MenuItems").Find(&restaurants)
+	query := requestDB(c).Preload("Owner").Preload("MenuItems")
+	if c.Query("featured") == "true" {
+		query = query.Scopes(featuredScope(now)).Order("featured_until")
+	}
+	switch c.Query("active") {
+	case "true":
+		query = query.Where("is_active = ?", true)
+	case "false":
+		query = query.Where("is_active = ?", false)
+	}
+	query.Find(&restaurants)
+	for i := range restaurants {
+		setFeaturedEndsIn(&restaurants[i], now)
+	}
 	c.JSON(http.StatusOK, gin.H{"count": len(restaurants), "restaurants": restaurants})
 }
 
+type AdminForceStatusRequest struct {
+	Status models.OrderStatus `json:"status" binding:"required"`
+	Reason string             `json:"reason"`
+}
+
+// AdminGetRestaurantRateStats shows a restaurant's order rate-limit bucket — admin only
+//
+// @Summary     Order rate-limit stats for a restaurant
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/rate-stats [get]
+func AdminGetRestaurantRateStats(c *gin.Context) {
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"restaurant_id":         restaurant.ID,
+		"restaurant":            restaurant.Name,
+		"max_orders_per_minute": restaurant.MaxOrdersPerMinute,
+		"bucket":                ratelimit.RestaurantStats(restaurant.ID, restaurant.MaxOrdersPerMinute),
+	})
+}
+
 // AdminForceOrderStatus lets admin override any order state (emergency use)
+//
+// @Summary     Force an order into any state
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Param       body  body  AdminForceStatusRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders/{id}/status [put]
 func AdminForceOrderStatus(c *gin.Context) {
 	orderID := c.Param("id")
-	var req struct {
-		Status models.OrderStatus `json:"status" binding:"required"`
-		Reason string             `json:"reason"`
-	}
+	var req AdminForceStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
 		return
 	}
 	var order models.Order
-	if err := config.DB.First(&order, orderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	if err := requestDB(c).First(&order, orderID).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
 		return
 	}
 	prevStatus := order.Status
-	config.DB.Model(&order).Update("status", req.Status)
-
-	history := models.OrderStatusHistory{
-		OrderID:    order.ID,
-		FromStatus: prevStatus,
-		ToStatus:   req.Status,
-		Note:       "[ADMIN OVERRIDE] " + req.Reason,
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		update := map[string]interface{}{"status": req.Status}
+		if req.Status == models.StatusDelivered && prevStatus != models.StatusDelivered {
+			var err error
+			if update, err = deliveredUpdate(tx, &order, time.Now()); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&order).Updates(update).Error; err != nil {
+			return err
+		}
+		// A delivered order's stock was used, whatever its status says later
+		if req.Status == models.StatusCancelled && prevStatus != models.StatusCancelled && prevStatus != models.StatusDelivered {
+			if err := restoreStock(tx, order.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: prevStatus,
+			ToStatus:   req.Status,
+			Note:       "[ADMIN OVERRIDE] " + req.Reason,
+		}).Error
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_order_status", nil)
+		return
+	}
+	publishStatusChange(order, prevStatus, req.Status)
+	if req.Status == models.StatusCancelled && prevStatus != models.StatusCancelled && prevStatus != models.StatusDelivered {
+		invalidateMenuCache(order.RestaurantID)
 	}
-	config.DB.Create(&history)
 
+	order.Status = req.Status
 	c.JSON(http.StatusOK, gin.H{
 		"message":         "Order status force-updated by admin",
 		"order_id":        order.ID,
+		"invoice_number":  order.InvoiceNumber,
 		"previous_status": prevStatus,
 		"new_status":      req.Status,
+		"links":           orderLinks(c, &order),
 	})
 }
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AllergensRequest struct {
+	Allergens []string `json:"allergens" binding:"required,min=1"`
+}
+
+type DietaryPreferencesRequest struct {
+	Allergens []string `json:"allergens"` // left as saved when omitted; [] clears them
+	Diets     []string `json:"diets"`     // e.g. "calorie_conscious"; left as saved when omitted
+}
+
+// resolveAllergens maps allergen names to their rows, rejecting unknown names
+func resolveAllergens(db *gorm.DB, names []string) ([]models.Allergen, string) {
+	var allergens []models.Allergen
+	for _, name := range names {
+		var a models.Allergen
+		if err := db.Where("name = ?", strings.ToLower(strings.TrimSpace(name))).First(&a).Error; err != nil {
+			return nil, name
+		}
+		allergens = append(allergens, a)
+	}
+	return allergens, ""
+}
+
+// parseNameList splits a comma-separated list of names, such as allergens or
+// diets, lowercased and without blanks
+func parseNameList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// allergensByItem returns allergen names for each of the given menu item IDs
+func allergensByItem(db *gorm.DB, itemIDs []uint) map[uint][]string {
+	result := map[uint][]string{}
+	if len(itemIDs) == 0 {
+		return result
+	}
+	var rows []struct {
+		MenuItemID uint
+		Name       string
+	}
+	db.Table("menu_item_allergens").
+		Select("menu_item_allergens.menu_item_id, allergens.name").
+		Joins("JOIN allergens ON allergens.id = menu_item_allergens.allergen_id").
+		Where("menu_item_allergens.menu_item_id IN ?", itemIDs).
+		Order("allergens.name").
+		Scan(&rows)
+	for _, r := range rows {
+		result[r.MenuItemID] = append(result[r.MenuItemID], r.Name)
+	}
+	return result
+}
+
+// attachAllergens fills the Allergens field on each menu item
+func attachAllergens(db *gorm.DB, items []models.MenuItem) {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	byItem := allergensByItem(db, ids)
+	for i := range items {
+		items[i].Allergens = byItem[items[i].ID]
+		if items[i].Allergens == nil {
+			items[i].Allergens = []string{}
+		}
+	}
+}
+
+// containsAllergen returns the first excluded allergen found in an item's allergens
+func containsAllergen(itemAllergens, excluded []string) (string, bool) {
+	for _, a := range itemAllergens {
+		for _, e := range excluded {
+			if a == e {
+				return a, true
+			}
+		}
+	}
+	return "", false
+}
+
+// savedAllergens returns the allergens stored in a customer's dietary preferences
+func savedAllergens(db *gorm.DB, customerID uint) []string {
+	var pref models.DietaryPreference
+	if err := db.Where("customer_id = ?", customerID).First(&pref).Error; err != nil {
+		return nil
+	}
+	return parseNameList(pref.Allergens)
+}
+
+// followsDiet reports whether a customer's dietary preferences include diet
+func followsDiet(db *gorm.DB, customerID uint, diet string) bool {
+	var pref models.DietaryPreference
+	if err := db.Where("customer_id = ?", customerID).First(&pref).Error; err != nil {
+		return false
+	}
+	for _, d := range parseNameList(pref.Diets) {
+		if d == diet {
+			return true
+		}
+	}
+	return false
+}
+
+// ownedMenuItem loads a menu item and checks it belongs to the caller's restaurant
+func ownedMenuItem(c *gin.Context) (*models.MenuItem, bool) {
+	ownerID := middleware.GetUserID(c)
+	var item models.MenuItem
+	if err := requestDB(c).First(&item, c.Param("itemId")).Error; err != nil {
+		c.Error(err).SetMeta("errors.menu_item_not_found")
+		c.Abort()
+		return nil, false
+	}
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("id = ? AND owner_id = ?", item.RestaurantID, ownerID).First(&restaurant).Error; err != nil {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.you_dont_own_this_menu_item", nil)
+		return nil, false
+	}
+	return &item, true
+}
+
+// AddMenuItemAllergens tags a menu item with allergens
+//
+// @Summary     Tag a menu item with allergens
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Param       body  body  AllergensRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId}/allergens [post]
+func AddMenuItemAllergens(c *gin.Context) {
+	item, ok := ownedMenuItem(c)
+	if !ok {
+		return
+	}
+	var req AllergensRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	allergens, unknown := resolveAllergens(requestDB(c), req.Allergens)
+	if unknown != "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.unknown_allergen", gin.H{
+			"allowed": models.AllergenNames,
+		}, unknown)
+		return
+	}
+	for _, a := range allergens {
+		link := models.MenuItemAllergen{MenuItemID: item.ID, AllergenID: a.ID}
+		requestDB(c).Where(link).FirstOrCreate(&link)
+	}
+	invalidateMenuCache(item.RestaurantID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Allergens added",
+		"item_id":   item.ID,
+		"allergens": allergensByItem(requestDB(c), []uint{item.ID})[item.ID],
+	})
+}
+
+// RemoveMenuItemAllergens removes allergen tags from a menu item
+//
+// @Summary     Remove allergen tags from a menu item
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Param       body  body  AllergensRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId}/allergens [delete]
+func RemoveMenuItemAllergens(c *gin.Context) {
+	item, ok := ownedMenuItem(c)
+	if !ok {
+		return
+	}
+	var req AllergensRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	allergens, unknown := resolveAllergens(requestDB(c), req.Allergens)
+	if unknown != "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.unknown_allergen", gin.H{
+			"allowed": models.AllergenNames,
+		}, unknown)
+		return
+	}
+	for _, a := range allergens {
+		requestDB(c).Where("menu_item_id = ? AND allergen_id = ?", item.ID, a.ID).Delete(&models.MenuItemAllergen{})
+	}
+	invalidateMenuCache(item.RestaurantID)
+	allergenNames := allergensByItem(requestDB(c), []uint{item.ID})[item.ID]
+	if allergenNames == nil {
+		allergenNames = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Allergens removed",
+		"item_id":   item.ID,
+		"allergens": allergenNames,
+	})
+}
+
+// GetDietaryPreferences returns the customer's saved allergies and diets
+//
+// @Summary     Get my saved allergies and diets
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/dietary-preferences [get]
+func GetDietaryPreferences(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var pref models.DietaryPreference
+	requestDB(c).Where("customer_id = ?", customerID).First(&pref)
+	allergens, diets := parseNameList(pref.Allergens), parseNameList(pref.Diets)
+	if allergens == nil {
+		allergens = []string{}
+	}
+	if diets == nil {
+		diets = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"allergens": allergens, "diets": diets})
+}
+
+// UpdateDietaryPreferences replaces the customer's saved allergies, and their
+// diets when given
+//
+// @Summary     Replace my saved allergies and diets
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       body  body  DietaryPreferencesRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/dietary-preferences [put]
+func UpdateDietaryPreferences(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var req DietaryPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	update := map[string]interface{}{}
+	if req.Allergens != nil {
+		names := make([]string, 0, len(req.Allergens))
+		for _, name := range req.Allergens {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, unknown := resolveAllergens(requestDB(c), []string{name}); unknown != "" {
+				apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.unknown_allergen", gin.H{
+					"allowed": models.AllergenNames,
+				}, unknown)
+				return
+			}
+			names = append(names, name)
+		}
+		update["allergens"] = strings.Join(names, ",")
+	}
+	if req.Diets != nil {
+		diets := make([]string, 0, len(req.Diets))
+		for _, diet := range req.Diets {
+			diet = strings.ToLower(strings.TrimSpace(diet))
+			known := false
+			for _, d := range models.DietNames {
+				known = known || d == diet
+			}
+			if !known {
+				apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.unknown_diet", gin.H{
+					"allowed": models.DietNames,
+				}, diet)
+				return
+			}
+			diets = append(diets, diet)
+		}
+		update["diets"] = strings.Join(diets, ",")
+	}
+
+	pref := models.DietaryPreference{CustomerID: customerID}
+	requestDB(c).Where("customer_id = ?", customerID).FirstOrCreate(&pref)
+	if len(update) > 0 {
+		requestDB(c).Model(&pref).Updates(update)
+		requestDB(c).First(&pref, pref.ID)
+	}
+	allergens, diets := parseNameList(pref.Allergens), parseNameList(pref.Diets)
+	if allergens == nil {
+		allergens = []string{}
+	}
+	if diets == nil {
+		diets = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Dietary preferences updated", "allergens": allergens, "diets": diets})
+}
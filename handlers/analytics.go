@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	heatmapCacheTTL = time.Hour
+	maxHeatmapWeeks = 52
+)
+
+type HeatmapCell struct {
+	Day        int     `json:"day"`  // 0 = Sunday
+	Hour       int     `json:"hour"` // 0-23
+	OrderCount int     `json:"order_count"`
+	AvgRevenue float64 `json:"avg_revenue"`
+}
+
+type heatmapEntry struct {
+	Matrix   [][]HeatmapCell
+	CachedAt time.Time
+}
+
+// heatmapCacheKey is where a restaurant's heatmap over weeks is cached
+func heatmapCacheKey(restaurantID uint, weeks int) string {
+	return fmt.Sprintf("heatmap:%d:%d", restaurantID, weeks)
+}
+
+// computeHeatmap builds a 7×24 matrix of delivered orders for the last N weeks
+func computeHeatmap(restaurantID uint, weeks int) [][]HeatmapCell {
+	var rows []struct {
+		Day        string
+		Hour       string
+		OrderCount int
+		AvgRevenue float64
+	}
+	config.DB.Model(&models.Order{}).
+		Select("strftime('%w', created_at) AS day, strftime('%H', created_at) AS hour, "+
+			"COUNT(*) AS order_count, AVG(total_price) AS avg_revenue").
+		Where("restaurant_id = ? AND status = ? AND created_at >= ?",
+			restaurantID, models.StatusDelivered, time.Now().AddDate(0, 0, -7*weeks)).
+		Group("day, hour").
+		Scan(&rows)
+
+	matrix := make([][]HeatmapCell, 7)
+	for d := range matrix {
+		matrix[d] = make([]HeatmapCell, 24)
+		for h := range matrix[d] {
+			matrix[d][h] = HeatmapCell{Day: d, Hour: h}
+		}
+	}
+	for _, r := range rows {
+		d, errD := strconv.Atoi(r.Day)
+		h, errH := strconv.Atoi(r.Hour)
+		if errD != nil || errH != nil || d < 0 || d > 6 || h < 0 || h > 23 {
+			continue
+		}
+		matrix[d][h].OrderCount = r.OrderCount
+		matrix[d][h].AvgRevenue = r.AvgRevenue
+	}
+	return matrix
+}
+
+// respondHeatmap validates ?weeks= and writes the (possibly cached) heatmap
+func respondHeatmap(c *gin.Context, restaurant models.Restaurant) {
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", "4"))
+	if err != nil || weeks < 1 || weeks > maxHeatmapWeeks {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.weeks_must_be_between_1_and_52", nil)
+		return
+	}
+
+	key := heatmapCacheKey(restaurant.ID, weeks)
+	var entry heatmapEntry
+	body, ok := cache.Default.Get(key)
+	if !ok || json.Unmarshal(body, &entry) != nil {
+		entry = heatmapEntry{Matrix: computeHeatmap(restaurant.ID, weeks), CachedAt: time.Now()}
+		if body, err := json.Marshal(entry); err == nil {
+			cache.Default.Set(key, body, heatmapCacheTTL)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"restaurant_id": restaurant.ID,
+		"restaurant":    restaurant.Name,
+		"weeks":         weeks,
+		"cached_at":     entry.CachedAt,
+		"heatmap":       entry.Matrix,
+	})
+}
+
+// invalidateHeatmap drops every cached heatmap for a restaurant, one per
+// number of weeks
+func invalidateHeatmap(restaurantID uint) int {
+	removed := 0
+	for weeks := 1; weeks <= maxHeatmapWeeks; weeks++ {
+		key := heatmapCacheKey(restaurantID, weeks)
+		if _, ok := cache.Default.Get(key); ok {
+			cache.Default.Delete(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// GetRestaurantHeatmap returns the busiest-hours heatmap for the caller's restaurant
+//
+// @Summary     Busiest hours heatmap for my restaurant
+// @Tags        restaurant
+// @Produce     json
+// @Param       weeks  query  int  false  "Weeks of history (1-52)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/analytics/heatmap [get]
+func GetRestaurantHeatmap(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.no_restaurant_found_for_your_account")
+		c.Abort()
+		return
+	}
+	respondHeatmap(c, restaurant)
+}
+
+// AdminGetHeatmap returns the busiest-hours heatmap for any restaurant — admin only
+//
+// @Summary     Busiest hours heatmap for a restaurant
+// @Tags        admin
+// @Produce     json
+// @Param       restaurant_id  query  int  true  "Restaurant ID"
+// @Param       weeks  query  int  false  "Weeks of history (1-52)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/analytics/heatmap [get]
+func AdminGetHeatmap(c *gin.Context) {
+	restaurantID := c.Query("restaurant_id")
+	if restaurantID == "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.restaurant_id_is_required", nil)
+		return
+	}
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, restaurantID).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	respondHeatmap(c, restaurant)
+}
+
+// AdminInvalidateHeatmap clears cached heatmaps for a restaurant — admin only
+//
+// @Summary     Invalidate cached heatmaps
+// @Tags        admin
+// @Produce     json
+// @Param       restaurant_id  query  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/analytics/heatmap/cache [delete]
+func AdminInvalidateHeatmap(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Query("restaurant_id"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.restaurant_id_is_required", nil)
+		return
+	}
+	removed := invalidateHeatmap(uint(restaurantID))
+	c.JSON(http.StatusOK, gin.H{"message": "Heatmap cache cleared", "entries_removed": removed})
+}
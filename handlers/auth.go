@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
+	"food-delivery-api/apierror"
 	"food-delivery-api/config"
 	"food-delivery-api/middleware"
 	"food-delivery-api/models"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type RegisterRequest struct {
@@ -17,6 +21,8 @@ type RegisterRequest struct {
 	Password string          `json:"password" binding:"required,min=6"`
 	Role     models.UserRole `json:"role" binding:"required"`
 	Phone    string          `json:"phone"`
+	// Optional code shared by an existing customer
+	ReferralCode string `json:"referral_code"`
 }
 
 type LoginRequest struct {
@@ -25,10 +31,20 @@ type LoginRequest struct {
 }
 
 // Register creates a new user account
+//
+// @Summary     Register a new user
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       body  body  RegisterRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Router      /auth/register [post]
 func Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
 		return
 	}
 
@@ -40,20 +56,36 @@ func Register(c *gin.Context) {
 		models.RoleAdmin:      true,
 	}
 	if !validRoles[req.Role] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Must be: customer, restaurant, driver, or admin"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_role", nil)
 		return
 	}
 
-	// Check email uniqueness
+	// Check email uniqueness, ignoring case
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
 	var existing models.User
-	if result := config.DB.Where("email = ?", req.Email).First(&existing); result.Error == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+	if result := requestDB(c).Where("LOWER(email) = ?", req.Email).First(&existing); result.Error == nil {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.email_already_registered", nil)
 		return
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	// A referral code only counts for new customers and must belong to an existing customer
+	var referrer models.User
+	if req.ReferralCode != "" {
+		if req.Role != models.RoleCustomer {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.referral_code_customers_only", nil)
+			return
+		}
+		err := requestDB(c).Where("referral_code = ? AND role = ?", strings.ToUpper(req.ReferralCode), models.RoleCustomer).
+			First(&referrer).Error
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_referral_code", nil)
+			return
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), config.BCryptCost())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_hash_password", nil)
 		return
 	}
 
@@ -64,15 +96,37 @@ func Register(c *gin.Context) {
 		Role:         req.Role,
 		Phone:        req.Phone,
 	}
+	if referrer.ID != 0 {
+		user.ReferredByID = &referrer.ID
+	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if req.Role == models.RoleCustomer {
+			code, err := newReferralCode(tx)
+			if err != nil {
+				return err
+			}
+			user.ReferralCode = &code
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if referrer.ID != 0 {
+			if err := tx.Create(&models.Referral{ReferrerID: referrer.ID, RefereeID: user.ID}).Error; err != nil {
+				return err
+			}
+			return countReferralLink(tx, *referrer.ReferralCode, referrer.ID, "conversion_count")
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_user", nil)
 		return
 	}
 
 	token, err := middleware.GenerateToken(&user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_generate_token", nil)
 		return
 	}
 
@@ -80,36 +134,58 @@ func Register(c *gin.Context) {
 		"message": "Account created successfully",
 		"token":   token,
 		"user": gin.H{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-			"role":  user.Role,
+			"id":            user.ID,
+			"name":          user.Name,
+			"email":         user.Email,
+			"role":          user.Role,
+			"referral_code": user.ReferralCode,
 		},
 	})
 }
 
-// Login authenticates a user and returns a JWT
+// Login authenticates a user and returns a JWT, or an mfa_token to finish at
+// POST /auth/totp when the account has two-factor authentication enabled
+//
+// @Summary     Log in and receive a JWT
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       body  body  LoginRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Router      /auth/login [post]
 func Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
 		return
 	}
 
 	var user models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	if err := requestDB(c).Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&user).Error; err != nil {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.invalid_email_or_password", nil)
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.invalid_email_or_password", nil)
 		return
 	}
 
-	token, err := middleware.GenerateToken(&user)
+	// Accounts with two-factor auth finish logging in at POST /auth/totp
+	if user.TOTPEnabled {
+		startMFAChallenge(c, user)
+		return
+	}
+	respondLogin(c, &user)
+}
+
+// respondLogin issues a JWT for an authenticated user
+func respondLogin(c *gin.Context, user *models.User) {
+	token, err := middleware.GenerateToken(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_generate_token", nil)
 		return
 	}
 
@@ -126,12 +202,30 @@ func Login(c *gin.Context) {
 }
 
 // GetProfile returns the authenticated user's profile
+//
+// @Summary     Get the authenticated user's profile
+// @Tags        auth
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /profile [get]
 func GetProfile(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 	var user models.User
-	if err := config.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if err := requestDB(c).First(&user, userID).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_not_found")
+		c.Abort()
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": user})
+
+	// Customers see when their delivery subscription runs out
+	var subscriptionExpiresAt *time.Time
+	if user.Role == models.RoleCustomer {
+		if sub, ok := activeSubscription(requestDB(c), user.ID); ok {
+			subscriptionExpiresAt = &sub.ExpiresAt
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user, "subscription_expires_at": subscriptionExpiresAt})
 }
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRegisteredUserCanLogInWithConfiguredBCryptCost(t *testing.T) {
+	db := newTestDB(t)
+
+	w := serve(Register, "/auth/register", 0, "", http.MethodPost, "/auth/register",
+		`{"name":"Asha","email":"Asha@Example.com","password":"secret123","role":"customer"}`)
+	wantStatus(t, w, http.StatusCreated)
+
+	var user models.User
+	if err := db.Where("email = ?", "asha@example.com").First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	cost, err := bcrypt.Cost([]byte(user.PasswordHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != config.BCryptCost() {
+		t.Errorf("password hashed with cost %d, want BCRYPT_COST's %d", cost, config.BCryptCost())
+	}
+
+	w = serve(Login, "/auth/login", 0, "", http.MethodPost, "/auth/login",
+		`{"email":"asha@example.com","password":"secret123"}`)
+	wantStatus(t, w, http.StatusOK)
+	w = serve(Login, "/auth/login", 0, "", http.MethodPost, "/auth/login",
+		`{"email":"asha@example.com","password":"wrong-password"}`)
+	wantStatus(t, w, http.StatusUnauthorized)
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/eventbus"
+	"food-delivery-api/geo"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+	"food-delivery-api/statemachine"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	autoAssignFirstWait     = 60 * time.Second
+	autoAssignRetryInterval = 30 * time.Second
+	autoAssignMaxRetries    = 5
+	autoAssignUnclaimWindow = 2 * time.Minute
+)
+
+// autoAssignSleep waits between attempts; tests replace it
+var autoAssignSleep = time.Sleep
+
+// autoAssignCandidate is a driver free to take an order. LastKnownDistanceKm
+// is nil when the driver never reported a position or the restaurant has no
+// coordinates.
+type autoAssignCandidate struct {
+	DriverID            uint
+	LastSeenAt          *time.Time
+	LastKnownDistanceKm *float64
+}
+
+// AutoAssignOnReady starts looking for a driver when an order of a restaurant
+// with auto_assign_driver becomes READY_FOR_PICKUP
+func AutoAssignOnReady(payload interface{}) {
+	e, ok := payload.(eventbus.OrderEvent)
+	if !ok || e.To != models.StatusReadyForPickup {
+		return
+	}
+	var restaurant models.Restaurant
+	if err := config.DB.First(&restaurant, e.Order.RestaurantID).Error; err != nil || !restaurant.AutoAssignDriver {
+		return
+	}
+	go autoAssignOrder(e.Order.ID, restaurant)
+}
+
+// ResumeAutoAssign restarts the search for every order still waiting for an
+// auto-assigned driver, which a restart would otherwise strand in
+// READY_FOR_PICKUP
+func ResumeAutoAssign() {
+	orders := awaitingAutoAssign(config.DB)
+	for _, order := range orders {
+		go autoAssignOrder(order.ID, order.Restaurant)
+	}
+	if len(orders) > 0 {
+		log.Printf("auto-assign: resumed %d order(s) waiting for a driver", len(orders))
+	}
+}
+
+// awaitingAutoAssign lists ready orders without a driver at restaurants that
+// auto-assign, with their restaurant
+func awaitingAutoAssign(db *gorm.DB) []models.Order {
+	var orders []models.Order
+	db.Preload("Restaurant").
+		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("orders.status = ? AND orders.driver_id IS NULL AND restaurants.auto_assign_driver = ?", models.StatusReadyForPickup, true).
+		Find(&orders)
+	return orders
+}
+
+// autoAssignOrder gives the order to the nearest free driver. When there is
+// none it looks again after 60 seconds, then every 30 seconds up to 5 retries
+// in all, and then raises alert.no_driver_available. It stops early once a
+// driver picks the order up themselves or it leaves READY_FOR_PICKUP.
+func autoAssignOrder(orderID uint, restaurant models.Restaurant) {
+	for attempt := 0; ; attempt++ {
+		switch attempt {
+		case 0:
+		case 1:
+			autoAssignSleep(autoAssignFirstWait)
+		default:
+			autoAssignSleep(autoAssignRetryInterval)
+		}
+		var order models.Order
+		if err := config.DB.First(&order, orderID).Error; err != nil {
+			return
+		}
+		if order.Status != models.StatusReadyForPickup || order.DriverID != nil {
+			return
+		}
+		candidates := autoAssignCandidates(order.ID, restaurant)
+		if len(candidates) > 0 {
+			if err := autoAssignDriver(order, candidates[0]); err != nil {
+				log.Printf("auto-assign: order %d: %v", order.ID, err)
+			}
+			return
+		}
+		if attempt == autoAssignMaxRetries {
+			eventbus.Default.Publish(eventbus.AlertNoDriverAvailable, eventbus.NoDriverAvailableEvent{Order: order, Attempts: attempt + 1})
+			return
+		}
+	}
+}
+
+// autoAssignCandidates lists online drivers carrying nothing, nearest to the
+// restaurant first by their last reported position. Drivers without a known
+// distance come last, most recently seen first. Drivers who unclaimed the
+// order before are left out.
+func autoAssignCandidates(orderID uint, restaurant models.Restaurant) []autoAssignCandidate {
+	var drivers []models.DriverProfile
+	config.DB.Select("driver_profiles.*").
+		Joins("JOIN users ON users.id = driver_profiles.user_id").
+		Where("driver_profiles.is_online = ? AND users.role = ?", true, models.RoleDriver).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.driver_id = driver_profiles.user_id AND orders.status = ? AND orders.deleted_at IS NULL)", models.StatusPickedUp).
+		Where("NOT EXISTS (SELECT 1 FROM order_status_histories h WHERE h.order_id = ? AND h.changed_by = driver_profiles.user_id AND h.from_status = ? AND h.to_status = ?)",
+			orderID, models.StatusPickedUp, models.StatusReadyForPickup).
+		Find(&drivers)
+
+	candidates := make([]autoAssignCandidate, len(drivers))
+	for i, d := range drivers {
+		candidates[i] = autoAssignCandidate{DriverID: d.UserID, LastSeenAt: d.LastSeenAt}
+		if restaurant.Latitude == nil || restaurant.Longitude == nil {
+			continue
+		}
+		if last := driverLastLocation(config.DB, d.UserID); last != nil {
+			km := math.Round(geo.DistanceKm(*last, geo.Point{Lat: *restaurant.Latitude, Lng: *restaurant.Longitude})*100) / 100
+			candidates[i].LastKnownDistanceKm = &km
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if (a.LastKnownDistanceKm == nil) != (b.LastKnownDistanceKm == nil) {
+			return a.LastKnownDistanceKm != nil
+		}
+		if a.LastKnownDistanceKm != nil && *a.LastKnownDistanceKm != *b.LastKnownDistanceKm {
+			return *a.LastKnownDistanceKm < *b.LastKnownDistanceKm
+		}
+		if a.LastSeenAt == nil || b.LastSeenAt == nil {
+			return a.LastSeenAt != nil
+		}
+		return a.LastSeenAt.After(*b.LastSeenAt)
+	})
+	return candidates
+}
+
+// driverLastLocation is the last position the driver reported on any
+// delivery, or nil when they never reported one
+func driverLastLocation(db *gorm.DB, driverID uint) *geo.Point {
+	var last struct {
+		Lat *float64
+		Lng *float64
+	}
+	db.Model(&models.DeliveryRoute{}).
+		Select("json_extract(coordinates, '$[#-1].lat') AS lat, json_extract(coordinates, '$[#-1].lng') AS lng").
+		Where("driver_id = ?", driverID).
+		Order("updated_at DESC").Limit(1).
+		Scan(&last)
+	if last.Lat == nil || last.Lng == nil {
+		return nil
+	}
+	return &geo.Point{Lat: *last.Lat, Lng: *last.Lng}
+}
+
+// autoAssignDriver picks the order up on the driver's behalf, as the system.
+// The update is conditional so a driver picking it up at the same time wins.
+func autoAssignDriver(order models.Order, candidate autoAssignCandidate) error {
+	if err := statemachine.CanTransition(order.Status, models.StatusPickedUp, "system"); err != nil {
+		return err
+	}
+	note := "[AUTO-ASSIGN] Nearest available driver"
+	if candidate.LastKnownDistanceKm != nil {
+		note = fmt.Sprintf("[AUTO-ASSIGN] Nearest available driver, %.2f km away", *candidate.LastKnownDistanceKm)
+	}
+	now := time.Now()
+	assigned := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Order{}).
+			Where("id = ? AND status = ? AND driver_id IS NULL", order.ID, models.StatusReadyForPickup).
+			Updates(map[string]interface{}{
+				"status":           models.StatusPickedUp,
+				"driver_id":        candidate.DriverID,
+				"auto_assigned_at": now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		assigned = true
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: models.StatusReadyForPickup,
+			ToStatus:   models.StatusPickedUp,
+			Note:       note,
+		}).Error
+	})
+	if err != nil || !assigned {
+		return err
+	}
+
+	order.Status = models.StatusPickedUp
+	order.DriverID = &candidate.DriverID
+	order.AutoAssignedAt = &now
+	publishStatusChange(order, models.StatusReadyForPickup, models.StatusPickedUp)
+
+	var driver models.User
+	if config.DB.First(&driver, candidate.DriverID).Error == nil {
+		notify.Default.Send(notify.Message{
+			UserID:  driver.ID,
+			Email:   driver.Email,
+			Phone:   driver.Phone,
+			Channel: notify.ChannelPush,
+			Title:   fmt.Sprintf("Order #%d has been assigned to you", order.ID),
+			Body:    fmt.Sprintf("Head to the restaurant to collect it. You can hand it back within %d minutes.", int(autoAssignUnclaimWindow.Minutes())),
+
+			EventType:     "order_auto_assigned",
+			ReferenceID:   order.ID,
+			ReferenceType: "order",
+		})
+	}
+	return nil
+}
+
+// NotifyNoDriverAvailable tells the admins and the restaurant owner that
+// auto-assignment gave up on an order
+func NotifyNoDriverAvailable(payload interface{}) {
+	e, ok := payload.(eventbus.NoDriverAvailableEvent)
+	if !ok {
+		return
+	}
+	title := fmt.Sprintf("No driver available for order #%d", e.Order.ID)
+	body := fmt.Sprintf("Auto-assignment found no free driver after %d attempts. The order stays in the available list for drivers to pick up.", e.Attempts)
+	var admins []models.User
+	config.DB.Where("role = ?", models.RoleAdmin).Find(&admins)
+	for _, admin := range admins {
+		err := notify.Default.Send(notify.Message{
+			UserID:  admin.ID,
+			Email:   admin.Email,
+			Phone:   admin.Phone,
+			Channel: notify.ChannelEmail,
+			Title:   title,
+			Body:    body,
+
+			EventType:     "no_driver_available",
+			ReferenceID:   e.Order.ID,
+			ReferenceType: "order",
+		})
+		if err != nil {
+			log.Printf("auto-assign: failed to notify admin %d about order %d: %v", admin.ID, e.Order.ID, err)
+		}
+	}
+	notifyRestaurantOwner(e.Order.RestaurantID, title, body)
+}
+
+// UnclaimOrder hands an auto-assigned order back within 2 minutes of the
+// assignment. The order returns to READY_FOR_PICKUP and is offered to the
+// next nearest driver; it won't be given to this driver again.
+//
+// @Summary     Hand back an auto-assigned order
+// @Tags        driver
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/orders/{id}/unclaim [put]
+func UnclaimOrder(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var order models.Order
+	if err := requestDB(c).Where("id = ? AND driver_id = ?", c.Param("id"), driverID).First(&order).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.Status != models.StatusPickedUp || order.AutoAssignedAt == nil {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.order_not_auto_assigned", gin.H{"status": order.Status})
+		return
+	}
+	if time.Since(*order.AutoAssignedAt) > autoAssignUnclaimWindow {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.unclaim_window_expired", gin.H{
+			"auto_assigned_at": order.AutoAssignedAt,
+			"window_minutes":   int(autoAssignUnclaimWindow.Minutes()),
+		}, int(autoAssignUnclaimWindow.Minutes()))
+		return
+	}
+
+	unclaimed := false
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Order{}).
+			Where("id = ? AND driver_id = ? AND status = ?", order.ID, driverID, models.StatusPickedUp).
+			Updates(map[string]interface{}{
+				"status":           models.StatusReadyForPickup,
+				"driver_id":        nil,
+				"auto_assigned_at": nil,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		unclaimed = true
+		// Positions reported on the way belong to this driver, not the next one
+		if err := tx.Where("order_id = ?", order.ID).Delete(&models.DeliveryRoute{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: models.StatusPickedUp,
+			ToStatus:   models.StatusReadyForPickup,
+			ChangedBy:  driverID,
+			Note:       "Driver unclaimed an auto-assigned order",
+		}).Error
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_unclaim_order", nil)
+		return
+	}
+	if !unclaimed {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.order_not_auto_assigned", gin.H{"status": order.Status})
+		return
+	}
+
+	order.Status = models.StatusReadyForPickup
+	order.DriverID = nil
+	order.AutoAssignedAt = nil
+	publishStatusChange(order, models.StatusPickedUp, models.StatusReadyForPickup)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Order handed back",
+		"order_id": order.ID,
+		"status":   order.Status,
+		"links":    orderLinks(c, &order),
+	})
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"food-delivery-api/eventbus"
+	"food-delivery-api/geo"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// autoAssignSetup is a restaurant at the origin with auto-assign on and one
+// order ready for pickup
+func autoAssignSetup(t *testing.T, db *gorm.DB) (models.Restaurant, models.Order) {
+	t.Helper()
+	restaurant, _ := createRestaurant(t, db)
+	lat, lng := 0.0, 0.0
+	restaurant.Latitude, restaurant.Longitude, restaurant.AutoAssignDriver = &lat, &lng, true
+	if err := db.Save(&restaurant).Error; err != nil {
+		t.Fatal(err)
+	}
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	order := unpaidOrder(t, db, restaurant, customer)
+	db.Model(&order).Update("status", models.StatusReadyForPickup)
+	order.Status = models.StatusReadyForPickup
+	return restaurant, order
+}
+
+// onlineDriver adds a driver who is online, was last seen seenAgo ago and,
+// unless at is nil, last reported a position there on an earlier delivery
+func onlineDriver(t *testing.T, db *gorm.DB, name string, seenAgo time.Duration, at *geo.Point) models.User {
+	t.Helper()
+	driver := createUser(t, db, name, models.RoleDriver)
+	seen := time.Now().Add(-seenAgo)
+	if err := db.Create(&models.DriverProfile{UserID: driver.ID, IsOnline: true, LastSeenAt: &seen}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if at != nil {
+		route := models.DeliveryRoute{OrderID: 10000 + driver.ID, DriverID: driver.ID, Coordinates: []models.RoutePoint{{Point: *at, TS: seen}}}
+		if err := db.Create(&route).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return driver
+}
+
+func candidateIDs(candidates []autoAssignCandidate) []uint {
+	ids := make([]uint, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.DriverID
+	}
+	return ids
+}
+
+func TestAutoAssignCandidateOrder(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, order := autoAssignSetup(t, db)
+	far := onlineDriver(t, db, "Far", time.Minute, &geo.Point{Lat: 0.05, Lng: 0})
+	unknownStale := onlineDriver(t, db, "Stale", time.Hour, nil)
+	near := onlineDriver(t, db, "Near", 10*time.Minute, &geo.Point{Lat: 0.01, Lng: 0})
+	unknownFresh := onlineDriver(t, db, "Fresh", time.Second, nil)
+	offline := onlineDriver(t, db, "Offline", time.Second, &geo.Point{Lat: 0, Lng: 0})
+	db.Model(&models.DriverProfile{}).Where("user_id = ?", offline.ID).Update("is_online", false)
+	busy := onlineDriver(t, db, "Busy", time.Second, &geo.Point{Lat: 0, Lng: 0})
+	customer := createUser(t, db, "Other", models.RoleCustomer)
+	delivering := unpaidOrder(t, db, restaurant, customer)
+	db.Model(&delivering).Updates(map[string]interface{}{"status": models.StatusPickedUp, "driver_id": busy.ID})
+
+	got := candidateIDs(autoAssignCandidates(order.ID, restaurant))
+	want := []uint{near.ID, far.ID, unknownFresh.ID, unknownStale.ID}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want %v (nearest first, then unknown distance by last seen)", got, want)
+	}
+}
+
+func TestAutoAssignLosesToADriverPickingUp(t *testing.T) {
+	db := newTestDB(t)
+	_, order := autoAssignSetup(t, db)
+	auto := onlineDriver(t, db, "Auto", time.Second, nil)
+	manual := onlineDriver(t, db, "Manual", time.Second, nil)
+
+	// The manual pickup lands after the auto-assigner read the order
+	db.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{"status": models.StatusPickedUp, "driver_id": manual.ID})
+	if err := autoAssignDriver(order, autoAssignCandidate{DriverID: auto.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got models.Order
+	db.First(&got, order.ID)
+	if got.DriverID == nil || *got.DriverID != manual.ID || got.AutoAssignedAt != nil {
+		t.Errorf("driver = %v, auto_assigned_at = %v; want the manual pickup to stand", got.DriverID, got.AutoAssignedAt)
+	}
+	var history int64
+	db.Model(&models.OrderStatusHistory{}).Where("order_id = ?", order.ID).Count(&history)
+	if history != 0 {
+		t.Errorf("%d history entries written for an assignment that lost", history)
+	}
+}
+
+func TestUnclaimWindow(t *testing.T) {
+	for _, tc := range []struct {
+		assignedAgo time.Duration
+		want        int
+	}{
+		{30 * time.Second, http.StatusOK},
+		{autoAssignUnclaimWindow + time.Second, http.StatusConflict},
+	} {
+		t.Run(tc.assignedAgo.String(), func(t *testing.T) {
+			db := newTestDB(t)
+			_, order := autoAssignSetup(t, db)
+			driver := onlineDriver(t, db, "Driver", time.Second, nil)
+			db.Model(&order).Updates(map[string]interface{}{
+				"status":           models.StatusPickedUp,
+				"driver_id":        driver.ID,
+				"auto_assigned_at": time.Now().Add(-tc.assignedAgo),
+			})
+
+			target := fmt.Sprintf("/driver/orders/%d/unclaim", order.ID)
+			w := serve(UnclaimOrder, "/driver/orders/:id/unclaim", driver.ID, models.RoleDriver, http.MethodPut, target, "")
+			wantStatus(t, w, tc.want)
+
+			var got models.Order
+			db.First(&got, order.ID)
+			wantStatus := models.StatusPickedUp
+			if tc.want == http.StatusOK {
+				wantStatus = models.StatusReadyForPickup
+			}
+			if got.Status != wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, wantStatus)
+			}
+		})
+	}
+}
+
+func TestUnclaimedOrderSkipsThatDriver(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, order := autoAssignSetup(t, db)
+	first := onlineDriver(t, db, "First", time.Second, &geo.Point{Lat: 0.01, Lng: 0})
+	second := onlineDriver(t, db, "Second", time.Second, &geo.Point{Lat: 0.02, Lng: 0})
+
+	if err := autoAssignDriver(order, autoAssignCandidate{DriverID: first.ID}); err != nil {
+		t.Fatal(err)
+	}
+	target := fmt.Sprintf("/driver/orders/%d/unclaim", order.ID)
+	w := serve(UnclaimOrder, "/driver/orders/:id/unclaim", first.ID, models.RoleDriver, http.MethodPut, target, "")
+	wantStatus(t, w, http.StatusOK)
+
+	got := candidateIDs(autoAssignCandidates(order.ID, restaurant))
+	if want := []uint{second.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates after unclaim = %v, want only %v", got, want)
+	}
+}
+
+func TestAutoAssignRetrySchedule(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, order := autoAssignSetup(t, db)
+	var waits []time.Duration
+	autoAssignSleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { autoAssignSleep = time.Sleep }()
+	bus := eventbus.Default
+	eventbus.Default = eventbus.New()
+	defer func() { eventbus.Default = bus }()
+	var alerts []eventbus.NoDriverAvailableEvent
+	eventbus.Default.Subscribe(eventbus.AlertNoDriverAvailable, func(payload interface{}) {
+		alerts = append(alerts, payload.(eventbus.NoDriverAvailableEvent))
+	})
+
+	autoAssignOrder(order.ID, restaurant)
+
+	want := []time.Duration{autoAssignFirstWait}
+	for i := 1; i < autoAssignMaxRetries; i++ {
+		want = append(want, autoAssignRetryInterval)
+	}
+	if !reflect.DeepEqual(waits, want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+	if len(alerts) != 1 || alerts[0].Attempts != autoAssignMaxRetries+1 {
+		t.Errorf("alerts = %+v, want one after %d attempts", alerts, autoAssignMaxRetries+1)
+	}
+}
+
+func TestAwaitingAutoAssign(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, waiting := autoAssignSetup(t, db)
+	customer := models.User{}
+	db.Where("role = ?", models.RoleCustomer).First(&customer)
+	taken := unpaidOrder(t, db, restaurant, customer)
+	driver := onlineDriver(t, db, "Driver", time.Second, nil)
+	db.Model(&taken).Updates(map[string]interface{}{"status": models.StatusReadyForPickup, "driver_id": driver.ID})
+	placed := unpaidOrder(t, db, restaurant, customer)
+
+	// A restaurant without auto-assign leaves its ready orders to the drivers
+	manual := models.Restaurant{OwnerID: restaurant.OwnerID, Name: "Manual Kitchen", MaxOrdersPerMinute: 10}
+	db.Create(&manual)
+	manualOrder := unpaidOrder(t, db, manual, customer)
+	db.Model(&manualOrder).Update("status", models.StatusReadyForPickup)
+
+	got := awaitingAutoAssign(db)
+	if len(got) != 1 || got[0].ID != waiting.ID || !got[0].Restaurant.AutoAssignDriver {
+		t.Errorf("awaiting = %+v, want only order %d with its restaurant (not %d or %d)", got, waiting.ID, placed.ID, manualOrder.ID)
+	}
+}
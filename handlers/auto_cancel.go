@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	autoCancelCheckInterval = 30 * time.Second
+	autoCancelWarningLead   = 2 * time.Minute // restaurant is warned this long before the cancel fires
+)
+
+// StartAutoCancelWorker cancels orders the restaurant hasn't confirmed within
+// AUTO_CANCEL_MINUTES, warning the restaurant shortly before it happens.
+func StartAutoCancelWorker() {
+	go func() {
+		for range time.Tick(autoCancelCheckInterval) {
+			runAutoCancel(time.Now())
+		}
+	}()
+}
+
+func runAutoCancel(now time.Time) {
+	limit := time.Duration(sysconfig.Int(sysconfig.KeyAutoCancelMinutes)) * time.Minute
+	if limit <= 0 {
+		return
+	}
+
+	var warn []models.Order
+	config.DB.Where("status = ? AND auto_cancel_warned_at IS NULL AND created_at <= ? AND created_at > ?",
+		models.StatusPlaced, now.Add(-(limit - autoCancelWarningLead)), now.Add(-limit)).
+		Find(&warn)
+	for _, order := range warn {
+		res := config.DB.Model(&models.Order{}).Where("id = ? AND auto_cancel_warned_at IS NULL", order.ID).
+			Update("auto_cancel_warned_at", now)
+		if res.RowsAffected == 0 {
+			continue
+		}
+		notifyRestaurantOwner(order.RestaurantID, "Order #"+fmt.Sprint(order.ID)+" is about to be auto-cancelled",
+			fmt.Sprintf("Confirm order #%d within %d minutes or it will be cancelled automatically.", order.ID, int(autoCancelWarningLead.Minutes())))
+	}
+
+	var expired []models.Order
+	config.DB.Where("status = ? AND created_at <= ?", models.StatusPlaced, now.Add(-limit)).Find(&expired)
+	for _, order := range expired {
+		reason := fmt.Sprintf("Restaurant did not confirm within %d minutes", int(limit.Minutes()))
+		cancelled := false
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			// Conditional on PLACED so a confirm racing the worker wins
+			res := tx.Model(&models.Order{}).Where("id = ? AND status = ?", order.ID, models.StatusPlaced).
+				Updates(map[string]interface{}{
+					"status":             models.StatusCancelled,
+					"auto_cancelled":     true,
+					"auto_cancel_reason": reason,
+				})
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			if err := restoreStock(tx, order.ID); err != nil {
+				return err
+			}
+			cancelled = true
+			return tx.Create(&models.OrderStatusHistory{
+				OrderID:    order.ID,
+				FromStatus: models.StatusPlaced,
+				ToStatus:   models.StatusCancelled,
+				Note:       "[AUTO-CANCEL] " + reason,
+			}).Error
+		})
+		if err != nil {
+			log.Printf("auto-cancel: order %d: %v", order.ID, err)
+			continue
+		}
+		if !cancelled {
+			continue
+		}
+		publishStatusChange(order, models.StatusPlaced, models.StatusCancelled)
+		invalidateMenuCache(order.RestaurantID)
+		recordAutoCancel(order.RestaurantID, now)
+
+		var customer models.User
+		if err := config.DB.First(&customer, order.CustomerID).Error; err == nil {
+			notify.Default.Send(notify.Message{
+				UserID:  customer.ID,
+				Email:   customer.Email,
+				Phone:   customer.Phone,
+				Channel: notify.ChannelPush,
+				Title:   fmt.Sprintf("Order #%d was cancelled", order.ID),
+				Body:    "The restaurant didn't confirm your order in time. You will be refunded.",
+
+				EventType:     "order_auto_cancelled",
+				ReferenceID:   order.ID,
+				ReferenceType: "order",
+			})
+		}
+		notifyRestaurantOwner(order.RestaurantID, fmt.Sprintf("Order #%d was auto-cancelled", order.ID), reason)
+	}
+}
+
+// notifyRestaurantOwner sends a push notification to the owner of a restaurant
+func notifyRestaurantOwner(restaurantID uint, title, body string) {
+	var restaurant models.Restaurant
+	if err := config.DB.Preload("Owner").First(&restaurant, restaurantID).Error; err != nil {
+		return
+	}
+	notify.Default.Send(notify.Message{
+		UserID:  restaurant.Owner.ID,
+		Email:   restaurant.Owner.Email,
+		Phone:   restaurant.Owner.Phone,
+		Channel: notify.ChannelPush,
+		Title:   title,
+		Body:    body,
+	})
+}
+
+// AdminGetAutoCancellations groups auto-cancelled orders by restaurant — admin only
+//
+// @Summary     Auto-cancellations per restaurant
+// @Tags        admin
+// @Produce     json
+// @Param       from  query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to    query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/auto-cancellations [get]
+func AdminGetAutoCancellations(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	rows := []struct {
+		RestaurantID      uint    `json:"restaurant_id"`
+		RestaurantName    string  `json:"restaurant_name"`
+		TotalOrders       int     `json:"total_orders"`
+		AutoCancellations int     `json:"auto_cancellations"`
+		AutoCancelRatePct float64 `json:"auto_cancel_rate_pct"`
+	}{}
+	requestDB(c).Table("orders").
+		Select("orders.restaurant_id, restaurants.name AS restaurant_name, COUNT(*) AS total_orders, "+
+			"SUM(CASE WHEN orders.auto_cancelled THEN 1 ELSE 0 END) AS auto_cancellations, "+
+			"ROUND(SUM(CASE WHEN orders.auto_cancelled THEN 1 ELSE 0 END) * 100.0 / COUNT(*), 2) AS auto_cancel_rate_pct").
+		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("orders.created_at >= ? AND orders.created_at < ? AND orders.deleted_at IS NULL", from, to).
+		Group("orders.restaurant_id, restaurants.name").
+		Having("SUM(CASE WHEN orders.auto_cancelled THEN 1 ELSE 0 END) > 0").
+		Order("auto_cancellations desc").
+		Scan(&rows)
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":        from.Format(dateLayout),
+		"to":          to.AddDate(0, 0, -1).Format(dateLayout),
+		"count":       len(rows),
+		"restaurants": rows,
+	})
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BundleItemRequest struct {
+	MenuItemID uint `json:"menu_item_id" binding:"required"`
+	Quantity   int  `json:"quantity" binding:"required,min=1"`
+}
+
+type CreateBundleRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	BundlePrice float64             `json:"bundle_price" binding:"required,gt=0"`
+	IsAvailable *bool               `json:"is_available"` // default true
+	Items       []BundleItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type UpdateBundleRequest struct {
+	Name        *string             `json:"name"`
+	Description *string             `json:"description"`
+	BundlePrice *float64            `json:"bundle_price" binding:"omitempty,gt=0"`
+	IsAvailable *bool               `json:"is_available"`
+	Items       []BundleItemRequest `json:"items" binding:"omitempty,min=1,dive"` // replaces the members when sent
+}
+
+// bundleListing is a bundle as shown on the menu, with its computed fields
+type bundleListing struct {
+	models.MenuBundle
+	RegularPrice float64 `json:"regular_price"` // what the items cost separately
+	Savings      float64 `json:"savings"`
+	Available    bool    `json:"available"` // bundle switched on and every member item available
+}
+
+// bundleRegularPrice is what the bundle's items would cost ordered separately
+func bundleRegularPrice(bundle models.MenuBundle) float64 {
+	sum := 0.0
+	for _, member := range bundle.Items {
+		sum += member.MenuItem.Price * float64(member.Quantity)
+	}
+	return sum
+}
+
+// bundleAvailable is the AND of the bundle's own flag and all its items'
+// availability. A member item that was deleted loads as a zero MenuItem, so it
+// counts as unavailable.
+func bundleAvailable(bundle models.MenuBundle) bool {
+	if !bundle.IsAvailable || len(bundle.Items) == 0 {
+		return false
+	}
+	for _, member := range bundle.Items {
+		if !member.MenuItem.IsAvailable || !member.MenuItem.InSeason(time.Now().Month()) {
+			return false
+		}
+	}
+	return true
+}
+
+func newBundleListing(bundle models.MenuBundle) bundleListing {
+	regular := bundleRegularPrice(bundle)
+	return bundleListing{
+		MenuBundle:   bundle,
+		RegularPrice: math.Round(regular*100) / 100,
+		Savings:      math.Round((regular-bundle.BundlePrice)*100) / 100,
+		Available:    bundleAvailable(bundle),
+	}
+}
+
+// bundleMembers checks every requested item is on the restaurant's menu
+func bundleMembers(db *gorm.DB, restaurantID uint, reqItems []BundleItemRequest) ([]models.MenuBundleItem, *apierror.Error) {
+	members := make([]models.MenuBundleItem, len(reqItems))
+	for i, it := range reqItems {
+		var menuItem models.MenuItem
+		if err := db.Where("id = ? AND restaurant_id = ?", it.MenuItemID, restaurantID).First(&menuItem).Error; err != nil {
+			return nil, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest,
+				"errors.menu_item_id_not_in_restaurant", nil, it.MenuItemID)
+		}
+		members[i] = models.MenuBundleItem{MenuItemID: it.MenuItemID, Quantity: it.Quantity}
+	}
+	return members, nil
+}
+
+// ownedBundle loads a bundle belonging to the caller's restaurant,
+// responding and returning false when it can't
+func ownedBundle(c *gin.Context, bundle *models.MenuBundle) bool {
+	ownerID := middleware.GetUserID(c)
+	if err := requestDB(c).First(bundle, c.Param("bundleId")).Error; err != nil {
+		c.Error(err).SetMeta("errors.bundle_not_found")
+		c.Abort()
+		return false
+	}
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("id = ? AND owner_id = ?", bundle.RestaurantID, ownerID).First(&restaurant).Error; err != nil {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.you_dont_own_this_bundle", nil)
+		return false
+	}
+	return true
+}
+
+// CreateBundle adds a meal deal to the restaurant's menu
+//
+// @Summary     Create a menu bundle
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  CreateBundleRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/bundles [post]
+func CreateBundle(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.create_restaurant_before_adding_bundles")
+		c.Abort()
+		return
+	}
+	var req CreateBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	members, apiErr := bundleMembers(requestDB(c), restaurant.ID, req.Items)
+	if apiErr != nil {
+		apierror.RespondError(c, apiErr)
+		return
+	}
+
+	bundle := models.MenuBundle{
+		RestaurantID: restaurant.ID,
+		Name:         req.Name,
+		Description:  req.Description,
+		BundlePrice:  req.BundlePrice,
+		IsAvailable:  true,
+		Items:        members,
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&bundle).Error; err != nil {
+			return err
+		}
+		// false is the zero value, so the column default would win on insert
+		if req.IsAvailable != nil && !*req.IsAvailable {
+			bundle.IsAvailable = false
+			return tx.Model(&bundle).Update("is_available", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_bundle", nil)
+		return
+	}
+	invalidateMenuCache(restaurant.ID)
+	requestDB(c).Preload("Items.MenuItem").First(&bundle, bundle.ID)
+	c.JSON(http.StatusCreated, gin.H{"message": "Bundle created", "bundle": newBundleListing(bundle)})
+}
+
+// GetBundles lists the restaurant's bundles, including unavailable ones
+//
+// @Summary     List my menu bundles
+// @Tags        restaurant
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/bundles [get]
+func GetBundles(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.no_restaurant_found_for_your_account")
+		c.Abort()
+		return
+	}
+	var bundles []models.MenuBundle
+	requestDB(c).Preload("Items.MenuItem").Where("restaurant_id = ?", restaurant.ID).Order("name").Find(&bundles)
+	listings := make([]bundleListing, len(bundles))
+	for i, b := range bundles {
+		listings[i] = newBundleListing(b)
+	}
+	c.JSON(http.StatusOK, gin.H{"count": len(listings), "bundles": listings})
+}
+
+// UpdateBundle edits a bundle; sending items replaces its members
+//
+// @Summary     Update a menu bundle
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       bundleId  path  int                  true  "Bundle ID"
+// @Param       body      body  UpdateBundleRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/bundles/{bundleId} [put]
+func UpdateBundle(c *gin.Context) {
+	var bundle models.MenuBundle
+	if !ownedBundle(c, &bundle) {
+		return
+	}
+	var req UpdateBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.BundlePrice != nil {
+		updates["bundle_price"] = *req.BundlePrice
+	}
+	if req.IsAvailable != nil {
+		updates["is_available"] = *req.IsAvailable
+	}
+	var members []models.MenuBundleItem
+	if req.Items != nil {
+		var apiErr *apierror.Error
+		if members, apiErr = bundleMembers(requestDB(c), bundle.RestaurantID, req.Items); apiErr != nil {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+	}
+
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&bundle).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if members == nil {
+			return nil
+		}
+		if err := tx.Where("bundle_id = ?", bundle.ID).Delete(&models.MenuBundleItem{}).Error; err != nil {
+			return err
+		}
+		for i := range members {
+			members[i].BundleID = bundle.ID
+		}
+		return tx.Create(&members).Error
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_bundle", nil)
+		return
+	}
+	invalidateMenuCache(bundle.RestaurantID)
+	requestDB(c).Preload("Items.MenuItem").First(&bundle, bundle.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle updated", "bundle": newBundleListing(bundle)})
+}
+
+// DeleteBundle removes a bundle from the menu
+//
+// @Summary     Delete a menu bundle
+// @Tags        restaurant
+// @Produce     json
+// @Param       bundleId  path  int  true  "Bundle ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/bundles/{bundleId} [delete]
+func DeleteBundle(c *gin.Context) {
+	var bundle models.MenuBundle
+	if !ownedBundle(c, &bundle) {
+		return
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", bundle.ID).Delete(&models.MenuBundleItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&bundle).Error
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_delete_bundle", nil)
+		return
+	}
+	invalidateMenuCache(bundle.RestaurantID)
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle deleted"})
+}
+
+// menuBundles lists a restaurant's bundles for the public menu. Bundles follow
+// the menu filters: is_veg keeps all-veg bundles, and an excluded allergen in
+// any member hides the bundle. Bundles have no category, so a category filter
+// hides them all.
+func menuBundles(db *gorm.DB, restaurantID uint64, category, isVeg string, exclude []string) []bundleListing {
+	listings := []bundleListing{}
+	if category != "" {
+		return listings
+	}
+	var bundles []models.MenuBundle
+	db.Preload("Items.MenuItem").Where("restaurant_id = ?", restaurantID).Order("name").Find(&bundles)
+
+	var itemAllergens map[uint][]string
+	if len(exclude) > 0 {
+		var ids []uint
+		for _, b := range bundles {
+			for _, member := range b.Items {
+				ids = append(ids, member.MenuItemID)
+			}
+		}
+		itemAllergens = allergensByItem(db, ids)
+	}
+
+bundles:
+	for _, b := range bundles {
+		for _, member := range b.Items {
+			if isVeg == "true" && !member.MenuItem.IsVeg {
+				continue bundles
+			}
+			if _, found := containsAllergen(itemAllergens[member.MenuItemID], exclude); found {
+				continue bundles
+			}
+		}
+		listings = append(listings, newBundleListing(b))
+	}
+	return listings
+}
+
+// orderLine is one menu item to put on an order. Bundle members carry the
+// share of the bundle price they were sold for.
+type orderLine struct {
+	MenuItemID uint
+	Quantity   int
+	BundleID   *uint
+	LineTotal  *float64
+}
+
+// expandOrderItems turns the requested items into order lines, replacing each
+// bundle with its member items. The bundle price is split across the members
+// in proportion to their regular prices, so line totals still add up to it.
+func expandOrderItems(db *gorm.DB, restaurantID uint, reqItems []PlaceOrderItem) ([]orderLine, *apierror.Error) {
+	var lines []orderLine
+	for _, reqItem := range reqItems {
+		if reqItem.Quantity < 1 {
+			return nil, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.quantity_must_be_at_least_1", nil)
+		}
+		if (reqItem.MenuItemID == 0) == (reqItem.BundleID == 0) {
+			return nil, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest,
+				"errors.item_needs_menu_item_or_bundle", nil)
+		}
+		if reqItem.BundleID == 0 {
+			lines = append(lines, orderLine{MenuItemID: reqItem.MenuItemID, Quantity: reqItem.Quantity})
+			continue
+		}
+
+		var bundle models.MenuBundle
+		err := db.Preload("Items.MenuItem").First(&bundle, reqItem.BundleID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.New(http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_bundle", nil)
+		}
+		if err != nil || bundle.RestaurantID != restaurantID {
+			return nil, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.bundle_id_not_found", nil, reqItem.BundleID)
+		}
+		if !bundleAvailable(bundle) {
+			return nil, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.bundle_is_not_available", nil, bundle.Name)
+		}
+
+		total := bundle.BundlePrice * float64(reqItem.Quantity)
+		regular := bundleRegularPrice(bundle)
+		allocated := 0.0
+		for i, member := range bundle.Items {
+			share := total - allocated // the last member takes the rounding remainder
+			if i < len(bundle.Items)-1 {
+				if regular > 0 {
+					share = total * member.MenuItem.Price * float64(member.Quantity) / regular
+				} else {
+					share = total / float64(len(bundle.Items))
+				}
+				share = math.Round(share*100) / 100
+			}
+			allocated += share
+			lines = append(lines, orderLine{
+				MenuItemID: member.MenuItemID,
+				Quantity:   member.Quantity * reqItem.Quantity,
+				BundleID:   &bundle.ID,
+				LineTotal:  &share,
+			})
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateClosureRequest struct {
+	StartsAt string `json:"starts_at" binding:"required"` // YYYY-MM-DD
+	EndsAt   string `json:"ends_at" binding:"required"`   // YYYY-MM-DD, inclusive
+	Reason   string `json:"reason" binding:"max=200"`
+}
+
+// activeClosure returns the closure covering the server-local date of now, if any
+func activeClosure(db *gorm.DB, restaurantID uint, now time.Time) (models.RestaurantClosure, bool) {
+	var closure models.RestaurantClosure
+	today := now.Format(dateLayout)
+	err := db.Where("restaurant_id = ? AND starts_at <= ? AND ends_at >= ?", restaurantID, today, today).
+		Order("ends_at DESC").First(&closure).Error
+	return closure, err == nil
+}
+
+// closedForHoliday returns which of the given restaurants have a closure covering today
+func closedForHoliday(db *gorm.DB, restaurantIDs []uint, now time.Time) map[uint]bool {
+	closed := map[uint]bool{}
+	if len(restaurantIDs) == 0 {
+		return closed
+	}
+	var ids []uint
+	today := now.Format(dateLayout)
+	db.Model(&models.RestaurantClosure{}).
+		Where("restaurant_id IN ? AND starts_at <= ? AND ends_at >= ?", restaurantIDs, today, today).
+		Distinct().Pluck("restaurant_id", &ids)
+	for _, id := range ids {
+		closed[id] = true
+	}
+	return closed
+}
+
+// notClosedForHolidayScope leaves out restaurants with a closure covering the
+// server-local date of now
+func notClosedForHolidayScope(now time.Time) func(*gorm.DB) *gorm.DB {
+	today := now.Format(dateLayout)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`NOT EXISTS (SELECT 1 FROM restaurant_closures
+			WHERE restaurant_closures.restaurant_id = restaurants.id AND starts_at <= ? AND ends_at >= ?)`, today, today)
+	}
+}
+
+// CreateClosure schedules days when the restaurant takes no orders, without
+// touching its weekly operating hours. Owners and staff manage closures alike.
+//
+// @Summary     Schedule a holiday closure
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  CreateClosureRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/closures [post]
+func CreateClosure(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := managedRestaurant(requestDB(c), userID, &restaurant); err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	var req CreateClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	startsAt, err := time.Parse(dateLayout, req.StartsAt)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_starts_at_expected_yyyy_mm_dd", nil)
+		return
+	}
+	endsAt, err := time.Parse(dateLayout, req.EndsAt)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_ends_at_expected_yyyy_mm_dd", nil)
+		return
+	}
+	if endsAt.Before(startsAt) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.ends_at_must_be_on_or_after_starts", nil)
+		return
+	}
+	if req.EndsAt < time.Now().Format(dateLayout) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.ends_at_is_in_the_past", nil)
+		return
+	}
+
+	closure := models.RestaurantClosure{
+		RestaurantID: restaurant.ID,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Reason:       req.Reason,
+		CreatedBy:    userID,
+	}
+	if err := requestDB(c).Create(&closure).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_closure", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Closure scheduled", "closure": closure})
+}
+
+// GetClosures lists the restaurant's closures that haven't ended yet
+//
+// @Summary     List my restaurant's holiday closures
+// @Tags        restaurant
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/closures [get]
+func GetClosures(c *gin.Context) {
+	var restaurant models.Restaurant
+	if err := managedRestaurant(requestDB(c), middleware.GetUserID(c), &restaurant); err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	now := time.Now()
+	closures := []models.RestaurantClosure{}
+	requestDB(c).Where("restaurant_id = ? AND ends_at >= ?", restaurant.ID, now.Format(dateLayout)).
+		Order("starts_at").Find(&closures)
+	_, closedToday := activeClosure(requestDB(c), restaurant.ID, now)
+	c.JSON(http.StatusOK, gin.H{
+		"is_closed_for_holiday": closedToday,
+		"count":                 len(closures),
+		"closures":              closures,
+	})
+}
+
+// DeleteClosure cancels a holiday closure
+//
+// @Summary     Delete a holiday closure
+// @Tags        restaurant
+// @Produce     json
+// @Param       id   path  int  true  "Closure ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/closures/{id} [delete]
+func DeleteClosure(c *gin.Context) {
+	var restaurant models.Restaurant
+	if err := managedRestaurant(requestDB(c), middleware.GetUserID(c), &restaurant); err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	var closure models.RestaurantClosure
+	if err := requestDB(c).Where("id = ? AND restaurant_id = ?", c.Param("id"), restaurant.ID).First(&closure).Error; err != nil {
+		c.Error(err).SetMeta("errors.closure_not_found")
+		c.Abort()
+		return
+	}
+	if err := requestDB(c).Delete(&closure).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_delete_closure", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted"})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+)
+
+func TestStaffManageClosuresLikeTheOwner(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	staff := createUser(t, db, "Staff", models.RoleRestaurant)
+	db.Create(&models.RestaurantStaff{RestaurantID: restaurant.ID, UserID: staff.ID})
+	stranger := createUser(t, db, "Stranger", models.RoleRestaurant)
+	today := time.Now().Format(dateLayout)
+	body := fmt.Sprintf(`{"starts_at":%q,"ends_at":%q,"reason":"Diwali"}`, today, today)
+
+	for _, userID := range []uint{restaurant.OwnerID, staff.ID} {
+		w := serve(CreateClosure, "/restaurant/closures", userID, models.RoleRestaurant, http.MethodPost, "/restaurant/closures", body)
+		wantStatus(t, w, http.StatusCreated)
+		w = serve(GetClosures, "/restaurant/closures", userID, models.RoleRestaurant, http.MethodGet, "/restaurant/closures", "")
+		wantStatus(t, w, http.StatusOK)
+	}
+	var closures []models.RestaurantClosure
+	db.Order("id").Find(&closures)
+	if len(closures) != 2 || closures[1].CreatedBy != staff.ID {
+		t.Fatalf("closures = %+v, want one each from the owner and staff", closures)
+	}
+
+	target := fmt.Sprintf("/restaurant/closures/%d", closures[0].ID)
+	w := serve(CreateClosure, "/restaurant/closures", stranger.ID, models.RoleRestaurant, http.MethodPost, "/restaurant/closures", body)
+	wantStatus(t, w, http.StatusNotFound)
+	w = serve(GetClosures, "/restaurant/closures", stranger.ID, models.RoleRestaurant, http.MethodGet, "/restaurant/closures", "")
+	wantStatus(t, w, http.StatusNotFound)
+	w = serve(DeleteClosure, "/restaurant/closures/:id", stranger.ID, models.RoleRestaurant, http.MethodDelete, target, "")
+	wantStatus(t, w, http.StatusNotFound)
+
+	w = serve(DeleteClosure, "/restaurant/closures/:id", staff.ID, models.RoleRestaurant, http.MethodDelete, target, "")
+	wantStatus(t, w, http.StatusOK)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCODPending lists delivered cash-on-delivery orders whose cash the driver still holds
+//
+// @Summary     Cash I still owe the platform
+// @Tags        driver
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /driver/cod-pending [get]
+func GetCODPending(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var orders []models.Order
+	requestDB(c).Where("driver_id = ? AND payment_method = ? AND status = ? AND cod_collected = ? AND cod_remitted_at IS NULL",
+		driverID, models.PaymentCOD, models.StatusDelivered, true).
+		Order("delivered_at").Find(&orders)
+
+	var owed float64
+	out := make([]gin.H, 0, len(orders))
+	for _, o := range orders {
+		owed += o.CODAmountCollected
+		out = append(out, gin.H{
+			"order_id":             o.ID,
+			"invoice_number":       o.InvoiceNumber,
+			"delivered_at":         o.DeliveredAt,
+			"expected_amount":      o.TotalPrice,
+			"cod_amount_collected": o.CODAmountCollected,
+			"cod_variance":         o.CODVariance,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":       len(out),
+		"total_owed":  math.Round(owed*100) / 100,
+		"pending_cod": out,
+	})
+}
+
+// AdminGetCODCollections summarises cash collected vs expected per driver, in
+// the base currency — admin only
+//
+// @Summary     Cash-on-delivery collections per driver
+// @Tags        admin
+// @Produce     json
+// @Param       driver_id  query  int     false  "Filter by driver"
+// @Param       from       query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to         query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/cod-collections [get]
+func AdminGetCODCollections(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	rows := []struct {
+		DriverID        uint    `json:"driver_id"`
+		DriverName      string  `json:"driver_name"`
+		Orders          int     `json:"orders"`
+		ExpectedAmount  float64 `json:"expected_amount"`
+		CollectedAmount float64 `json:"collected_amount"`
+		Variance        float64 `json:"variance"`
+		MismatchedCount int     `json:"mismatched_orders"`
+		UnremittedTotal float64 `json:"unremitted_amount"`
+	}{}
+	q := requestDB(c).Table("orders").
+		Select("orders.driver_id, users.name AS driver_name, COUNT(*) AS orders, "+
+			"ROUND(SUM(orders.total_price_base), 2) AS expected_amount, "+
+			"ROUND(SUM(orders.cod_amount_collected * orders.exchange_rate), 2) AS collected_amount, "+
+			"ROUND(SUM(orders.cod_variance * orders.exchange_rate), 2) AS variance, "+
+			"SUM(CASE WHEN orders.cod_variance != 0 THEN 1 ELSE 0 END) AS mismatched_count, "+
+			"ROUND(SUM(CASE WHEN orders.cod_remitted_at IS NULL THEN orders.cod_amount_collected * orders.exchange_rate ELSE 0 END), 2) AS unremitted_total").
+		Joins("JOIN users ON users.id = orders.driver_id").
+		Where("orders.payment_method = ? AND orders.cod_collected = ?", models.PaymentCOD, true).
+		Where("orders.created_at >= ? AND orders.created_at < ? AND orders.deleted_at IS NULL", from, to)
+	if s := c.Query("driver_id"); s != "" {
+		driverID, err := strconv.Atoi(s)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.driver_id_must_be_a_number", nil)
+			return
+		}
+		q = q.Where("orders.driver_id = ?", driverID)
+	}
+	q.Group("orders.driver_id, users.name").Order("unremitted_total desc").Scan(&rows)
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":          from.Format(dateLayout),
+		"to":            to.AddDate(0, 0, -1).Format(dateLayout),
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"count":         len(rows),
+		"drivers":       rows,
+	})
+}
+
+// AdminMarkCODRemitted records that a driver has handed over all cash collected so far — admin only
+//
+// @Summary     Mark a driver's COD cash as remitted
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Driver user ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/drivers/{id}/cod-remitted [put]
+func AdminMarkCODRemitted(c *gin.Context) {
+	var driver models.User
+	if err := requestDB(c).Where("role = ?", models.RoleDriver).First(&driver, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.driver_not_found")
+		c.Abort()
+		return
+	}
+	const pending = "driver_id = ? AND payment_method = ? AND cod_collected = ? AND cod_remitted_at IS NULL"
+	var total float64
+	requestDB(c).Model(&models.Order{}).Where(pending, driver.ID, models.PaymentCOD, true).
+		Select("COALESCE(SUM(cod_amount_collected), 0)").Scan(&total)
+	res := requestDB(c).Model(&models.Order{}).Where(pending, driver.ID, models.PaymentCOD, true).
+		Update("cod_remitted_at", time.Now())
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "COD cash marked as remitted",
+		"driver_id":       driver.ID,
+		"orders":          res.RowsAffected,
+		"amount_remitted": math.Round(total*100) / 100,
+	})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"food-delivery-api/models"
+)
+
+func TestCODCollectionsSumTheBaseCurrency(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	driver := createUser(t, db, "Driver", models.RoleDriver)
+	// One INR order collected 10 short, one USD order collected exactly
+	for i, o := range []models.Order{
+		{TotalPrice: 1000, Currency: "INR", ExchangeRate: 0.012, TotalPriceBase: 12, CODAmountCollected: 990, CODVariance: -10},
+		{TotalPrice: 20, Currency: "USD", ExchangeRate: 1, TotalPriceBase: 20, CODAmountCollected: 20},
+	} {
+		o.InvoiceNumber, o.CustomerID, o.RestaurantID, o.DriverID = fmt.Sprint(i), customer.ID, restaurant.ID, &driver.ID
+		o.DeliveryAddress, o.Status = "1 Low St", models.StatusDelivered
+		o.PaymentMethod, o.CODCollected = models.PaymentCOD, true
+		if err := db.Create(&o).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := serve(AdminGetCODCollections, "/cod", 0, models.RoleAdmin, http.MethodGet, "/cod", "")
+	wantStatus(t, w, http.StatusOK)
+	var resp struct {
+		Drivers []struct {
+			Expected   float64 `json:"expected_amount"`
+			Collected  float64 `json:"collected_amount"`
+			Variance   float64 `json:"variance"`
+			Unremitted float64 `json:"unremitted_amount"`
+			Mismatched int     `json:"mismatched_orders"`
+		} `json:"drivers"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Drivers) != 1 {
+		t.Fatalf("drivers = %s", w.Body.String())
+	}
+	got := resp.Drivers[0]
+	if got.Expected != 32 || got.Collected != 31.88 || got.Variance != -0.12 || got.Unremitted != 31.88 || got.Mismatched != 1 {
+		t.Errorf("collections = %+v, want 32 expected and 31.88 collected in the base currency", got)
+	}
+}
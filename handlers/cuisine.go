@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/currency"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	cuisinesCacheKey = "cuisines"
+	cuisinesCacheTTL = 5 * time.Minute
+	popularCuisines  = 5 // the cuisines with the most restaurants are marked popular
+)
+
+// CuisineStats summarises the active restaurants of one cuisine. Cuisines are
+// grouped ignoring case and surrounding spaces. AvgRating only counts rated
+// restaurants and is null when none is; MinDeliveryFee is the lowest base
+// delivery fee in the base currency.
+type CuisineStats struct {
+	Cuisine            string   `json:"cuisine"`
+	RestaurantCount    int      `json:"restaurant_count"`
+	AvgRating          *float64 `json:"avg_rating"`
+	MinDeliveryFee     *float64 `json:"min_delivery_fee"`
+	HasOpenRestaurants bool     `json:"has_open_restaurants"`
+	Popular            bool     `json:"popular"`
+}
+
+// CuisineAnalytics adds order figures for a date range to a cuisine's stats.
+// Revenue is delivered orders only, in the base currency.
+type CuisineAnalytics struct {
+	CuisineStats
+	TotalOrders  int64   `json:"total_orders"`
+	TotalRevenue float64 `json:"total_revenue"`
+}
+
+// cuisineKey is how cuisines are grouped
+func cuisineKey(cuisine string) string {
+	return strings.ToLower(strings.TrimSpace(cuisine))
+}
+
+// cuisineStats groups active restaurants by cuisine, most restaurants first
+func cuisineStats(db *gorm.DB) []CuisineStats {
+	var restaurants []models.Restaurant
+	db.Select("id, cuisine, currency, base_delivery_fee, rating, review_count, is_open").
+		Where("is_active = ? AND TRIM(cuisine) <> ''", true).
+		Order("id").Find(&restaurants)
+	ids := make([]uint, len(restaurants))
+	for i, r := range restaurants {
+		ids[i] = r.ID
+	}
+	closed := closedForHoliday(db, ids, time.Now())
+	base := sysconfig.Get(sysconfig.KeyBaseCurrency)
+
+	type group struct {
+		stats       CuisineStats
+		ratingSum   float64
+		ratingCount int
+	}
+	groups := map[string]*group{}
+	var order []string
+	for _, r := range restaurants {
+		key := cuisineKey(r.Cuisine)
+		g := groups[key]
+		if g == nil {
+			g = &group{stats: CuisineStats{Cuisine: strings.TrimSpace(r.Cuisine)}}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.stats.RestaurantCount++
+		if r.ReviewCount > 0 {
+			g.ratingSum += r.Rating
+			g.ratingCount++
+		}
+		if r.IsOpen && !closed[r.ID] {
+			g.stats.HasOpenRestaurants = true
+		}
+		fee := r.BaseDeliveryFee
+		if r.Currency != "" && r.Currency != base {
+			converted, _, err := currency.Default.Convert(fee, r.Currency, base)
+			if err != nil {
+				continue
+			}
+			fee = converted
+		}
+		fee = roundCents(fee)
+		if g.stats.MinDeliveryFee == nil || fee < *g.stats.MinDeliveryFee {
+			g.stats.MinDeliveryFee = &fee
+		}
+	}
+
+	out := make([]CuisineStats, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		if g.ratingCount > 0 {
+			avg := math.Round(g.ratingSum/float64(g.ratingCount)*100) / 100
+			g.stats.AvgRating = &avg
+		}
+		out = append(out, g.stats)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].RestaurantCount != out[j].RestaurantCount {
+			return out[i].RestaurantCount > out[j].RestaurantCount
+		}
+		return cuisineKey(out[i].Cuisine) < cuisineKey(out[j].Cuisine)
+	})
+	for i := 0; i < len(out) && i < popularCuisines; i++ {
+		out[i].Popular = true
+	}
+	return out
+}
+
+// ListCuisines lists the cuisines of active restaurants with how many
+// restaurants serve each, their average rating, the cheapest delivery fee and
+// whether any is open now. The five with the most restaurants are popular.
+// Cached for 5 minutes.
+//
+// @Summary     List cuisines
+// @Tags        public
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Router      /cuisines [get]
+func ListCuisines(c *gin.Context) {
+	if body, ok := cache.Default.Get(cuisinesCacheKey); ok {
+		writeCachedJSON(c, body)
+		return
+	}
+	cuisines := cuisineStats(requestDB(c))
+	body, err := json.Marshal(gin.H{
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"count":         len(cuisines),
+		"cuisines":      cuisines,
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_list_cuisines", nil)
+		return
+	}
+	// A query cut short by a client going away mustn't be cached for everyone
+	if c.Request.Context().Err() == nil {
+		cache.Default.Set(cuisinesCacheKey, body, cuisinesCacheTTL)
+	}
+	writeCachedJSON(c, body)
+}
+
+// ListCuisineRestaurants lists the open restaurants of a cuisine, matched the
+// way cuisines are grouped. It takes the other filters of GET /restaurants.
+//
+// @Summary     Open restaurants of a cuisine
+// @Tags        public
+// @Produce     json
+// @Param       name        path   string  true   "Cuisine"
+// @Param       search      query  string  false  "Search by name"
+// @Param       min_rating  query  number  false  "Only restaurants rated at least this"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Router      /cuisines/{name}/restaurants [get]
+func ListCuisineRestaurants(c *gin.Context) {
+	query := requestDB(c).Where("is_active = ? AND LOWER(TRIM(cuisine)) = ?", true, cuisineKey(c.Param("name")))
+	listRestaurants(c, query, true)
+}
+
+// AdminGetCuisineAnalytics adds, to each cuisine's stats, the orders placed at
+// its restaurants in the date range and the revenue of those delivered —
+// admin only
+//
+// @Summary     Orders and revenue by cuisine
+// @Tags        admin
+// @Produce     json
+// @Param       from  query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to    query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/analytics/cuisines [get]
+func AdminGetCuisineAnalytics(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var rows []struct {
+		Cuisine      string
+		TotalOrders  int64
+		TotalRevenue float64
+	}
+	requestDB(c).Model(&models.Order{}).
+		Select("LOWER(TRIM(restaurants.cuisine)) AS cuisine, COUNT(*) AS total_orders, "+
+			"COALESCE(SUM(CASE WHEN orders.status = ? THEN orders.total_price_base ELSE 0 END), 0) AS total_revenue",
+			models.StatusDelivered).
+		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("orders.created_at >= ? AND orders.created_at < ?", from, to).
+		Group("LOWER(TRIM(restaurants.cuisine))").
+		Scan(&rows)
+	byCuisine := make(map[string]int, len(rows))
+	for i, r := range rows {
+		byCuisine[r.Cuisine] = i
+	}
+
+	stats := cuisineStats(requestDB(c))
+	out := make([]CuisineAnalytics, len(stats))
+	for i, s := range stats {
+		out[i] = CuisineAnalytics{CuisineStats: s}
+		if j, ok := byCuisine[cuisineKey(s.Cuisine)]; ok {
+			out[i].TotalOrders = rows[j].TotalOrders
+			out[i].TotalRevenue = roundCents(rows[j].TotalRevenue)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":          from.Format(dateLayout),
+		"to":            to.AddDate(0, 0, -1).Format(dateLayout),
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"count":         len(out),
+		"cuisines":      out,
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// restaurantNames lists the names in a restaurant list response
+func restaurantNames(t *testing.T, handler gin.HandlerFunc, route, target string) []string {
+	t.Helper()
+	w := serve(handler, route, 0, "", http.MethodGet, target, "")
+	wantStatus(t, w, http.StatusOK)
+	var body struct{ Restaurants []models.Restaurant }
+	json.Unmarshal(w.Body.Bytes(), &body)
+	names := make([]string, len(body.Restaurants))
+	for i, r := range body.Restaurants {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func TestCuisineRestaurantsMatchExactlyAndSkipHolidays(t *testing.T) {
+	db := newTestDB(t)
+	owner := createUser(t, db, "Owner", models.RoleRestaurant)
+	add := func(name, cuisine string, open bool) models.Restaurant {
+		t.Helper()
+		r := models.Restaurant{OwnerID: owner.ID, Name: name, Cuisine: cuisine, Address: "1 High St"}
+		if err := db.Create(&r).Error; err != nil {
+			t.Fatal(err)
+		}
+		db.Model(&r).Update("is_open", open)
+		return r
+	}
+	add("Thali House", " Indian ", true)
+	add("Curry Co", "indian", true)
+	add("Wok On", "Indian-Chinese", true)
+	add("Late Dosa", "Indian", false)
+	holiday := add("Diwali Break", "Indian", true)
+	today := time.Now().Format(dateLayout)
+	db.Create(&models.RestaurantClosure{RestaurantID: holiday.ID, StartsAt: today, EndsAt: today})
+
+	got := restaurantNames(t, ListCuisineRestaurants, "/cuisines/:name/restaurants", "/cuisines/INDIAN/restaurants")
+	if want := []string{"Thali House", "Curry Co"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cuisine restaurants = %v, want %v", got, want)
+	}
+	got = restaurantNames(t, ListRestaurants, "/restaurants", "/restaurants?open=true")
+	if want := []string{"Thali House", "Curry Co", "Wok On"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("open restaurants = %v, want %v", got, want)
+	}
+}
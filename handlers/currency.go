@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/currency"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// convertToBase snapshots the order's currency and exchange rate and fills in
+// TotalPriceBase from TotalPrice
+func convertToBase(order *models.Order, restaurantCurrency string) error {
+	if restaurantCurrency == "" {
+		restaurantCurrency = "USD"
+	}
+	base := sysconfig.Get(sysconfig.KeyBaseCurrency)
+	order.Currency = restaurantCurrency
+	order.ExchangeRate = 1
+	order.TotalPriceBase = order.TotalPrice
+	if restaurantCurrency == base {
+		return nil
+	}
+	converted, rate, err := currency.Default.Convert(order.TotalPrice, restaurantCurrency, base)
+	if err != nil {
+		log.Printf("currency: failed to convert %s to %s: %v", restaurantCurrency, base, err)
+		return apierror.New(http.StatusServiceUnavailable, apierror.ErrUnavailable,
+			"errors.restaurant_cannot_price_orders", gin.H{"currency": restaurantCurrency, "base_currency": base})
+	}
+	order.ExchangeRate = rate
+	order.TotalPriceBase = converted
+	return nil
+}
+
+// toBase converts an amount in an order's currency with the order's snapshot rate
+func toBase(amount, exchangeRate float64) float64 {
+	return math.Round(amount*exchangeRate*100) / 100
+}
@@ -1,170 +1,495 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"food-delivery-api/config"
+	"food-delivery-api/apierror"
 	"food-delivery-api/middleware"
 	"food-delivery-api/models"
+	"food-delivery-api/ratelimit"
 	"food-delivery-api/statemachine"
+	"food-delivery-api/sysconfig"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+type PlaceOrderItem struct {
+	MenuItemID uint `json:"menu_item_id" binding:"required_without=BundleID"`
+	// Order a whole bundle instead of a single item; all its items are added
+	BundleID uint `json:"bundle_id"`
+	Quantity int  `json:"quantity" binding:"required,min=1"`
+}
+
 type PlaceOrderRequest struct {
-	RestaurantID    uint   `json:"restaurant_id" binding:"required"`
-	DeliveryAddress string `json:"delivery_address" binding:"required"`
-	Notes           string `json:"notes"`
-	Items           []struct {
-		MenuItemID uint `json:"menu_item_id" binding:"required"`
-		Quantity   int  `json:"quantity" binding:"required,min=1"`
-	} `json:"items" binding:"required,min=1"`
+	RestaurantID    uint             `json:"restaurant_id" binding:"required"`
+	DeliveryAddress string           `json:"delivery_address" binding:"required_without=AddressID,excluded_with=AddressID"`
+	AddressID       *uint            `json:"address_id"` // a saved address, instead of delivery_address; its coordinates are copied to the order
+	Notes           string           `json:"notes"`
+	Items           []PlaceOrderItem `json:"items" binding:"required,min=1"`
+	// Allergens the customer must avoid; defaults to saved dietary preferences when omitted
+	ExcludeAllergens []string `json:"exclude_allergens"`
+	// "prepaid" (default) or "cod"
+	PaymentMethod string `json:"payment_method"`
+	// For the driver, in the restaurant's currency
+	TipAmount float64 `json:"tip_amount" binding:"min=0"`
 }
 
 // PlaceOrder creates a new order (customer only)
+//
+// @Summary     Place an order
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       body  body  PlaceOrderRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     429  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders [post]
 func PlaceOrder(c *gin.Context) {
 	customerID := middleware.GetUserID(c)
 
 	var req PlaceOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	order, apiErr := placeOrder(requestDB(c), customerID, req)
+	if apiErr != nil {
+		if retryAfter, ok := apiErr.Details["retry_after_seconds"].(int); ok {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+		}
+		apierror.RespondError(c, apiErr)
 		return
 	}
 
+	order.Links = orderLinks(c, &order)
+	resp := gin.H{
+		"message":        "Order placed successfully",
+		"order":          order,
+		"estimated_time": order.EstimatedTime,
+		"price_breakdown": gin.H{
+			"subtotal":     order.Subtotal(),
+			"delivery_fee": order.DeliveryFee,
+			"service_fee":  order.ServiceFee,
+			"tip":          order.TipAmount,
+			"total":        order.TotalPrice,
+		},
+		"pairing_suggestions": pairingSuggestions(requestDB(c), &order),
+	}
+	if followsDiet(requestDB(c), customerID, models.DietCalorieConscious) {
+		resp["nutritional_summary"] = orderNutrition(requestDB(c), &order)
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// placeOrder validates and creates an order for a customer. It is shared by the
+// PlaceOrder handler and background jobs such as recurring orders. The
+// handler passes the request-scoped DB so a client that goes away stops its
+// queries; jobs pass config.DB.
+func placeOrder(db *gorm.DB, customerID uint, req PlaceOrderRequest) (models.Order, *apierror.Error) {
+	switch req.PaymentMethod {
+	case "":
+		req.PaymentMethod = models.PaymentPrepaid
+	case models.PaymentPrepaid, models.PaymentCOD:
+	default:
+		return models.Order{}, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.payment_method_must_be_prepaid_or_cod", nil)
+	}
+
 	// Validate restaurant exists and is open
 	var restaurant models.Restaurant
-	if err := config.DB.First(&restaurant, req.RestaurantID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
-		return
+	if err := db.Where("is_active = ?", true).First(&restaurant, req.RestaurantID).Error; err != nil {
+		return models.Order{}, apierror.New(http.StatusNotFound, apierror.ErrNotFound, "errors.restaurant_not_found", nil)
+	}
+	if closure, closed := activeClosure(db, restaurant.ID, time.Now()); closed {
+		return models.Order{}, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.restaurant_is_closed_for_a_holiday",
+			gin.H{"reason": closure.Reason, "ends_at": closure.EndsAt})
 	}
 	if !restaurant.IsOpen {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Restaurant is currently closed"})
-		return
+		return models.Order{}, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.restaurant_is_currently_closed", nil)
+	}
+	if len(req.Items) < restaurant.MinItemCount {
+		return models.Order{}, apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.too_few_order_items",
+			gin.H{"min_item_count": restaurant.MinItemCount}, restaurant.MinItemCount)
+	}
+	if len(req.Items) > restaurant.MaxItemCount {
+		return models.Order{}, apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.too_many_order_items",
+			gin.H{"max_item_count": restaurant.MaxItemCount}, restaurant.MaxItemCount)
 	}
 
-	// Build order items and calculate total
-	var orderItems []models.OrderItem
-	var total float64
+	// Per-restaurant token bucket so a spike can't swamp a small kitchen
+	if ok, wait := ratelimit.AllowOrder(restaurant.ID, restaurant.MaxOrdersPerMinute); !ok {
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		return models.Order{}, apierror.New(http.StatusTooManyRequests, apierror.ErrRateLimited,
+			"errors.restaurant_throttled",
+			gin.H{"retry_after_seconds": retryAfter})
+	}
 
-	for _, reqItem := range req.Items {
-		var menuItem models.MenuItem
-		if err := config.DB.First(&menuItem, reqItem.MenuItemID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Menu item not found: " + string(rune(reqItem.MenuItemID))})
-			return
+	// Allergen safety check — an explicit list (even empty) overrides saved preferences
+	excluded := req.ExcludeAllergens
+	if excluded == nil {
+		excluded = savedAllergens(db, customerID)
+	} else {
+		excluded = parseNameList(strings.Join(excluded, ","))
+	}
+	lines, apiErr := expandOrderItems(db, restaurant.ID, req.Items)
+	if apiErr != nil {
+		return models.Order{}, apiErr
+	}
+	var itemAllergens map[uint][]string
+	if len(excluded) > 0 {
+		ids := make([]uint, len(lines))
+		for i, line := range lines {
+			ids[i] = line.MenuItemID
 		}
-		if menuItem.RestaurantID != req.RestaurantID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Menu item does not belong to this restaurant"})
-			return
+		itemAllergens = allergensByItem(db, ids)
+	}
+
+	var deliveryLat, deliveryLng *float64
+	if req.AddressID != nil {
+		var address models.CustomerAddress
+		if err := db.Where("id = ? AND customer_id = ?", *req.AddressID, customerID).First(&address).Error; err != nil {
+			return models.Order{}, apierror.New(http.StatusNotFound, apierror.ErrNotFound, "errors.address_not_found", nil)
 		}
-		if !menuItem.IsAvailable {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Menu item '" + menuItem.Name + "' is not available"})
-			return
+		req.DeliveryAddress = address.Address
+		if address.GeocodedAt != nil {
+			deliveryLat, deliveryLng = &address.Latitude, &address.Longitude
 		}
-		lineTotal := menuItem.Price * float64(reqItem.Quantity)
-		total += lineTotal
-		orderItems = append(orderItems, models.OrderItem{
-			MenuItemID: menuItem.ID,
-			Quantity:   reqItem.Quantity,
-			Price:      menuItem.Price,
-			Name:       menuItem.Name,
-		})
 	}
 
+	// Subscribers and gold-tier loyalty members get free delivery; everyone
+	// else pays by distance once the subtotal is known
+	_, subscribed := activeSubscription(db, customerID)
+	freeDelivery := subscribed || hasFreeDeliveryPerk(db, customerID)
+	distanceKm := deliveryDistanceKm(restaurant, deliveryLat, deliveryLng)
+
 	// Novelty: calculate estimated delivery time (base 30 min + 5 per item)
-	estimatedTime := 30 + (5 * len(req.Items))
+	estimatedTime := estimateDeliveryMinutes(restaurant, len(req.Items))
 
 	order := models.Order{
-		CustomerID:      customerID,
-		RestaurantID:    req.RestaurantID,
-		Status:          models.StatusPlaced,
-		TotalPrice:      total,
-		DeliveryAddress: req.DeliveryAddress,
-		Notes:           req.Notes,
-		EstimatedTime:   estimatedTime,
-		Items:           orderItems,
-	}
-
-	if err := config.DB.Create(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place order"})
-		return
+		CustomerID:          customerID,
+		RestaurantID:        req.RestaurantID,
+		Status:              models.StatusPlaced,
+		SubscriptionApplied: subscribed,
+		PaymentMethod:       req.PaymentMethod,
+		DeliveryAddress:     req.DeliveryAddress,
+		DeliveryLat:         deliveryLat,
+		DeliveryLng:         deliveryLng,
+		DistanceKm:          distanceKm,
+		Notes:               req.Notes,
+		TipAmount:           math.Round(req.TipAmount*100) / 100,
+		EstimatedTime:       estimatedTime,
 	}
 
-	// Record initial status history
-	history := models.OrderStatusHistory{
-		OrderID:   order.ID,
-		ToStatus:  models.StatusPlaced,
-		ChangedBy: customerID,
-		Note:      "Order placed by customer",
-	}
-	config.DB.Create(&history)
+	// Item checks, stock decrements and all inserts commit together or not at all
+	stockTaken := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := checkHourlyOrderLimit(tx, customerID, time.Now()); err != nil {
+			return err
+		}
 
-	config.DB.Preload("Items.MenuItem").Preload("Restaurant").First(&order, order.ID)
+		var total float64
+		for _, line := range lines {
+			var menuItem models.MenuItem
+			if err := tx.First(&menuItem, line.MenuItemID).Error; err != nil {
+				return apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.menu_item_id_not_found", nil, line.MenuItemID)
+			}
+			if menuItem.RestaurantID != req.RestaurantID {
+				return apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.menu_item_not_in_restaurant", nil)
+			}
+			if !menuItem.IsAvailable {
+				return apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.menu_item_is_not_available", nil, menuItem.Name)
+			}
+			if !menuItem.InSeason(time.Now().Month()) {
+				return apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.menu_item_out_of_season", nil, menuItem.Name)
+			}
+			if allergen, found := containsAllergen(itemAllergens[menuItem.ID], excluded); found {
+				return apierror.New(http.StatusBadRequest, apierror.ErrBadRequest,
+					"errors.menu_item_contains_an_excluded_allergen", gin.H{"allergen": allergen}, menuItem.Name)
+			}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message":        "Order placed successfully",
-		"order":          order,
-		"estimated_time": estimatedTime,
+			// Conditional decrement so two concurrent orders can't oversell the last unit
+			if menuItem.TrackStock {
+				res := tx.Model(&models.MenuItem{}).
+					Where("id = ? AND stock_quantity >= ?", menuItem.ID, line.Quantity).
+					UpdateColumn("stock_quantity", gorm.Expr("stock_quantity - ?", line.Quantity))
+				if res.Error != nil {
+					return res.Error
+				}
+				if res.RowsAffected == 0 {
+					return apierror.New(http.StatusConflict, apierror.ErrConflict,
+						"errors.not_enough_stock_for", gin.H{"available": menuItem.StockQuantity}, menuItem.Name)
+				}
+				stockTaken = true
+			}
+
+			// Bundle members are priced at their share of the bundle price
+			price := menuItem.Price
+			lineTotal := menuItem.Price * float64(line.Quantity)
+			if line.LineTotal != nil {
+				lineTotal = *line.LineTotal
+				price = lineTotal / float64(line.Quantity)
+			}
+			total += lineTotal
+			order.Items = append(order.Items, models.OrderItem{
+				MenuItemID: menuItem.ID,
+				BundleID:   line.BundleID,
+				Quantity:   line.Quantity,
+				Price:      price,
+				Name:       menuItem.Name,
+			})
+		}
+		if !freeDelivery {
+			order.DeliveryFee = deliveryFee(restaurant, distanceKm, total)
+		}
+		order.ServiceFee = math.Round(total*sysconfig.Float(sysconfig.KeyServiceFeePercent)) / 100
+		order.TotalPrice = total + order.DeliveryFee + order.ServiceFee + order.TipAmount
+		if err := convertToBase(&order, restaurant.Currency); err != nil {
+			return err
+		}
+
+		invoiceNumber, err := nextInvoiceNumber(tx, time.Now())
+		if err != nil {
+			return err
+		}
+		order.InvoiceNumber = invoiceNumber
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		// Record initial status history
+		history := models.OrderStatusHistory{
+			OrderID:   order.ID,
+			ToStatus:  models.StatusPlaced,
+			ChangedBy: customerID,
+			Note:      "Order placed by customer",
+		}
+		return tx.Create(&history).Error
 	})
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) {
+			return models.Order{}, apiErr
+		}
+		return models.Order{}, apierror.New(http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_place_order", nil)
+	}
+
+	publishOrderPlaced(order)
+	if stockTaken {
+		invalidateMenuCache(order.RestaurantID)
+	}
+
+	db.Preload("Items.MenuItem").Preload("Restaurant").First(&order, order.ID)
+	return order, nil
 }
 
-// GetMyOrders returns all orders for the logged-in customer
+// checkHourlyOrderLimit caps how many orders one customer can place in an
+// hour at MAX_ORDERS_PER_HOUR. It counts from the database inside the order's
+// transaction, so it holds across concurrent requests and server instances.
+func checkHourlyOrderLimit(tx *gorm.DB, customerID uint, now time.Time) error {
+	limit := sysconfig.Int(sysconfig.KeyMaxOrdersPerHour)
+	if limit <= 0 {
+		return nil
+	}
+	since := now.Add(-time.Hour)
+	var count int64
+	if err := tx.Model(&models.Order{}).Where("customer_id = ? AND created_at > ?", customerID, since).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count < int64(limit) {
+		return nil
+	}
+	// The window frees up a slot when the oldest order in it turns an hour old
+	var oldest models.Order
+	tx.Select("created_at").Where("customer_id = ? AND created_at > ?", customerID, since).
+		Order("created_at").First(&oldest)
+	retryAfter := int(math.Ceil(oldest.CreatedAt.Add(time.Hour).Sub(now).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	return apierror.New(http.StatusTooManyRequests, apierror.ErrRateLimited,
+		"errors.you_can_place_at_most_orders_per_hour",
+		gin.H{"limit": limit, "retry_after_seconds": retryAfter}, limit)
+}
+
+// GetMyOrders returns the logged-in customer's orders, newest first, optionally
+// searched by item name, restaurant name and date
+//
+// @Summary     List my orders
+// @Tags        customer
+// @Produce     json
+// @Param       q           query  string  false  "Only orders with an item whose name contains this"
+// @Param       restaurant  query  string  false  "Only orders from restaurants whose name contains this"
+// @Param       from        query  string  false  "Placed on or after this date (YYYY-MM-DD)"
+// @Param       to          query  string  false  "Placed on or before this date (YYYY-MM-DD)"
+// @Param       page        query  int     false  "Page number (default 1)"
+// @Param       page_size   query  int     false  "Page size (default 20, max 100)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders [get]
 func GetMyOrders(c *gin.Context) {
 	customerID := middleware.GetUserID(c)
-	var orders []models.Order
-	config.DB.Preload("Items.MenuItem").Preload("Restaurant").
-		Where("customer_id = ?", customerID).
-		Order("created_at desc").
+	page, pageSize := parsePagination(c)
+	query := requestDB(c).Model(&models.Order{}).Where("orders.customer_id = ?", customerID)
+
+	if q := c.Query("q"); q != "" {
+		query = query.Where("EXISTS (?)", requestDB(c).Model(&models.OrderItem{}).Select("1").
+			Where("order_items.order_id = orders.id AND order_items.name LIKE ?", "%"+q+"%"))
+	}
+	if name := c.Query("restaurant"); name != "" {
+		query = query.Where("orders.restaurant_id IN (?)", requestDB(c).Model(&models.Restaurant{}).Select("id").
+			Where("name LIKE ?", "%"+name+"%"))
+	}
+	if s := c.Query("from"); s != "" {
+		from, err := time.Parse(dateLayout, s)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_from_date_expected_yyyy_mm_dd", nil)
+			return
+		}
+		query = query.Where("orders.created_at >= ?", from)
+	}
+	if s := c.Query("to"); s != "" {
+		to, err := time.Parse(dateLayout, s)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_to_date_expected_yyyy_mm_dd", nil)
+			return
+		}
+		query = query.Where("orders.created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	query.Count(&total)
+	orders := []models.Order{}
+	query.Preload("Items.MenuItem").Preload("Restaurant").
+		Order("orders.created_at desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
 		Find(&orders)
-	c.JSON(http.StatusOK, gin.H{"count": len(orders), "orders": orders})
+	setOrderLinks(c, orders)
+	c.JSON(http.StatusOK, gin.H{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"count":     len(orders),
+		"orders":    orders,
+	})
 }
 
-// GetOrderDetail returns a single order's full detail with history
+// GetOrderDetail returns a single order's full detail with history. The
+// status also comes as a display label in the requested locale.
+//
+// @Summary     Get one of my orders
+// @Tags        customer
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Param       locale  query  string  false  "Locale for the status label (default: Accept-Language, then en)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id} [get]
 func GetOrderDetail(c *gin.Context) {
 	customerID := middleware.GetUserID(c)
 	orderID := c.Param("id")
 
 	var order models.Order
-	if err := config.DB.
+	if err := requestDB(c).
 		Preload("Items.MenuItem").
 		Preload("Restaurant").
 		Preload("StatusHistory").
 		Preload("Driver").
 		First(&order, orderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
 		return
 	}
 	if order.CustomerID != customerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "This order does not belong to you"})
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
 		return
 	}
 
+	undelivered := []models.OrderItem{}
+	for _, item := range order.Items {
+		if !item.WasDelivered {
+			undelivered = append(undelivered, item)
+		}
+	}
+
 	// Novelty: compute time elapsed
 	elapsed := time.Since(order.CreatedAt).Minutes()
+	order.Links = orderLinks(c, &order)
 	c.JSON(http.StatusOK, gin.H{
-		"order":           order,
-		"minutes_elapsed": int(elapsed),
+		"order":                order,
+		"status_display_label": statemachine.DisplayLabel(order.Status, requestLocale(c)),
+		"minutes_elapsed":      int(elapsed),
+		"partial_delivery":     order.PartialDelivery,
+		"undelivered_items":    undelivered,
 	})
 }
 
-// CancelOrder cancels an order (customer can cancel PLACED or CONFIRMED)
+type CancelOrderRequest struct {
+	// changed_mind, wait_too_long, wrong_items, wrong_address or other
+	Reason string `json:"reason" binding:"omitempty,oneof=changed_mind wait_too_long wrong_items wrong_address other"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// cancelWaitThreshold is how long an order sits in PLACED before a
+// wait_too_long cancel counts against the restaurant
+const cancelWaitThreshold = 10 * time.Minute
+
+// CancelOrder cancels an order (customer can cancel PLACED or CONFIRMED),
+// optionally saying why
+//
+// @Summary     Cancel an order
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                 true   "Order ID"
+// @Param       body  body  CancelOrderRequest  false  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/cancel [put]
 func CancelOrder(c *gin.Context) {
 	customerID := middleware.GetUserID(c)
 	orderID := c.Param("id")
 
+	var req CancelOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+
 	var order models.Order
-	if err := config.DB.First(&order, orderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	if err := requestDB(c).First(&order, orderID).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
 		return
 	}
 	if order.CustomerID != customerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "This order does not belong to you"})
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
 		return
 	}
 
 	if err := statemachine.CanTransition(order.Status, models.StatusCancelled, "customer"); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"error":         "Cannot cancel order",
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrInvalidTransition, "errors.cannot_cancel_order", gin.H{
 			"reason":        err.Error(),
 			"current_state": order.Status,
 		})
@@ -172,16 +497,58 @@ func CancelOrder(c *gin.Context) {
 	}
 
 	prevStatus := order.Status
-	config.DB.Model(&order).Update("status", models.StatusCancelled)
+	note := "Order cancelled by customer"
+	if req.Reason != "" {
+		note += ": " + req.Reason
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		// Conditional on the status read so a racing transition can't be cancelled over
+		res := tx.Model(&models.Order{}).Where("id = ? AND status = ?", order.ID, prevStatus).
+			Updates(map[string]interface{}{
+				"status":              models.StatusCancelled,
+				"cancellation_reason": req.Reason,
+				"cancellation_note":   req.Note,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.order_modified_concurrently", nil)
+		}
+		if err := restoreStock(tx, order.ID); err != nil {
+			return err
+		}
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: prevStatus,
+			ToStatus:   models.StatusCancelled,
+			ChangedBy:  customerID,
+			Note:       note,
+		}).Error
+	})
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_cancel_order", nil)
+		return
+	}
+	publishStatusChange(order, prevStatus, models.StatusCancelled)
+	invalidateMenuCache(order.RestaurantID)
 
-	history := models.OrderStatusHistory{
-		OrderID:    order.ID,
-		FromStatus: prevStatus,
-		ToStatus:   models.StatusCancelled,
-		ChangedBy:  customerID,
-		Note:       "Order cancelled by customer",
+	// A customer giving up on an unconfirmed order counts like an auto-cancel
+	now := time.Now()
+	if req.Reason == models.CancelWaitTooLong && prevStatus == models.StatusPlaced && now.Sub(order.CreatedAt) > cancelWaitThreshold {
+		recordAutoCancel(order.RestaurantID, now)
 	}
-	config.DB.Create(&history)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Order cancelled successfully", "order_id": order.ID})
+	order.Status = models.StatusCancelled
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Order cancelled successfully",
+		"order_id":       order.ID,
+		"invoice_number": order.InvoiceNumber,
+		"links":          orderLinks(c, &order),
+	})
 }
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Customer analytics never look further back than this
+const customerAnalyticsMonths = 12
+
+// Restaurants listed per month before the rest go into "other"
+const spendingTopRestaurants = 3
+
+type RestaurantSpend struct {
+	RestaurantName string  `json:"restaurant_name"`
+	Amount         float64 `json:"amount"`
+}
+
+type MonthlySpend struct {
+	Month     int               `json:"month"` // 1-12
+	Total     float64           `json:"total"`
+	Breakdown []RestaurantSpend `json:"breakdown"`
+	Other     float64           `json:"other"`
+}
+
+// GetSpendingAnalytics breaks the customer's delivered-order spend down by
+// month and restaurant, in the base currency
+//
+// @Summary     My monthly spending by restaurant
+// @Tags        customer
+// @Produce     json
+// @Param       year  query  int  false  "Calendar year (default current); only the last 12 months are counted"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/analytics/spending [get]
+func GetSpendingAnalytics(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	now := time.Now()
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(now.Year())))
+	if err != nil || year < 2000 || year > now.Year() {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_year", nil)
+		return
+	}
+
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	if cutoff := now.AddDate(0, -customerAnalyticsMonths, 0); start.Before(cutoff) {
+		start = cutoff
+	}
+	end := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var rows []struct {
+		Month          string
+		RestaurantName string
+		Amount         float64
+	}
+	requestDB(c).Table("orders").
+		Select("strftime('%m', orders.created_at) AS month, restaurants.name AS restaurant_name, SUM(orders.total_price_base) AS amount").
+		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("orders.customer_id = ? AND orders.status = ? AND orders.created_at >= ? AND orders.created_at < ? AND orders.deleted_at IS NULL",
+			customerID, models.StatusDelivered, start, end).
+		Group("month, orders.restaurant_id").
+		Scan(&rows)
+
+	byMonth := map[int][]RestaurantSpend{}
+	for _, r := range rows {
+		m, err := strconv.Atoi(r.Month)
+		if err != nil {
+			continue
+		}
+		byMonth[m] = append(byMonth[m], RestaurantSpend{RestaurantName: r.RestaurantName, Amount: r.Amount})
+	}
+
+	months := []MonthlySpend{}
+	var yearTotal float64
+	for m := 1; m <= 12; m++ {
+		spends, ok := byMonth[m]
+		if !ok {
+			continue
+		}
+		sort.Slice(spends, func(i, j int) bool { return spends[i].Amount > spends[j].Amount })
+		entry := MonthlySpend{Month: m, Breakdown: []RestaurantSpend{}}
+		for i, s := range spends {
+			entry.Total += s.Amount
+			if i < spendingTopRestaurants {
+				entry.Breakdown = append(entry.Breakdown, s)
+			} else {
+				entry.Other += s.Amount
+			}
+		}
+		yearTotal += entry.Total
+		months = append(months, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"year":          year,
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"total":         yearTotal,
+		"months":        months,
+	})
+}
+
+// GetFavoriteItems lists the items the customer has ordered most over the last 12 months
+//
+// @Summary     My most-ordered items
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /customer/analytics/favorite-items [get]
+func GetFavoriteItems(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	items := []struct {
+		ItemName      string `json:"item_name"`
+		TotalQuantity int    `json:"total_quantity"`
+	}{}
+	requestDB(c).Table("order_items").
+		Select("order_items.name AS item_name, SUM(order_items.quantity) AS total_quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.customer_id = ? AND orders.status <> ? AND orders.created_at >= ? AND orders.deleted_at IS NULL",
+			customerID, models.StatusCancelled, time.Now().AddDate(0, -customerAnalyticsMonths, 0)).
+		Group("order_items.name").
+		Order("total_quantity desc").
+		Limit(10).
+		Scan(&items)
+
+	c.JSON(http.StatusOK, gin.H{"count": len(items), "items": items})
+}
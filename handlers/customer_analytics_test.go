@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+)
+
+func TestSpendingAnalyticsSumsTheBaseCurrency(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	// 1000 INR at 0.012 and 20 USD at 1 are 32 in the base currency, not 1020
+	for i, o := range []models.Order{
+		{TotalPrice: 1000, Currency: "INR", ExchangeRate: 0.012, TotalPriceBase: 12},
+		{TotalPrice: 20, Currency: "USD", ExchangeRate: 1, TotalPriceBase: 20},
+	} {
+		o.InvoiceNumber, o.CustomerID, o.RestaurantID = fmt.Sprint(i), customer.ID, restaurant.ID
+		o.DeliveryAddress, o.Status = "1 Low St", models.StatusDelivered
+		if err := db.Create(&o).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := serve(GetSpendingAnalytics, "/spending", customer.ID, models.RoleCustomer, http.MethodGet,
+		fmt.Sprintf("/spending?year=%d", time.Now().Year()), "")
+	wantStatus(t, w, http.StatusOK)
+	var resp struct {
+		Total  float64        `json:"total"`
+		Months []MonthlySpend `json:"months"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 32 || len(resp.Months) != 1 || resp.Months[0].Breakdown[0].Amount != 32 {
+		t.Errorf("spending = %s, want 32 in the base currency", w.Body.String())
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLTVLimit        = 50
+	maxLTVLimit            = 500
+	defaultChurnAfterDays  = 60
+	defaultChurnMinOrders  = 2
+	maxChurnInactivityDays = 730
+)
+
+// ltvSorts maps ?sort_by= to the column customers are ranked by
+var ltvSorts = map[string]string{
+	"total_spend":     "total_spend",
+	"total_orders":    "total_orders",
+	"avg_order_value": "avg_order_value",
+	"last_order_at":   "last_order_at",
+}
+
+// CustomerLTV is one customer's delivered-order history. Spend is in the base currency.
+type CustomerLTV struct {
+	UserID               uint    `json:"user_id"`
+	Name                 string  `json:"name"`
+	Email                string  `json:"email"`
+	FirstOrderAt         sqlTime `json:"first_order_at"`
+	LastOrderAt          sqlTime `json:"last_order_at"`
+	TotalOrders          int64   `json:"total_orders"`
+	TotalSpend           float64 `json:"total_spend"`
+	AvgOrderValue        float64 `json:"avg_order_value"`
+	FavoriteRestaurantID uint    `json:"favorite_restaurant_id"`
+	FavoriteRestaurant   string  `json:"favorite_restaurant"` // most orders; the latest breaks ties
+}
+
+// Delivered orders per customer in [@from, @to), and each customer's most
+// ordered-from restaurant over the same orders
+const customerLTVCTEs = `
+WITH ltv AS (
+	SELECT customer_id,
+		MIN(created_at) AS first_order_at, MAX(created_at) AS last_order_at,
+		COUNT(*) AS total_orders, SUM(total_price_base) AS total_spend
+	FROM orders
+	WHERE status = @delivered AND created_at >= @from AND created_at < @to AND deleted_at IS NULL
+	GROUP BY customer_id
+	HAVING COUNT(*) >= @min_orders
+), fav AS (
+	SELECT customer_id, restaurant_id,
+		ROW_NUMBER() OVER (PARTITION BY customer_id ORDER BY COUNT(*) DESC, MAX(created_at) DESC) AS rank
+	FROM orders
+	WHERE status = @delivered AND created_at >= @from AND created_at < @to AND deleted_at IS NULL
+	GROUP BY customer_id, restaurant_id
+)`
+
+// AdminGetCustomerLTV ranks customers by what their delivered orders are worth
+// — admin only. Without from/to the whole order history counts.
+//
+// @Summary     Customer lifetime value
+// @Tags        admin
+// @Produce     json
+// @Param       min_orders  query  int     false  "Only customers with at least this many delivered orders (default 1)"
+// @Param       sort_by     query  string  false  "total_spend (default), total_orders, avg_order_value or last_order_at"
+// @Param       limit       query  int     false  "Customers to return, 1 to 500 (default 50)"
+// @Param       from        query  string  false  "Start date (YYYY-MM-DD)"
+// @Param       to          query  string  false  "End date (YYYY-MM-DD)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/customer-ltv [get]
+func AdminGetCustomerLTV(c *gin.Context) {
+	minOrders, err := strconv.Atoi(c.DefaultQuery("min_orders", "1"))
+	if err != nil || minOrders < 1 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.min_orders_must_be_a_positive_integer", nil)
+		return
+	}
+	sortBy := c.DefaultQuery("sort_by", "total_spend")
+	sortCol, ok := ltvSorts[sortBy]
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_ltv_sort_by", nil)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLTVLimit)))
+	if err != nil || limit < 1 || limit > maxLTVLimit {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.limit_must_be_between_1_and", nil, maxLTVLimit)
+		return
+	}
+	from, to := time.Time{}, time.Now().AddDate(0, 0, 1)
+	if c.Query("from") != "" || c.Query("to") != "" {
+		if from, to, err = parseDateRange(c, 30, 0); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+
+	rows := []CustomerLTV{}
+	requestDB(c).Raw(customerLTVCTEs+fmt.Sprintf(`
+SELECT ltv.customer_id AS user_id, users.name, users.email,
+	ltv.first_order_at, ltv.last_order_at, ltv.total_orders,
+	ROUND(ltv.total_spend, 2) AS total_spend,
+	ROUND(ltv.total_spend / ltv.total_orders, 2) AS avg_order_value,
+	fav.restaurant_id AS favorite_restaurant_id, restaurants.name AS favorite_restaurant
+FROM ltv
+JOIN users ON users.id = ltv.customer_id
+JOIN fav ON fav.customer_id = ltv.customer_id AND fav.rank = 1
+JOIN restaurants ON restaurants.id = fav.restaurant_id
+ORDER BY %s DESC, ltv.customer_id
+LIMIT @limit`, sortCol), map[string]interface{}{
+		"delivered":  models.StatusDelivered,
+		"from":       from,
+		"to":         to,
+		"min_orders": minOrders,
+		"limit":      limit,
+	}).Scan(&rows)
+
+	response := gin.H{
+		"min_orders":    minOrders,
+		"sort_by":       sortBy,
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"count":         len(rows),
+		"customers":     rows,
+	}
+	if !from.IsZero() {
+		response["from"] = from.Format(dateLayout)
+		response["to"] = to.AddDate(0, 0, -1).Format(dateLayout)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type churnedCustomer struct {
+	UserID             uint    `json:"user_id"`
+	Name               string  `json:"name"`
+	Email              string  `json:"email"`
+	TotalOrders        int64   `json:"total_orders"`
+	LifetimeSpend      float64 `json:"lifetime_spend"`
+	LastOrderAt        sqlTime `json:"last_order_at"`
+	DaysSinceLastOrder int     `json:"days_since_last_order"`
+}
+
+// AdminGetChurnedCustomers lists customers with at least two delivered orders
+// who haven't ordered anything in the last 60 days — candidates for a
+// win-back campaign. Admin only; the biggest spenders come first.
+//
+// @Summary     Customers who stopped ordering
+// @Tags        admin
+// @Produce     json
+// @Param       inactive_days  query  int  false  "Days without an order (default 60)"
+// @Param       min_orders     query  int  false  "Minimum delivered orders (default 2)"
+// @Param       page           query  int  false  "Page number (default 1)"
+// @Param       page_size      query  int  false  "Page size (default 20, max 100)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/churn [get]
+func AdminGetChurnedCustomers(c *gin.Context) {
+	inactiveDays, err := strconv.Atoi(c.DefaultQuery("inactive_days", strconv.Itoa(defaultChurnAfterDays)))
+	if err != nil || inactiveDays < 1 || inactiveDays > maxChurnInactivityDays {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.inactive_days_must_be_between_1_and", nil, maxChurnInactivityDays)
+		return
+	}
+	minOrders, err := strconv.Atoi(c.DefaultQuery("min_orders", strconv.Itoa(defaultChurnMinOrders)))
+	if err != nil || minOrders < 1 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.min_orders_must_be_a_positive_integer", nil)
+		return
+	}
+	page, pageSize := parsePagination(c)
+	cutoff := time.Now().AddDate(0, 0, -inactiveDays)
+
+	// Any order since the cutoff, even a cancelled one, means the customer is still around
+	churned := func() *gorm.DB {
+		recent := requestDB(c).Model(&models.Order{}).Select("customer_id").Where("created_at >= ?", cutoff)
+		return requestDB(c).Table("orders").
+			Joins("JOIN users ON users.id = orders.customer_id").
+			Where("orders.status = ? AND orders.deleted_at IS NULL AND orders.customer_id NOT IN (?)", models.StatusDelivered, recent).
+			Group("orders.customer_id, users.name, users.email").
+			Having("COUNT(*) >= ?", minOrders)
+	}
+
+	var total int64
+	requestDB(c).Table("(?) AS churned", churned().Select("orders.customer_id")).Count(&total)
+
+	rows := []churnedCustomer{}
+	churned().Select("orders.customer_id AS user_id, users.name, users.email, COUNT(*) AS total_orders, " +
+		"ROUND(SUM(orders.total_price_base), 2) AS lifetime_spend, MAX(orders.created_at) AS last_order_at").
+		Order("lifetime_spend DESC, orders.customer_id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&rows)
+	now := time.Now()
+	for i := range rows {
+		rows[i].DaysSinceLastOrder = int(now.Sub(rows[i].LastOrderAt.Time).Hours() / 24)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"inactive_days": inactiveDays,
+		"min_orders":    minOrders,
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"page":          page,
+		"page_size":     pageSize,
+		"total":         total,
+		"count":         len(rows),
+		"customers":     rows,
+	})
+}
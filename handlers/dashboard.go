@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/config"
+	"food-delivery-api/eventbus"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const dashboardCacheKey = "admin-dashboard-metrics"
+
+type TopRestaurant struct {
+	Name       string `json:"name"`
+	OrderCount int64  `json:"order_count"`
+}
+
+type DashboardMetrics struct {
+	BaseCurrency           string         `json:"base_currency"`
+	OrdersToday            int64          `json:"orders_today"`
+	RevenueToday           float64        `json:"revenue_today"` // delivered orders only, in the base currency
+	OrdersLast7Days        int64          `json:"orders_last_7_days"`
+	RevenueLast7Days       float64        `json:"revenue_last_7_days"`
+	OrdersLast30Days       int64          `json:"orders_last_30_days"`
+	RevenueLast30Days      float64        `json:"revenue_last_30_days"`
+	ActiveRestaurants      int64          `json:"active_restaurants"`
+	ActiveDrivers          int64          `json:"active_drivers"`
+	AutoCancellationsToday int64          `json:"auto_cancellations_today"`
+	UnreadSupportMessages  int64          `json:"unread_support_messages"` // customer messages no admin has read
+	TopRestaurantToday     *TopRestaurant `json:"top_restaurant_today"`    // null before the first order of the day
+	GeneratedAt            time.Time      `json:"generated_at"`
+}
+
+// orderTotals counts orders created since start and sums the delivered ones
+func orderTotals(db *gorm.DB, start time.Time) (count int64, revenue float64, err error) {
+	var row struct {
+		Count   int64
+		Revenue float64
+	}
+	err = db.Model(&models.Order{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(CASE WHEN status = ? THEN total_price_base ELSE 0 END), 0) AS revenue",
+			models.StatusDelivered).
+		Where("created_at >= ?", start).
+		Scan(&row).Error
+	return row.Count, math.Round(row.Revenue*100) / 100, err
+}
+
+// computeDashboardMetrics runs every metric's query concurrently and returns
+// the first error any of them hit. "Today" starts at local midnight.
+func computeDashboardMetrics(db *gorm.DB, now time.Time) (DashboardMetrics, error) {
+	m := DashboardMetrics{BaseCurrency: sysconfig.Get(sysconfig.KeyBaseCurrency), GeneratedAt: now}
+	today := startOfDay(now)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	run := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				errOnce.Do(func() { firstErr = err })
+			}
+		}()
+	}
+
+	run(func() (err error) {
+		m.OrdersToday, m.RevenueToday, err = orderTotals(db, today)
+		return err
+	})
+	run(func() (err error) {
+		m.OrdersLast7Days, m.RevenueLast7Days, err = orderTotals(db, now.AddDate(0, 0, -7))
+		return err
+	})
+	run(func() (err error) {
+		m.OrdersLast30Days, m.RevenueLast30Days, err = orderTotals(db, now.AddDate(0, 0, -30))
+		return err
+	})
+	run(func() error {
+		return db.Model(&models.Restaurant{}).Where("is_open = ?", true).Count(&m.ActiveRestaurants).Error
+	})
+	run(func() error {
+		return db.Model(&models.DriverProfile{}).Where("is_online = ?", true).Count(&m.ActiveDrivers).Error
+	})
+	run(func() error {
+		return db.Model(&models.OrderStatusHistory{}).
+			Where("to_status = ? AND note LIKE ? AND created_at >= ?", models.StatusCancelled, "[AUTO-CANCEL]%", today).
+			Count(&m.AutoCancellationsToday).Error
+	})
+	run(func() error {
+		return db.Model(&models.SupportMessage{}).
+			Where("sender_role = ? AND is_read = ?", models.RoleCustomer, false).
+			Count(&m.UnreadSupportMessages).Error
+	})
+	run(func() error {
+		var top TopRestaurant
+		res := db.Table("orders").
+			Select("restaurants.name, COUNT(*) AS order_count").
+			Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+			Where("orders.created_at >= ? AND orders.status <> ? AND orders.deleted_at IS NULL", today, models.StatusCancelled).
+			Group("orders.restaurant_id, restaurants.name").
+			Order("order_count DESC").
+			Limit(1).
+			Scan(&top)
+		if res.Error == nil && res.RowsAffected > 0 {
+			m.TopRestaurantToday = &top
+		}
+		return res.Error
+	})
+
+	wg.Wait()
+	return m, firstErr
+}
+
+// InvalidateDashboardOnTerminal drops the cached dashboard when an order is
+// delivered or cancelled
+func InvalidateDashboardOnTerminal(payload interface{}) {
+	if e, ok := payload.(eventbus.OrderEvent); ok &&
+		(e.To == models.StatusDelivered || e.To == models.StatusCancelled) {
+		cache.Default.Delete(dashboardCacheKey)
+	}
+}
+
+// AdminGetDashboardMetrics returns the headline order, revenue and capacity
+// numbers in one response — admin only. Cached for DASHBOARD_CACHE_TTL.
+//
+// @Summary     Dashboard metrics
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     500  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/dashboard/metrics [get]
+func AdminGetDashboardMetrics(c *gin.Context) {
+	if body, ok := cache.Default.Get(dashboardCacheKey); ok {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+	// The result is shared by every admin, so one client hanging up mustn't cancel it
+	metrics, err := computeDashboardMetrics(config.DB, time.Now())
+	if err != nil {
+		log.Printf("dashboard: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_build_dashboard", nil)
+		return
+	}
+	body, err := json.Marshal(metrics)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_build_dashboard", nil)
+		return
+	}
+	cache.Default.Set(dashboardCacheKey, body, config.DashboardCacheTTL())
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
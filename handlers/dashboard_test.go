@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"food-delivery-api/cache"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+func TestDashboardTodayStartsAtLocalMidnight(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5:30", 5*3600+1800)
+	defer func() { time.Local = local }()
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	now := time.Date(2026, 10, 18, 1, 0, 0, 0, time.Local) // still the 17th in UTC
+	for _, at := range []time.Time{now.Add(-30 * time.Minute), now.Add(-2 * time.Hour)} {
+		order := unpaidOrder(t, db, restaurant, customer)
+		db.Model(&order).Update("created_at", at)
+	}
+
+	m, err := computeDashboardMetrics(db, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.OrdersToday != 1 {
+		t.Errorf("orders today = %d, want 1: the other was placed before local midnight", m.OrdersToday)
+	}
+}
+
+func TestDashboardFailuresAreNotCached(t *testing.T) {
+	db := newTestDB(t)
+	err := db.Callback().Query().Before("gorm:query").Register("test:fail_support", func(tx *gorm.DB) {
+		if tx.Statement.Table == "support_messages" {
+			tx.AddError(errors.New("database is locked"))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func() int {
+		return serve(AdminGetDashboardMetrics, "/dashboard", 1, models.RoleAdmin, http.MethodGet, "/dashboard", "").Code
+	}
+
+	if code := get(); code != http.StatusInternalServerError {
+		t.Fatalf("status with a failing query = %d, want 500", code)
+	}
+	if _, ok := cache.Default.Get(dashboardCacheKey); ok {
+		t.Fatal("a failed dashboard was cached")
+	}
+	db.Callback().Query().Remove("test:fail_support")
+	if code := get(); code != http.StatusOK {
+		t.Fatalf("status once the query works = %d, want 200", code)
+	}
+	body, ok := cache.Default.Get(dashboardCacheKey)
+	var m DashboardMetrics
+	if !ok || json.Unmarshal(body, &m) != nil {
+		t.Errorf("dashboard not cached after a successful build")
+	}
+}
+
+func TestRestaurantComparisonFailuresAreNotCached(t *testing.T) {
+	db := newTestDB(t)
+	err := db.Callback().Row().Before("gorm:row").Register("test:fail_comparison", func(tx *gorm.DB) {
+		if strings.Contains(tx.Statement.SQL.String(), "WITH o AS") {
+			tx.AddError(errors.New("database is locked"))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func() int {
+		return serve(AdminGetRestaurantComparison, "/comparison", 1, models.RoleAdmin, http.MethodGet, "/comparison", "").Code
+	}
+
+	if code := get(); code != http.StatusInternalServerError {
+		t.Fatalf("status with a failing query = %d, want 500", code)
+	}
+	db.Callback().Row().Remove("test:fail_comparison")
+	if code := get(); code != http.StatusOK {
+		t.Errorf("status once the query works = %d, want 200, not a cached failure", code)
+	}
+}
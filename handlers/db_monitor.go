@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activeQueryMinAge hides the statements that finish too fast to be worth cancelling
+const activeQueryMinAge = time.Second
+
+// AdminGetActiveQueries lists the database statements that have been running
+// for more than a second, longest first — admin only
+//
+// @Summary     List long-running database queries
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/db/active-queries [get]
+func AdminGetActiveQueries(c *gin.Context) {
+	queries := config.Queries.Active(activeQueryMinAge)
+	c.JSON(http.StatusOK, gin.H{
+		"min_running_ms":    activeQueryMinAge.Milliseconds(),
+		"slow_threshold_ms": config.Queries.SlowThreshold.Milliseconds(),
+		"count":             len(queries),
+		"queries":           queries,
+	})
+}
+
+// AdminCancelQuery aborts a running database statement by cancelling its
+// context; the request that issued it fails — admin only
+//
+// @Summary     Cancel a running database query
+// @Tags        admin
+// @Produce     json
+// @Param       uuid  path  string  true  "Query ID from the active query list"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/db/active-queries/{uuid} [delete]
+func AdminCancelQuery(c *gin.Context) {
+	id := c.Param("uuid")
+	if !config.Queries.Cancel(id) {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.no_running_query_with_that_id", gin.H{"id": id})
+		return
+	}
+	log.Printf("db monitor: query %s cancelled by admin %d", id, middleware.GetUserID(c))
+	c.JSON(http.StatusOK, gin.H{"message": "Query cancelled", "id": id})
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/geo"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ChangeDeliveryAddressRequest struct {
+	NewAddress string `json:"new_address" binding:"required,max=300"`
+	Reason     string `json:"reason" binding:"max=300"`
+}
+
+// changeDeliveryAddress moves an order in one of statuses to a new address,
+// re-geocoding it, notes the change in the order's history and tells the
+// order's live streams. The distance and delivery fee are repriced for the new
+// address, keeping a waived fee waived, and the totals follow. The order's
+// coordinates are cleared when the new address can't be geocoded; strict
+// rejects it instead. Without coordinates the distance can't be priced, so the
+// fee already charged stands.
+func changeDeliveryAddress(c *gin.Context, order models.Order, changedBy uint, strict bool, statuses ...models.OrderStatus) {
+	var req ChangeDeliveryAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	allowed := false
+	for _, s := range statuses {
+		allowed = allowed || order.Status == s
+	}
+	if !allowed {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.delivery_address_locked",
+			gin.H{"status": order.Status, "allowed_statuses": statuses})
+		return
+	}
+
+	var lat, lng *float64
+	point, geoErr := geo.Default.Geocode(c.Request.Context(), req.NewAddress)
+	if geoErr == nil {
+		lat, lng = &point.Lat, &point.Lng
+	} else if strict {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.address_could_not_be_geocoded",
+			gin.H{"reason": geoErr.Error()})
+		return
+	}
+
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, order.RestaurantID).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	distanceKm := deliveryDistanceKm(restaurant, lat, lng)
+	fee := order.DeliveryFee
+	switch {
+	case order.SubscriptionApplied || hasFreeDeliveryPerk(requestDB(c), order.CustomerID):
+		fee = 0
+	case distanceKm != nil:
+		fee = deliveryFee(restaurant, distanceKm, order.Subtotal())
+	}
+	totalPrice := math.Round((order.TotalPrice-order.DeliveryFee+fee)*100) / 100
+	totalPriceBase := toBase(totalPrice, order.ExchangeRate)
+
+	note := fmt.Sprintf("[ADDRESS CHANGE] %q -> %q", order.DeliveryAddress, req.NewAddress)
+	if req.Reason != "" {
+		note += ": " + req.Reason
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		// The order may have moved on, or been repriced, since it was read
+		res := tx.Model(&models.Order{}).Where("id = ? AND status = ? AND total_price = ?", order.ID, order.Status, order.TotalPrice).
+			Updates(map[string]interface{}{
+				"delivery_address": req.NewAddress,
+				"delivery_lat":     lat,
+				"delivery_lng":     lng,
+				"distance_km":      distanceKm,
+				"delivery_fee":     fee,
+				"total_price":      totalPrice,
+				"total_price_base": totalPriceBase,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.order_modified_concurrently", nil)
+		}
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: order.Status,
+			ToStatus:   order.Status,
+			ChangedBy:  changedBy,
+			Note:       note,
+		}).Error
+	})
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_delivery_address", nil)
+		return
+	}
+
+	event := realtime.Event{
+		Event:           "address_updated",
+		OrderID:         order.ID,
+		From:            string(order.Status),
+		To:              string(order.Status),
+		RestaurantID:    order.RestaurantID,
+		Restaurant:      restaurant.Name,
+		DeliveryAddress: req.NewAddress,
+		Timestamp:       time.Now(),
+	}
+	realtime.Default.Publish(event)
+
+	resp := gin.H{
+		"message":          "Delivery address updated",
+		"order_id":         order.ID,
+		"old_address":      order.DeliveryAddress,
+		"delivery_address": req.NewAddress,
+		"delivery_lat":     lat,
+		"delivery_lng":     lng,
+		"distance_km":      distanceKm,
+		"old_delivery_fee": order.DeliveryFee,
+		"delivery_fee":     fee,
+		"total_price":      totalPrice,
+		"geocoded":         geoErr == nil,
+	}
+	if geoErr != nil {
+		resp["geocode_error"] = geoErr.Error()
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AdminChangeDeliveryAddress corrects the delivery address of an order that
+// is still PLACED or CONFIRMED — admin only. The address is re-geocoded when
+// a geocoder is configured; failing that the order loses its coordinates.
+//
+// @Summary     Change an order's delivery address
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                           true  "Order ID"
+// @Param       body  body  ChangeDeliveryAddressRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders/{id}/delivery-address [put]
+func AdminChangeDeliveryAddress(c *gin.Context) {
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	changeDeliveryAddress(c, order, middleware.GetUserID(c), false, models.StatusPlaced, models.StatusConfirmed)
+}
+
+// ChangeDeliveryAddress lets a customer fix the delivery address of their
+// order until the restaurant confirms it. The new address is geocoded; with
+// GEOCODER_STRICT one that can't be is rejected.
+//
+// @Summary     Change my order's delivery address
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                           true  "Order ID"
+// @Param       body  body  ChangeDeliveryAddressRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/delivery-address [put]
+func ChangeDeliveryAddress(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.CustomerID != customerID {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
+		return
+	}
+	changeDeliveryAddress(c, order, customerID, config.GeocoderStrict, models.StatusPlaced)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"food-delivery-api/geo"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// fakeGeocoder geocodes the addresses it knows and nothing else
+type fakeGeocoder map[string]geo.Point
+
+func (f fakeGeocoder) Geocode(_ context.Context, address string) (geo.Point, error) {
+	if p, ok := f[address]; ok {
+		return p, nil
+	}
+	return geo.Point{}, geo.ErrAddressNotFound
+}
+
+// useGeocoder swaps geo.Default for the test
+func useGeocoder(t *testing.T, g geo.Geocoder) {
+	t.Helper()
+	prev := geo.Default
+	geo.Default = g
+	t.Cleanup(func() { geo.Default = prev })
+}
+
+// placedNearby adds a PLACED order for customer from restaurant, delivered
+// to near, with a 50.00 subtotal, the fee for that distance and a 2.50 tip
+func placedNearby(t *testing.T, db *gorm.DB, restaurant models.Restaurant, customer models.User, near geo.Point) models.Order {
+	t.Helper()
+	distance := deliveryDistanceKm(restaurant, &near.Lat, &near.Lng)
+	fee := deliveryFee(restaurant, distance, 50)
+	order := models.Order{
+		InvoiceNumber:   fmt.Sprintf("INV-%d", time.Now().UnixNano()),
+		CustomerID:      customer.ID,
+		RestaurantID:    restaurant.ID,
+		Status:          models.StatusPlaced,
+		DeliveryAddress: "near",
+		DeliveryLat:     &near.Lat,
+		DeliveryLng:     &near.Lng,
+		DistanceKm:      distance,
+		DeliveryFee:     fee,
+		TipAmount:       2.5,
+		TotalPrice:      50 + fee + 2.5,
+		ExchangeRate:    2,
+		TotalPriceBase:  (50 + fee + 2.5) * 2,
+	}
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestChangeDeliveryAddressReprices(t *testing.T) {
+	near, far := geo.Point{Lat: 0, Lng: 0.01}, geo.Point{Lat: 0, Lng: 0.1}
+	useGeocoder(t, fakeGeocoder{"near": near, "far": far})
+	route := "/customer/orders/:id/delivery-address"
+
+	tests := []struct {
+		name       string
+		address    string
+		subscribed bool
+		freeAbove  float64
+		// wantFee is "far" for the fee to far, "free" or "unchanged"
+		wantFee      string
+		wantDistance bool
+	}{
+		{"farther away", "far", false, 0, "far", true},
+		{"subscription keeps delivery free", "far", true, 0, "free", true},
+		{"free above the subtotal", "far", false, 40, "free", true},
+		{"address that can't be geocoded keeps the fee", "somewhere", false, 0, "unchanged", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			restaurant, _ := createRestaurant(t, db)
+			lat, lng := 0.0, 0.0
+			db.Model(&restaurant).Updates(map[string]interface{}{
+				"latitude": lat, "longitude": lng, "base_delivery_fee": 10, "price_per_km": 2, "free_delivery_above": 0,
+			})
+			restaurant.Latitude, restaurant.Longitude, restaurant.BaseDeliveryFee, restaurant.PricePerKm = &lat, &lng, 10, 2
+			customer := createUser(t, db, "Asha", models.RoleCustomer)
+			order := placedNearby(t, db, restaurant, customer, near)
+			if tt.subscribed {
+				db.Model(&order).Update("subscription_applied", true)
+				db.Model(&order).Updates(map[string]interface{}{"delivery_fee": 0, "total_price": 52.5, "total_price_base": 105})
+				order.DeliveryFee, order.TotalPrice = 0, 52.5
+			}
+			if tt.freeAbove > 0 {
+				db.Model(&restaurant).Update("free_delivery_above", tt.freeAbove)
+			}
+
+			target := fmt.Sprintf("/customer/orders/%d/delivery-address", order.ID)
+			w := serve(ChangeDeliveryAddress, route, customer.ID, models.RoleCustomer, http.MethodPut, target,
+				fmt.Sprintf(`{"new_address":%q}`, tt.address))
+			wantStatus(t, w, http.StatusOK)
+
+			var got models.Order
+			if err := db.First(&got, order.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			var wantFee float64
+			switch tt.wantFee {
+			case "far":
+				wantFee = deliveryFee(restaurant, deliveryDistanceKm(restaurant, &far.Lat, &far.Lng), 50)
+				if wantFee <= order.DeliveryFee {
+					t.Fatalf("fee for far = %.2f, want more than near's %.2f", wantFee, order.DeliveryFee)
+				}
+			case "unchanged":
+				wantFee = order.DeliveryFee
+			}
+			if got.DeliveryFee != wantFee {
+				t.Errorf("delivery_fee = %.2f, want %.2f", got.DeliveryFee, wantFee)
+			}
+			if want := math.Round((order.TotalPrice-order.DeliveryFee+wantFee)*100) / 100; got.TotalPrice != want {
+				t.Errorf("total_price = %.2f, want %.2f", got.TotalPrice, want)
+			}
+			if want := toBase(got.TotalPrice, 2); got.TotalPriceBase != want {
+				t.Errorf("total_price_base = %.2f, want %.2f", got.TotalPriceBase, want)
+			}
+			if (got.DistanceKm != nil) != tt.wantDistance || (got.DeliveryLat != nil) != tt.wantDistance {
+				t.Errorf("distance_km = %v, delivery_lat = %v; want both set: %v", got.DistanceKm, got.DeliveryLat, tt.wantDistance)
+			}
+			if tt.wantDistance {
+				if want := deliveryDistanceKm(restaurant, got.DeliveryLat, got.DeliveryLng); *got.DistanceKm != *want {
+					t.Errorf("distance_km = %.2f, want %.2f for the new coordinates", *got.DistanceKm, *want)
+				}
+			}
+		})
+	}
+}
+
+func TestChangeDeliveryAddressRejected(t *testing.T) {
+	db := newTestDB(t)
+	useGeocoder(t, fakeGeocoder{})
+	restaurant, _ := createRestaurant(t, db)
+	customer := createUser(t, db, "Asha", models.RoleCustomer)
+	other := createUser(t, db, "Ben", models.RoleCustomer)
+	order := placedNearby(t, db, restaurant, customer, geo.Point{})
+	route := "/customer/orders/:id/delivery-address"
+	target := fmt.Sprintf("/customer/orders/%d/delivery-address", order.ID)
+	body := `{"new_address":"far"}`
+
+	w := serve(ChangeDeliveryAddress, route, other.ID, models.RoleCustomer, http.MethodPut, target, body)
+	wantStatus(t, w, http.StatusForbidden)
+
+	db.Model(&order).Update("status", models.StatusConfirmed)
+	w = serve(ChangeDeliveryAddress, route, customer.ID, models.RoleCustomer, http.MethodPut, target, body)
+	wantStatus(t, w, http.StatusConflict)
+
+	// Admins may still correct a confirmed order
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+	w = serve(AdminChangeDeliveryAddress, "/admin/orders/:id/delivery-address", admin.ID, models.RoleAdmin, http.MethodPut,
+		fmt.Sprintf("/admin/orders/%d/delivery-address", order.ID), body)
+	wantStatus(t, w, http.StatusOK)
+
+	var history []models.OrderStatusHistory
+	db.Where("order_id = ?", order.ID).Find(&history)
+	if len(history) != 1 || history[0].ChangedBy != admin.ID {
+		t.Errorf("history = %+v, want one [ADDRESS CHANGE] entry by the admin", history)
+	}
+}
+
+func TestPlaceOrderRejectsAddressAndAddressID(t *testing.T) {
+	db := newTestDB(t)
+	_, items := createRestaurant(t, db, models.MenuItem{Name: "Dal", Price: 120})
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	address := models.CustomerAddress{CustomerID: customer.ID, Label: "Home", Address: "2 Saved Rd"}
+	if err := db.Create(&address).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := fmt.Sprintf(`{"restaurant_id":%d,"address_id":%d,"delivery_address":"9 Typed St","items":[{"menu_item_id":%d,"quantity":1}]}`,
+		items[0].RestaurantID, address.ID, items[0].ID)
+	w := serve(PlaceOrder, "/customer/orders", customer.ID, models.RoleCustomer, http.MethodPost, "/customer/orders", body)
+	wantStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "excluded_with") {
+		t.Errorf("rejected for the wrong reason: %s", w.Body)
+	}
+	var orders int64
+	db.Model(&models.Order{}).Count(&orders)
+	if orders != 0 {
+		t.Errorf("%d order(s) placed from an ambiguous address", orders)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/geo"
+	"food-delivery-api/models"
+)
+
+// DeliveryPricing is a restaurant's delivery fee formula, shown before
+// checkout so customers can estimate the fee:
+// base_fee + distance_km * price_per_km, free from free_delivery_above
+type DeliveryPricing struct {
+	BaseFee           float64 `json:"base_fee"`
+	PricePerKm        float64 `json:"price_per_km"`
+	FreeDeliveryAbove float64 `json:"free_delivery_above"` // 0: never free
+	Currency          string  `json:"currency"`
+}
+
+func deliveryPricing(r models.Restaurant) DeliveryPricing {
+	return DeliveryPricing{
+		BaseFee:           r.BaseDeliveryFee,
+		PricePerKm:        r.PricePerKm,
+		FreeDeliveryAbove: r.FreeDeliveryAbove,
+		Currency:          r.Currency,
+	}
+}
+
+// deliveryDistanceKm is the straight-line distance from the restaurant to the
+// delivery point, or nil unless both have coordinates
+func deliveryDistanceKm(r models.Restaurant, lat, lng *float64) *float64 {
+	if r.Latitude == nil || r.Longitude == nil || lat == nil || lng == nil {
+		return nil
+	}
+	km := math.Round(geo.DistanceKm(geo.Point{Lat: *r.Latitude, Lng: *r.Longitude}, geo.Point{Lat: *lat, Lng: *lng})*100) / 100
+	return &km
+}
+
+// deliveryFee prices delivery of an order worth subtotal. Without a distance
+// only the base fee is charged.
+func deliveryFee(r models.Restaurant, distanceKm *float64, subtotal float64) float64 {
+	if r.FreeDeliveryAbove > 0 && subtotal >= r.FreeDeliveryAbove {
+		return 0
+	}
+	fee := r.BaseDeliveryFee
+	if distanceKm != nil {
+		fee += *distanceKm * r.PricePerKm
+	}
+	return math.Round(fee*100) / 100
+}
+
+// deliveryPricingUpdate validates the delivery pricing fields of a restaurant
+// update and copies them into update. latitude and longitude go together;
+// null clears both.
+func deliveryPricingUpdate(req map[string]interface{}, update map[string]interface{}) *apierror.Error {
+	for _, field := range []string{"base_delivery_fee", "price_per_km", "free_delivery_above"} {
+		v, ok := req[field]
+		if !ok {
+			continue
+		}
+		n, isNum := v.(float64)
+		if !isNum || n < 0 {
+			return apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.field_must_be_a_number_of_at_least_0", nil, field)
+		}
+		update[field] = n
+	}
+
+	lat, hasLat := req["latitude"]
+	lng, hasLng := req["longitude"]
+	if !hasLat && !hasLng {
+		return nil
+	}
+	if lat == nil && lng == nil {
+		update["latitude"], update["longitude"] = nil, nil
+		return nil
+	}
+	latN, latOK := lat.(float64)
+	lngN, lngOK := lng.(float64)
+	if !latOK || !lngOK || latN < -90 || latN > 90 || lngN < -180 || lngN > 180 {
+		return apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.latitude_and_longitude_must_be_set_together", nil)
+	}
+	update["latitude"], update["longitude"] = latN, lngN
+	return nil
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/geo"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxRoutePoints caps a route's length; a full route keeps every other point
+// so its shape survives however long the delivery takes
+const maxRoutePoints = 500
+
+// appendRoutePoint adds a position report to the order's route, starting the
+// route on the first report. The append happens in SQL so concurrent reports
+// can't overwrite each other.
+func appendRoutePoint(db *gorm.DB, orderID, driverID uint, point geo.Point, at time.Time) error {
+	encoded, err := json.Marshal(models.RoutePoint{Point: point, TS: at.UTC()})
+	if err != nil {
+		return err
+	}
+	appendPoint := func() (bool, error) {
+		res := db.Model(&models.DeliveryRoute{}).
+			Where("order_id = ? AND json_array_length(coordinates) < ?", orderID, maxRoutePoints).
+			Updates(map[string]interface{}{
+				"coordinates": gorm.Expr("json_insert(coordinates, '$[#]', json(?))", string(encoded)),
+				"updated_at":  at,
+			})
+		return res.RowsAffected > 0, res.Error
+	}
+	if ok, err := appendPoint(); ok || err != nil {
+		return err
+	}
+	var full models.DeliveryRoute
+	err = db.Where("order_id = ?", orderID).First(&full).Error
+	if err == nil {
+		return thinRoute(db, full, models.RoutePoint{Point: point, TS: at.UTC()}, at)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	route := models.DeliveryRoute{
+		OrderID:     orderID,
+		DriverID:    driverID,
+		Coordinates: []models.RoutePoint{{Point: point, TS: at.UTC()}},
+		UpdatedAt:   at,
+	}
+	err = db.Create(&route).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// Another report started the route first
+		_, err = appendPoint()
+	}
+	return err
+}
+
+// thinRoute drops every other point of a full route, keeping its first and
+// last, then appends next. It only writes if no report changed the route
+// since it was read; otherwise that report has made room and next is appended.
+func thinRoute(db *gorm.DB, route models.DeliveryRoute, next models.RoutePoint, at time.Time) error {
+	points := route.Coordinates
+	kept := make([]models.RoutePoint, 0, len(points)/2+2)
+	for i := 0; i < len(points); i += 2 {
+		kept = append(kept, points[i])
+	}
+	if len(points)%2 == 0 {
+		kept = append(kept, points[len(points)-1])
+	}
+	kept = append(kept, next)
+	encoded, err := json.Marshal(kept)
+	if err != nil {
+		return err
+	}
+	res := db.Model(&models.DeliveryRoute{}).
+		Where("order_id = ? AND json_array_length(coordinates) = ?", route.OrderID, len(points)).
+		Updates(map[string]interface{}{"coordinates": string(encoded), "updated_at": at})
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+	encodedNext, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.DeliveryRoute{}).
+		Where("order_id = ? AND json_array_length(coordinates) < ?", route.OrderID, maxRoutePoints).
+		Updates(map[string]interface{}{
+			"coordinates": gorm.Expr("json_insert(coordinates, '$[#]', json(?))", string(encodedNext)),
+			"updated_at":  at,
+		}).Error
+}
+
+// routeDistanceKm is the length of the order's reported route, or nil when the
+// driver never reported a position
+func routeDistanceKm(db *gorm.DB, orderID uint) (*float64, error) {
+	var route models.DeliveryRoute
+	if err := db.Where("order_id = ?", orderID).First(&route).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	km := math.Round(geo.PathLengthKm(route.Path())*1000) / 1000
+	return &km, nil
+}
+
+// AdminGetOrderRoute returns the path the driver reported while delivering an
+// order, oldest point first — admin only
+//
+// @Summary     Driver route for an order
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders/{id}/route [get]
+func AdminGetOrderRoute(c *gin.Context) {
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	var route models.DeliveryRoute
+	if err := requestDB(c).Where("order_id = ?", order.ID).First(&route).Error; err != nil {
+		c.Error(err).SetMeta("errors.no_route_recorded_for_this_order")
+		c.Abort()
+		return
+	}
+	distance := order.RouteDistanceKm
+	if distance == nil {
+		// Still out for delivery; measure what there is so far
+		km := math.Round(geo.PathLengthKm(route.Path())*1000) / 1000
+		distance = &km
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"order_id":    order.ID,
+		"driver_id":   route.DriverID,
+		"status":      order.Status,
+		"distance_km": distance,
+		"count":       len(route.Coordinates),
+		"coordinates": route.Coordinates,
+	})
+}
+
+// AdminGetDriverDistance reports, per driver, the distance covered delivering
+// orders placed in the date range and the delivery fees earned per km. Only
+// deliveries with a recorded route count.
+//
+// @Summary     Driver distance and earnings per km
+// @Tags        admin
+// @Produce     json
+// @Param       driver_id  query  int     false  "Only this driver"
+// @Param       from       query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to         query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/analytics/driver-distance [get]
+func AdminGetDriverDistance(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	type driverDistance struct {
+		DriverID      uint    `json:"driver_id"`
+		DriverName    string  `json:"driver_name"`
+		Deliveries    int64   `json:"deliveries"`
+		DistanceKm    float64 `json:"distance_km"`
+		Earnings      float64 `json:"earnings"` // delivery fees, in the base currency
+		EarningsPerKm float64 `json:"earnings_per_km"`
+		AvgDeliveryKm float64 `json:"avg_delivery_km"`
+	}
+	query := requestDB(c).Model(&models.Order{}).
+		Select("orders.driver_id, users.name AS driver_name, COUNT(*) AS deliveries, "+
+			"SUM(orders.route_distance_km) AS distance_km, SUM(orders.delivery_fee * orders.exchange_rate) AS earnings").
+		Joins("JOIN users ON users.id = orders.driver_id").
+		Where("orders.status = ? AND orders.route_distance_km IS NOT NULL AND orders.created_at >= ? AND orders.created_at < ?",
+			models.StatusDelivered, from, to)
+	if driverID := c.Query("driver_id"); driverID != "" {
+		query = query.Where("orders.driver_id = ?", driverID)
+	}
+	rows := []driverDistance{}
+	query.Group("orders.driver_id, users.name").Order("distance_km DESC").Scan(&rows)
+
+	for i := range rows {
+		row := &rows[i]
+		if row.DistanceKm > 0 {
+			row.EarningsPerKm = math.Round(row.Earnings/row.DistanceKm*100) / 100
+		}
+		row.AvgDeliveryKm = math.Round(row.DistanceKm/float64(row.Deliveries)*100) / 100
+		row.DistanceKm = math.Round(row.DistanceKm*100) / 100
+		row.Earnings = math.Round(row.Earnings*100) / 100
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.Format(dateLayout),
+		"to":      to.AddDate(0, 0, -1).Format(dateLayout),
+		"count":   len(rows),
+		"drivers": rows,
+	})
+}
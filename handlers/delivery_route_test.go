@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"food-delivery-api/geo"
+	"food-delivery-api/models"
+)
+
+func TestAppendRoutePointThinsAFullRoute(t *testing.T) {
+	db := newTestDB(t)
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i <= maxRoutePoints; i++ {
+		point := geo.Point{Lat: 12.9 + float64(i)/10000, Lng: 77.6}
+		if err := appendRoutePoint(db, 1, 2, point, start.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var route models.DeliveryRoute
+	if err := db.Where("order_id = ?", 1).First(&route).Error; err != nil {
+		t.Fatal(err)
+	}
+	points := route.Coordinates
+	if len(points) != maxRoutePoints/2+2 {
+		t.Fatalf("%d points after %d reports, want %d", len(points), maxRoutePoints+1, maxRoutePoints/2+2)
+	}
+	if points[0].Lat != 12.9 || points[len(points)-1].Lat != 12.9+float64(maxRoutePoints)/10000 {
+		t.Errorf("route runs %v to %v, want the first and latest reports kept", points[0].Point, points[len(points)-1].Point)
+	}
+	for i := 1; i < len(points); i++ {
+		if !points[i].TS.After(points[i-1].TS) {
+			t.Fatalf("points out of order at %d: %v then %v", i, points[i-1].TS, points[i].TS)
+		}
+	}
+
+	// The thinned route has room again, so the next report is a plain append
+	if err := appendRoutePoint(db, 1, 2, geo.Point{Lat: 13, Lng: 77.6}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	route = models.DeliveryRoute{}
+	db.Where("order_id = ?", 1).First(&route)
+	if n := len(route.Coordinates); n != maxRoutePoints/2+3 || route.Coordinates[n-1].Lat != 13 {
+		t.Errorf("%d points after one more report, last %v", n, route.Coordinates[n-1].Point)
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	documentExpiryCheckInterval = 24 * time.Hour
+	documentExpiryWarningDays   = 30
+	maxExpiringWithinDays       = 365
+)
+
+// ExpiringDocument is an approved driver document that runs out soon
+type ExpiringDocument struct {
+	models.DriverDocument
+	DaysLeft int `json:"days_left"`
+	// RenewalID is the driver's renewal awaiting review, if any
+	RenewalID *uint `json:"renewal_id"`
+}
+
+// currentDocumentScope limits a query to approved documents that nothing
+// newer supersedes, mirroring latestDriverDocuments
+func currentDocumentScope(db *gorm.DB) *gorm.DB {
+	return db.Where("driver_documents.status = ?", models.DocumentApproved).
+		Where("NOT EXISTS (SELECT 1 FROM driver_documents newer WHERE newer.driver_id = driver_documents.driver_id"+
+			" AND newer.document_type = driver_documents.document_type AND newer.id > driver_documents.id"+
+			" AND (newer.renews_id IS NULL OR newer.status = ?))", models.DocumentApproved)
+}
+
+// StartDocumentExpiryWorker warns drivers and admins about documents expiring
+// within 30 days and marks documents past their expiry as expired, once a day
+func StartDocumentExpiryWorker() {
+	go func() {
+		runDocumentExpiry(time.Now())
+		for range time.Tick(documentExpiryCheckInterval) {
+			runDocumentExpiry(time.Now())
+		}
+	}()
+}
+
+func runDocumentExpiry(now time.Time) {
+	var docs []models.DriverDocument
+	config.DB.Preload("Driver").Scopes(currentDocumentScope).
+		Where("expires_at IS NOT NULL AND expires_at <= ? AND expired_alert_sent = ?",
+			now.AddDate(0, 0, documentExpiryWarningDays), false).
+		Find(&docs)
+	if len(docs) > 0 {
+		var admins []models.User
+		config.DB.Where("role = ?", models.RoleAdmin).Find(&admins)
+		for _, doc := range docs {
+			// Claim the alert first so an overlapping run can't send it twice
+			res := config.DB.Model(&models.DriverDocument{}).
+				Where("id = ? AND expired_alert_sent = ?", doc.ID, false).
+				Update("expired_alert_sent", true)
+			if res.Error != nil {
+				log.Printf("documents: failed to mark expiry alert for document %d: %v", doc.ID, res.Error)
+				continue
+			}
+			if res.RowsAffected == 1 {
+				sendDocumentExpiryAlerts(doc, admins)
+			}
+		}
+	}
+
+	res := config.DB.Model(&models.DriverDocument{}).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", models.DocumentApproved, now).
+		Update("status", models.DocumentExpired)
+	if res.Error != nil {
+		log.Printf("documents: failed to expire documents: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("documents: %d driver document(s) expired", res.RowsAffected)
+	}
+}
+
+func sendDocumentExpiryAlerts(doc models.DriverDocument, admins []models.User) {
+	expires := doc.ExpiresAt.Format(dateLayout)
+	err := notify.Default.Send(notify.Message{
+		UserID:  doc.DriverID,
+		Email:   doc.Driver.Email,
+		Phone:   doc.Driver.Phone,
+		Channel: notify.ChannelPush,
+		Title:   fmt.Sprintf("Your %s expires on %s", doc.DocumentType, expires),
+		Body:    "Upload a renewal before then to keep going online.",
+
+		EventType:     "driver_document_expiring",
+		ReferenceID:   doc.ID,
+		ReferenceType: "driver_document",
+	})
+	if err != nil {
+		log.Printf("documents: failed to notify driver %d about document %d: %v", doc.DriverID, doc.ID, err)
+	}
+	for _, admin := range admins {
+		err := notify.Default.Send(notify.Message{
+			UserID:  admin.ID,
+			Email:   admin.Email,
+			Phone:   admin.Phone,
+			Channel: notify.ChannelEmail,
+			Title:   fmt.Sprintf("Driver %d's %s expires on %s", doc.DriverID, doc.DocumentType, expires),
+			Body:    fmt.Sprintf("%s (document %d) must renew it to keep going online.", doc.Driver.Name, doc.ID),
+
+			EventType:     "driver_document_expiring",
+			ReferenceID:   doc.DriverID,
+			ReferenceType: "driver",
+		})
+		if err != nil {
+			log.Printf("documents: failed to notify admin %d about document %d: %v", admin.ID, doc.ID, err)
+		}
+	}
+}
+
+// AdminGetExpiringDocuments lists drivers' approved documents that expire
+// within the given number of days, soonest first — admin only
+//
+// @Summary     Driver documents expiring soon
+// @Tags        admin
+// @Produce     json
+// @Param       within_days  query  int  false  "Days ahead to look (default 30, max 365)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/drivers/expiring-documents [get]
+func AdminGetExpiringDocuments(c *gin.Context) {
+	withinDays := documentExpiryWarningDays
+	if s := c.Query("within_days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > maxExpiringWithinDays {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.within_days_must_be_between_1_and_365", nil)
+			return
+		}
+		withinDays = v
+	}
+
+	now := time.Now()
+	var docs []models.DriverDocument
+	requestDB(c).Preload("Driver").Scopes(currentDocumentScope).
+		Where("expires_at > ? AND expires_at <= ?", now, now.AddDate(0, 0, withinDays)).
+		Order("expires_at, id").Find(&docs)
+
+	renewals := map[uint]uint{}
+	if len(docs) > 0 {
+		ids := make([]uint, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc.ID
+		}
+		var pending []models.DriverDocument
+		requestDB(c).Where("renews_id IN ? AND status = ?", ids, models.DocumentPending).Find(&pending)
+		for _, r := range pending {
+			renewals[*r.RenewsID] = r.ID
+		}
+	}
+
+	drivers := map[uint]bool{}
+	expiring := make([]ExpiringDocument, len(docs))
+	for i, doc := range docs {
+		drivers[doc.DriverID] = true
+		expiring[i] = ExpiringDocument{DriverDocument: doc, DaysLeft: int(doc.ExpiresAt.Sub(now).Hours() / 24)}
+		if id, ok := renewals[doc.ID]; ok {
+			expiring[i].RenewalID = &id
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"within_days":  withinDays,
+		"driver_count": len(drivers),
+		"count":        len(expiring),
+		"documents":    expiring,
+	})
+}
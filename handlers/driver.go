@@ -1,23 +1,46 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"time"
 
-	"food-delivery-api/config"
+	"food-delivery-api/apierror"
+	"food-delivery-api/geo"
 	"food-delivery-api/middleware"
 	"food-delivery-api/models"
 	"food-delivery-api/statemachine"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// GetAvailableOrders shows orders READY_FOR_PICKUP that have no driver assigned
+// GetAvailableOrders shows orders READY_FOR_PICKUP that have no driver assigned (online drivers only)
+//
+// @Summary     List orders ready for pickup
+// @Tags        driver
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/orders/available [get]
 func GetAvailableOrders(c *gin.Context) {
+	if !requireOnlineDriver(c, middleware.GetUserID(c)) {
+		return
+	}
 	var orders []models.Order
-	config.DB.Preload("Restaurant").Preload("Customer").
+	requestDB(c).Preload("Restaurant").Preload("Customer").
 		Where("status = ? AND driver_id IS NULL", models.StatusReadyForPickup).
 		Order("created_at asc").
 		Find(&orders)
+	for i, o := range orders {
+		if o.DeliveryLat != nil && o.DeliveryLng != nil {
+			orders[i].DeliveryCoords = &geo.Point{Lat: *o.DeliveryLat, Lng: *o.DeliveryLng}
+		}
+	}
+	setOrderLinks(c, orders)
 	c.JSON(http.StatusOK, gin.H{
 		"count":  len(orders),
 		"orders": orders,
@@ -25,36 +48,59 @@ func GetAvailableOrders(c *gin.Context) {
 }
 
 // GetMyDeliveries returns all orders assigned to the logged-in driver
+//
+// @Summary     List my deliveries
+// @Tags        driver
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/orders/my-deliveries [get]
 func GetMyDeliveries(c *gin.Context) {
 	driverID := middleware.GetUserID(c)
 	var orders []models.Order
-	config.DB.Preload("Items.MenuItem").Preload("Restaurant").Preload("Customer").
+	requestDB(c).Preload("Items.MenuItem").Preload("Restaurant").Preload("Customer").
 		Where("driver_id = ?", driverID).
 		Order("updated_at desc").
 		Find(&orders)
+	setOrderLinks(c, orders)
 	c.JSON(http.StatusOK, gin.H{"count": len(orders), "orders": orders})
 }
 
 // PickupOrder assigns order to the driver and transitions READY_FOR_PICKUP → PICKED_UP
+//
+// @Summary     Pick up an order
+// @Tags        driver
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/orders/{id}/pickup [put]
 func PickupOrder(c *gin.Context) {
 	driverID := middleware.GetUserID(c)
 	orderID := c.Param("id")
+	if !requireOnlineDriver(c, driverID) {
+		return
+	}
 
 	var order models.Order
-	if err := config.DB.First(&order, orderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	if err := requestDB(c).First(&order, orderID).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
 		return
 	}
 
 	// Prevent two drivers picking up same order
 	if order.DriverID != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Order has already been picked up by another driver"})
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.order_already_picked_up", nil)
 		return
 	}
 
 	if err := statemachine.CanTransition(order.Status, models.StatusPickedUp, "driver"); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"error":             "Invalid state transition",
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrInvalidTransition, "errors.invalid_state_transition", gin.H{
 			"current_status":    order.Status,
 			"reason":            err.Error(),
 			"valid_next_states": statemachine.ValidTransitionsFrom(order.Status),
@@ -62,8 +108,21 @@ func PickupOrder(c *gin.Context) {
 		return
 	}
 
+	// Enforce the driver's concurrent delivery cap
+	profile, err := getDriverProfile(requestDB(c), driverID)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_driver_profile", nil)
+		return
+	}
+	if activeDeliveryCount(requestDB(c), driverID) >= int64(profile.MaxConcurrentOrders) {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.max_concurrent_deliveries_reached", gin.H{
+			"max_concurrent_orders": profile.MaxConcurrentOrders,
+		})
+		return
+	}
+
 	prevStatus := order.Status
-	config.DB.Model(&order).Updates(map[string]interface{}{
+	requestDB(c).Model(&order).Updates(map[string]interface{}{
 		"status":    models.StatusPickedUp,
 		"driver_id": driverID,
 	})
@@ -75,55 +134,165 @@ func PickupOrder(c *gin.Context) {
 		ChangedBy:  driverID,
 		Note:       "Driver picked up the order",
 	}
-	config.DB.Create(&history)
+	requestDB(c).Create(&history)
+	publishStatusChange(order, prevStatus, models.StatusPickedUp)
 
+	order.Status = models.StatusPickedUp
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "Order picked up successfully",
-		"order_id": order.ID,
-		"status":   models.StatusPickedUp,
+		"message":        "Order picked up successfully",
+		"order_id":       order.ID,
+		"invoice_number": order.InvoiceNumber,
+		"status":         models.StatusPickedUp,
+		"links":          orderLinks(c, &order),
 	})
 }
 
+type DeliverOrderRequest struct {
+	// Required for cash-on-delivery orders
+	CODAmountCollected *float64 `json:"cod_amount_collected" binding:"omitempty,min=0"`
+	// Order items that never reached the customer; their price is taken off the total
+	UndeliveredItemIDs []uint `json:"undelivered_item_ids"`
+}
+
+// deliveredUpdate stamps order as delivered at now — delivery time, SLA, tip
+// split and route distance — and returns the columns to save, status
+// included. Every path that delivers an order goes through it.
+func deliveredUpdate(tx *gorm.DB, order *models.Order, now time.Time) (map[string]interface{}, error) {
+	var restaurant models.Restaurant
+	if err := tx.Select("id", "sla_minutes").First(&restaurant, order.RestaurantID).Error; err != nil {
+		return nil, err
+	}
+	order.DeliveredAt = &now
+	order.SLAMet = now.Sub(order.CreatedAt) <= time.Duration(restaurant.SLAMinutes)*time.Minute
+	update := map[string]interface{}{"status": models.StatusDelivered, "delivered_at": now, "sla_met": order.SLAMet}
+	if order.TipAmount > 0 {
+		order.DriverTipAmount, order.PlatformTipIncome = splitTip(order.TipAmount)
+		update["driver_tip_amount"] = order.DriverTipAmount
+		update["platform_tip_income"] = order.PlatformTipIncome
+	}
+	distance, err := routeDistanceKm(tx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if distance != nil {
+		order.RouteDistanceKm = distance
+		update["route_distance_km"] = *distance
+	}
+	return update, nil
+}
+
 // DeliverOrder transitions PICKED_UP → DELIVERED
+//
+// @Summary     Mark an order as delivered
+// @Tags        driver
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                  true   "Order ID"
+// @Param       body  body  DeliverOrderRequest  false  "Cash collected (COD orders only) and any undelivered items"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/orders/{id}/deliver [put]
 func DeliverOrder(c *gin.Context) {
 	driverID := middleware.GetUserID(c)
 	orderID := c.Param("id")
 
 	var order models.Order
-	if err := config.DB.First(&order, orderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	if err := requestDB(c).First(&order, orderID).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
 		return
 	}
 
 	if order.DriverID == nil || *order.DriverID != driverID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not the assigned driver for this order"})
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.not_assigned_driver", nil)
 		return
 	}
 
 	if err := statemachine.CanTransition(order.Status, models.StatusDelivered, "driver"); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"error":          "Invalid state transition",
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrInvalidTransition, "errors.invalid_state_transition", gin.H{
 			"current_status": order.Status,
 			"reason":         err.Error(),
 		})
 		return
 	}
 
+	var req DeliverOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+	if order.PaymentMethod == models.PaymentCOD && req.CODAmountCollected == nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.cod_amount_collected_required",
+			gin.H{"expected_amount": order.TotalPrice})
+		return
+	}
+
 	prevStatus := order.Status
-	config.DB.Model(&order).Update("status", models.StatusDelivered)
+	missing := []models.OrderItem{}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if len(req.UndeliveredItemIDs) > 0 {
+			var err error
+			if missing, err = markUndelivered(tx, &order, req.UndeliveredItemIDs); err != nil {
+				return err
+			}
+		}
+		update, err := deliveredUpdate(tx, &order, time.Now())
+		if err != nil {
+			return err
+		}
+		if order.PaymentMethod == models.PaymentCOD {
+			// Expected cash is the total after any undelivered items came off
+			collected := *req.CODAmountCollected
+			order.CODCollected = true
+			order.CODAmountCollected = collected
+			order.CODVariance = math.Round((collected-order.TotalPrice)*100) / 100
+			update["cod_collected"] = true
+			update["cod_amount_collected"] = collected
+			update["cod_variance"] = order.CODVariance
+		}
+		if err := tx.Model(&order).Updates(update).Error; err != nil {
+			return err
+		}
+		note := "Order delivered to customer"
+		if len(missing) > 0 {
+			note = fmt.Sprintf("Order delivered with %d item(s) missing", len(missing))
+		}
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: prevStatus,
+			ToStatus:   models.StatusDelivered,
+			ChangedBy:  driverID,
+			Note:       note,
+		}).Error
+	})
+	if err != nil {
+		c.Error(err).SetMeta("errors.failed_to_deliver_order")
+		c.Abort()
+		return
+	}
 
-	history := models.OrderStatusHistory{
-		OrderID:    order.ID,
-		FromStatus: prevStatus,
-		ToStatus:   models.StatusDelivered,
-		ChangedBy:  driverID,
-		Note:       "Order delivered to customer",
+	publishStatusChange(order, prevStatus, models.StatusDelivered)
+	if len(missing) > 0 {
+		notifyAdminsPartialDelivery(order, missing)
 	}
-	config.DB.Create(&history)
 
+	order.Status = models.StatusDelivered
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "Order delivered successfully! 🎉",
-		"order_id": order.ID,
-		"status":   models.StatusDelivered,
+		"message":           "Order delivered successfully! 🎉",
+		"order_id":          order.ID,
+		"invoice_number":    order.InvoiceNumber,
+		"status":            models.StatusDelivered,
+		"payment_method":    order.PaymentMethod,
+		"cod_variance":      order.CODVariance,
+		"partial_delivery":  order.PartialDelivery,
+		"total_price":       order.TotalPrice,
+		"undelivered_items": missing,
+		"links":             orderLinks(c, &order),
 	})
 }
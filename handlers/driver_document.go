@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SubmitDriverDocumentRequest struct {
+	DocumentType string `json:"document_type" binding:"required,oneof=license insurance identity"`
+	URL          string `json:"url" binding:"required,url"`
+	// ExpiresAt is when the document stops being valid, if it expires
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RenewDriverDocumentRequest struct {
+	URL       string     `json:"url" binding:"required,url"`
+	ExpiresAt *time.Time `json:"expires_at" binding:"required"`
+}
+
+type ReviewDriverDocumentRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Note   string `json:"note"`
+}
+
+// latestDriverDocuments returns each document type's most recent submission.
+// Renewals count only once approved.
+func latestDriverDocuments(db *gorm.DB, driverID uint) map[string]models.DriverDocument {
+	var docs []models.DriverDocument
+	db.Where("driver_id = ?", driverID).Order("created_at, id").Find(&docs)
+	latest := make(map[string]models.DriverDocument, len(docs))
+	for _, d := range docs {
+		if d.RenewsID != nil && d.Status != models.DocumentApproved {
+			continue
+		}
+		latest[d.DocumentType] = d
+	}
+	return latest
+}
+
+// missingDriverDocuments lists the required document types whose latest
+// submission isn't approved, with why: "missing", "pending", "rejected" or
+// "expired". A document past its expiry counts as expired before the expiry
+// worker gets to it.
+func missingDriverDocuments(db *gorm.DB, driverID uint) map[string]string {
+	latest := latestDriverDocuments(db, driverID)
+	now := time.Now()
+	missing := map[string]string{}
+	for _, docType := range models.RequiredDriverDocuments {
+		doc, ok := latest[docType]
+		switch {
+		case !ok:
+			missing[docType] = "missing"
+		case doc.Status != models.DocumentApproved:
+			missing[docType] = doc.Status
+		case doc.ExpiresAt != nil && !doc.ExpiresAt.After(now):
+			missing[docType] = models.DocumentExpired
+		}
+	}
+	return missing
+}
+
+// validDocumentExpiry responds 400 and returns false unless expiresAt is
+// unset or in the future
+func validDocumentExpiry(c *gin.Context, expiresAt *time.Time) bool {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.expires_at_must_be_in_the_future", nil)
+		return false
+	}
+	return true
+}
+
+// SubmitDriverDocument records an onboarding document for admin review
+//
+// @Summary     Submit an onboarding document
+// @Tags        driver
+// @Accept      json
+// @Produce     json
+// @Param       body  body  SubmitDriverDocumentRequest  true  "Document type (license, insurance or identity), URL and optional expiry"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/documents [post]
+func SubmitDriverDocument(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var req SubmitDriverDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if !validDocumentExpiry(c, req.ExpiresAt) {
+		return
+	}
+	doc := models.DriverDocument{
+		DriverID:     driverID,
+		DocumentType: req.DocumentType,
+		URL:          req.URL,
+		Status:       models.DocumentPending,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if err := requestDB(c).Create(&doc).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_document", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Document submitted for review", "document": doc})
+}
+
+// RenewDriverDocument submits a renewal of an approved document before it
+// expires. The renewal goes to admin review; the current document stays valid
+// until it expires or the renewal is approved, whichever comes first.
+//
+// @Summary     Renew a document
+// @Tags        driver
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                         true  "Document ID"
+// @Param       body  body  RenewDriverDocumentRequest  true  "URL of the renewed document and its expiry"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/documents/{id} [put]
+func RenewDriverDocument(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var req RenewDriverDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if !validDocumentExpiry(c, req.ExpiresAt) {
+		return
+	}
+	var doc models.DriverDocument
+	if err := requestDB(c).First(&doc, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.document_not_found")
+		c.Abort()
+		return
+	}
+	if doc.DriverID != driverID {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_document_does_not_belong_to_you", nil)
+		return
+	}
+	if doc.Status != models.DocumentApproved || (doc.ExpiresAt != nil && !doc.ExpiresAt.After(time.Now())) {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict,
+			"errors.document_not_renewable",
+			gin.H{"status": doc.Status, "expires_at": doc.ExpiresAt})
+		return
+	}
+	if current := latestDriverDocuments(requestDB(c), driverID)[doc.DocumentType]; current.ID != doc.ID {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.this_document_has_been_superseded",
+			gin.H{"current_document_id": current.ID})
+		return
+	}
+
+	renewal := models.DriverDocument{
+		DriverID:     driverID,
+		DocumentType: doc.DocumentType,
+		URL:          req.URL,
+		Status:       models.DocumentPending,
+		ExpiresAt:    req.ExpiresAt,
+		RenewsID:     &doc.ID,
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		tx.Model(&models.DriverDocument{}).Where("renews_id = ? AND status = ?", doc.ID, models.DocumentPending).Count(&pending)
+		if pending > 0 {
+			return apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.document_renewal_pending", nil)
+		}
+		return tx.Create(&renewal).Error
+	})
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		apierror.RespondError(c, apiErr)
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_renewal", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Renewal submitted for review", "document": renewal})
+}
+
+// GetMyDriverDocuments lists the caller's submitted documents and what is still
+// needed before they can go online
+//
+// @Summary     My onboarding documents
+// @Tags        driver
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /driver/documents [get]
+func GetMyDriverDocuments(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var docs []models.DriverDocument
+	requestDB(c).Where("driver_id = ?", driverID).Order("created_at desc").Find(&docs)
+	missing := missingDriverDocuments(requestDB(c), driverID)
+	c.JSON(http.StatusOK, gin.H{
+		"documents":          docs,
+		"can_go_online":      len(missing) == 0,
+		"required_documents": missing,
+	})
+}
+
+// AdminGetPendingDocuments lists driver documents awaiting review, oldest first — admin only
+//
+// @Summary     Driver documents awaiting review
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/documents/pending [get]
+func AdminGetPendingDocuments(c *gin.Context) {
+	var docs []models.DriverDocument
+	requestDB(c).Preload("Driver").Where("status = ?", models.DocumentPending).Order("created_at").Find(&docs)
+	c.JSON(http.StatusOK, gin.H{"count": len(docs), "documents": docs})
+}
+
+// AdminReviewDocument approves or rejects a pending driver document — admin only
+//
+// @Summary     Review a driver document
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                          true  "Document ID"
+// @Param       body  body  ReviewDriverDocumentRequest  true  "approved or rejected, with an optional note"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/documents/{id}/review [put]
+func AdminReviewDocument(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req ReviewDriverDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var doc models.DriverDocument
+	if err := requestDB(c).Preload("Driver").First(&doc, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.document_not_found")
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	res := requestDB(c).Model(&models.DriverDocument{}).
+		Where("id = ? AND status = ?", doc.ID, models.DocumentPending).
+		Updates(map[string]interface{}{
+			"status":      req.Status,
+			"admin_note":  req.Note,
+			"reviewed_by": adminID,
+			"reviewed_at": now,
+		})
+	if res.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_review", nil)
+		return
+	}
+	if res.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.document_has_already_been_reviewed",
+			gin.H{"status": doc.Status})
+		return
+	}
+	doc.Status, doc.AdminNote, doc.ReviewedBy, doc.ReviewedAt = req.Status, req.Note, &adminID, &now
+
+	body := fmt.Sprintf("Your %s was approved.", doc.DocumentType)
+	if req.Status == models.DocumentRejected {
+		body = fmt.Sprintf("Your %s was rejected. Please submit a new one.", doc.DocumentType)
+	}
+	if req.Note != "" {
+		body += " Note: " + req.Note
+	}
+	err := notify.Default.Send(notify.Message{
+		UserID:  doc.DriverID,
+		Email:   doc.Driver.Email,
+		Phone:   doc.Driver.Phone,
+		Channel: notify.ChannelPush,
+		Title:   "Document " + req.Status,
+		Body:    body,
+
+		EventType:     "driver_document_" + req.Status,
+		ReferenceID:   doc.ID,
+		ReferenceType: "driver_document",
+	})
+	if err != nil {
+		log.Printf("driver documents: failed to notify driver %d about document %d: %v", doc.DriverID, doc.ID, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document " + req.Status, "document": doc})
+}
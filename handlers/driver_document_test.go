@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+)
+
+// submitDocument submits a document as driverID and returns it
+func submitDocument(t *testing.T, driverID uint, docType string) models.DriverDocument {
+	t.Helper()
+	w := serve(SubmitDriverDocument, "/driver/documents", driverID, models.RoleDriver, http.MethodPost, "/driver/documents",
+		fmt.Sprintf(`{"document_type":%q,"url":"https://files.example.com/%s.pdf"}`, docType, docType))
+	wantStatus(t, w, http.StatusCreated)
+	var body struct{ Document models.DriverDocument }
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return body.Document
+}
+
+// reviewDocument reviews a document as adminID and returns the response code
+func reviewDocument(adminID, docID uint, status string) int {
+	return serve(AdminReviewDocument, "/admin/documents/:id/review", adminID, models.RoleAdmin, http.MethodPut,
+		fmt.Sprintf("/admin/documents/%d/review", docID), fmt.Sprintf(`{"status":%q,"note":"checked"}`, status)).Code
+}
+
+func goOnline(driverID uint) int {
+	return serve(SetDriverAvailability, "/driver/availability", driverID, models.RoleDriver, http.MethodPut,
+		"/driver/availability", `{"online":true}`).Code
+}
+
+func TestDriverGoesOnlineOnceDocumentsAreApproved(t *testing.T) {
+	db := newTestDB(t)
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+	driver := createUser(t, db, "Dev", models.RoleDriver)
+
+	license := submitDocument(t, driver.ID, models.DocumentLicense)
+	if license.Status != models.DocumentPending {
+		t.Fatalf("new document status = %q, want pending", license.Status)
+	}
+	insurance := submitDocument(t, driver.ID, models.DocumentInsurance)
+	if code := goOnline(driver.ID); code != http.StatusConflict {
+		t.Fatalf("online with pending documents: status = %d, want 409", code)
+	}
+
+	if code := reviewDocument(admin.ID, license.ID, models.DocumentApproved); code != http.StatusOK {
+		t.Fatalf("approve: status = %d", code)
+	}
+	if code := reviewDocument(admin.ID, license.ID, models.DocumentRejected); code != http.StatusConflict {
+		t.Errorf("second review: status = %d, want 409", code)
+	}
+	// A rejected insurance is replaced by submitting a new one
+	reviewDocument(admin.ID, insurance.ID, models.DocumentRejected)
+	w := serve(GetMyDriverDocuments, "/driver/documents", driver.ID, models.RoleDriver, http.MethodGet, "/driver/documents", "")
+	var mine struct {
+		CanGoOnline       bool              `json:"can_go_online"`
+		RequiredDocuments map[string]string `json:"required_documents"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	if mine.CanGoOnline || len(mine.RequiredDocuments) != 1 || mine.RequiredDocuments[models.DocumentInsurance] != models.DocumentRejected {
+		t.Errorf("my documents = %+v, want only the insurance, rejected", mine)
+	}
+
+	insurance = submitDocument(t, driver.ID, models.DocumentInsurance)
+	reviewDocument(admin.ID, insurance.ID, models.DocumentApproved)
+	if code := goOnline(driver.ID); code != http.StatusOK {
+		t.Errorf("online with approved documents: status = %d, want 200", code)
+	}
+
+	var notifications int64
+	db.Model(&models.Notification{}).Where("user_id = ?", driver.ID).Count(&notifications)
+	if notifications != 3 {
+		t.Errorf("%d notifications sent to the driver, want one per review (3)", notifications)
+	}
+}
+
+func TestExpiredDocumentKeepsDriverOffline(t *testing.T) {
+	db := newTestDB(t)
+	driver := createUser(t, db, "Dev", models.RoleDriver)
+	expired := time.Now().Add(-time.Hour)
+	for _, docType := range models.RequiredDriverDocuments {
+		db.Create(&models.DriverDocument{DriverID: driver.ID, DocumentType: docType, URL: "https://x", Status: models.DocumentApproved, ExpiresAt: &expired})
+	}
+	missing := missingDriverDocuments(db, driver.ID)
+	if len(missing) != 2 || missing[models.DocumentLicense] != models.DocumentExpired {
+		t.Errorf("missing = %v, want both expired", missing)
+	}
+
+	w := serve(SubmitDriverDocument, "/driver/documents", driver.ID, models.RoleDriver, http.MethodPost, "/driver/documents",
+		fmt.Sprintf(`{"document_type":"license","url":"https://x","expires_at":%q}`, expired.Format(time.RFC3339)))
+	wantStatus(t, w, http.StatusBadRequest)
+}
+
+func TestDocumentRenewal(t *testing.T) {
+	db := newTestDB(t)
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+	driver := createUser(t, db, "Dev", models.RoleDriver)
+	other := createUser(t, db, "Ola", models.RoleDriver)
+	soon := time.Now().Add(48 * time.Hour)
+	license := models.DriverDocument{DriverID: driver.ID, DocumentType: models.DocumentLicense, URL: "https://old", Status: models.DocumentApproved, ExpiresAt: &soon}
+	db.Create(&license)
+
+	renew := func(userID uint) int {
+		next := time.Now().AddDate(1, 0, 0).Format(time.RFC3339)
+		return serve(RenewDriverDocument, "/driver/documents/:id", userID, models.RoleDriver, http.MethodPut,
+			fmt.Sprintf("/driver/documents/%d", license.ID), fmt.Sprintf(`{"url":"https://new","expires_at":%q}`, next)).Code
+	}
+	if code := renew(other.ID); code != http.StatusForbidden {
+		t.Errorf("another driver's document: status = %d, want 403", code)
+	}
+	if code := renew(driver.ID); code != http.StatusCreated {
+		t.Fatalf("renew: status = %d, want 201", code)
+	}
+	if code := renew(driver.ID); code != http.StatusConflict {
+		t.Errorf("second pending renewal: status = %d, want 409", code)
+	}
+	// The pending renewal doesn't replace the approved document
+	if got := latestDriverDocuments(db, driver.ID)[models.DocumentLicense]; got.ID != license.ID {
+		t.Errorf("current license = %d before approval, want %d", got.ID, license.ID)
+	}
+
+	var renewal models.DriverDocument
+	db.Where("renews_id = ?", license.ID).First(&renewal)
+	reviewDocument(admin.ID, renewal.ID, models.DocumentApproved)
+	if got := latestDriverDocuments(db, driver.ID)[models.DocumentLicense]; got.ID != renewal.ID {
+		t.Errorf("current license = %d after approval, want the renewal %d", got.ID, renewal.ID)
+	}
+	if code := renew(driver.ID); code != http.StatusConflict {
+		t.Errorf("renewing a superseded document: status = %d, want 409", code)
+	}
+}
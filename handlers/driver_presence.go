@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	driverIdleCheckInterval = time.Minute
+	staleDriverAfter        = time.Hour // GET /admin/drivers/stale cut-off
+)
+
+// MaintenanceDriverAutoOffline is logged when the idle worker takes a driver offline
+const MaintenanceDriverAutoOffline = "DRIVER_AUTO_OFFLINE"
+
+// StartDriverIdleWorker takes drivers offline once they have made no API call
+// for DRIVER_IDLE_OFFLINE_MINUTES, so a crashed app doesn't leave them online.
+func StartDriverIdleWorker() {
+	go func() {
+		for range time.Tick(driverIdleCheckInterval) {
+			runDriverIdleCheck(time.Now())
+		}
+	}()
+}
+
+func runDriverIdleCheck(now time.Time) {
+	limit := time.Duration(sysconfig.Int(sysconfig.KeyDriverIdleMinutes)) * time.Minute
+	if limit <= 0 {
+		return
+	}
+	cutoff := now.Add(-limit)
+	var idle []models.DriverProfile
+	config.DB.Where("is_online = ? AND last_seen_at < ?", true, cutoff).Find(&idle)
+	for _, p := range idle {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			// Skip if the driver showed up again since the query
+			res := tx.Model(&models.DriverProfile{}).
+				Where("id = ? AND is_online = ? AND last_seen_at < ?", p.ID, true, cutoff).
+				Update("is_online", false)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			return logMaintenance(tx, MaintenanceDriverAutoOffline, nil, gin.H{
+				"driver_id":    p.UserID,
+				"last_seen_at": p.LastSeenAt,
+				"idle_minutes": int(now.Sub(*p.LastSeenAt).Minutes()),
+			})
+		})
+		if err != nil {
+			log.Printf("driver idle: failed to take driver %d offline: %v", p.UserID, err)
+		}
+	}
+}
+
+type DriverAvailabilityRequest struct {
+	Online *bool `json:"online" binding:"required"`
+}
+
+// SetDriverAvailability lets a driver go online or offline
+//
+// @Summary     Go online or offline
+// @Tags        driver
+// @Accept      json
+// @Produce     json
+// @Param       body  body  DriverAvailabilityRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/availability [put]
+func SetDriverAvailability(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var req DriverAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	profile, err := getDriverProfile(requestDB(c), driverID)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_driver_profile", nil)
+		return
+	}
+	if *req.Online {
+		if missing := missingDriverDocuments(requestDB(c), driverID); len(missing) > 0 {
+			apierror.Respond(c, http.StatusConflict, apierror.ErrConflict,
+				"errors.driver_documents_not_approved",
+				gin.H{"required_documents": missing})
+			return
+		}
+	}
+	requestDB(c).Model(&profile).Update("is_online", *req.Online)
+	c.JSON(http.StatusOK, gin.H{"message": "Availability updated", "is_online": *req.Online})
+}
+
+// requireOnlineDriver responds 409 and returns false when the driver is offline
+func requireOnlineDriver(c *gin.Context, driverID uint) bool {
+	profile, err := getDriverProfile(requestDB(c), driverID)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_driver_profile", nil)
+		return false
+	}
+	if !profile.IsOnline {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.driver_offline",
+			gin.H{"last_seen_at": profile.LastSeenAt})
+		return false
+	}
+	return true
+}
+
+// AdminGetStaleDrivers lists drivers with no API activity in the last hour — admin only
+//
+// @Summary     Drivers not seen in the last hour
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/drivers/stale [get]
+func AdminGetStaleDrivers(c *gin.Context) {
+	now := time.Now()
+	var profiles []models.DriverProfile
+	requestDB(c).Preload("User").
+		Where("last_seen_at IS NULL OR last_seen_at < ?", now.Add(-staleDriverAfter)).
+		Order("last_seen_at").Find(&profiles)
+
+	drivers := make([]gin.H, 0, len(profiles))
+	for _, p := range profiles {
+		row := gin.H{
+			"driver_id":    p.UserID,
+			"name":         p.User.Name,
+			"is_online":    p.IsOnline,
+			"last_seen_at": p.LastSeenAt,
+		}
+		if p.LastSeenAt != nil {
+			row["idle_minutes"] = int(now.Sub(*p.LastSeenAt).Minutes())
+		}
+		drivers = append(drivers, row)
+	}
+	c.JSON(http.StatusOK, gin.H{"count": len(drivers), "drivers": drivers})
+}
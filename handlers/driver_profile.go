@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DriverProfileRequest struct {
+	VehicleType string `json:"vehicle_type" binding:"required"`
+}
+
+type AdminDriverProfileRequest struct {
+	VehicleType         *string `json:"vehicle_type"`
+	MaxConcurrentOrders *int    `json:"max_concurrent_orders" binding:"omitempty,min=1"`
+	ZoneID              *uint   `json:"zone_id"` // 0 removes the zone
+}
+
+var validVehicleTypes = map[string]bool{
+	models.VehicleBicycle: true,
+	models.VehicleScooter: true,
+	models.VehicleCar:     true,
+}
+
+// getDriverProfile loads a driver's profile, creating one with defaults on first use
+func getDriverProfile(db *gorm.DB, driverID uint) (models.DriverProfile, error) {
+	profile := models.DriverProfile{UserID: driverID, MaxConcurrentOrders: models.DefaultMaxConcurrentOrders}
+	err := db.Where("user_id = ?", driverID).FirstOrCreate(&profile).Error
+	return profile, err
+}
+
+// activeDeliveryCount counts the orders a driver is currently carrying
+func activeDeliveryCount(db *gorm.DB, driverID uint) int64 {
+	var count int64
+	db.Model(&models.Order{}).
+		Where("driver_id = ? AND status = ?", driverID, models.StatusPickedUp).
+		Count(&count)
+	return count
+}
+
+// GetDriverProfile returns the logged-in driver's profile
+//
+// @Summary     Get my driver profile
+// @Tags        driver
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     500  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/profile [get]
+func GetDriverProfile(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	profile, err := getDriverProfile(requestDB(c), driverID)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_driver_profile", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"profile":           profile,
+		"active_deliveries": activeDeliveryCount(requestDB(c), driverID),
+	})
+}
+
+// UpdateDriverProfile lets a driver set their vehicle type
+//
+// @Summary     Set my vehicle type
+// @Tags        driver
+// @Accept      json
+// @Produce     json
+// @Param       body  body  DriverProfileRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/profile [put]
+func UpdateDriverProfile(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var req DriverProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if !validVehicleTypes[req.VehicleType] {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_vehicle_type", nil)
+		return
+	}
+
+	profile, err := getDriverProfile(requestDB(c), driverID)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_driver_profile", nil)
+		return
+	}
+	profile.SetVehicleType(req.VehicleType)
+	requestDB(c).Save(&profile)
+	c.JSON(http.StatusOK, gin.H{"message": "Driver profile updated", "profile": profile})
+}
+
+// AdminUpdateDriverProfile lets admin override a driver's vehicle and delivery cap — admin only
+//
+// @Summary     Override a driver's vehicle or delivery cap
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Driver user ID"
+// @Param       body  body  AdminDriverProfileRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/drivers/{id}/profile [put]
+func AdminUpdateDriverProfile(c *gin.Context) {
+	var driver models.User
+	if err := requestDB(c).Where("id = ? AND role = ?", c.Param("id"), models.RoleDriver).First(&driver).Error; err != nil {
+		c.Error(err).SetMeta("errors.driver_not_found")
+		c.Abort()
+		return
+	}
+
+	var req AdminDriverProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	profile, err := getDriverProfile(requestDB(c), driver.ID)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_driver_profile", nil)
+		return
+	}
+	if req.VehicleType != nil {
+		if !validVehicleTypes[*req.VehicleType] {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_vehicle_type", nil)
+			return
+		}
+		profile.SetVehicleType(*req.VehicleType)
+	}
+	// An explicit cap always wins over the vehicle default
+	if req.MaxConcurrentOrders != nil {
+		profile.MaxConcurrentOrders = *req.MaxConcurrentOrders
+	}
+	if req.ZoneID != nil {
+		if *req.ZoneID == 0 {
+			profile.ZoneID = nil
+		} else {
+			var zone models.DeliveryZone
+			if err := requestDB(c).First(&zone, *req.ZoneID).Error; err != nil {
+				c.Error(err).SetMeta("errors.zone_not_found")
+				c.Abort()
+				return
+			}
+			profile.ZoneID = &zone.ID
+		}
+	}
+	requestDB(c).Save(&profile)
+	c.JSON(http.StatusOK, gin.H{"message": "Driver profile updated by admin", "profile": profile})
+}
+
+// AdminGetOverloadedDrivers lists drivers carrying more orders than their cap allows.
+// Pickup enforces the cap, so any result here points to a bug or data inconsistency.
+//
+// @Summary     List drivers over their concurrent delivery cap
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/drivers/overloaded [get]
+func AdminGetOverloadedDrivers(c *gin.Context) {
+	type overloaded struct {
+		DriverID            uint   `json:"driver_id"`
+		Name                string `json:"name"`
+		Email               string `json:"email"`
+		ActiveDeliveries    int64  `json:"active_deliveries"`
+		MaxConcurrentOrders int    `json:"max_concurrent_orders"`
+	}
+
+	var rows []overloaded
+	requestDB(c).Table("orders").
+		Select("users.id AS driver_id, users.name, users.email, COUNT(orders.id) AS active_deliveries, "+
+			"COALESCE(driver_profiles.max_concurrent_orders, ?) AS max_concurrent_orders", models.DefaultMaxConcurrentOrders).
+		Joins("JOIN users ON users.id = orders.driver_id").
+		Joins("LEFT JOIN driver_profiles ON driver_profiles.user_id = orders.driver_id").
+		Where("orders.status = ? AND orders.deleted_at IS NULL", models.StatusPickedUp).
+		Group("users.id, users.name, users.email, driver_profiles.max_concurrent_orders").
+		Having("COUNT(orders.id) > COALESCE(driver_profiles.max_concurrent_orders, ?)", models.DefaultMaxConcurrentOrders).
+		Scan(&rows)
+
+	c.JSON(http.StatusOK, gin.H{"count": len(rows), "drivers": rows})
+}
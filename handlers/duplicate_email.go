@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DuplicateEmailUser is one of the accounts sharing an email
+type DuplicateEmailUser struct {
+	ID        uint            `json:"id"`
+	Name      string          `json:"name"`
+	Email     string          `json:"email"`
+	Role      models.UserRole `json:"role"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// DuplicateEmailGroup is the accounts registered under one email, ignoring case
+type DuplicateEmailGroup struct {
+	Email string               `json:"email"` // lowercased
+	Count int                  `json:"count"`
+	Users []DuplicateEmailUser `json:"users"`
+}
+
+type DeduplicateUsersRequest struct {
+	KeepID    uint   `json:"keep_id" binding:"required"`
+	DeleteIDs []uint `json:"delete_ids" binding:"required,min=1"`
+}
+
+// AdminGetDuplicateEmails lists emails registered more than once, ignoring
+// case, with the accounts behind each, oldest first — admin only
+//
+// @Summary     Accounts sharing an email
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/users/duplicate-emails [get]
+func AdminGetDuplicateEmails(c *gin.Context) {
+	var emails []struct {
+		Email string
+		Count int
+	}
+	requestDB(c).Model(&models.User{}).
+		Select("LOWER(email) AS email, COUNT(*) AS count").
+		Group("LOWER(email)").Having("COUNT(*) > 1").
+		Order("email").Scan(&emails)
+
+	groups := make([]DuplicateEmailGroup, len(emails))
+	index := make(map[string]int, len(emails))
+	keys := make([]string, len(emails))
+	for i, e := range emails {
+		groups[i] = DuplicateEmailGroup{Email: e.Email, Count: e.Count, Users: []DuplicateEmailUser{}}
+		index[e.Email] = i
+		keys[i] = e.Email
+	}
+	if len(keys) > 0 {
+		var users []DuplicateEmailUser
+		requestDB(c).Model(&models.User{}).Where("LOWER(email) IN ?", keys).Order("id").Scan(&users)
+		for _, u := range users {
+			g := &groups[index[strings.ToLower(u.Email)]]
+			g.Users = append(g.Users, u)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"count": len(groups), "duplicates": groups})
+}
+
+// AdminDeduplicateUsers merges accounts sharing an email into the one to
+// keep, one after the other in a single transaction: if any merge fails none
+// is applied. Every account must have the kept account's email, ignoring
+// case. Admin only.
+//
+// @Summary     Merge accounts sharing an email
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  DeduplicateUsersRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/users/deduplicate [post]
+func AdminDeduplicateUsers(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req DeduplicateUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var keep models.User
+	if err := requestDB(c).First(&keep, req.KeepID).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_to_keep_not_found")
+		c.Abort()
+		return
+	}
+	seen := map[uint]bool{}
+	var dups []models.User
+	for _, id := range req.DeleteIDs {
+		if id == keep.ID {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.delete_ids_include_keep_id", nil)
+			return
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var dup models.User
+		if err := requestDB(c).First(&dup, id).Error; err != nil {
+			c.Error(err).SetMeta("errors.user_to_delete_not_found")
+			c.Abort()
+			return
+		}
+		if !strings.EqualFold(dup.Email, keep.Email) {
+			apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.user_email_differs_from_kept", gin.H{"delete_user_id": dup.ID}, dup.ID)
+			return
+		}
+		if apiErr := checkMergeable(keep, dup); apiErr != nil {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		dups = append(dups, dup)
+	}
+
+	merged := make([]gin.H, 0, len(dups))
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		for _, dup := range dups {
+			summary := gin.H{}
+			if err := mergeUsers(tx, adminID, keep, dup, summary); err != nil {
+				return err
+			}
+			merged = append(merged, summary)
+		}
+		return nil
+	})
+	if err != nil {
+		c.Error(err).SetMeta("errors.failed_to_merge_users")
+		c.Abort()
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Duplicate accounts merged", "keep_id": keep.ID, "merged": merged})
+}
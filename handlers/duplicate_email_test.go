@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// emailTwin adds a customer registered under email before emails were
+// lowercased, flagged like the migration flags them
+func emailTwin(t *testing.T, db *gorm.DB, name, email string) models.User {
+	t.Helper()
+	user := models.User{Name: name, Email: email, PasswordHash: "x", Role: models.RoleCustomer, DuplicateEmail: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestDuplicateEmailsAreGroupedIgnoringCase(t *testing.T) {
+	db := newTestDB(t)
+	keep := createUser(t, db, "Asha", models.RoleCustomer)
+	twin := emailTwin(t, db, "Asha Twin", "ASHA@example.com")
+	createUser(t, db, "Ravi", models.RoleCustomer)
+
+	w := serve(AdminGetDuplicateEmails, "/admin/users/duplicate-emails", 1, models.RoleAdmin, http.MethodGet, "/admin/users/duplicate-emails", "")
+	wantStatus(t, w, http.StatusOK)
+	var body struct {
+		Duplicates []DuplicateEmailGroup
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Duplicates) != 1 {
+		t.Fatalf("duplicates = %+v, want one group", body.Duplicates)
+	}
+	g := body.Duplicates[0]
+	if g.Email != "asha@example.com" || g.Count != 2 || len(g.Users) != 2 || g.Users[0].ID != keep.ID || g.Users[1].ID != twin.ID {
+		t.Errorf("group = %+v, want asha@example.com with users %d then %d", g, keep.ID, twin.ID)
+	}
+}
+
+func TestDuplicateEmailFlagIsNotExposed(t *testing.T) {
+	out, _ := json.Marshal(models.User{DuplicateEmail: true})
+	var fields map[string]interface{}
+	json.Unmarshal(out, &fields)
+	if _, ok := fields["duplicate_email"]; ok {
+		t.Errorf("user JSON has duplicate_email: %s", out)
+	}
+}
+
+func TestDeduplicateMergesEveryTwinIntoTheKeptAccount(t *testing.T) {
+	db := newTestDB(t)
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+	restaurant, _ := createRestaurant(t, db)
+	keep := createUser(t, db, "Asha", models.RoleCustomer)
+	twins := []models.User{emailTwin(t, db, "Asha Two", "Asha@example.com"), emailTwin(t, db, "Asha Three", "ASHA@EXAMPLE.COM")}
+	for _, twin := range twins {
+		unpaidOrder(t, db, restaurant, twin)
+		db.Create(&models.LoyaltyAccount{CustomerID: twin.ID, Points: 40, LifetimePoints: 40})
+	}
+	db.Create(&models.LoyaltyAccount{CustomerID: keep.ID, Points: 20, LifetimePoints: 20})
+
+	w := serve(AdminDeduplicateUsers, "/admin/users/deduplicate", admin.ID, models.RoleAdmin, http.MethodPost, "/admin/users/deduplicate",
+		fmt.Sprintf(`{"keep_id":%d,"delete_ids":[%d,%d,%d]}`, keep.ID, twins[0].ID, twins[1].ID, twins[0].ID))
+	wantStatus(t, w, http.StatusOK)
+
+	var users int64
+	db.Model(&models.User{}).Where("LOWER(email) = ?", "asha@example.com").Count(&users)
+	var orders int64
+	db.Model(&models.Order{}).Where("customer_id = ?", keep.ID).Count(&orders)
+	var account models.LoyaltyAccount
+	db.Where("customer_id = ?", keep.ID).First(&account)
+	var logs int64
+	db.Model(&models.MaintenanceLog{}).Where("action = ?", MaintenanceMergeUsers).Count(&logs)
+	if users != 1 || orders != 2 || account.Points != 100 || logs != 2 {
+		t.Errorf("after merge: %d accounts, %d orders, %d points, %d logs; want 1, 2, 100, 2", users, orders, account.Points, logs)
+	}
+}
+
+func TestMergeClearsTheDuplicateFlagWithTheLastTwin(t *testing.T) {
+	db := newTestDB(t)
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+	// The flagged account is the one kept, as when the older one is abandoned
+	older := createUser(t, db, "Asha", models.RoleCustomer)
+	keep := emailTwin(t, db, "Asha Two", "Asha@example.com")
+
+	w := serve(AdminMergeUsers, "/admin/users/merge", admin.ID, models.RoleAdmin, http.MethodPost, "/admin/users/merge",
+		fmt.Sprintf(`{"keep_user_id":%d,"delete_user_id":%d}`, keep.ID, older.ID))
+	wantStatus(t, w, http.StatusOK)
+
+	var kept models.User
+	db.First(&kept, keep.ID)
+	if kept.DuplicateEmail {
+		t.Error("kept account still flagged with no twin left")
+	}
+	// Back under the case-insensitive unique index
+	if err := db.Create(&models.User{Name: "Again", Email: "ASHA@example.com", PasswordHash: "x"}).Error; err == nil {
+		t.Error("registered a new twin of the merged account")
+	}
+}
+
+func TestDeduplicateRejectsBeforeMergingAny(t *testing.T) {
+	tests := []struct {
+		name       string
+		deleteIDs  func(keep, twin, other models.User) string
+		wantStatus int
+	}{
+		{"keep among delete_ids", func(keep, twin, _ models.User) string { return fmt.Sprintf("[%d,%d]", twin.ID, keep.ID) }, http.StatusBadRequest},
+		{"different email", func(_, twin, other models.User) string { return fmt.Sprintf("[%d,%d]", twin.ID, other.ID) }, http.StatusConflict},
+		{"unknown user", func(_, twin, _ models.User) string { return fmt.Sprintf("[%d,999]", twin.ID) }, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			keep := createUser(t, db, "Asha", models.RoleCustomer)
+			twin := emailTwin(t, db, "Asha Two", "Asha@example.com")
+			other := createUser(t, db, "Ravi", models.RoleCustomer)
+
+			w := serve(AdminDeduplicateUsers, "/admin/users/deduplicate", 1, models.RoleAdmin, http.MethodPost, "/admin/users/deduplicate",
+				fmt.Sprintf(`{"keep_id":%d,"delete_ids":%s}`, keep.ID, tt.deleteIDs(keep, twin, other)))
+			wantStatus(t, w, tt.wantStatus)
+			if err := db.First(&models.User{}, twin.ID).Error; err != nil {
+				t.Errorf("twin merged although the request was rejected: %v", err)
+			}
+		})
+	}
+}
+
+func TestMergeUsersChecks(t *testing.T) {
+	db := newTestDB(t)
+	customer := createUser(t, db, "Asha", models.RoleCustomer)
+	other := createUser(t, db, "Ravi", models.RoleCustomer)
+	driver := createUser(t, db, "Dev", models.RoleDriver)
+	driverTwin := createUser(t, db, "Dev Two", models.RoleDriver)
+
+	tests := []struct {
+		name       string
+		keep, dup  uint
+		wantStatus int
+	}{
+		{"same account", customer.ID, customer.ID, http.StatusBadRequest},
+		{"different roles", customer.ID, driver.ID, http.StatusConflict},
+		{"not customers", driver.ID, driverTwin.ID, http.StatusBadRequest},
+		{"unknown account", customer.ID, 999, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := serve(AdminMergeUsers, "/admin/users/merge", 1, models.RoleAdmin, http.MethodPost, "/admin/users/merge",
+			fmt.Sprintf(`{"keep_user_id":%d,"delete_user_id":%d}`, tt.keep, tt.dup))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d; body: %s", tt.name, w.Code, tt.wantStatus, w.Body.String())
+		}
+	}
+
+	// The kept account's dietary preferences win
+	db.Create(&models.DietaryPreference{CustomerID: customer.ID, Allergens: "peanut"})
+	db.Create(&models.DietaryPreference{CustomerID: other.ID, Allergens: "gluten"})
+	w := serve(AdminMergeUsers, "/admin/users/merge", 1, models.RoleAdmin, http.MethodPost, "/admin/users/merge",
+		fmt.Sprintf(`{"keep_user_id":%d,"delete_user_id":%d}`, customer.ID, other.ID))
+	wantStatus(t, w, http.StatusOK)
+	var prefs []models.DietaryPreference
+	db.Find(&prefs)
+	if len(prefs) != 1 || prefs[0].CustomerID != customer.ID || prefs[0].Allergens != "peanut" {
+		t.Errorf("preferences = %+v, want only the kept account's", prefs)
+	}
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/eventbus"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EightySixRequest struct {
+	Reason string `json:"reason"`
+}
+
+type BatchAvailabilityRequest struct {
+	ItemIDs     []uint `json:"item_ids" binding:"required,min=1"`
+	IsAvailable *bool  `json:"is_available" binding:"required"`
+	Reason      string `json:"reason"` // recorded when items are 86'd
+}
+
+// managedMenuItem loads a menu item that belongs to the caller's restaurant,
+// responding and returning false when it can't
+func managedMenuItem(c *gin.Context, item *models.MenuItem) bool {
+	userID := middleware.GetUserID(c)
+	if err := requestDB(c).First(item, c.Param("itemId")).Error; err != nil {
+		c.Error(err).SetMeta("errors.menu_item_not_found")
+		c.Abort()
+		return false
+	}
+	var restaurant models.Restaurant
+	if err := managedRestaurant(requestDB(c), userID, &restaurant); err != nil || restaurant.ID != item.RestaurantID {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.you_dont_manage_this_menu_item", nil)
+		return false
+	}
+	return true
+}
+
+// EightySixMenuItem takes an item off the menu for the rest of service ("86 it")
+//
+// @Summary     86 a menu item
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       itemId  path  int               true   "Menu item ID"
+// @Param       body    body  EightySixRequest  false  "Why the item is out"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId}/eighty-six [put]
+func EightySixMenuItem(c *gin.Context) {
+	var item models.MenuItem
+	if !managedMenuItem(c, &item) {
+		return
+	}
+	var req EightySixRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+	if item.EightySixedAt != nil {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.menu_item_is_already_86d",
+			gin.H{"eightysixed_at": item.EightySixedAt})
+		return
+	}
+
+	now := time.Now()
+	requestDB(c).Model(&item).Updates(map[string]interface{}{
+		"is_available":      false,
+		"eighty_sixed_at":   now,
+		"eighty_six_reason": req.Reason,
+	})
+	requestDB(c).Create(&models.MenuItemEightySix{
+		MenuItemID:    item.ID,
+		RestaurantID:  item.RestaurantID,
+		Reason:        req.Reason,
+		EightySixedAt: now,
+	})
+	invalidateMenuCache(item.RestaurantID)
+	item.EightySixed = true
+	eventbus.Default.Publish(eventbus.ItemEightySixed, eventbus.ItemEightySixedEvent{Item: item, Reason: req.Reason, At: now})
+	c.JSON(http.StatusOK, gin.H{"message": "Menu item 86'd", "item": item})
+}
+
+// RestoreMenuItem puts an 86'd item back on the menu
+//
+// @Summary     Restore an 86'd menu item
+// @Tags        restaurant
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId}/restore [put]
+func RestoreMenuItem(c *gin.Context) {
+	var item models.MenuItem
+	if !managedMenuItem(c, &item) {
+		return
+	}
+	if item.EightySixedAt == nil {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.menu_item_is_not_86d", nil)
+		return
+	}
+	if err := restoreEightySixed(requestDB(c), item); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_menu_item", nil)
+		return
+	}
+	// Re-read into a fresh struct: scanning leaves the old eighty_sixed_at in place of NULL
+	item = models.MenuItem{ID: item.ID}
+	requestDB(c).First(&item)
+	c.JSON(http.StatusOK, gin.H{"message": "Menu item restored", "item": item})
+}
+
+// SetMenuAvailability switches many menu items on or off at once. Turning items
+// off 86's them; turning them on restores any that were 86'd. Either every
+// item is updated or none is.
+//
+// @Summary     Set availability for several menu items
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  BatchAvailabilityRequest  true  "Item IDs and the new availability"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/availability [put]
+func SetMenuAvailability(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	var req BatchAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var restaurant models.Restaurant
+	if err := managedRestaurant(requestDB(c), userID, &restaurant); err != nil {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.you_dont_manage_a_restaurant", nil)
+		return
+	}
+
+	seen := map[uint]bool{}
+	ids := make([]uint, 0, len(req.ItemIDs))
+	for _, id := range req.ItemIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	var owned []uint
+	requestDB(c).Model(&models.MenuItem{}).Where("id IN ? AND restaurant_id = ?", ids, restaurant.ID).Pluck("id", &owned)
+	if len(owned) != len(ids) {
+		for _, id := range owned {
+			delete(seen, id)
+		}
+		foreign := make([]uint, 0, len(seen))
+		for _, id := range ids {
+			if seen[id] {
+				foreign = append(foreign, id)
+			}
+		}
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.menu_items_not_in_restaurant",
+			gin.H{"item_ids": foreign})
+		return
+	}
+
+	now := time.Now()
+	var updated int64
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.MenuItem{}).Where("id IN ? AND restaurant_id = ?", ids, restaurant.ID).
+			Update("is_available", *req.IsAvailable)
+		if res.Error != nil {
+			return res.Error
+		}
+		updated = res.RowsAffected
+
+		if *req.IsAvailable {
+			if err := tx.Model(&models.MenuItem{}).Where("id IN ? AND eighty_sixed_at IS NOT NULL", ids).
+				Updates(map[string]interface{}{"eighty_sixed_at": nil, "eighty_six_reason": ""}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.MenuItemEightySix{}).Where("menu_item_id IN ? AND restored_at IS NULL", ids).
+				Update("restored_at", now).Error
+		}
+
+		// Items that weren't already 86'd get a timestamp and a log entry
+		var fresh []uint
+		tx.Model(&models.MenuItem{}).Where("id IN ? AND eighty_sixed_at IS NULL", ids).Pluck("id", &fresh)
+		if len(fresh) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.MenuItem{}).Where("id IN ?", fresh).
+			Updates(map[string]interface{}{"eighty_sixed_at": now, "eighty_six_reason": req.Reason}).Error; err != nil {
+			return err
+		}
+		entries := make([]models.MenuItemEightySix, len(fresh))
+		for i, id := range fresh {
+			entries[i] = models.MenuItemEightySix{MenuItemID: id, RestaurantID: restaurant.ID, Reason: req.Reason, EightySixedAt: now}
+		}
+		return tx.Create(&entries).Error
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_menu_items", nil)
+		return
+	}
+	invalidateMenuCache(restaurant.ID)
+	c.JSON(http.StatusOK, gin.H{"updated": updated, "is_available": *req.IsAvailable})
+}
+
+// restoreEightySixed clears an item's 86 and closes its open log entry
+func restoreEightySixed(db *gorm.DB, item models.MenuItem) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&item).Updates(map[string]interface{}{
+			"is_available":      true,
+			"eighty_sixed_at":   nil,
+			"eighty_six_reason": "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.MenuItemEightySix{}).
+			Where("menu_item_id = ? AND restored_at IS NULL", item.ID).
+			Update("restored_at", time.Now()).Error
+	})
+	if err != nil {
+		return err
+	}
+	invalidateMenuCache(item.RestaurantID)
+	return nil
+}
+
+// AdminGetEightySixStats counts how often items were 86'd per restaurant per day — admin only
+//
+// @Summary     86'd items per restaurant per day
+// @Tags        admin
+// @Produce     json
+// @Param       restaurant_id  query  int     false  "Filter by restaurant"
+// @Param       from           query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to             query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/analytics/eighty-six [get]
+func AdminGetEightySixStats(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	rows := []struct {
+		RestaurantID   uint   `json:"restaurant_id"`
+		RestaurantName string `json:"restaurant_name"`
+		Day            string `json:"day"`
+		Count          int    `json:"count"`
+		DistinctItems  int    `json:"distinct_items"`
+	}{}
+	q := requestDB(c).Table("menu_item_eighty_sixes AS e").
+		Select("e.restaurant_id, restaurants.name AS restaurant_name, date(e.eighty_sixed_at) AS day, "+
+			"COUNT(*) AS count, COUNT(DISTINCT e.menu_item_id) AS distinct_items").
+		Joins("JOIN restaurants ON restaurants.id = e.restaurant_id").
+		Where("e.eighty_sixed_at >= ? AND e.eighty_sixed_at < ?", from, to)
+	if s := c.Query("restaurant_id"); s != "" {
+		restaurantID, err := strconv.Atoi(s)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.restaurant_id_must_be_a_number", nil)
+			return
+		}
+		q = q.Where("e.restaurant_id = ?", restaurantID)
+	}
+	q.Group("e.restaurant_id, restaurants.name, day").Order("day desc, count desc").Scan(&rows)
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":  from.Format(dateLayout),
+		"to":    to.AddDate(0, 0, -1).Format(dateLayout),
+		"count": len(rows),
+		"days":  rows,
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// eightySixed adds a restaurant with one menu item 86'd an hour ago
+func eightySixed(t *testing.T, db *gorm.DB) models.MenuItem {
+	t.Helper()
+	at := time.Now().Add(-time.Hour)
+	_, items := createRestaurant(t, db, models.MenuItem{Name: "Paneer", Price: 200})
+	db.Model(&items[0]).Updates(map[string]interface{}{"is_available": false, "eighty_sixed_at": at})
+	items[0].IsAvailable, items[0].EightySixedAt = false, &at
+	db.Create(&models.MenuItemEightySix{MenuItemID: items[0].ID, RestaurantID: items[0].RestaurantID, EightySixedAt: at})
+	return items[0]
+}
+
+func TestRestoreEightySixedUsesTheRequest(t *testing.T) {
+	db := newTestDB(t)
+	items := []models.MenuItem{eightySixed(t, db)}
+
+	c, cancel := requestContext(t)
+	cancel()
+	if err := restoreEightySixed(requestDB(c), items[0]); !errors.Is(err, context.Canceled) {
+		t.Errorf("restore after cancelling: err = %v, want context.Canceled", err)
+	}
+	var item models.MenuItem
+	db.First(&item, items[0].ID)
+	if item.EightySixedAt == nil || item.IsAvailable {
+		t.Fatal("a cancelled restore changed the item")
+	}
+
+	if err := restoreEightySixed(db, items[0]); err != nil {
+		t.Fatal(err)
+	}
+	var open int64
+	db.Model(&models.MenuItemEightySix{}).Where("menu_item_id = ? AND restored_at IS NULL", items[0].ID).Count(&open)
+	item = models.MenuItem{}
+	db.First(&item, items[0].ID)
+	if item.EightySixedAt != nil || !item.IsAvailable || open != 0 {
+		t.Errorf("after restoring: available %v, 86'd at %v, %d open log entries", item.IsAvailable, item.EightySixedAt, open)
+	}
+}
+
+func TestRestoreMenuItemReturnsTheRestoredItem(t *testing.T) {
+	db := newTestDB(t)
+	item := eightySixed(t, db)
+	var restaurant models.Restaurant
+	db.First(&restaurant, item.RestaurantID)
+
+	target := fmt.Sprintf("/restaurant/menu/%d/restore", item.ID)
+	w := serve(RestoreMenuItem, "/restaurant/menu/:itemId/restore", restaurant.OwnerID, models.RoleRestaurant, http.MethodPut, target, "")
+	wantStatus(t, w, http.StatusOK)
+	var resp struct {
+		Item models.MenuItem `json:"item"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Item.EightySixedAt != nil || !resp.Item.IsAvailable {
+		t.Errorf("response item: available %v, 86'd at %v; want it restored", resp.Item.IsAvailable, resp.Item.EightySixedAt)
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sentinels come back from the real database and are mapped by ErrorHandler
+
+func TestMissingOrderIsNotFound(t *testing.T) {
+	newTestDB(t)
+	w := serve(GetOrderDetail, "/orders/:id", 1, models.RoleCustomer, http.MethodGet, "/orders/999", "")
+	wantStatus(t, w, http.StatusNotFound)
+	var body apierror.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Code != apierror.ErrNotFound || body.Message != "Order not found" {
+		t.Errorf("got %s %q, want %s %q", body.Code, body.Message, apierror.ErrNotFound, "Order not found")
+	}
+}
+
+func TestDuplicateKeyIsConflict(t *testing.T) {
+	db := newTestDB(t)
+	createUser(t, db, "Asha", models.RoleCustomer)
+	w := serve(func(c *gin.Context) {
+		dup := models.User{Name: "Asha", Email: "asha@example.com", PasswordHash: "x"}
+		if err := requestDB(c).Create(&dup).Error; err != nil {
+			c.Error(err)
+			c.Abort()
+		}
+	}, "/users", 0, "", http.MethodPost, "/users", "")
+	wantStatus(t, w, http.StatusConflict)
+	var body apierror.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Code != apierror.ErrConflict {
+		t.Errorf("code = %s, want %s", body.Code, apierror.ErrConflict)
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+	"food-delivery-api/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	etaHistoryDays = 30 // how far back stage durations are averaged
+	etaMinStage    = 2  // a stage that is running late still counts this many minutes
+
+	// Used when a restaurant has no history for a stage yet
+	defaultPrepMinutes     = 20 // CONFIRMED → READY_FOR_PICKUP
+	defaultPickupMinutes   = 10 // READY_FOR_PICKUP → PICKED_UP
+	defaultDeliveryMinutes = 15 // PICKED_UP → DELIVERED
+)
+
+// stageEstimate is how long one stage of an order usually takes
+type stageEstimate struct {
+	Minutes float64
+	Samples int64
+}
+
+// avgStageMinutes averages how long the restaurant's recent orders took to go
+// from one status to the next, falling back to a default without history.
+// History rows that leave the status as it was, e.g. address changes, don't count.
+func avgStageMinutes(db *gorm.DB, restaurantID uint, from, to models.OrderStatus, fallback float64, now time.Time) stageEstimate {
+	var row struct {
+		Minutes *float64
+		Samples int64
+	}
+	db.Raw(`SELECT AVG((julianday(b.created_at) - julianday(a.created_at)) * 1440) AS minutes, COUNT(*) AS samples
+		FROM order_status_histories a
+		JOIN order_status_histories b ON b.order_id = a.order_id AND b.to_status = ? AND b.from_status <> b.to_status
+		JOIN orders ON orders.id = a.order_id
+		WHERE a.to_status = ? AND a.from_status <> a.to_status AND orders.restaurant_id = ? AND a.created_at >= ?
+			AND orders.deleted_at IS NULL`,
+		to, from, restaurantID, now.AddDate(0, 0, -etaHistoryDays)).Scan(&row)
+	if row.Minutes == nil || row.Samples == 0 {
+		return stageEstimate{Minutes: fallback}
+	}
+	return stageEstimate{Minutes: *row.Minutes, Samples: row.Samples}
+}
+
+func (s stageEstimate) describe(what string) string {
+	if s.Samples == 0 {
+		return fmt.Sprintf("%s: %.0f min (default, no recent history)", what, s.Minutes)
+	}
+	return fmt.Sprintf("%s: %.0f min (average of %d orders in the last %d days)", what, s.Minutes, s.Samples, etaHistoryDays)
+}
+
+// remaining is what is left of a stage that has been running for elapsed
+func (s stageEstimate) remaining(elapsed time.Duration) float64 {
+	return math.Max(s.Minutes-elapsed.Minutes(), etaMinStage)
+}
+
+// estimateETA works out the minutes left until delivery from the order's
+// current status. There is no live driver location, so travel legs use the
+// restaurant's recent pickup and delivery times.
+func estimateETA(db *gorm.DB, order models.Order, now time.Time) (int, []string) {
+	var enteredAt time.Time
+	var entered models.OrderStatusHistory
+	if err := db.Where("order_id = ? AND to_status = ? AND from_status <> to_status", order.ID, order.Status).
+		Order("created_at DESC").First(&entered).Error; err == nil {
+		enteredAt = entered.CreatedAt
+	} else {
+		enteredAt = order.UpdatedAt
+	}
+	elapsed := now.Sub(enteredAt)
+
+	prep := avgStageMinutes(db, order.RestaurantID, models.StatusConfirmed, models.StatusReadyForPickup, defaultPrepMinutes, now)
+	cooking := avgStageMinutes(db, order.RestaurantID, models.StatusPreparing, models.StatusReadyForPickup, defaultPrepMinutes, now)
+	pickup := avgStageMinutes(db, order.RestaurantID, models.StatusReadyForPickup, models.StatusPickedUp, defaultPickupMinutes, now)
+	delivery := avgStageMinutes(db, order.RestaurantID, models.StatusPickedUp, models.StatusDelivered, defaultDeliveryMinutes, now)
+
+	var minutes float64
+	var reasoning []string
+	switch order.Status {
+	case models.StatusConfirmed:
+		minutes = prep.Minutes + pickup.Minutes + delivery.Minutes
+		reasoning = []string{prep.describe("prep time"), pickup.describe("driver to restaurant"), delivery.describe("restaurant to customer")}
+	case models.StatusPreparing:
+		minutes = cooking.remaining(elapsed) + pickup.Minutes + delivery.Minutes
+		reasoning = []string{
+			fmt.Sprintf("%s, %.0f min in so far", cooking.describe("prep time"), elapsed.Minutes()),
+			pickup.describe("driver to restaurant"), delivery.describe("restaurant to customer"),
+		}
+	case models.StatusReadyForPickup:
+		minutes = pickup.remaining(elapsed) + delivery.Minutes
+		reasoning = []string{
+			fmt.Sprintf("%s, ready for %.0f min", pickup.describe("driver to restaurant"), elapsed.Minutes()),
+			delivery.describe("restaurant to customer"),
+		}
+	case models.StatusPickedUp:
+		minutes = delivery.remaining(elapsed)
+		reasoning = []string{fmt.Sprintf("%s, picked up %.0f min ago", delivery.describe("restaurant to customer"), elapsed.Minutes())}
+	}
+	return int(math.Ceil(minutes)), reasoning
+}
+
+// AdminRecalculateETA re-estimates an active order's delivery time from its
+// current status, e.g. after a delay or a driver reassignment — admin only
+//
+// @Summary     Recalculate an order's ETA
+// @Tags        admin
+// @Produce     json
+// @Param       id   path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders/{id}/recalculate-eta [post]
+func AdminRecalculateETA(c *gin.Context) {
+	var order models.Order
+	if err := requestDB(c).Preload("Restaurant").First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	switch order.Status {
+	case models.StatusConfirmed, models.StatusPreparing, models.StatusReadyForPickup, models.StatusPickedUp:
+	default:
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.eta_recalculation_only_active",
+			gin.H{"status": order.Status})
+		return
+	}
+
+	now := time.Now()
+	eta, reasoning := estimateETA(requestDB(c), order, now)
+	if err := requestDB(c).Model(&order).Updates(map[string]interface{}{
+		"estimated_time":      eta,
+		"eta_recalculated_at": now,
+	}).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_eta", nil)
+		return
+	}
+
+	realtime.Default.Publish(realtime.Event{
+		Event:        "eta_updated",
+		OrderID:      order.ID,
+		From:         string(order.Status),
+		To:           string(order.Status),
+		RestaurantID: order.RestaurantID,
+		Restaurant:   order.Restaurant.Name,
+		ETAMinutes:   eta,
+		Timestamp:    now,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"order_id":               order.ID,
+		"status":                 order.Status,
+		"estimated_time_minutes": eta,
+		"eta_recalculated_at":    now,
+		"reasoning":              reasoning,
+	})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	etaAccurateFactor   = 1.2 // a delivery within 1.2x its ETA counts as accurate
+	etaBufferBelowRate  = 0.7 // restaurants less accurate than this get padded ETAs
+	etaBufferFactor     = 1.2
+	etaBufferMinRatings = 5 // too few ratings say nothing about a restaurant
+)
+
+// etaAccurateSQL is etaAccurateFactor as a condition on eta_ratings
+var etaAccurateSQL = fmt.Sprintf("actual_minutes <= estimated_minutes * %g", etaAccurateFactor)
+
+type RateETARequest struct {
+	OnTime        *bool `json:"on_time" binding:"required"`
+	ActualMinutes *int  `json:"actual_minutes" binding:"omitempty,min=1,max=1440"` // default: placement to delivery, from the status history
+}
+
+// estimateDeliveryMinutes is the ETA quoted when an order is placed: 30
+// minutes plus 5 per line, padded by 20% for restaurants whose deliveries
+// customers have often rated as late
+func estimateDeliveryMinutes(restaurant models.Restaurant, lines int) int {
+	minutes := 30 + 5*lines
+	if restaurant.ETARatingCount >= etaBufferMinRatings && restaurant.ETAAccuracyRate < etaBufferBelowRate {
+		minutes = int(math.Ceil(float64(minutes) * etaBufferFactor))
+	}
+	return minutes
+}
+
+// refreshETAAccuracy recomputes a restaurant's denormalised ETA accuracy from its ratings
+func refreshETAAccuracy(db *gorm.DB, restaurantID uint) error {
+	var agg struct {
+		Accurate int
+		Count    int
+	}
+	if err := db.Model(&models.ETARating{}).
+		Select("COALESCE(SUM(CASE WHEN "+etaAccurateSQL+" THEN 1 ELSE 0 END), 0) AS accurate, COUNT(*) AS count").
+		Where("restaurant_id = ?", restaurantID).
+		Scan(&agg).Error; err != nil {
+		return err
+	}
+	rate := 1.0
+	if agg.Count > 0 {
+		rate = float64(agg.Accurate) / float64(agg.Count)
+	}
+	return db.Model(&models.Restaurant{}).Where("id = ?", restaurantID).Updates(map[string]interface{}{
+		"eta_accuracy_rate": rate,
+		"eta_rating_count":  agg.Count,
+	}).Error
+}
+
+// deliveryMinutes is how long a delivered order took from placement to
+// delivery according to its status history
+func deliveryMinutes(db *gorm.DB, order models.Order) (int, bool) {
+	var delivered models.OrderStatusHistory
+	if err := db.Where("order_id = ? AND to_status = ?", order.ID, models.StatusDelivered).First(&delivered).Error; err != nil {
+		return 0, false
+	}
+	minutes := int(math.Round(delivered.CreatedAt.Sub(order.CreatedAt).Minutes()))
+	return max(minutes, 1), true
+}
+
+// RateOrderETA lets a customer say whether a delivered order arrived when
+// promised. The rating feeds the restaurant's eta_accuracy_rate.
+//
+// @Summary     Rate how accurate a delivered order's ETA was
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Param       body  body  RateETARequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/rate-eta [post]
+func RateOrderETA(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+
+	var req RateETARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.CustomerID != customerID {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
+		return
+	}
+	if order.Status != models.StatusDelivered {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.eta_rating_only_delivered", gin.H{
+			"current_status": order.Status,
+		})
+		return
+	}
+
+	rating := models.ETARating{
+		OrderID:          order.ID,
+		CustomerID:       customerID,
+		RestaurantID:     order.RestaurantID,
+		EstimatedMinutes: order.EstimatedTime,
+		OnTime:           *req.OnTime,
+	}
+	if req.ActualMinutes != nil {
+		rating.ActualMinutes = *req.ActualMinutes
+	} else if minutes, ok := deliveryMinutes(requestDB(c), order); ok {
+		rating.ActualMinutes = minutes
+	} else {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.actual_minutes_is_required_for_this_order", nil)
+		return
+	}
+
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rating).Error; err != nil {
+			return err
+		}
+		return refreshETAAccuracy(tx, order.RestaurantID)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.you_have_already_rated_this_orders_eta", nil)
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_rating", nil)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Thanks for the feedback!",
+		"rating":   rating,
+		"accurate": float64(rating.ActualMinutes) <= float64(rating.EstimatedMinutes)*etaAccurateFactor,
+	})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	featuredExpiryCheckInterval = time.Minute
+	featuredPreviewSize         = 3 // restaurants shown on the welcome endpoint
+)
+
+// MaintenanceFeatureRestaurant is logged when an admin features or unfeatures a restaurant
+const MaintenanceFeatureRestaurant = "FEATURE_RESTAURANT"
+
+type FeatureRestaurantRequest struct {
+	FeaturedUntil *time.Time `json:"featured_until" binding:"required"`
+}
+
+// featuredScope limits a query to restaurants whose feature hasn't run out,
+// even if the expiry worker hasn't caught up yet
+func featuredScope(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_featured = ? AND (featured_until IS NULL OR featured_until > ?)", true, now)
+	}
+}
+
+// FeaturedPreview returns the top few featured restaurants that are open,
+// best rated first, for the welcome endpoint
+func FeaturedPreview(db *gorm.DB) []models.Restaurant {
+	restaurants := []models.Restaurant{}
+	db.Scopes(featuredScope(time.Now())).Where("is_open = ? AND is_active = ?", true, true).
+		Order("rating DESC").Limit(featuredPreviewSize).Find(&restaurants)
+	return restaurants
+}
+
+// StartFeaturedExpiryWorker unfeatures restaurants once their featured_until
+// has passed, once a minute.
+func StartFeaturedExpiryWorker() {
+	go func() {
+		for range time.Tick(featuredExpiryCheckInterval) {
+			runFeaturedExpiry(time.Now())
+		}
+	}()
+}
+
+func runFeaturedExpiry(now time.Time) {
+	res := config.DB.Model(&models.Restaurant{}).
+		Where("is_featured = ? AND featured_until IS NOT NULL AND featured_until <= ?", true, now).
+		Updates(map[string]interface{}{"is_featured": false, "featured_until": nil})
+	if res.Error != nil {
+		log.Printf("featured: failed to clear expired features: %v", res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("featured: %d restaurant(s) no longer featured", res.RowsAffected)
+	}
+}
+
+// AdminFeatureRestaurant features a restaurant until the given time — admin only
+//
+// @Summary     Feature a restaurant
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Param       body  body  FeatureRestaurantRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/feature [put]
+func AdminFeatureRestaurant(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req FeatureRestaurantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if !req.FeaturedUntil.After(time.Now()) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.featured_until_must_be_in_the_future", nil)
+		return
+	}
+	setRestaurantFeatured(c, adminID, true, req.FeaturedUntil)
+}
+
+// AdminUnfeatureRestaurant ends a restaurant's feature early — admin only
+//
+// @Summary     Stop featuring a restaurant
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/feature [delete]
+func AdminUnfeatureRestaurant(c *gin.Context) {
+	setRestaurantFeatured(c, middleware.GetUserID(c), false, nil)
+}
+
+func setRestaurantFeatured(c *gin.Context, adminID uint, featured bool, until *time.Time) {
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&restaurant).Updates(map[string]interface{}{
+			"is_featured":    featured,
+			"featured_until": until,
+		}).Error; err != nil {
+			return err
+		}
+		return logMaintenance(tx, MaintenanceFeatureRestaurant, &adminID, gin.H{
+			"restaurant_id":  restaurant.ID,
+			"featured":       featured,
+			"featured_until": until,
+		})
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_restaurant", nil)
+		return
+	}
+	restaurant.IsFeatured, restaurant.FeaturedUntil = featured, until
+	setFeaturedEndsIn(&restaurant, time.Now())
+	c.JSON(http.StatusOK, gin.H{"restaurant": restaurant})
+}
+
+// setFeaturedEndsIn fills the computed featured_ends_in_hours for admin responses
+func setFeaturedEndsIn(r *models.Restaurant, now time.Time) {
+	if !r.IsFeatured || r.FeaturedUntil == nil {
+		return
+	}
+	hours := math.Max(0, math.Round(r.FeaturedUntil.Sub(now).Hours()*100)/100)
+	r.FeaturedEndsInHours = &hours
+}
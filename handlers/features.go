@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/features"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// requireFeature responds 501 and returns false when the feature flag is off
+func requireFeature(c *gin.Context, name string) bool {
+	if features.IsEnabled(name) {
+		return true
+	}
+	apierror.Respond(c, http.StatusNotImplemented, apierror.ErrNotImplemented, "errors.this_feature_is_currently_disabled",
+		gin.H{"feature": name})
+	return false
+}
+
+type SetFeatureRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+// AdminGetFeatures lists every feature flag — admin only
+//
+// @Summary     List feature flags
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/features [get]
+func AdminGetFeatures(c *gin.Context) {
+	var flags []models.FeatureFlag
+	requestDB(c).Order("name").Find(&flags)
+	c.JSON(http.StatusOK, gin.H{"count": len(flags), "features": flags})
+}
+
+// AdminSetFeature switches a feature flag on or off — admin only. Other
+// instances pick the change up within features.RefreshInterval.
+//
+// @Summary     Enable or disable a feature
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  SetFeatureRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/features [put]
+func AdminSetFeature(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req SetFeatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	flag, err := features.Set(req.Name, *req.Enabled, adminID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.unknown_feature_flag", gin.H{"name": req.Name})
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_feature_flag", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Feature flag updated", "feature": flag})
+}
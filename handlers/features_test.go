@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"food-delivery-api/features"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// seedFeatures loads the default flags, with loyalty set to on, into the
+// database and the features cache, emptying the cache again afterwards
+func seedFeatures(t *testing.T, db *gorm.DB, loyalty bool) {
+	t.Helper()
+	for _, flag := range models.DefaultFeatureFlags {
+		if flag.Name == models.FeatureLoyalty {
+			flag.Enabled = loyalty
+		}
+		if err := db.Select("*").Create(&flag).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := features.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Where("1 = 1").Delete(&models.FeatureFlag{})
+		features.Refresh()
+	})
+}
+
+func TestHandlerGatedOnFeatureFlag(t *testing.T) {
+	db := newTestDB(t)
+	seedFeatures(t, db, false)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+
+	w := serve(GetLoyaltyTier, "/loyalty/tier", customer.ID, models.RoleCustomer, http.MethodGet, "/loyalty/tier", "")
+	wantStatus(t, w, http.StatusNotImplemented)
+
+	w = serve(AdminSetFeature, "/features", admin.ID, models.RoleAdmin, http.MethodPut, "/features",
+		`{"name":"loyalty","enabled":true}`)
+	wantStatus(t, w, http.StatusOK)
+	w = serve(GetLoyaltyTier, "/loyalty/tier", customer.ID, models.RoleCustomer, http.MethodGet, "/loyalty/tier", "")
+	wantStatus(t, w, http.StatusOK)
+
+	w = serve(AdminSetFeature, "/features", admin.ID, models.RoleAdmin, http.MethodPut, "/features",
+		`{"name":"loyalty","enabled":false}`)
+	wantStatus(t, w, http.StatusOK)
+	w = serve(GetLoyaltyTier, "/loyalty/tier", customer.ID, models.RoleCustomer, http.MethodGet, "/loyalty/tier", "")
+	wantStatus(t, w, http.StatusNotImplemented)
+}
+
+func TestSetUnknownFeatureFlag(t *testing.T) {
+	db := newTestDB(t)
+	seedFeatures(t, db, true)
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+
+	w := serve(AdminSetFeature, "/features", admin.ID, models.RoleAdmin, http.MethodPut, "/features",
+		`{"name":"teleportation","enabled":true}`)
+	wantStatus(t, w, http.StatusNotFound)
+}
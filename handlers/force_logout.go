@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ForceLogoutRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AdminForceLogout invalidates every token already issued to a user — admin only
+//
+// @Summary     Force-logout a user everywhere
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                 true  "User ID"
+// @Param       body  body  ForceLogoutRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/users/{id}/force-logout [post]
+func AdminForceLogout(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req ForceLogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var user models.User
+	if err := requestDB(c).First(&user, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_not_found")
+		c.Abort()
+		return
+	}
+
+	// JWT iat has one-second precision, so round up: a token from this same
+	// second must not survive the logout.
+	now := time.Now()
+	validFrom := now.Truncate(time.Second).Add(time.Second)
+
+	var terminated int64
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		// Issue records are cleared on every force-logout, so anything within
+		// the token lifetime is a session that is still alive
+		tx.Model(&models.TokenIssue{}).Where("user_id = ? AND issued_at > ?", user.ID, now.Add(-middleware.TokenLifetime)).
+			Count(&terminated)
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.TokenIssue{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("tokens_valid_from", validFrom).Error; err != nil {
+			return err
+		}
+		return logMaintenance(tx, MaintenanceForceLogout, &adminID, gin.H{
+			"user_id":             user.ID,
+			"email":               user.Email,
+			"reason":              req.Reason,
+			"sessions_terminated": terminated,
+		})
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_force_logout", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "All sessions invalidated",
+		"user_id":             user.ID,
+		"sessions_terminated": terminated,
+		"tokens_valid_from":   validFrom,
+	})
+}
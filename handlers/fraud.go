@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/fraud"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// How far back the suspicious-orders report looks by default, and at most
+const (
+	fraudDefaultDays = 7
+	fraudMaxDays     = 30
+)
+
+type suspiciousOrder struct {
+	OrderID         uint               `json:"order_id"`
+	InvoiceNumber   string             `json:"invoice_number"`
+	CustomerID      uint               `json:"customer_id"`
+	RestaurantID    uint               `json:"restaurant_id"`
+	Status          models.OrderStatus `json:"status"`
+	TotalPrice      float64            `json:"total_price"`
+	DeliveryAddress string             `json:"delivery_address"`
+	CreatedAt       time.Time          `json:"created_at"`
+	FraudReviewed   bool               `json:"fraud_reviewed"`
+	Reasons         []string           `json:"reasons"`
+}
+
+// normaliseAddress makes "12 MG Road " and "12 mg road" the same address
+func normaliseAddress(address string) string {
+	return strings.Join(strings.Fields(strings.ToLower(address)), " ")
+}
+
+// AdminGetSuspiciousOrders flags recent orders that match any fraud rule — admin only
+//
+// @Summary     List suspicious orders
+// @Tags        admin
+// @Produce     json
+// @Param       threshold         query  number  false  "Order total above which to flag (default 200)"
+// @Param       days              query  int     false  "How many days back to scan (default 7, max 30)"
+// @Param       include_reviewed  query  bool    false  "Also list orders already marked reviewed"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/fraud/suspicious-orders [get]
+func AdminGetSuspiciousOrders(c *gin.Context) {
+	threshold := fraud.DefaultTotalThreshold
+	if s := c.Query("threshold"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.threshold_must_be_non_negative_number", nil)
+			return
+		}
+		threshold = v
+	}
+	days := fraudDefaultDays
+	if s := c.Query("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > fraudMaxDays {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.days_must_be_between_1_and_30", nil)
+			return
+		}
+		days = v
+	}
+	includeReviewed := c.Query("include_reviewed") == "true"
+
+	// Load enough history before the scan window for the look-back rules
+	since := time.Now().AddDate(0, 0, -days)
+	var orders []models.Order
+	requestDB(c).Where("created_at >= ?", since.Add(-fraud.AddressWindow)).Order("created_at").Find(&orders)
+
+	customerIDs := map[uint]bool{}
+	for _, o := range orders {
+		customerIDs[o.CustomerID] = true
+	}
+	ids := make([]uint, 0, len(customerIDs))
+	for id := range customerIDs {
+		ids = append(ids, id)
+	}
+	var users []models.User
+	requestDB(c).Select("id", "created_at").Where("id IN ?", ids).Find(&users)
+	accountCreated := map[uint]time.Time{}
+	for _, u := range users {
+		accountCreated[u.ID] = u.CreatedAt
+	}
+
+	byCustomer := map[uint][]models.Order{}
+	byAddress := map[string][]models.Order{}
+	for _, o := range orders {
+		byCustomer[o.CustomerID] = append(byCustomer[o.CustomerID], o)
+		addr := normaliseAddress(o.DeliveryAddress)
+		byAddress[addr] = append(byAddress[addr], o)
+	}
+
+	flagged := []suspiciousOrder{}
+	for _, o := range orders {
+		if o.CreatedAt.Before(since) || (o.FraudReviewed && !includeReviewed) {
+			continue
+		}
+		facts := fraud.Facts{
+			TotalPrice:       o.TotalPrice,
+			TotalThreshold:   threshold,
+			PlacedAt:         o.CreatedAt,
+			AccountCreatedAt: accountCreated[o.CustomerID],
+		}
+		for _, other := range byCustomer[o.CustomerID] {
+			if !other.CreatedAt.After(o.CreatedAt) && o.CreatedAt.Sub(other.CreatedAt) < fraud.RapidRepeatWindow {
+				facts.CustomerRecentOrders++
+			}
+		}
+		customers := map[uint]bool{}
+		for _, other := range byAddress[normaliseAddress(o.DeliveryAddress)] {
+			if !other.CreatedAt.After(o.CreatedAt) && o.CreatedAt.Sub(other.CreatedAt) < fraud.AddressWindow {
+				facts.AddressOrders++
+				customers[other.CustomerID] = true
+			}
+		}
+		facts.AddressCustomers = len(customers)
+
+		reasons := fraud.Check(facts)
+		if len(reasons) == 0 {
+			continue
+		}
+		flagged = append(flagged, suspiciousOrder{
+			OrderID:         o.ID,
+			InvoiceNumber:   o.InvoiceNumber,
+			CustomerID:      o.CustomerID,
+			RestaurantID:    o.RestaurantID,
+			Status:          o.Status,
+			TotalPrice:      o.TotalPrice,
+			DeliveryAddress: o.DeliveryAddress,
+			CreatedAt:       o.CreatedAt,
+			FraudReviewed:   o.FraudReviewed,
+			Reasons:         reasons,
+		})
+	}
+
+	// Newest first
+	for i, j := 0, len(flagged)-1; i < j; i, j = i+1, j-1 {
+		flagged[i], flagged[j] = flagged[j], flagged[i]
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"threshold": threshold,
+		"days":      days,
+		"count":     len(flagged),
+		"orders":    flagged,
+	})
+}
+
+// AdminMarkOrderReviewed records that an admin has checked a flagged order — admin only
+//
+// @Summary     Mark an order as fraud-reviewed
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders/{id}/mark-reviewed [put]
+func AdminMarkOrderReviewed(c *gin.Context) {
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	requestDB(c).Model(&order).Update("fraud_reviewed", true)
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Order marked as reviewed",
+		"order_id":       order.ID,
+		"invoice_number": order.InvoiceNumber,
+	})
+}
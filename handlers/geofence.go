@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/eventbus"
+	"food-delivery-api/geo"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+// geofenceAlertThreshold is how many out-of-zone reports in one delivery alert the admins
+const geofenceAlertThreshold = 3
+
+type DriverLocationRequest struct {
+	Lat *float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Lng *float64 `json:"lng" binding:"required,min=-180,max=180"`
+}
+
+type CreateZoneRequest struct {
+	Name    string      `json:"name" binding:"required"`
+	Polygon []geo.Point `json:"polygon" binding:"required,min=3,dive"`
+}
+
+// UpdateDeliveryLocation records where the driver is while carrying an order
+// and checks the position against the driver's assigned zone
+//
+// @Summary     Report my location during a delivery
+// @Tags        driver
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                    true  "Order ID"
+// @Param       body  body  DriverLocationRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/orders/{id}/location [put]
+func UpdateDeliveryLocation(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	var req DriverLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var order models.Order
+	if err := requestDB(c).Where("id = ? AND driver_id = ?", c.Param("id"), driverID).First(&order).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.Status != models.StatusPickedUp {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.location_only_out_for_delivery",
+			gin.H{"status": order.Status})
+		return
+	}
+
+	point := geo.Point{Lat: *req.Lat, Lng: *req.Lng}
+	if err := appendRoutePoint(requestDB(c), order.ID, driverID, point, time.Now()); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_location", nil)
+		return
+	}
+
+	profile, err := getDriverProfile(requestDB(c), driverID)
+	if err != nil || profile.ZoneID == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Location recorded", "in_zone": nil})
+		return
+	}
+	var zone models.DeliveryZone
+	if err := requestDB(c).First(&zone, *profile.ZoneID).Error; err != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Location recorded", "in_zone": nil})
+		return
+	}
+	if geo.InPolygon(point, zone.Polygon) {
+		c.JSON(http.StatusOK, gin.H{"message": "Location recorded", "in_zone": true})
+		return
+	}
+
+	violation := models.GeofenceViolation{DriverID: driverID, OrderID: order.ID, ZoneID: zone.ID, Lat: point.Lat, Lng: point.Lng}
+	if err := requestDB(c).Create(&violation).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_location", nil)
+		return
+	}
+	var violations int64
+	requestDB(c).Model(&models.GeofenceViolation{}).Where("driver_id = ? AND order_id = ?", driverID, order.ID).Count(&violations)
+	// Alert once per delivery, when the threshold is first reached
+	if violations == geofenceAlertThreshold {
+		eventbus.Default.Publish(eventbus.AlertDriverOutOfZone, eventbus.DriverOutOfZoneEvent{Violation: violation, Violations: violations})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Location recorded",
+		"in_zone":    false,
+		"zone":       zone.Name,
+		"violations": violations,
+	})
+}
+
+// NotifyAdminsDriverOutOfZone tells every admin about a driver who keeps
+// leaving their zone during a delivery
+func NotifyAdminsDriverOutOfZone(payload interface{}) {
+	e, ok := payload.(eventbus.DriverOutOfZoneEvent)
+	if !ok {
+		return
+	}
+	v := e.Violation
+	var admins []models.User
+	config.DB.Where("role = ?", models.RoleAdmin).Find(&admins)
+	for _, admin := range admins {
+		err := notify.Default.Send(notify.Message{
+			UserID:  admin.ID,
+			Email:   admin.Email,
+			Phone:   admin.Phone,
+			Channel: notify.ChannelEmail,
+			Title:   fmt.Sprintf("Driver %d left their zone on order #%d", v.DriverID, v.OrderID),
+			Body:    fmt.Sprintf("%d location reports outside zone %d; last at %.5f, %.5f.", e.Violations, v.ZoneID, v.Lat, v.Lng),
+
+			EventType:     "driver_out_of_zone",
+			ReferenceID:   v.OrderID,
+			ReferenceType: "order",
+		})
+		if err != nil {
+			log.Printf("geofence: failed to notify admin %d about order %d: %v", admin.ID, v.OrderID, err)
+		}
+	}
+}
+
+// AdminCreateZone adds a delivery zone drivers can be assigned to — admin only
+//
+// @Summary     Create a delivery zone
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  CreateZoneRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/zones [post]
+func AdminCreateZone(c *gin.Context) {
+	var req CreateZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	zone := models.DeliveryZone{Name: req.Name, Polygon: req.Polygon}
+	if err := requestDB(c).Create(&zone).Error; err != nil {
+		c.Error(err).SetMeta("errors.a_zone_with_this_name_already_exists")
+		c.Abort()
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Zone created", "zone": zone})
+}
+
+// AdminGetZones lists every delivery zone — admin only
+//
+// @Summary     List delivery zones
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/zones [get]
+func AdminGetZones(c *gin.Context) {
+	zones := []models.DeliveryZone{}
+	requestDB(c).Order("name").Find(&zones)
+	c.JSON(http.StatusOK, gin.H{"count": len(zones), "zones": zones})
+}
+
+// AdminGetGeofenceViolations lists out-of-zone location reports, newest first — admin only
+//
+// @Summary     List geofence violations
+// @Tags        admin
+// @Produce     json
+// @Param       driver_id  query  int  false  "Only this driver"
+// @Param       order_id   query  int  false  "Only this order"
+// @Param       page       query  int  false  "Page number (default 1)"
+// @Param       page_size  query  int  false  "Page size (default 20, max 100)"
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/geofence-violations [get]
+func AdminGetGeofenceViolations(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+	query := requestDB(c).Model(&models.GeofenceViolation{})
+	if driverID := c.Query("driver_id"); driverID != "" {
+		query = query.Where("driver_id = ?", driverID)
+	}
+	if orderID := c.Query("order_id"); orderID != "" {
+		query = query.Where("order_id = ?", orderID)
+	}
+	var total int64
+	query.Count(&total)
+	violations := []models.GeofenceViolation{}
+	query.Order("created_at desc, id desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&violations)
+	c.JSON(http.StatusOK, gin.H{
+		"page":       page,
+		"page_size":  pageSize,
+		"total":      total,
+		"count":      len(violations),
+		"violations": violations,
+	})
+}
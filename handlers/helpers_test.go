@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"food-delivery-api/cache"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var testDBs atomic.Int64
+
+// newTestDB points config.DB at a fresh in-memory SQLite database with every
+// migration applied, putting the previous one back when the test ends. It
+// allows a single connection; raise it for code that queries while it reads.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:test%d?mode=memory&cache=shared", testDBs.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if _, err := config.MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(&config.LazyLoadChecker{Threshold: config.DefaultLazyLoadThreshold, Strict: true}); err != nil {
+		t.Fatal(err)
+	}
+	prev, prevCache := config.DB, cache.Default
+	config.DB = db
+	// Cached responses belong to the database they were built from
+	cache.Default = &cache.MemoryCache{}
+	logs := captureLog(t)
+	t.Cleanup(func() {
+		config.DB, cache.Default = prev, prevCache
+		sqlDB.Close()
+		if n := strings.Count(logs.String(), "N+1 query detected"); n > 0 {
+			t.Errorf("%d lazy-load warnings logged; add the missing Preloads", n)
+		}
+	})
+	return db
+}
+
+// logBuffer collects log output safely from any goroutine
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureLog copies everything logged until the test ends into a buffer
+func captureLog(t *testing.T) *logBuffer {
+	b := &logBuffer{}
+	prev := log.Writer()
+	log.SetOutput(io.MultiWriter(prev, b))
+	t.Cleanup(func() { log.SetOutput(prev) })
+	return b
+}
+
+// serve runs handler, registered on route, for a request to target made by
+// userID in role, and returns the response
+func serve(handler gin.HandlerFunc, route string, userID uint, role models.UserRole, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return serveRequest(handler, route, userID, role, req)
+}
+
+// serveRequest is serve for a request the test built itself, e.g. to set headers
+func serveRequest(handler gin.HandlerFunc, route string, userID uint, role models.UserRole, req *http.Request) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(middleware.I18n(), middleware.QueryTrace(), middleware.ErrorHandler(), func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Set("role", string(role))
+	})
+	r.Handle(req.Method, route, handler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// wantStatus fails the test now unless the response has status code
+func wantStatus(t *testing.T, w *httptest.ResponseRecorder, code int) {
+	t.Helper()
+	if w.Code != code {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, code, w.Body.String())
+	}
+}
+
+// createUser adds a user with role; the email is derived from name
+func createUser(t *testing.T, db *gorm.DB, name string, role models.UserRole) models.User {
+	t.Helper()
+	user := models.User{Name: name, Email: strings.ToLower(name) + "@example.com", PasswordHash: "x", Role: role}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// createRestaurant adds an open restaurant owned by a new owner, with the
+// given menu items
+func createRestaurant(t *testing.T, db *gorm.DB, items ...models.MenuItem) (models.Restaurant, []models.MenuItem) {
+	t.Helper()
+	owner := createUser(t, db, "Owner", models.RoleRestaurant)
+	restaurant := models.Restaurant{OwnerID: owner.ID, Name: "Test Kitchen", Address: "1 High St", MaxOrdersPerMinute: 1000}
+	if err := db.Create(&restaurant).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i := range items {
+		items[i].RestaurantID = restaurant.ID
+		items[i].IsAvailable = true
+		if err := db.Create(&items[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return restaurant, items
+}
+
+// stockOf reads a menu item's stock quantity
+func stockOf(t *testing.T, db *gorm.DB, itemID uint) int {
+	t.Helper()
+	var item models.MenuItem
+	if err := db.Select("stock_quantity").First(&item, itemID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return item.StockQuantity
+}
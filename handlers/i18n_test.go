@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorsFollowAcceptLanguage(t *testing.T) {
+	db := newTestDB(t)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	restaurant, items := createRestaurant(t, db, models.MenuItem{Name: "Paneer", Price: 200, TrackStock: true, StockQuantity: 1})
+	placeBody := fmt.Sprintf(`{"restaurant_id":%d,"delivery_address":"2 Low St","items":[{"menu_item_id":%d,"quantity":3}]}`,
+		restaurant.ID, items[0].ID)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		handler        gin.HandlerFunc
+		method, body   string
+		wantStatus     int
+		wantMessage    string
+	}{
+		{"lookup failure in Spanish", "es", GetOrderDetail, http.MethodGet, "", http.StatusNotFound, "Pedido no encontrado"},
+		{"lookup failure in English", "en-GB,en;q=0.9", GetOrderDetail, http.MethodGet, "", http.StatusNotFound, "Order not found"},
+		{"formatted message in Spanish", "es-MX,es;q=0.9", PlaceOrder, http.MethodPost, placeBody, http.StatusConflict, "No hay existencias suficientes de 'Paneer'"},
+		{"unsupported language falls back to English", "de", PlaceOrder, http.MethodPost, placeBody, http.StatusConflict, "Not enough stock for 'Paneer'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/orders/999", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := serveRequest(tt.handler, "/orders/:id", customer.ID, models.RoleCustomer, req)
+
+			wantStatus(t, w, tt.wantStatus)
+			var body apierror.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestUpdateValidationMessagesAreTranslated(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     gin.HandlerFunc
+		route       string
+		target      func(item models.MenuItem) string
+		body        string
+		wantMessage string
+	}{
+		{"delivery fee", UpdateRestaurant, "/restaurant", nil, `"price_per_km":-1`,
+			"price_per_km debe ser un número mayor o igual que 0"},
+		{"location", UpdateRestaurant, "/restaurant", nil, `"latitude":12.9`,
+			"latitude (-90 a 90) y longitude (-180 a 180) deben indicarse juntas"},
+		{"manual override", UpdateRestaurant, "/restaurant", nil, `"manual_override_until":"tomorrow"`,
+			"manual_override_until debe ser una marca de tiempo RFC3339 o null"},
+		{"calories", UpdateMenuItem, "/restaurant/menu/:itemId", func(item models.MenuItem) string {
+			return fmt.Sprintf("/restaurant/menu/%d", item.ID)
+		}, `"calories":1.5`, "calories debe ser un número entero mayor o igual que 0"},
+		{"macros", UpdateMenuItem, "/restaurant/menu/:itemId", func(item models.MenuItem) string {
+			return fmt.Sprintf("/restaurant/menu/%d", item.ID)
+		}, `"fat_g":-2`, "fat_g debe ser un número mayor o igual que 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			restaurant, items := createRestaurant(t, db, models.MenuItem{Name: "Paneer", Price: 200})
+			target := tt.route
+			if tt.target != nil {
+				target = tt.target(items[0])
+			}
+			req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(`{"version":1,`+tt.body+`}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", "es")
+			w := serveRequest(tt.handler, tt.route, restaurant.OwnerID, models.RoleRestaurant, req)
+
+			wantStatus(t, w, http.StatusBadRequest)
+			var body apierror.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRequestLocaleHonoursQualityValues(t *testing.T) {
+	tests := []struct {
+		target, acceptLanguage, want string
+	}{
+		{"/state-machine", "en;q=0.5,es", "es"},
+		{"/state-machine", "es-MX;q=0.2,en-GB;q=0.8", "en"},
+		{"/state-machine", "*", "en"},
+		{"/state-machine?locale=FR", "es", "fr"},
+	}
+	for _, tt := range tests {
+		var got string
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		serveRequest(func(c *gin.Context) { got = requestLocale(c) }, "/state-machine", 0, "", req)
+		if got != tt.want {
+			t.Errorf("%s with Accept-Language %q: locale = %q, want %q", tt.target, tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
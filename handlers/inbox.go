@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyNotifications lists the caller's in-app notifications, newest first
+//
+// @Summary     My notifications
+// @Tags        auth
+// @Produce     json
+// @Param       unread     query  bool  false  "Only unread notifications"
+// @Param       page       query  int   false  "Page number (default 1)"
+// @Param       page_size  query  int   false  "Page size (default 20, max 100)"
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /profile/notifications [get]
+func GetMyNotifications(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+	query := requestDB(c).Model(&models.Notification{}).Where("user_id = ?", middleware.GetUserID(c))
+	if c.Query("unread") == "true" {
+		query = query.Where("is_read = ?", false)
+	}
+	var total int64
+	query.Count(&total)
+
+	notifications := []models.Notification{}
+	query.Order("created_at desc, id desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&notifications)
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":          page,
+		"page_size":     pageSize,
+		"total":         total,
+		"count":         len(notifications),
+		"notifications": notifications,
+	})
+}
+
+// GetUnreadNotificationCount returns how many of the caller's notifications are unread
+//
+// @Summary     Unread notification count
+// @Tags        auth
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /profile/notifications/count [get]
+func GetUnreadNotificationCount(c *gin.Context) {
+	var unread int64
+	requestDB(c).Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", middleware.GetUserID(c), false).
+		Count(&unread)
+	c.JSON(http.StatusOK, gin.H{"unread": unread})
+}
+
+// MarkNotificationRead marks one of the caller's notifications as read
+//
+// @Summary     Mark a notification as read
+// @Tags        auth
+// @Produce     json
+// @Param       id   path  int  true  "Notification ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /profile/notifications/{id}/read [put]
+func MarkNotificationRead(c *gin.Context) {
+	var notification models.Notification
+	if err := requestDB(c).Where("id = ? AND user_id = ?", c.Param("id"), middleware.GetUserID(c)).
+		First(&notification).Error; err != nil {
+		c.Error(err).SetMeta("errors.notification_not_found")
+		c.Abort()
+		return
+	}
+	if !notification.IsRead {
+		if err := requestDB(c).Model(&notification).Update("is_read", true).Error; err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_notification", nil)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read", "notification": notification})
+}
+
+// MarkAllNotificationsRead marks every unread notification of the caller as read
+//
+// @Summary     Mark all notifications as read
+// @Tags        auth
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /profile/notifications/read-all [put]
+func MarkAllNotificationsRead(c *gin.Context) {
+	res := requestDB(c).Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", middleware.GetUserID(c), false).
+		Update("is_read", true)
+	if res.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_notifications", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": res.RowsAffected})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// nextInvoiceNumber allocates the next INV-<year>-<seq> number on tx. The
+// increment and read-back share the caller's transaction, so two orders can
+// never be handed the same number.
+func nextInvoiceNumber(tx *gorm.DB, now time.Time) (string, error) {
+	year := now.Year()
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.InvoiceSequence{Year: year}).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Model(&models.InvoiceSequence{}).Where("year = ?", year).
+		UpdateColumn("last_seq", gorm.Expr("last_seq + 1")).Error; err != nil {
+		return "", err
+	}
+	var seq models.InvoiceSequence
+	if err := tx.Where("year = ?", year).First(&seq).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("INV-%d-%05d", year, seq.LastSeq), nil
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// orderLines builds n order lines of the same item
+func orderLines(itemID uint, n int) []PlaceOrderItem {
+	lines := make([]PlaceOrderItem, n)
+	for i := range lines {
+		lines[i] = PlaceOrderItem{MenuItemID: itemID, Quantity: 1}
+	}
+	return lines
+}
+
+func TestOrderItemCountLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		min, max     int
+		lines        int
+		wantErrorKey string
+	}{
+		{"below minimum", 2, 4, 1, "errors.too_few_order_items"},
+		{"at minimum", 2, 4, 2, ""},
+		{"at maximum", 2, 4, 4, ""},
+		{"above maximum", 2, 4, 5, "errors.too_many_order_items"},
+		{"min equals max", 3, 3, 3, ""},
+		{"default limits, one line", 0, 0, 1, ""},
+		{"default limits, 50 lines", 0, 0, 50, ""},
+		{"default limits, 51 lines", 0, 0, 51, "errors.too_many_order_items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			customer := createUser(t, db, "Customer", models.RoleCustomer)
+			restaurant, items := createRestaurant(t, db, models.MenuItem{Name: "Momo", Price: 10})
+			// Zero keeps the column defaults of 1 and 50
+			if tt.min > 0 {
+				db.Model(&restaurant).Updates(map[string]interface{}{"min_item_count": tt.min, "max_item_count": tt.max})
+			}
+
+			_, apiErr := placeOrder(db, customer.ID, PlaceOrderRequest{
+				RestaurantID:    restaurant.ID,
+				DeliveryAddress: "2 Low St",
+				Items:           orderLines(items[0].ID, tt.lines),
+			})
+			switch {
+			case tt.wantErrorKey == "" && apiErr != nil:
+				t.Errorf("%d lines: %v, want the order placed", tt.lines, apiErr)
+			case tt.wantErrorKey != "" && apiErr == nil:
+				t.Errorf("%d lines: order placed, want %s", tt.lines, tt.wantErrorKey)
+			case tt.wantErrorKey != "" && (apiErr.Message != tt.wantErrorKey || apiErr.Status != http.StatusBadRequest):
+				t.Errorf("%d lines: %d %s, want 400 %s", tt.lines, apiErr.Status, apiErr.Message, tt.wantErrorKey)
+			}
+		})
+	}
+}
+
+func TestUpdateRestaurantItemCountLimits(t *testing.T) {
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantKey    string
+	}{
+		{`"min_item_count":2,"max_item_count":10`, http.StatusOK, ""},
+		{`"min_item_count":5,"max_item_count":5`, http.StatusOK, ""},
+		{`"min_item_count":0`, http.StatusBadRequest, "errors.invalid_min_item_count"},
+		{`"min_item_count":1.5`, http.StatusBadRequest, "errors.invalid_min_item_count"},
+		{`"max_item_count":0`, http.StatusBadRequest, "errors.invalid_max_item_count"},
+		{`"min_item_count":6,"max_item_count":5`, http.StatusBadRequest, "errors.min_item_count_above_max"},
+		// Checked against the saved maximum of 50 when only the minimum changes
+		{`"min_item_count":51`, http.StatusBadRequest, "errors.min_item_count_above_max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			db := newTestDB(t)
+			restaurant, _ := createRestaurant(t, db)
+			w := serve(UpdateRestaurant, "/restaurant", restaurant.OwnerID, models.RoleRestaurant, http.MethodPut, "/restaurant",
+				`{"version":1,`+tt.body+`}`)
+			wantStatus(t, w, tt.wantStatus)
+			if tt.wantKey != "" {
+				var body apierror.ErrorResponse
+				json.Unmarshal(w.Body.Bytes(), &body)
+				if want := apierror.New(0, "", tt.wantKey, nil).Error(); body.Message != want {
+					t.Errorf("message = %q, want %q", body.Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMenuShowsItemCountLimits(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db, models.MenuItem{Name: "Momo", Price: 10})
+	db.Model(&restaurant).Updates(map[string]interface{}{"min_item_count": 2, "max_item_count": 8})
+
+	pages := []struct {
+		handler gin.HandlerFunc
+		route   string
+	}{
+		{GetRestaurant, "/restaurants/:id"},
+		{GetMenu, "/restaurants/:id/menu"},
+	}
+	for _, p := range pages {
+		target := strings.Replace(p.route, ":id", fmt.Sprint(restaurant.ID), 1)
+		w := serve(p.handler, p.route, 0, "", http.MethodGet, target, "")
+		wantStatus(t, w, http.StatusOK)
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		// GetRestaurant nests the restaurant; the menu has the limits at the top
+		if r, ok := body["restaurant"].(map[string]interface{}); ok {
+			body = r
+		}
+		if body["min_item_count"] != 2.0 || body["max_item_count"] != 8.0 {
+			t.Errorf("%s: min_item_count %v, max_item_count %v, want 2 and 8", target, body["min_item_count"], body["max_item_count"])
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"math/big"
+	"net/http"
+
+	"food-delivery-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWK is one RSA public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// GetJWKS publishes the public key tokens are signed with, so other services
+// can verify them without the secret. The set is empty while tokens are
+// signed with HS256.
+//
+// @Summary     JSON Web Key Set
+// @Tags        auth
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Router      /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	keys := []JWK{}
+	if pub := config.JWTPublicKey; pub != nil {
+		keys = append(keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: config.JWTKeyID,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
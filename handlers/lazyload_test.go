@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Each list is checked for lazy loads by newTestDB, which fails the test if
+// the LazyLoadChecker logged a warning. A dozen orders is past its threshold.
+func TestOrderListsHaveNoLazyLoads(t *testing.T) {
+	db := newTestDB(t)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	driver := createUser(t, db, "Driver", models.RoleDriver)
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+	restaurant, items := createRestaurant(t, db,
+		models.MenuItem{Name: "Paneer", Price: 200},
+		models.MenuItem{Name: "Naan", Price: 30},
+	)
+	for i := 0; i < 12; i++ {
+		order := models.Order{
+			InvoiceNumber:   fmt.Sprintf("INV-TEST-%05d", i+1),
+			CustomerID:      customer.ID,
+			RestaurantID:    restaurant.ID,
+			Status:          models.StatusPickedUp,
+			DriverID:        &driver.ID,
+			DeliveryAddress: "2 Low St",
+			TotalPrice:      260,
+			Items: []models.OrderItem{
+				{MenuItemID: items[0].ID, Quantity: 1, Price: 200, Name: "Paneer"},
+				{MenuItemID: items[1].ID, Quantity: 2, Price: 30, Name: "Naan"},
+			},
+			StatusHistory: []models.OrderStatusHistory{
+				{FromStatus: models.StatusPlaced, ToStatus: models.StatusPickedUp, ChangedBy: driver.ID},
+			},
+		}
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lists := []struct {
+		name    string
+		handler func(*gin.Context)
+		userID  uint
+		role    models.UserRole
+	}{
+		{"customer orders", GetMyOrders, customer.ID, models.RoleCustomer},
+		{"restaurant orders", GetRestaurantOrders, restaurant.OwnerID, models.RoleRestaurant},
+		{"driver deliveries", GetMyDeliveries, driver.ID, models.RoleDriver},
+		{"admin orders", AdminGetAllOrders, admin.ID, models.RoleAdmin},
+	}
+	for _, l := range lists {
+		w := serve(l.handler, "/orders", l.userID, l.role, http.MethodGet, "/orders", "")
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200; body: %s", l.name, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	leaderboardCacheTTL  = 5 * time.Minute
+	leaderboardSize      = 20
+	minDriverRatings     = 5
+	minRestaurantReviews = 10
+)
+
+type leaderboardEntry struct {
+	Data     json.RawMessage
+	CachedAt time.Time
+}
+
+type DriverLeaderboardRow struct {
+	Rank            int     `json:"rank"`
+	DriverID        uint    `json:"driver_id,omitempty"`
+	Name            string  `json:"name"`
+	AvgRating       float64 `json:"avg_rating"`
+	RatingCount     int     `json:"rating_count"`
+	TotalDeliveries int     `json:"total_deliveries"`
+	OnTimeRate      float64 `json:"on_time_rate"` // share of deliveries within the order's ETA
+}
+
+type RestaurantLeaderboardRow struct {
+	Rank        int     `json:"rank"`
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Cuisine     string  `json:"cuisine"`
+	Rating      float64 `json:"rating"`
+	ReviewCount int     `json:"review_count"`
+}
+
+// cachedLeaderboard decodes the leaderboard cached under key ("drivers:<period>"
+// or "restaurants") into out, computing and caching it first when missing
+func cachedLeaderboard(key string, out interface{}, compute func() interface{}) time.Time {
+	key = "leaderboard:" + key
+	var entry leaderboardEntry
+	if body, ok := cache.Default.Get(key); ok && json.Unmarshal(body, &entry) == nil && json.Unmarshal(entry.Data, out) == nil {
+		return entry.CachedAt
+	}
+	entry.CachedAt = time.Now()
+	data, err := json.Marshal(compute())
+	if err == nil {
+		entry.Data = data
+		if body, err := json.Marshal(entry); err == nil {
+			cache.Default.Set(key, body, leaderboardCacheTTL)
+		}
+		json.Unmarshal(data, out)
+	}
+	return entry.CachedAt
+}
+
+// periodStart maps a leaderboard period to its start time (zero for all time)
+func periodStart(period string) (time.Time, bool) {
+	switch period {
+	case "weekly":
+		return time.Now().AddDate(0, 0, -7), true
+	case "monthly":
+		return time.Now().AddDate(0, -1, 0), true
+	case "alltime":
+		return time.Time{}, true
+	}
+	return time.Time{}, false
+}
+
+// computeDriverLeaderboard ranks drivers by average rating over the period
+func computeDriverLeaderboard(since time.Time) []DriverLeaderboardRow {
+	var ratings []struct {
+		DriverID    uint
+		Name        string
+		AvgRating   float64
+		RatingCount int
+	}
+	config.DB.Table("reviews").
+		Select("reviews.driver_id, users.name, AVG(reviews.driver_rating) AS avg_rating, COUNT(reviews.driver_rating) AS rating_count").
+		Joins("JOIN users ON users.id = reviews.driver_id").
+		Where("reviews.driver_rating IS NOT NULL AND reviews.created_at >= ?", since).
+		Group("reviews.driver_id, users.name").
+		Having("COUNT(reviews.driver_rating) >= ?", minDriverRatings).
+		Scan(&ratings)
+
+	// Delivery timings for on-time rate: delivered_at vs placed_at + ETA
+	var deliveries []struct {
+		DriverID      uint
+		CreatedAt     time.Time
+		EstimatedTime int
+		DeliveredAt   time.Time
+	}
+	config.DB.Table("orders").
+		Select("orders.driver_id, orders.created_at, orders.estimated_time, h.created_at AS delivered_at").
+		Joins("JOIN order_status_histories h ON h.order_id = orders.id AND h.to_status = ?", models.StatusDelivered).
+		Where("orders.status = ? AND orders.driver_id IS NOT NULL AND orders.deleted_at IS NULL AND h.created_at >= ?", models.StatusDelivered, since).
+		Scan(&deliveries)
+
+	total := map[uint]int{}
+	onTime := map[uint]int{}
+	for _, d := range deliveries {
+		total[d.DriverID]++
+		if d.DeliveredAt.Sub(d.CreatedAt) <= time.Duration(d.EstimatedTime)*time.Minute {
+			onTime[d.DriverID]++
+		}
+	}
+
+	rows := make([]DriverLeaderboardRow, 0, len(ratings))
+	for _, r := range ratings {
+		row := DriverLeaderboardRow{
+			DriverID:        r.DriverID,
+			Name:            r.Name,
+			AvgRating:       r.AvgRating,
+			RatingCount:     r.RatingCount,
+			TotalDeliveries: total[r.DriverID],
+		}
+		if row.TotalDeliveries > 0 {
+			row.OnTimeRate = float64(onTime[r.DriverID]) / float64(row.TotalDeliveries)
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].AvgRating != rows[j].AvgRating {
+			return rows[i].AvgRating > rows[j].AvgRating
+		}
+		return rows[i].TotalDeliveries > rows[j].TotalDeliveries
+	})
+	if len(rows) > leaderboardSize {
+		rows = rows[:leaderboardSize]
+	}
+	for i := range rows {
+		rows[i].Rank = i + 1
+	}
+	return rows
+}
+
+// anonymiseName turns "John Doe" into "John D." for public display
+func anonymiseName(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) < 2 {
+		return name
+	}
+	last := []rune(parts[len(parts)-1])
+	return parts[0] + " " + strings.ToUpper(string(last[0])) + "."
+}
+
+// driverLeaderboard validates ?period= and returns the cached driver leaderboard
+func driverLeaderboard(c *gin.Context) ([]DriverLeaderboardRow, string, time.Time, bool) {
+	period := c.DefaultQuery("period", "alltime")
+	since, ok := periodStart(period)
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_period", nil)
+		return nil, "", time.Time{}, false
+	}
+	rows := []DriverLeaderboardRow{}
+	cachedAt := cachedLeaderboard("drivers:"+period, &rows, func() interface{} {
+		return computeDriverLeaderboard(since)
+	})
+	return rows, period, cachedAt, true
+}
+
+// AdminGetDriverLeaderboard returns the top drivers by rating — admin only
+//
+// @Summary     Driver leaderboard
+// @Tags        admin
+// @Produce     json
+// @Param       period  query  string  false  "weekly, monthly or alltime"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/leaderboard/drivers [get]
+func AdminGetDriverLeaderboard(c *gin.Context) {
+	rows, period, cachedAt, ok := driverLeaderboard(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"period": period, "cached_at": cachedAt, "count": len(rows), "drivers": rows})
+}
+
+// GetPublicDriverLeaderboard returns the driver leaderboard with anonymised names (public)
+//
+// @Summary     Public driver leaderboard
+// @Tags        public
+// @Produce     json
+// @Param       period  query  string  false  "weekly, monthly or alltime"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Router      /leaderboard/drivers [get]
+func GetPublicDriverLeaderboard(c *gin.Context) {
+	rows, period, cachedAt, ok := driverLeaderboard(c)
+	if !ok {
+		return
+	}
+	public := make([]DriverLeaderboardRow, len(rows))
+	for i, r := range rows {
+		r.DriverID = 0
+		r.Name = anonymiseName(r.Name)
+		public[i] = r
+	}
+	c.JSON(http.StatusOK, gin.H{"period": period, "cached_at": cachedAt, "count": len(public), "drivers": public})
+}
+
+// GetRestaurantLeaderboard returns the top-rated restaurants (public)
+//
+// @Summary     Restaurant leaderboard
+// @Tags        public
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Router      /leaderboard/restaurants [get]
+func GetRestaurantLeaderboard(c *gin.Context) {
+	rows := []RestaurantLeaderboardRow{}
+	cachedAt := cachedLeaderboard("restaurants", &rows, func() interface{} {
+		top := []RestaurantLeaderboardRow{}
+		requestDB(c).Model(&models.Restaurant{}).
+			Select("id, name, cuisine, rating, review_count").
+			Where("review_count >= ?", minRestaurantReviews).
+			Order("rating desc, review_count desc").
+			Limit(leaderboardSize).
+			Scan(&top)
+		for i := range top {
+			top[i].Rank = i + 1
+		}
+		return top
+	})
+	c.JSON(http.StatusOK, gin.H{"cached_at": cachedAt, "count": len(rows), "restaurants": rows})
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"food-delivery-api/hateoas"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// orderLinks lists the status changes the caller may make to order
+func orderLinks(c *gin.Context, order *models.Order) []models.Link {
+	return hateoas.OrderLinks(order, middleware.GetRole(c), "")
+}
+
+// setOrderLinks fills Links on each order for the caller
+func setOrderLinks(c *gin.Context, orders []models.Order) {
+	for i := range orders {
+		orders[i].Links = orderLinks(c, &orders[i])
+	}
+}
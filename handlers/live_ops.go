@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RestaurantLoad struct {
+	RestaurantID        uint   `json:"restaurant_id"`
+	RestaurantName      string `json:"restaurant_name"`
+	ActiveOrders        int    `json:"active_orders"`
+	OldestActiveMinutes int    `json:"oldest_active_minutes"`
+}
+
+// restaurantLoad counts non-terminal orders per restaurant in one grouped query,
+// keeping restaurants with more than threshold active orders
+func restaurantLoad(db *gorm.DB, threshold int) []RestaurantLoad {
+	rows := []RestaurantLoad{}
+	db.Table("orders").
+		Select("orders.restaurant_id, restaurants.name AS restaurant_name, COUNT(*) AS active_orders, "+
+			"CAST((julianday('now') - julianday(MIN(orders.created_at))) * 1440 AS INTEGER) AS oldest_active_minutes").
+		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("orders.status NOT IN ? AND orders.deleted_at IS NULL", []models.OrderStatus{models.StatusDelivered, models.StatusCancelled}).
+		Group("orders.restaurant_id, restaurants.name").
+		Having("COUNT(*) > ?", threshold).
+		Order("active_orders desc").
+		Scan(&rows)
+	return rows
+}
+
+// AdminGetRestaurantLoad shows how many orders each restaurant is juggling right now — admin only
+//
+// @Summary     Active orders per restaurant (live ops)
+// @Tags        admin
+// @Produce     json
+// @Param       threshold  query  int  false  "Only restaurants with more than this many active orders"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/live/restaurant-load [get]
+func AdminGetRestaurantLoad(c *gin.Context) {
+	threshold, err := strconv.Atoi(c.DefaultQuery("threshold", "0"))
+	if err != nil || threshold < 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.threshold_must_be_non_negative_integer", nil)
+		return
+	}
+	rows := restaurantLoad(requestDB(c), threshold)
+	c.JSON(http.StatusOK, gin.H{"threshold": threshold, "count": len(rows), "restaurants": rows})
+}
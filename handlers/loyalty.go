@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"food-delivery-api/config"
+	"food-delivery-api/features"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// One base point is earned per this many rupees of item subtotal
+const rupeesPerLoyaltyPoint = 10.0
+
+// tierPerks describes what each tier unlocks
+type tierPerks struct {
+	PointsMultiplier float64 `json:"points_multiplier"`
+	FreeDelivery     bool    `json:"free_delivery"`
+}
+
+var loyaltyPerks = map[models.LoyaltyTier]tierPerks{
+	models.TierBronze: {PointsMultiplier: 1},
+	models.TierSilver: {PointsMultiplier: 1.25},
+	models.TierGold:   {PointsMultiplier: 1.5, FreeDelivery: true},
+}
+
+// tierFor maps lifetime points to a tier using the SystemConfig thresholds
+func tierFor(lifetimePoints int) models.LoyaltyTier {
+	switch {
+	case lifetimePoints >= sysconfig.Int(sysconfig.KeyLoyaltyGoldThreshold):
+		return models.TierGold
+	case lifetimePoints >= sysconfig.Int(sysconfig.KeyLoyaltySilverThreshold):
+		return models.TierSilver
+	default:
+		return models.TierBronze
+	}
+}
+
+// loyaltyAccount returns the customer's account, creating an empty bronze one if needed
+func loyaltyAccount(db *gorm.DB, customerID uint) (models.LoyaltyAccount, error) {
+	account := models.LoyaltyAccount{CustomerID: customerID, Tier: models.TierBronze}
+	err := db.Where(models.LoyaltyAccount{CustomerID: customerID}).FirstOrCreate(&account).Error
+	return account, err
+}
+
+// hasFreeDeliveryPerk reports whether the customer's tier waives the delivery fee
+func hasFreeDeliveryPerk(db *gorm.DB, customerID uint) bool {
+	if !features.IsEnabled(models.FeatureLoyalty) {
+		return false
+	}
+	var account models.LoyaltyAccount
+	if err := db.Where("customer_id = ?", customerID).First(&account).Error; err != nil {
+		return false
+	}
+	return loyaltyPerks[account.Tier].FreeDelivery
+}
+
+// awardLoyaltyPoints is the earn-points hook run when an order is delivered.
+// Points are based on the item subtotal, scaled by the customer's current tier.
+// Nothing is earned while the loyalty feature is off.
+func awardLoyaltyPoints(order models.Order) {
+	if !features.IsEnabled(models.FeatureLoyalty) {
+		return
+	}
+	subtotal := order.Subtotal()
+	var upgradedTo models.LoyaltyTier
+	var earned int
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Each order earns once, even if it is forced back to DELIVERED later
+		res := tx.Model(&models.Order{}).Where("id = ? AND loyalty_points_earned = 0", order.ID).
+			Update("loyalty_points_earned", -1)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		account, err := loyaltyAccount(tx, order.CustomerID)
+		if err != nil {
+			return err
+		}
+		earned = int(math.Floor(subtotal / rupeesPerLoyaltyPoint * loyaltyPerks[account.Tier].PointsMultiplier))
+
+		account.Points += earned
+		account.LifetimePoints += earned
+		if tier := tierFor(account.LifetimePoints); tier != account.Tier {
+			if loyaltyRank(tier) > loyaltyRank(account.Tier) {
+				upgradedTo = tier
+			}
+			account.Tier = tier
+		}
+		if err := tx.Save(&account).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("loyalty_points_earned", earned).Error
+	})
+	if err != nil {
+		log.Printf("loyalty: failed to award points for order %d: %v", order.ID, err)
+		return
+	}
+
+	if upgradedTo != "" {
+		var customer models.User
+		config.DB.First(&customer, order.CustomerID)
+		notify.Default.Send(notify.Message{
+			UserID:  customer.ID,
+			Email:   customer.Email,
+			Phone:   customer.Phone,
+			Channel: notify.ChannelPush,
+			Title:   fmt.Sprintf("You've reached %s tier!", upgradedTo),
+			Body:    fmt.Sprintf("Your %d points on order #%d unlocked new perks.", earned, order.ID),
+
+			EventType:     "loyalty_tier_upgraded",
+			ReferenceID:   order.ID,
+			ReferenceType: "order",
+		})
+	}
+}
+
+func loyaltyRank(tier models.LoyaltyTier) int {
+	switch tier {
+	case models.TierGold:
+		return 2
+	case models.TierSilver:
+		return 1
+	}
+	return 0
+}
+
+// GetLoyaltyTier shows the customer's tier, points and what it takes to reach the next one
+//
+// @Summary     My loyalty tier and perks
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /customer/loyalty/tier [get]
+func GetLoyaltyTier(c *gin.Context) {
+	if !requireFeature(c, models.FeatureLoyalty) {
+		return
+	}
+	customerID := middleware.GetUserID(c)
+	account, err := loyaltyAccount(requestDB(c), customerID)
+	if err != nil {
+		account = models.LoyaltyAccount{CustomerID: customerID, Tier: models.TierBronze}
+	}
+
+	resp := gin.H{
+		"tier":            account.Tier,
+		"points":          account.Points,
+		"lifetime_points": account.LifetimePoints,
+		"perks":           loyaltyPerks[account.Tier],
+		"next_tier":       nil,
+		"next_threshold":  nil,
+	}
+	switch account.Tier {
+	case models.TierBronze:
+		resp["next_tier"] = models.TierSilver
+		resp["next_threshold"] = sysconfig.Int(sysconfig.KeyLoyaltySilverThreshold)
+	case models.TierSilver:
+		resp["next_tier"] = models.TierGold
+		resp["next_threshold"] = sysconfig.Int(sysconfig.KeyLoyaltyGoldThreshold)
+	}
+	c.JSON(http.StatusOK, resp)
+}
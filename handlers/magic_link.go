@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+	"food-delivery-api/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	magicLinkTTL       = 15 * time.Minute
+	magicLinkPerHour   = 3 // requests per email
+	magicLinkTokenSize = 32
+)
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func hashMagicToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// magicLinkURL builds the frontend login link. FRONTEND_URL may be a bare host,
+// in which case https is assumed.
+func magicLinkURL(token string) string {
+	base := strings.TrimRight(config.FrontendURL, "/")
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return base + "/auth/magic?token=" + url.QueryEscape(token)
+}
+
+// RequestMagicLink emails a customer a one-time login link valid for 15 minutes.
+// The response is the same whether or not the email belongs to a customer.
+//
+// @Summary     Email a password-less login link
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       body  body  MagicLinkRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     429  {object}  apierror.ErrorResponse
+// @Router      /auth/magic-link [post]
+func RequestMagicLink(c *gin.Context) {
+	if !requireFeature(c, models.FeatureMagicLink) {
+		return
+	}
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if ok, wait := ratelimit.Allow("magic-link:"+email, magicLinkPerHour, time.Hour); !ok {
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		apierror.Respond(c, http.StatusTooManyRequests, apierror.ErrRateLimited,
+			"errors.too_many_login_links", gin.H{"retry_after_seconds": retryAfter})
+		return
+	}
+
+	sent := gin.H{"message": "If that email belongs to a customer account, a login link is on its way"}
+	var user models.User
+	if err := requestDB(c).Where("LOWER(email) = ?", email).First(&user).Error; err != nil || user.Role != models.RoleCustomer {
+		c.JSON(http.StatusOK, sent)
+		return
+	}
+
+	buf := make([]byte, magicLinkTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_login_link", nil)
+		return
+	}
+	token := hex.EncodeToString(buf)
+	link := models.MagicLinkToken{
+		UserID:    user.ID,
+		TokenHash: hashMagicToken(token),
+		ExpiresAt: time.Now().Add(magicLinkTTL),
+	}
+	if err := requestDB(c).Create(&link).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_login_link", nil)
+		return
+	}
+
+	err := notify.Default.Send(notify.Message{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Channel: notify.ChannelEmail,
+		Title:   "Your login link",
+		Body:    "Sign in within 15 minutes: " + magicLinkURL(token),
+		Private: true,
+	})
+	if err != nil {
+		log.Printf("magic link: failed to email user %d: %v", user.ID, err)
+	}
+	c.JSON(http.StatusOK, sent)
+}
+
+// VerifyMagicLink exchanges a login link token for a JWT. Each token works once.
+//
+// @Summary     Log in with a magic link token
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       body  body  MagicLinkVerifyRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Router      /auth/magic-link/verify [post]
+func VerifyMagicLink(c *gin.Context) {
+	if !requireFeature(c, models.FeatureMagicLink) {
+		return
+	}
+	var req MagicLinkVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	now := time.Now()
+	var link models.MagicLinkToken
+	if err := requestDB(c).Where("token_hash = ?", hashMagicToken(req.Token)).First(&link).Error; err != nil ||
+		link.UsedAt != nil || now.After(link.ExpiresAt) {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.login_link_is_invalid_or_has_expired", nil)
+		return
+	}
+	// Claim the token so two concurrent verifications can't both log in
+	res := requestDB(c).Model(&models.MagicLinkToken{}).Where("id = ? AND used_at IS NULL", link.ID).Update("used_at", now)
+	if res.Error != nil || res.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.login_link_is_invalid_or_has_expired", nil)
+		return
+	}
+
+	var user models.User
+	if err := requestDB(c).First(&user, link.UserID).Error; err != nil || user.Role != models.RoleCustomer {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.login_link_is_invalid_or_has_expired", nil)
+		return
+	}
+
+	token, err := middleware.GenerateToken(&user)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_generate_token", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Login successful",
+		"token":   token,
+		"user": gin.H{
+			"id":    user.ID,
+			"name":  user.Name,
+			"email": user.Email,
+			"role":  user.Role,
+		},
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// Maintenance actions recorded in MaintenanceLog
+const (
+	MaintenanceMergeUsers  = "MERGE_USERS"
+	MaintenanceForceLogout = "FORCE_LOGOUT"
+)
+
+// logMaintenance writes a MaintenanceLog row on tx; performedBy is nil for background jobs
+func logMaintenance(tx *gorm.DB, action string, performedBy *uint, details interface{}) error {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.MaintenanceLog{
+		Action:      action,
+		PerformedBy: performedBy,
+		Details:     string(encoded),
+	}).Error
+}
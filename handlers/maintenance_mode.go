@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceRequest struct {
+	Enabled *bool      `json:"enabled" binding:"required"`
+	Message *string    `json:"message"` // shown by GET /api/status; left as is when omitted
+	EndsAt  *time.Time `json:"ends_at"` // expected end, sent as Retry-After; clients are told 30 minutes without it
+}
+
+// AdminSetMaintenance turns maintenance mode on or off. While it is on every
+// non-admin API request gets a 503. Admin only.
+//
+// @Summary     Turn maintenance mode on or off
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  MaintenanceRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/config/maintenance [put]
+func AdminSetMaintenance(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(time.Now()) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.maintenance_end_in_past", nil)
+		return
+	}
+
+	end := ""
+	if *req.Enabled && req.EndsAt != nil {
+		end = req.EndsAt.UTC().Format(time.RFC3339)
+	}
+	// The switch itself goes last so blocked clients never see a stale message
+	settings := [][2]string{{sysconfig.KeyMaintenanceEnd, end}}
+	if req.Message != nil {
+		settings = append(settings, [2]string{sysconfig.KeyMaintenanceMessage, *req.Message})
+	}
+	settings = append(settings, [2]string{sysconfig.KeyMaintenanceMode, strconv.FormatBool(*req.Enabled)})
+	for _, s := range settings {
+		if err := sysconfig.Set(s[0], s[1], &adminID); err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_config", nil)
+			return
+		}
+	}
+	log.Printf("maintenance: admin %d set maintenance mode to %t", adminID, *req.Enabled)
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance mode updated", "status": maintenanceStatus()})
+}
+
+// maintenanceStatus is the body of GET /api/status
+func maintenanceStatus() gin.H {
+	status := gin.H{
+		"maintenance": sysconfig.Bool(sysconfig.KeyMaintenanceMode),
+		"message":     sysconfig.Get(sysconfig.KeyMaintenanceMessage),
+		"ends_at":     nil,
+	}
+	if status["maintenance"] == true {
+		status["ends_at"] = middleware.MaintenanceEnd(time.Now()).UTC()
+	}
+	return status
+}
+
+// GetStatus reports whether the platform is under maintenance, with the
+// message admins left for users and the expected end. It stays reachable
+// during maintenance.
+//
+// @Summary     Platform status
+// @Tags        public
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Router      /status [get]
+func GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, maintenanceStatus())
+}
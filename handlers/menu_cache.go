@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"food-delivery-api/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Public menus change rarely; edits invalidate the restaurant's entries right away
+const menuCacheTTL = 2 * time.Minute
+
+// menuCacheKey covers every query parameter that changes the menu response
+func menuCacheKey(restaurantID uint64, category, isVeg string, excludeAllergens []string, maxCalories string) string {
+	exclude := append([]string(nil), excludeAllergens...)
+	sort.Strings(exclude)
+	return fmt.Sprintf("menu:%d:%s:%s:%s:%s", restaurantID, category, isVeg, strings.Join(exclude, ","), maxCalories)
+}
+
+// invalidateMenuCache drops every cached menu variant for a restaurant
+func invalidateMenuCache(restaurantID uint) {
+	cache.Default.DeletePrefix(fmt.Sprintf("menu:%d:", restaurantID))
+}
+
+// writeCachedJSON sends a JSON body with a content ETag, or 304 when the client already has it
+func writeCachedJSON(c *gin.Context, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	c.Header("ETag", etag)
+	if match := c.GetHeader("If-None-Match"); match == etag || match == strings.Trim(etag, `"`) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
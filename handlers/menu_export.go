@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MenuExport is one restaurant's full menu as written by GET /admin/export/menus
+// and read back by POST /admin/import/menus
+type MenuExport struct {
+	Restaurant MenuExportRestaurant `json:"restaurant"`
+	Categories []string             `json:"categories"`
+	Items      []MenuExportItem     `json:"items"`
+	Bundles    []MenuExportBundle   `json:"bundles"`
+}
+
+type MenuExportRestaurant struct {
+	ID                 uint   `json:"id"`
+	OwnerID            uint   `json:"owner_id"`
+	Name               string `json:"name"`
+	Cuisine            string `json:"cuisine"`
+	Address            string `json:"address"`
+	Description        string `json:"description"`
+	MaxOrdersPerMinute int    `json:"max_orders_per_minute"`
+}
+
+// MenuExportItem keeps the item's original ID so bundles can refer to it
+type MenuExportItem struct {
+	ID            uint     `json:"id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Price         float64  `json:"price"`
+	Category      string   `json:"category"`
+	IsAvailable   bool     `json:"is_available"`
+	IsVeg         bool     `json:"is_veg"`
+	TrackStock    bool     `json:"track_stock"`
+	StockQuantity int      `json:"stock_quantity"`
+	Allergens     []string `json:"allergens"`
+	Calories      *int     `json:"calories,omitempty"`
+	ProteinG      *float64 `json:"protein_g,omitempty"`
+	CarbsG        *float64 `json:"carbs_g,omitempty"`
+	FatG          *float64 `json:"fat_g,omitempty"`
+}
+
+type MenuExportBundle struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	BundlePrice float64                `json:"bundle_price"`
+	IsAvailable bool                   `json:"is_available"`
+	Items       []MenuExportBundleItem `json:"items"`
+}
+
+type MenuExportBundleItem struct {
+	MenuItemID uint `json:"menu_item_id"` // an id from the export's items
+	Quantity   int  `json:"quantity"`
+}
+
+// exportMenu loads everything on one restaurant's menu
+func exportMenu(db *gorm.DB, r models.Restaurant) (MenuExport, error) {
+	export := MenuExport{
+		Restaurant: MenuExportRestaurant{
+			ID:                 r.ID,
+			OwnerID:            r.OwnerID,
+			Name:               r.Name,
+			Cuisine:            r.Cuisine,
+			Address:            r.Address,
+			Description:        r.Description,
+			MaxOrdersPerMinute: r.MaxOrdersPerMinute,
+		},
+		Categories: []string{},
+		Items:      []MenuExportItem{},
+		Bundles:    []MenuExportBundle{},
+	}
+
+	var items []models.MenuItem
+	if err := db.Where("restaurant_id = ?", r.ID).Order("id").Find(&items).Error; err != nil {
+		return export, err
+	}
+	attachAllergens(db, items)
+	seen := map[string]bool{}
+	for _, item := range items {
+		if item.Category != "" && !seen[item.Category] {
+			seen[item.Category] = true
+			export.Categories = append(export.Categories, item.Category)
+		}
+		export.Items = append(export.Items, MenuExportItem{
+			ID:            item.ID,
+			Name:          item.Name,
+			Description:   item.Description,
+			Price:         item.Price,
+			Category:      item.Category,
+			IsAvailable:   item.IsAvailable,
+			IsVeg:         item.IsVeg,
+			TrackStock:    item.TrackStock,
+			StockQuantity: item.StockQuantity,
+			Allergens:     item.Allergens,
+			Calories:      item.Calories,
+			ProteinG:      item.ProteinG,
+			CarbsG:        item.CarbsG,
+			FatG:          item.FatG,
+		})
+	}
+
+	var bundles []models.MenuBundle
+	if err := db.Preload("Items").Where("restaurant_id = ?", r.ID).Order("id").Find(&bundles).Error; err != nil {
+		return export, err
+	}
+	for _, b := range bundles {
+		eb := MenuExportBundle{
+			Name:        b.Name,
+			Description: b.Description,
+			BundlePrice: b.BundlePrice,
+			IsAvailable: b.IsAvailable,
+			Items:       make([]MenuExportBundleItem, len(b.Items)),
+		}
+		for i, member := range b.Items {
+			eb.Items[i] = MenuExportBundleItem{MenuItemID: member.MenuItemID, Quantity: member.Quantity}
+		}
+		export.Bundles = append(export.Bundles, eb)
+	}
+	return export, nil
+}
+
+// AdminExportMenus streams every restaurant's menu as a JSON array, one
+// restaurant at a time, for backup or migration — admin only
+//
+// @Summary     Export restaurant menus
+// @Tags        admin
+// @Produce     json
+// @Param       restaurant_id  query  int  false  "Only this restaurant"
+// @Success     200  {array}   MenuExport
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/export/menus [get]
+func AdminExportMenus(c *gin.Context) {
+	query := requestDB(c).Model(&models.Restaurant{}).Order("id")
+	if s := c.Query("restaurant_id"); s != "" {
+		restaurantID, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.restaurant_id_must_be_a_number", nil)
+			return
+		}
+		query = query.Where("id = ?", restaurantID)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_export_menus", nil)
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Transfer-Encoding", "chunked")
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	c.Writer.WriteString("[")
+	first := true
+	for rows.Next() {
+		var restaurant models.Restaurant
+		if err := requestDB(c).ScanRows(rows, &restaurant); err != nil {
+			abortMenuExport(c, first, fmt.Errorf("failed to read restaurant: %w", err))
+			return
+		}
+		export, err := exportMenu(requestDB(c), restaurant)
+		if err != nil {
+			abortMenuExport(c, first, fmt.Errorf("failed to read the menu of restaurant %d: %w", restaurant.ID, err))
+			return
+		}
+		if !first {
+			c.Writer.WriteString(",")
+		}
+		first = false
+		if err := enc.Encode(export); err != nil {
+			// The client went away; the response is already partly sent
+			log.Printf("menu export: stopped at restaurant %d: %v", restaurant.ID, err)
+			return
+		}
+		c.Writer.Flush()
+	}
+	if err := rows.Err(); err != nil {
+		abortMenuExport(c, first, err)
+		return
+	}
+	c.Writer.WriteString("]\n")
+}
+
+// abortMenuExport ends an export that failed part way. The status is long
+// sent, so the array is left unclosed after an error object: a truncated
+// backup then fails to parse instead of passing for a complete one.
+func abortMenuExport(c *gin.Context, first bool, err error) {
+	log.Printf("menu export: %v", err)
+	if !first {
+		c.Writer.WriteString(",")
+	}
+	c.Writer.WriteString(`{"error":"export failed; this backup is incomplete"}`)
+	c.Writer.Flush()
+}
+
+// importMenu recreates one exported menu. The restaurant is matched by ID and
+// created (keeping that ID) when it doesn't exist yet. Items the restaurant
+// already has, by exported ID or else by name, are updated in place, and
+// bundles by name, so importing the same backup twice doesn't duplicate the
+// menu. Anything else the restaurant has is left alone.
+func importMenu(tx *gorm.DB, export MenuExport, importedBy uint) (restaurantCreated bool, err error) {
+	if export.Restaurant.ID == 0 {
+		return false, fmt.Errorf("restaurant.id is required")
+	}
+	var restaurant models.Restaurant
+	if err := tx.First(&restaurant, export.Restaurant.ID).Error; err != nil {
+		var owner models.User
+		if err := tx.Where("id = ? AND role = ?", export.Restaurant.OwnerID, models.RoleRestaurant).First(&owner).Error; err != nil {
+			return false, fmt.Errorf("restaurant %d doesn't exist and owner %d is not a restaurant account",
+				export.Restaurant.ID, export.Restaurant.OwnerID)
+		}
+		restaurant = models.Restaurant{
+			ID:                 export.Restaurant.ID,
+			OwnerID:            owner.ID,
+			Name:               export.Restaurant.Name,
+			Cuisine:            export.Restaurant.Cuisine,
+			Address:            export.Restaurant.Address,
+			Description:        export.Restaurant.Description,
+			MaxOrdersPerMinute: export.Restaurant.MaxOrdersPerMinute,
+		}
+		if restaurant.MaxOrdersPerMinute <= 0 {
+			restaurant.MaxOrdersPerMinute = 10
+		}
+		if err := tx.Create(&restaurant).Error; err != nil {
+			return false, err
+		}
+		restaurantCreated = true
+	}
+
+	// Re-importing a backup updates the items it already restored: match by
+	// the exported ID first, then by name
+	var existing []models.MenuItem
+	if err := tx.Where("restaurant_id = ?", restaurant.ID).Find(&existing).Error; err != nil {
+		return restaurantCreated, err
+	}
+	byID := make(map[uint]models.MenuItem, len(existing))
+	byName := make(map[string]models.MenuItem, len(existing))
+	for _, item := range existing {
+		byID[item.ID] = item
+		byName[strings.ToLower(item.Name)] = item
+	}
+
+	items := make([]models.MenuItem, len(export.Items))
+	var added []models.MenuItem
+	var addedAt []int // index in items of each added one
+	for i, e := range export.Items {
+		items[i] = models.MenuItem{
+			Name:          e.Name,
+			Description:   e.Description,
+			Price:         e.Price,
+			Category:      e.Category,
+			IsAvailable:   e.IsAvailable,
+			IsVeg:         e.IsVeg,
+			TrackStock:    e.TrackStock,
+			StockQuantity: e.StockQuantity,
+			Calories:      e.Calories,
+			ProteinG:      e.ProteinG,
+			CarbsG:        e.CarbsG,
+			FatG:          e.FatG,
+		}
+		if reason := validateImportedItem(items[i]); reason != "" {
+			return restaurantCreated, fmt.Errorf("item %d (%q): %s", e.ID, e.Name, reason)
+		}
+		match, ok := byID[e.ID]
+		if !ok {
+			match, ok = byName[strings.ToLower(e.Name)]
+		}
+		if !ok {
+			added = append(added, items[i])
+			addedAt = append(addedAt, i)
+			continue
+		}
+		// Each existing item takes at most one exported item
+		delete(byID, match.ID)
+		delete(byName, strings.ToLower(match.Name))
+		items[i].ID = match.ID
+		if err := tx.Model(&models.MenuItem{}).Where("id = ?", match.ID).
+			Select("name", "description", "price", "category", "is_available", "is_veg",
+				"track_stock", "stock_quantity", "calories", "protein_g", "carbs_g", "fat_g").
+			Updates(&items[i]).Error; err != nil {
+			return restaurantCreated, err
+		}
+		if err := tx.Model(&models.MenuItem{}).Where("id = ?", match.ID).
+			Update("version", gorm.Expr("version + 1")).Error; err != nil {
+			return restaurantCreated, err
+		}
+		if match.Price != e.Price {
+			if err := tx.Create(&models.MenuItemPriceHistory{
+				MenuItemID: match.ID,
+				OldPrice:   match.Price,
+				NewPrice:   e.Price,
+				ChangedBy:  importedBy,
+				ChangedAt:  time.Now(),
+			}).Error; err != nil {
+				return restaurantCreated, err
+			}
+		}
+		if err := tx.Where("menu_item_id = ?", match.ID).Delete(&models.MenuItemAllergen{}).Error; err != nil {
+			return restaurantCreated, err
+		}
+	}
+	if err := createMenuItems(tx, restaurant.ID, added); err != nil {
+		return restaurantCreated, err
+	}
+	for j, i := range addedAt {
+		items[i].ID = added[j].ID
+		// gorm fills in default:true for a false bool on create
+		if !export.Items[i].IsAvailable {
+			if err := tx.Model(&items[i]).Update("is_available", false).Error; err != nil {
+				return restaurantCreated, err
+			}
+		}
+	}
+
+	var allergens []models.Allergen
+	tx.Find(&allergens)
+	allergenIDs := make(map[string]uint, len(allergens))
+	for _, a := range allergens {
+		allergenIDs[a.Name] = a.ID
+	}
+	newIDs := make(map[uint]uint, len(items)) // exported id → id in this database
+	for i, e := range export.Items {
+		newIDs[e.ID] = items[i].ID
+		for _, name := range e.Allergens {
+			allergenID, ok := allergenIDs[name]
+			if !ok {
+				return restaurantCreated, fmt.Errorf("item %d (%q): unknown allergen %q", e.ID, e.Name, name)
+			}
+			if err := tx.Create(&models.MenuItemAllergen{MenuItemID: items[i].ID, AllergenID: allergenID}).Error; err != nil {
+				return restaurantCreated, err
+			}
+		}
+	}
+
+	// Bundles are matched by name and get the exported members
+	var existingBundles []models.MenuBundle
+	if err := tx.Where("restaurant_id = ?", restaurant.ID).Find(&existingBundles).Error; err != nil {
+		return restaurantCreated, err
+	}
+	bundlesByName := make(map[string]models.MenuBundle, len(existingBundles))
+	for _, b := range existingBundles {
+		bundlesByName[strings.ToLower(b.Name)] = b
+	}
+	for _, e := range export.Bundles {
+		var members []models.MenuBundleItem
+		for _, member := range e.Items {
+			id, ok := newIDs[member.MenuItemID]
+			if !ok {
+				return restaurantCreated, fmt.Errorf("bundle %q: item %d is not in the export", e.Name, member.MenuItemID)
+			}
+			members = append(members, models.MenuBundleItem{MenuItemID: id, Quantity: member.Quantity})
+		}
+		bundle, ok := bundlesByName[strings.ToLower(e.Name)]
+		if !ok {
+			bundle = models.MenuBundle{
+				RestaurantID: restaurant.ID,
+				Name:         e.Name,
+				Description:  e.Description,
+				BundlePrice:  e.BundlePrice,
+				Items:        members,
+			}
+			if err := tx.Create(&bundle).Error; err != nil {
+				return restaurantCreated, err
+			}
+			if !e.IsAvailable {
+				if err := tx.Model(&bundle).Update("is_available", false).Error; err != nil {
+					return restaurantCreated, err
+				}
+			}
+			continue
+		}
+		delete(bundlesByName, strings.ToLower(e.Name))
+		if err := tx.Model(&bundle).Select("description", "bundle_price", "is_available").Updates(&models.MenuBundle{
+			Description: e.Description,
+			BundlePrice: e.BundlePrice,
+			IsAvailable: e.IsAvailable,
+		}).Error; err != nil {
+			return restaurantCreated, err
+		}
+		if err := tx.Where("bundle_id = ?", bundle.ID).Delete(&models.MenuBundleItem{}).Error; err != nil {
+			return restaurantCreated, err
+		}
+		for i := range members {
+			members[i].BundleID = bundle.ID
+		}
+		if len(members) > 0 {
+			if err := tx.Create(&members).Error; err != nil {
+				return restaurantCreated, err
+			}
+		}
+	}
+	return restaurantCreated, nil
+}
+
+// AdminImportMenus loads menus in the format written by GET /admin/export/menus — admin only.
+// Each restaurant is imported in its own transaction; failures are reported by
+// index and don't stop the rest.
+//
+// @Summary     Import restaurant menus
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  []MenuExport  true  "Output of GET /admin/export/menus"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/import/menus [post]
+func AdminImportMenus(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var exports []MenuExport
+	if err := c.ShouldBindJSON(&exports); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var restaurants, created, items, bundles int
+	errs := []gin.H{}
+	for i, export := range exports {
+		var restaurantCreated bool
+		err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+			var err error
+			restaurantCreated, err = importMenu(tx, export, adminID)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, gin.H{"index": i, "restaurant_id": export.Restaurant.ID, "error": err.Error()})
+			continue
+		}
+		invalidateMenuCache(export.Restaurant.ID)
+		restaurants++
+		if restaurantCreated {
+			created++
+		}
+		items += len(export.Items)
+		bundles += len(export.Bundles)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"restaurants":         restaurants,
+		"created_restaurants": created,
+		"items":               items,
+		"bundles":             bundles,
+		"errors":              errs,
+	})
+}
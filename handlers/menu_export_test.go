@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// exportMenus runs the export against config.DB. The export reads
+// restaurants from a cursor while it queries, so it needs two connections.
+func exportMenus(t *testing.T, db *gorm.DB) []byte {
+	t.Helper()
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(2)
+	w := serve(AdminExportMenus, "/export/menus", 1, models.RoleAdmin, http.MethodGet, "/export/menus", "")
+	wantStatus(t, w, http.StatusOK)
+	return w.Body.Bytes()
+}
+
+func seedAllergens(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, name := range models.AllergenNames {
+		if err := db.Create(&models.Allergen{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMenuExportImportRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	seedAllergens(t, db)
+	calories, protein := 450, 18.5
+	_, items := createRestaurant(t, db,
+		models.MenuItem{Name: "Paneer Tikka", Price: 220, Category: "Starters", IsVeg: true, Calories: &calories, ProteinG: &protein},
+		models.MenuItem{Name: "Butter Naan", Price: 40, Category: "Breads", TrackStock: true, StockQuantity: 12},
+		models.MenuItem{Name: "Kulfi", Price: 90, Category: "Desserts"},
+	)
+	db.Model(&items[2]).Update("is_available", false)
+	var dairy models.Allergen
+	db.Where("name = ?", "dairy").First(&dairy)
+	db.Create(&models.MenuItemAllergen{MenuItemID: items[0].ID, AllergenID: dairy.ID})
+	db.Create(&models.MenuBundle{RestaurantID: items[0].RestaurantID, Name: "Meal for one", BundlePrice: 230, Items: []models.MenuBundleItem{
+		{MenuItemID: items[0].ID, Quantity: 1},
+		{MenuItemID: items[1].ID, Quantity: 2},
+	}})
+	exported := exportMenus(t, db)
+
+	var menus []MenuExport
+	if err := json.Unmarshal(exported, &menus); err != nil {
+		t.Fatalf("export is not a JSON array: %v\n%s", err, exported)
+	}
+	if len(menus) != 1 || len(menus[0].Items) != 3 || len(menus[0].Bundles) != 1 || len(menus[0].Categories) != 3 {
+		t.Fatalf("export = %+v, want 1 restaurant with 3 items, 1 bundle and 3 categories", menus)
+	}
+
+	// Import into an empty database that only has the owner's account
+	db = newTestDB(t)
+	seedAllergens(t, db)
+	createUser(t, db, "Owner", models.RoleRestaurant)
+	w := serve(AdminImportMenus, "/import/menus", 1, models.RoleAdmin, http.MethodPost, "/import/menus", string(exported))
+	wantStatus(t, w, http.StatusOK)
+	var summary struct {
+		Restaurants int                      `json:"restaurants"`
+		Created     int                      `json:"created_restaurants"`
+		Items       int                      `json:"items"`
+		Bundles     int                      `json:"bundles"`
+		Errors      []map[string]interface{} `json:"errors"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &summary)
+	if summary.Restaurants != 1 || summary.Created != 1 || summary.Items != 3 || summary.Bundles != 1 || len(summary.Errors) != 0 {
+		t.Fatalf("import summary = %+v, want 1 restaurant created with 3 items and 1 bundle", summary)
+	}
+
+	var itemCount, bundleCount int64
+	db.Model(&models.MenuItem{}).Count(&itemCount)
+	db.Model(&models.MenuBundle{}).Count(&bundleCount)
+	if itemCount != 3 || bundleCount != 1 {
+		t.Errorf("imported %d items and %d bundles, want 3 and 1", itemCount, bundleCount)
+	}
+
+	// A fresh database hands out the same IDs, so the menus export identically
+	if again := exportMenus(t, db); !bytes.Equal(again, exported) {
+		t.Errorf("re-export differs from the original\noriginal: %s\nre-export: %s", exported, again)
+	}
+}
+
+func TestMenuImportTwiceUpdatesInPlace(t *testing.T) {
+	db := newTestDB(t)
+	seedAllergens(t, db)
+	_, items := createRestaurant(t, db,
+		models.MenuItem{Name: "Paneer Tikka", Price: 220, Category: "Starters"},
+		models.MenuItem{Name: "Butter Naan", Price: 40, Category: "Breads"},
+	)
+	var dairy models.Allergen
+	db.Where("name = ?", "dairy").First(&dairy)
+	db.Create(&models.MenuItemAllergen{MenuItemID: items[0].ID, AllergenID: dairy.ID})
+	db.Create(&models.MenuBundle{RestaurantID: items[0].RestaurantID, Name: "Meal for one", BundlePrice: 230, Items: []models.MenuBundleItem{
+		{MenuItemID: items[0].ID, Quantity: 1},
+		{MenuItemID: items[1].ID, Quantity: 2},
+	}})
+	exported := exportMenus(t, db)
+
+	// The owner renamed one item and repriced the other since the backup
+	db.Model(&items[0]).Update("name", "Paneer Tikka (new)")
+	db.Model(&items[1]).Update("price", 45)
+	for i := 0; i < 2; i++ {
+		w := serve(AdminImportMenus, "/import/menus", 1, models.RoleAdmin, http.MethodPost, "/import/menus", string(exported))
+		wantStatus(t, w, http.StatusOK)
+	}
+
+	var itemCount, bundleCount, memberCount, allergenCount int64
+	db.Model(&models.MenuItem{}).Count(&itemCount)
+	db.Model(&models.MenuBundle{}).Count(&bundleCount)
+	db.Model(&models.MenuBundleItem{}).Count(&memberCount)
+	db.Model(&models.MenuItemAllergen{}).Count(&allergenCount)
+	if itemCount != 2 || bundleCount != 1 || memberCount != 2 || allergenCount != 1 {
+		t.Errorf("after importing twice: %d items, %d bundles, %d bundle members, %d allergen links; want 2, 1, 2 and 1",
+			itemCount, bundleCount, memberCount, allergenCount)
+	}
+	var restored models.MenuItem
+	db.First(&restored, items[0].ID)
+	if restored.Name != "Paneer Tikka" {
+		t.Errorf("item %d is named %q, want the backup's name back", items[0].ID, restored.Name)
+	}
+	var history []models.MenuItemPriceHistory
+	db.Where("menu_item_id = ?", items[1].ID).Find(&history)
+	if len(history) != 1 || history[0].OldPrice != 45 || history[0].NewPrice != 40 {
+		t.Errorf("price history = %+v, want one change from 45 back to 40", history)
+	}
+}
+
+func TestMenuImportMatchesByName(t *testing.T) {
+	db := newTestDB(t)
+	seedAllergens(t, db)
+	restaurant, items := createRestaurant(t, db, models.MenuItem{Name: "Kulfi", Price: 90})
+	export := fmt.Sprintf(`[{"restaurant":{"id":%d},"items":[
+		{"id":999,"name":"kulfi","price":95,"is_available":false},
+		{"id":1000,"name":"Lassi","price":60,"is_available":true}]}]`, restaurant.ID)
+	w := serve(AdminImportMenus, "/import/menus", 1, models.RoleAdmin, http.MethodPost, "/import/menus", export)
+	wantStatus(t, w, http.StatusOK)
+
+	var got []models.MenuItem
+	db.Order("id").Find(&got)
+	if len(got) != 2 || got[0].ID != items[0].ID || got[0].Price != 95 || got[0].IsAvailable || got[1].Name != "Lassi" || !got[1].IsAvailable {
+		t.Errorf("items = %+v, want Kulfi updated in place and Lassi added", got)
+	}
+}
+
+func TestMenuExportFailureLeavesInvalidJSON(t *testing.T) {
+	db := newTestDB(t)
+	first, _ := createRestaurant(t, db, models.MenuItem{Name: "Kulfi", Price: 90})
+	if err := db.Create(&models.Restaurant{OwnerID: first.OwnerID, Name: "Second Kitchen", MaxOrdersPerMinute: 10}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Fail reading the second restaurant's bundles
+	bundleReads := 0
+	err := db.Callback().Query().Before("gorm:query").Register("test:fail_bundles", func(tx *gorm.DB) {
+		if tx.Statement.Table == "menu_bundles" {
+			bundleReads++
+			if bundleReads == 2 {
+				tx.AddError(errors.New("disk I/O error"))
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Callback().Query().Remove("test:fail_bundles")
+
+	body := exportMenus(t, db)
+	var menus []MenuExport
+	if err := json.Unmarshal(body, &menus); err == nil {
+		t.Fatalf("a failed export parsed as %d complete menus:\n%s", len(menus), body)
+	}
+	if !strings.Contains(string(body), `"error"`) {
+		t.Errorf("export body has no error marker:\n%s", body)
+	}
+}
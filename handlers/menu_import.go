@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/pos"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Most menu items a single import may create
+const maxImportItems = 500
+
+type POSImportRequest struct {
+	Format  string          `json:"format" binding:"required"`
+	Payload json.RawMessage `json:"payload" binding:"required" swaggertype:"object"`
+	// generic format only: menu item field → key in the payload objects
+	FieldMap map[string]string `json:"field_map"`
+}
+
+// validateImportedItem returns why an imported item can't be saved, or ""
+func validateImportedItem(item models.MenuItem) string {
+	switch {
+	case strings.TrimSpace(item.Name) == "":
+		return "name is required"
+	case item.Price <= 0:
+		return "price must be greater than 0"
+	case !validNutrition(item):
+		return "calories, protein_g, carbs_g and fat_g can't be negative"
+	}
+	return ""
+}
+
+// createMenuItems is the bulk-create path shared by menu imports: it saves
+// every item for the restaurant in one transaction
+func createMenuItems(db *gorm.DB, restaurantID uint, items []models.MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	for i := range items {
+		items[i].ID = 0
+		items[i].RestaurantID = restaurantID
+		items[i].IsAvailable = true
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&items).Error
+	})
+}
+
+// ImportPOSMenu imports menu items from a point-of-sale export. Items that
+// fail validation are skipped and reported; the rest are added.
+//
+// @Summary     Import menu items from a POS export
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  POSImportRequest  true  "format (square or generic), payload, and field_map for generic"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/import-pos [post]
+func ImportPOSMenu(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.create_restaurant_before_importing_menu")
+		c.Abort()
+		return
+	}
+	var req POSImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	importer, err := pos.GetImporter(req.Format)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, err.Error(), gin.H{"formats": pos.Formats()})
+		return
+	}
+	if generic, ok := importer.(*pos.GenericImporter); ok {
+		for field := range req.FieldMap {
+			if !containsString(pos.GenericFields, field) {
+				apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.unknown_field_in_field_map",
+					gin.H{"fields": pos.GenericFields}, field)
+				return
+			}
+		}
+		generic.FieldMap = req.FieldMap
+	}
+
+	items, err := importer.Import(req.Payload)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, err.Error(), nil)
+		return
+	}
+	if len(items) > maxImportItems {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest,
+			"errors.an_import_can_add_at_most_items", gin.H{"items": len(items)}, maxImportItems)
+		return
+	}
+
+	valid := make([]models.MenuItem, 0, len(items))
+	errs := []gin.H{}
+	for i, item := range items {
+		if reason := validateImportedItem(item); reason != "" {
+			errs = append(errs, gin.H{"index": i, "name": item.Name, "error": reason})
+			continue
+		}
+		valid = append(valid, item)
+	}
+	if err := createMenuItems(requestDB(c), restaurant.ID, valid); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_imported_items", nil)
+		return
+	}
+	if len(valid) > 0 {
+		invalidateMenuCache(restaurant.ID)
+	}
+	c.JSON(http.StatusOK, gin.H{"imported": len(valid), "errors": errs})
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
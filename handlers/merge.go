@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MergeUsersRequest struct {
+	KeepUserID   uint `json:"keep_user_id" binding:"required"`
+	DeleteUserID uint `json:"delete_user_id" binding:"required"`
+}
+
+// customerOwnedTables lists every table whose customer_id column moves to the kept account
+var customerOwnedTables = []string{
+	"reviews",
+	"delivery_subscriptions",
+	"reassignment_requests",
+	"restaurant_waitlists",
+	"recurring_orders",
+	"customer_addresses",
+	"eta_ratings",
+}
+
+// AdminMergeUsers folds a duplicate customer account into another and deletes it — admin only
+//
+// @Summary     Merge duplicate customer accounts
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  MergeUsersRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/users/merge [post]
+func AdminMergeUsers(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req MergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if req.KeepUserID == req.DeleteUserID {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.merge_user_ids_must_differ", nil)
+		return
+	}
+
+	var keep, dup models.User
+	if err := requestDB(c).First(&keep, req.KeepUserID).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_to_keep_not_found")
+		c.Abort()
+		return
+	}
+	if err := requestDB(c).First(&dup, req.DeleteUserID).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_to_delete_not_found")
+		c.Abort()
+		return
+	}
+	if apiErr := checkMergeable(keep, dup); apiErr != nil {
+		apierror.RespondError(c, apiErr)
+		return
+	}
+
+	summary := gin.H{}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		return mergeUsers(tx, adminID, keep, dup, summary)
+	})
+	if err != nil {
+		c.Error(err).SetMeta("errors.failed_to_merge_users")
+		c.Abort()
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Users merged", "summary": summary})
+}
+
+// checkMergeable reports why dup can't be merged into keep, if it can't
+func checkMergeable(keep, dup models.User) *apierror.Error {
+	if keep.Role != dup.Role {
+		return apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.cannot_merge_users_with_different_roles",
+			gin.H{"keep_role": keep.Role, "delete_role": dup.Role, "delete_user_id": dup.ID})
+	}
+	if keep.Role != models.RoleCustomer {
+		return apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.only_customer_accounts_can_be_merged", nil)
+	}
+	return nil
+}
+
+// mergeUsers moves everything dup owns to keep and deletes dup, inside the
+// caller's transaction, recording what moved in summary. Both must have
+// passed checkMergeable.
+func mergeUsers(tx *gorm.DB, adminID uint, keep, dup models.User, summary gin.H) error {
+	summary["keep_user_id"], summary["delete_user_id"] = keep.ID, dup.ID
+	res := tx.Model(&models.Order{}).Where("customer_id = ?", dup.ID).Update("customer_id", keep.ID)
+	if res.Error != nil {
+		return res.Error
+	}
+	summary["reassigned_orders"] = res.RowsAffected
+
+	if err := tx.Model(&models.OrderStatusHistory{}).Where("changed_by = ?", dup.ID).
+		Update("changed_by", keep.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.SupportMessage{}).Where("sender_id = ? AND sender_role = ?", dup.ID, models.RoleCustomer).
+		Update("sender_id", keep.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Notification{}).Where("user_id = ?", dup.ID).
+		Update("user_id", keep.ID).Error; err != nil {
+		return err
+	}
+
+	// Drop the duplicate's waitlist entries the kept account already has
+	if err := tx.Where("customer_id = ? AND notified_at IS NULL AND restaurant_id IN (?)", dup.ID,
+		tx.Model(&models.RestaurantWaitlist{}).Select("restaurant_id").
+			Where("customer_id = ? AND notified_at IS NULL", keep.ID)).
+		Delete(&models.RestaurantWaitlist{}).Error; err != nil {
+		return err
+	}
+	for _, table := range customerOwnedTables {
+		if err := tx.Table(table).Where("customer_id = ?", dup.ID).Update("customer_id", keep.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	// Referrals: the kept account inherits the people the duplicate referred, and
+	// the duplicate's own referral only if the kept account has none
+	if err := tx.Model(&models.Referral{}).Where("referrer_id = ?", dup.ID).
+		Update("referrer_id", keep.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.User{}).Where("referred_by_id = ?", dup.ID).
+		Update("referred_by_id", keep.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.ReferralLink{}).Where("user_id = ?", dup.ID).
+		Update("user_id", keep.ID).Error; err != nil {
+		return err
+	}
+	var keepReferred int64
+	tx.Model(&models.Referral{}).Where("referee_id = ?", keep.ID).Count(&keepReferred)
+	if keepReferred > 0 {
+		if err := tx.Where("referee_id = ?", dup.ID).Delete(&models.Referral{}).Error; err != nil {
+			return err
+		}
+	} else {
+		if err := tx.Model(&models.Referral{}).Where("referee_id = ?", dup.ID).
+			Update("referee_id", keep.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", keep.ID).
+			Update("referred_by_id", dup.ReferredByID).Error; err != nil {
+			return err
+		}
+	}
+	// A customer can't have referred themselves
+	if err := tx.Where("referrer_id = ? AND referee_id = ?", keep.ID, keep.ID).
+		Delete(&models.Referral{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.User{}).Where("id = ? AND referred_by_id = ?", keep.ID, keep.ID).
+		Update("referred_by_id", nil).Error; err != nil {
+		return err
+	}
+
+	// Dietary preferences are one row per customer — the kept account's row wins
+	var existing int64
+	tx.Model(&models.DietaryPreference{}).Where("customer_id = ?", keep.ID).Count(&existing)
+	if existing > 0 {
+		if err := tx.Where("customer_id = ?", dup.ID).Delete(&models.DietaryPreference{}).Error; err != nil {
+			return err
+		}
+	} else if err := tx.Model(&models.DietaryPreference{}).Where("customer_id = ?", dup.ID).
+		Update("customer_id", keep.ID).Error; err != nil {
+		return err
+	}
+
+	// Loyalty points are summed and the tier re-evaluated on the combined lifetime total
+	summary["merged_points"] = 0
+	var dupAccount models.LoyaltyAccount
+	if err := tx.Where("customer_id = ?", dup.ID).First(&dupAccount).Error; err == nil {
+		keepAccount, err := loyaltyAccount(tx, keep.ID)
+		if err != nil {
+			return err
+		}
+		keepAccount.Points += dupAccount.Points
+		keepAccount.LifetimePoints += dupAccount.LifetimePoints
+		keepAccount.Tier = tierFor(keepAccount.LifetimePoints)
+		if err := tx.Save(&keepAccount).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&dupAccount).Error; err != nil {
+			return err
+		}
+		summary["merged_points"] = dupAccount.Points
+	}
+
+	if err := tx.Where("user_id = ?", dup.ID).Delete(&models.TokenIssue{}).Error; err != nil {
+		return err
+	}
+
+	// Hard delete: AuthRequired rejects tokens for users that no longer exist
+	if err := tx.Delete(&dup).Error; err != nil {
+		return err
+	}
+	// With its last email twin gone the kept account joins the case-insensitive unique index
+	if err := tx.Model(&models.User{}).
+		Where("id = ? AND duplicate_email = ? AND NOT EXISTS (SELECT 1 FROM users twin WHERE LOWER(twin.email) = LOWER(users.email) AND twin.id <> users.id)", keep.ID, true).
+		Update("duplicate_email", false).Error; err != nil {
+		return err
+	}
+	return logMaintenance(tx, MaintenanceMergeUsers, &adminID, gin.H{
+		"keep_user_id":      keep.ID,
+		"delete_user_id":    dup.ID,
+		"deleted_email":     dup.Email,
+		"reassigned_orders": summary["reassigned_orders"],
+		"merged_points":     summary["merged_points"],
+	})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	broadcastBatchSize  = 100
+	broadcastMaxWorkers = 10
+)
+
+type BroadcastRequest struct {
+	TargetRole models.UserRole `json:"target_role" binding:"required"`
+	Title      string          `json:"title" binding:"required"`
+	Body       string          `json:"body" binding:"required"`
+	Channel    string          `json:"channel"`
+}
+
+// AdminBroadcast queues a notification to every user of a role — admin only.
+// The log entry is returned immediately; sends happen in the background.
+//
+// @Summary     Broadcast a notification to every user of a role
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  BroadcastRequest  true  "Request body"
+// @Success     202  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/notifications/broadcast [post]
+func AdminBroadcast(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+
+	var req BroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	validRoles := map[models.UserRole]bool{
+		models.RoleCustomer:   true,
+		models.RoleRestaurant: true,
+		models.RoleDriver:     true,
+		models.RoleAdmin:      true,
+	}
+	if !validRoles[req.TargetRole] {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_target_role", nil)
+		return
+	}
+	if req.Channel == "" {
+		req.Channel = notify.ChannelEmail
+	}
+	if !notify.ValidChannel(req.Channel) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_channel", nil)
+		return
+	}
+
+	var recipientCount int64
+	requestDB(c).Model(&models.User{}).Where("role = ?", req.TargetRole).Count(&recipientCount)
+
+	entry := models.BroadcastLog{
+		AdminID:        adminID,
+		TargetRole:     req.TargetRole,
+		Channel:        req.Channel,
+		Title:          req.Title,
+		Body:           req.Body,
+		RecipientCount: int(recipientCount),
+	}
+	if err := requestDB(c).Create(&entry).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_broadcast", nil)
+		return
+	}
+
+	go runBroadcast(entry)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Broadcast queued", "broadcast": entry})
+}
+
+// runBroadcast sends a broadcast in batches through a bounded worker pool
+// and records the final success/error counts on the log entry.
+func runBroadcast(entry models.BroadcastLog) {
+	var sent, failed int64
+
+	var batch []models.User
+	config.DB.Where("role = ?", entry.TargetRole).
+		FindInBatches(&batch, broadcastBatchSize, func(_ *gorm.DB, _ int) error {
+			jobs := make(chan models.User)
+			var wg sync.WaitGroup
+			for w := 0; w < broadcastMaxWorkers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for u := range jobs {
+						err := notify.Default.Send(notify.Message{
+							UserID:  u.ID,
+							Email:   u.Email,
+							Phone:   u.Phone,
+							Channel: entry.Channel,
+							Title:   entry.Title,
+							Body:    entry.Body,
+
+							EventType:     "broadcast",
+							ReferenceID:   entry.ID,
+							ReferenceType: "broadcast",
+						})
+						if err != nil {
+							atomic.AddInt64(&failed, 1)
+							log.Printf("broadcast %d: failed to notify user %d: %v", entry.ID, u.ID, err)
+							continue
+						}
+						atomic.AddInt64(&sent, 1)
+					}
+				}()
+			}
+			for _, u := range batch {
+				jobs <- u
+			}
+			close(jobs)
+			wg.Wait()
+			return nil
+		})
+
+	now := time.Now()
+	config.DB.Model(&entry).Updates(map[string]interface{}{
+		"recipient_count": sent + failed,
+		"success_count":   sent,
+		"errors":          failed,
+		"sent_at":         now,
+	})
+}
+
+// AdminGetBroadcastHistory lists past broadcasts with their delivery counts — admin only
+//
+// @Summary     List past broadcasts
+// @Tags        admin
+// @Produce     json
+// @Param       target_role  query  string  false  "Filter by target role"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/notifications/broadcast-history [get]
+func AdminGetBroadcastHistory(c *gin.Context) {
+	var logs []models.BroadcastLog
+	query := requestDB(c)
+	if role := c.Query("target_role"); role != "" {
+		query = query.Where("target_role = ?", role)
+	}
+	query.Order("created_at desc").Find(&logs)
+	c.JSON(http.StatusOK, gin.H{"count": len(logs), "broadcasts": logs})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// NutritionalSummary totals the nutrition of an order's items, per serving
+// times quantity. Only items with calorie data count; the others are listed.
+type NutritionalSummary struct {
+	TotalCalories    int      `json:"total_calories"`
+	TotalProteinG    float64  `json:"total_protein_g"`
+	TotalCarbsG      float64  `json:"total_carbs_g"`
+	TotalFatG        float64  `json:"total_fat_g"`
+	ItemsWithoutData []string `json:"items_without_data,omitempty"`
+	Note             string   `json:"note,omitempty"`
+}
+
+// nutritionUpdate validates the nutrition fields of a menu item update into
+// update. Each may be null to clear it.
+func nutritionUpdate(req map[string]interface{}, update map[string]interface{}) *apierror.Error {
+	if v, ok := req["calories"]; ok {
+		n, isNum := v.(float64)
+		switch {
+		case v == nil:
+			update["calories"] = nil
+		case !isNum || n < 0 || n != float64(int(n)):
+			return apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.calories_must_be_a_whole_number", nil)
+		default:
+			update["calories"] = int(n)
+		}
+	}
+	for _, field := range []string{"protein_g", "carbs_g", "fat_g"} {
+		v, ok := req[field]
+		if !ok {
+			continue
+		}
+		n, isNum := v.(float64)
+		switch {
+		case v == nil:
+			update[field] = nil
+		case !isNum || n < 0:
+			return apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.field_must_be_a_number_of_at_least_0", nil, field)
+		default:
+			update[field] = n
+		}
+	}
+	return nil
+}
+
+// validNutrition reports whether none of an item's nutrition values is negative
+func validNutrition(item models.MenuItem) bool {
+	if item.Calories != nil && *item.Calories < 0 {
+		return false
+	}
+	for _, v := range []*float64{item.ProteinG, item.CarbsG, item.FatG} {
+		if v != nil && *v < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// orderNutrition sums the nutrition of an order's items from their menu items
+func orderNutrition(db *gorm.DB, order *models.Order) NutritionalSummary {
+	ids := make([]uint, len(order.Items))
+	for i, item := range order.Items {
+		ids[i] = item.MenuItemID
+	}
+	var menuItems []models.MenuItem
+	db.Select("id, calories, protein_g, carbs_g, fat_g").Where("id IN ?", ids).Find(&menuItems)
+	byID := make(map[uint]models.MenuItem, len(menuItems))
+	for _, m := range menuItems {
+		byID[m.ID] = m
+	}
+
+	var summary NutritionalSummary
+	for _, item := range order.Items {
+		m, ok := byID[item.MenuItemID]
+		if !ok || m.Calories == nil {
+			summary.ItemsWithoutData = append(summary.ItemsWithoutData, item.Name)
+			continue
+		}
+		qty := float64(item.Quantity)
+		summary.TotalCalories += *m.Calories * item.Quantity
+		if m.ProteinG != nil {
+			summary.TotalProteinG += *m.ProteinG * qty
+		}
+		if m.CarbsG != nil {
+			summary.TotalCarbsG += *m.CarbsG * qty
+		}
+		if m.FatG != nil {
+			summary.TotalFatG += *m.FatG * qty
+		}
+	}
+	summary.TotalProteinG = math.Round(summary.TotalProteinG*10) / 10
+	summary.TotalCarbsG = math.Round(summary.TotalCarbsG*10) / 10
+	summary.TotalFatG = math.Round(summary.TotalFatG*10) / 10
+	if n := len(summary.ItemsWithoutData); n > 0 {
+		summary.Note = fmt.Sprintf("%d of %d items have no nutritional data and are left out of the totals", n, len(order.Items))
+	}
+	return summary
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"food-delivery-api/models"
+)
+
+func intPtr(n int) *int           { return &n }
+func floatPtr(f float64) *float64 { return &f }
+
+func TestOrderNutritionTotalsItemsWithData(t *testing.T) {
+	db := newTestDB(t)
+	_, items := createRestaurant(t, db,
+		models.MenuItem{Name: "Paneer", Price: 200, Calories: intPtr(450), ProteinG: floatPtr(20.25), CarbsG: floatPtr(12), FatG: floatPtr(30.5)},
+		models.MenuItem{Name: "Naan", Price: 30, Calories: intPtr(260), CarbsG: floatPtr(45.1)},
+		models.MenuItem{Name: "Lassi", Price: 60},
+	)
+	order := models.Order{Items: []models.OrderItem{
+		{MenuItemID: items[0].ID, Name: "Paneer", Quantity: 2},
+		{MenuItemID: items[1].ID, Name: "Naan", Quantity: 3},
+		{MenuItemID: items[2].ID, Name: "Lassi", Quantity: 1},
+	}}
+
+	got := orderNutrition(db, &order)
+
+	if got.TotalCalories != 2*450+3*260 {
+		t.Errorf("calories = %d, want %d", got.TotalCalories, 2*450+3*260)
+	}
+	// Per serving times quantity, rounded to one decimal; missing macros count as 0
+	if got.TotalProteinG != 40.5 || got.TotalCarbsG != 159.3 || got.TotalFatG != 61 {
+		t.Errorf("protein %v, carbs %v, fat %v; want 40.5, 159.3, 61", got.TotalProteinG, got.TotalCarbsG, got.TotalFatG)
+	}
+	if len(got.ItemsWithoutData) != 1 || got.ItemsWithoutData[0] != "Lassi" {
+		t.Errorf("items without data = %v, want [Lassi]", got.ItemsWithoutData)
+	}
+	if got.Note != "1 of 3 items have no nutritional data and are left out of the totals" {
+		t.Errorf("note = %q", got.Note)
+	}
+
+	full := models.Order{Items: order.Items[:2]}
+	if got := orderNutrition(db, &full); got.ItemsWithoutData != nil || got.Note != "" {
+		t.Errorf("every item has data, but got %v and note %q", got.ItemsWithoutData, got.Note)
+	}
+}
+
+func TestDietaryPreferencesDiets(t *testing.T) {
+	db := newTestDB(t)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	update := func(body string) map[string][]string {
+		t.Helper()
+		w := serve(UpdateDietaryPreferences, "/prefs", customer.ID, models.RoleCustomer, http.MethodPut, "/prefs", body)
+		wantStatus(t, w, http.StatusOK)
+		var resp map[string][]string
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	if got := update(`{"diets":[" Calorie_Conscious "]}`); len(got["diets"]) != 1 || got["diets"][0] != models.DietCalorieConscious {
+		t.Errorf("diets = %v, want [%s]", got["diets"], models.DietCalorieConscious)
+	}
+	if !followsDiet(db, customer.ID, models.DietCalorieConscious) {
+		t.Error("saved diet not followed")
+	}
+	// Omitting diets leaves them as saved
+	if got := update(`{"allergens":[]}`); len(got["diets"]) != 1 {
+		t.Errorf("diets = %v after an update without them, want them kept", got["diets"])
+	}
+
+	w := serve(UpdateDietaryPreferences, "/prefs", customer.ID, models.RoleCustomer, http.MethodPut, "/prefs", `{"diets":["keto"]}`)
+	wantStatus(t, w, http.StatusBadRequest)
+	if !followsDiet(db, customer.ID, models.DietCalorieConscious) {
+		t.Error("a rejected update changed the saved diets")
+	}
+
+	if got := update(`{"diets":[]}`); len(got["diets"]) != 0 {
+		t.Errorf("diets = %v after clearing, want []", got["diets"])
+	}
+	if followsDiet(db, customer.ID, models.DietCalorieConscious) {
+		t.Error("cleared diet still followed")
+	}
+}
+
+func TestPlaceOrderSummarisesNutritionForCalorieConsciousCustomers(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, items := createRestaurant(t, db, models.MenuItem{Name: "Paneer", Price: 200, Calories: intPtr(450)})
+	body := fmt.Sprintf(`{"restaurant_id":%d,"delivery_address":"2 Low St","items":[{"menu_item_id":%d,"quantity":2}]}`,
+		restaurant.ID, items[0].ID)
+	for _, calorieConscious := range []bool{false, true} {
+		name := fmt.Sprintf("Customer%v", calorieConscious)
+		customer := createUser(t, db, name, models.RoleCustomer)
+		if calorieConscious {
+			db.Create(&models.DietaryPreference{CustomerID: customer.ID, Diets: models.DietCalorieConscious})
+		}
+
+		w := serve(PlaceOrder, "/orders", customer.ID, models.RoleCustomer, http.MethodPost, "/orders", body)
+		wantStatus(t, w, http.StatusCreated)
+		var resp struct {
+			Summary *NutritionalSummary `json:"nutritional_summary"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		switch {
+		case calorieConscious && (resp.Summary == nil || resp.Summary.TotalCalories != 900):
+			t.Errorf("calorie-conscious customer got summary %+v, want 900 calories", resp.Summary)
+		case !calorieConscious && resp.Summary != nil:
+			t.Errorf("customer without the diet got a nutritional summary %+v", resp.Summary)
+		}
+	}
+}
+
+func TestGetMenuMaxCalories(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db,
+		models.MenuItem{Name: "Salad", Price: 100, Calories: intPtr(300)},
+		models.MenuItem{Name: "Soup", Price: 80, Calories: intPtr(500)},
+		models.MenuItem{Name: "Biryani", Price: 250, Calories: intPtr(900)},
+		models.MenuItem{Name: "Special", Price: 300},
+	)
+	menu := func(query string) []string {
+		t.Helper()
+		w := serve(GetMenu, "/restaurants/:id/menu", 0, "", http.MethodGet,
+			fmt.Sprintf("/restaurants/%d/menu%s", restaurant.ID, query), "")
+		wantStatus(t, w, http.StatusOK)
+		var resp struct {
+			Menu []models.MenuItem `json:"menu"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		names := make([]string, len(resp.Menu))
+		for i, item := range resp.Menu {
+			names[i] = item.Name
+		}
+		return names
+	}
+
+	// Items without calorie data can't be shown to be under the limit
+	if got := fmt.Sprint(menu("?max_calories=500")); got != "[Salad Soup]" {
+		t.Errorf("max_calories=500 menu = %s, want [Salad Soup]", got)
+	}
+	if got := fmt.Sprint(menu("?max_calories=0")); got != "[]" {
+		t.Errorf("max_calories=0 menu = %s, want []", got)
+	}
+	// Cached per limit, so a different limit isn't served the first one's menu
+	if got := len(menu("")); got != 4 {
+		t.Errorf("unfiltered menu has %d items, want 4", got)
+	}
+
+	for _, bad := range []string{"-1", "abc", "1.5"} {
+		w := serve(GetMenu, "/restaurants/:id/menu", 0, "", http.MethodGet,
+			fmt.Sprintf("/restaurants/%d/menu?max_calories=%s", restaurant.ID, bad), "")
+		wantStatus(t, w, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const operatingHoursCheckInterval = time.Minute
+
+// schedulerState is what GET /admin/scheduler/status reports about the last tick
+var schedulerState struct {
+	sync.Mutex
+	lastTick    time.Time
+	restaurants int
+	opened      int
+	closed      int
+	skipped     int
+}
+
+type OperatingHoursEntry struct {
+	DayOfWeek int    `json:"day_of_week" binding:"min=0,max=6"` // 0 = Sunday
+	OpensAt   string `json:"opens_at" binding:"required"`
+	ClosesAt  string `json:"closes_at" binding:"required"`
+}
+
+type SetOperatingHoursRequest struct {
+	Hours []OperatingHoursEntry `json:"hours" binding:"required,dive"`
+}
+
+// minutesOfDay parses "HH:MM" into minutes since midnight
+func minutesOfDay(hhmm string) (int, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid HH:MM time", hhmm)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// withinOperatingHours reports whether now falls inside any of the windows.
+// A window that closes earlier than it opens runs past midnight into the next day.
+func withinOperatingHours(hours []models.OperatingHours, now time.Time) bool {
+	today := int(now.Weekday())
+	yesterday := (today + 6) % 7
+	current := now.Hour()*60 + now.Minute()
+	for _, h := range hours {
+		opens, err1 := minutesOfDay(h.OpensAt)
+		closes, err2 := minutesOfDay(h.ClosesAt)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		overnight := closes <= opens
+		switch {
+		case h.DayOfWeek == today && !overnight && current >= opens && current < closes:
+			return true
+		case h.DayOfWeek == today && overnight && current >= opens:
+			return true
+		case h.DayOfWeek == yesterday && overnight && current < closes:
+			return true
+		}
+	}
+	return false
+}
+
+// StartOperatingHoursScheduler opens and closes restaurants according to
+// their operating hours, once a minute.
+func StartOperatingHoursScheduler() {
+	go func() {
+		for range time.Tick(operatingHoursCheckInterval) {
+			runOperatingHoursScheduler(time.Now())
+		}
+	}()
+}
+
+func runOperatingHoursScheduler(now time.Time) {
+	var hours []models.OperatingHours
+	config.DB.Find(&hours)
+	byRestaurant := map[uint][]models.OperatingHours{}
+	for _, h := range hours {
+		byRestaurant[h.RestaurantID] = append(byRestaurant[h.RestaurantID], h)
+	}
+
+	opened, closed, skipped := 0, 0, 0
+	for restaurantID, windows := range byRestaurant {
+		var restaurant models.Restaurant
+		if err := config.DB.Where("is_active = ?", true).First(&restaurant, restaurantID).Error; err != nil {
+			continue
+		}
+		// A manual open/close pins the restaurant until the override expires
+		if restaurant.ManualOverrideUntil != nil && restaurant.ManualOverrideUntil.After(now) {
+			skipped++
+			continue
+		}
+		// Holiday closures take orders off without flipping is_open, so leave it be
+		if _, closed := activeClosure(config.DB, restaurantID, now); closed {
+			skipped++
+			continue
+		}
+		shouldOpen := withinOperatingHours(windows, now)
+		if shouldOpen == restaurant.IsOpen {
+			continue
+		}
+		setRestaurantOpen(&restaurant, shouldOpen, 0, "operating hours")
+		if shouldOpen {
+			opened++
+		} else {
+			closed++
+		}
+	}
+
+	schedulerState.Lock()
+	schedulerState.lastTick = now
+	schedulerState.restaurants = len(byRestaurant)
+	schedulerState.opened = opened
+	schedulerState.closed = closed
+	schedulerState.skipped = skipped
+	schedulerState.Unlock()
+}
+
+// SetOperatingHours replaces the restaurant's weekly operating hours
+//
+// @Summary     Set my restaurant's operating hours
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  SetOperatingHoursRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/operating-hours [put]
+func SetOperatingHours(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	var req SetOperatingHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	seen := map[int]bool{}
+	hours := make([]models.OperatingHours, 0, len(req.Hours))
+	for _, h := range req.Hours {
+		if seen[h.DayOfWeek] {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.each_day_of_week_may_appear_only_once",
+				gin.H{"day_of_week": h.DayOfWeek})
+			return
+		}
+		seen[h.DayOfWeek] = true
+		opens, err := minutesOfDay(h.OpensAt)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, err.Error(), nil)
+			return
+		}
+		closes, err := minutesOfDay(h.ClosesAt)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, err.Error(), nil)
+			return
+		}
+		if opens == closes {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.opens_at_and_closes_at_must_differ",
+				gin.H{"day_of_week": h.DayOfWeek})
+			return
+		}
+		hours = append(hours, models.OperatingHours{
+			RestaurantID: restaurant.ID,
+			DayOfWeek:    h.DayOfWeek,
+			OpensAt:      h.OpensAt,
+			ClosesAt:     h.ClosesAt,
+		})
+	}
+
+	tx := requestDB(c).Begin()
+	tx.Where("restaurant_id = ?", restaurant.ID).Delete(&models.OperatingHours{})
+	if len(hours) > 0 {
+		if err := tx.Create(&hours).Error; err != nil {
+			tx.Rollback()
+			apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_operating_hours", nil)
+			return
+		}
+	}
+	tx.Commit()
+	c.JSON(http.StatusOK, gin.H{"message": "Operating hours updated", "hours": hours})
+}
+
+// GetOperatingHours lists the restaurant's operating hours and recent open/close changes
+//
+// @Summary     Get my restaurant's operating hours
+// @Tags        restaurant
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/operating-hours [get]
+func GetOperatingHours(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := managedRestaurant(requestDB(c), userID, &restaurant); err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.restaurant_not_found", nil)
+		return
+	}
+	var hours []models.OperatingHours
+	requestDB(c).Where("restaurant_id = ?", restaurant.ID).Order("day_of_week").Find(&hours)
+	var logs []models.RestaurantStatusLog
+	requestDB(c).Where("restaurant_id = ?", restaurant.ID).Order("created_at DESC").Limit(20).Find(&logs)
+	c.JSON(http.StatusOK, gin.H{
+		"hours":                 hours,
+		"is_open":               restaurant.IsOpen,
+		"manual_override_until": restaurant.ManualOverrideUntil,
+		"status_log":            logs,
+	})
+}
+
+// AdminGetSchedulerStatus reports when the operating-hours scheduler last ran and what it did
+//
+// @Summary     Operating-hours scheduler status
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/scheduler/status [get]
+func AdminGetSchedulerStatus(c *gin.Context) {
+	schedulerState.Lock()
+	defer schedulerState.Unlock()
+	var lastTick *time.Time
+	if !schedulerState.lastTick.IsZero() {
+		t := schedulerState.lastTick
+		lastTick = &t
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"last_tick":        lastTick,
+		"interval_seconds": int(operatingHoursCheckInterval.Seconds()),
+		"restaurants":      schedulerState.restaurants,
+		"opened":           schedulerState.opened,
+		"closed":           schedulerState.closed,
+		"skipped_override": schedulerState.skipped,
+	})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Maintenance actions for soft-deleting orders
+const (
+	MaintenanceOrderDeleted  = "ORDER_DELETED"
+	MaintenanceOrderRestored = "ORDER_RESTORED"
+)
+
+type DeleteOrderRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// AdminDeleteOrder soft-deletes a delivered or cancelled order and its items,
+// e.g. a duplicate. The rows stay in the database and can be restored;
+// customers get a 404 for the order meanwhile. Active orders are refused:
+// their restaurant and driver still need them, so cancel them first. Admin
+// only.
+//
+// @Summary     Soft-delete an order
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                 true   "Order ID"
+// @Param       body  body  DeleteOrderRequest  false  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders/{id} [delete]
+func AdminDeleteOrder(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req DeleteOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+
+	finished := []models.OrderStatus{models.StatusDelivered, models.StatusCancelled}
+	if order.Status != models.StatusDelivered && order.Status != models.StatusCancelled {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.only_finished_orders_can_be_deleted",
+			gin.H{"status": order.Status, "allowed_statuses": finished})
+		return
+	}
+
+	var items int64
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		// An admin may have forced the order back into play since it was read
+		res := tx.Where("status IN ?", finished).Delete(&order)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.order_modified_concurrently", nil)
+		}
+		res = tx.Where("order_id = ?", order.ID).Delete(&models.OrderItem{})
+		if res.Error != nil {
+			return res.Error
+		}
+		items = res.RowsAffected
+		return logMaintenance(tx, MaintenanceOrderDeleted, &adminID, gin.H{
+			"order_id": order.ID,
+			"status":   order.Status,
+			"items":    items,
+			"reason":   req.Reason,
+		})
+	})
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_delete_order", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Order deleted; restore it with PUT /api/admin/orders/:id/restore",
+		"order_id":      order.ID,
+		"items_deleted": items,
+	})
+}
+
+// AdminRestoreOrder brings back a soft-deleted order and its items — admin only
+//
+// @Summary     Restore a soft-deleted order
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/orders/{id}/restore [put]
+func AdminRestoreOrder(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var order models.Order
+	if err := requestDB(c).Unscoped().First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if !order.DeletedAt.Valid {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.order_is_not_deleted", nil)
+		return
+	}
+
+	deletedAt := order.DeletedAt.Time
+	var items int64
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Model(&models.OrderItem{}).
+			Where("order_id = ? AND deleted_at IS NOT NULL", order.ID).
+			Update("deleted_at", nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		items = res.RowsAffected
+		if err := tx.Unscoped().Model(&order).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return logMaintenance(tx, MaintenanceOrderRestored, &adminID, gin.H{
+			"order_id":   order.ID,
+			"status":     order.Status,
+			"items":      items,
+			"deleted_at": deletedAt,
+		})
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_restore_order", nil)
+		return
+	}
+	requestDB(c).Preload("Items").First(&order, order.ID)
+	order.Links = orderLinks(c, &order)
+	c.JSON(http.StatusOK, gin.H{"message": "Order restored", "order": order})
+}
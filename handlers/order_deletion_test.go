@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"food-delivery-api/models"
+)
+
+func TestAdminDeleteOrderOnlyDeletesFinishedOrders(t *testing.T) {
+	tests := []struct {
+		status models.OrderStatus
+		want   int
+	}{
+		{models.StatusPlaced, http.StatusConflict},
+		{models.StatusPreparing, http.StatusConflict},
+		{models.StatusPickedUp, http.StatusConflict},
+		{models.StatusDelivered, http.StatusOK},
+		{models.StatusCancelled, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			db := newTestDB(t)
+			restaurant, _ := createRestaurant(t, db)
+			order := unpaidOrder(t, db, restaurant, createUser(t, db, "Asha", models.RoleCustomer))
+			db.Model(&order).Update("status", tt.status)
+			db.Create(&models.OrderItem{OrderID: order.ID, MenuItemID: 1, Quantity: 1, Price: 5})
+			admin := createUser(t, db, "Admin", models.RoleAdmin)
+
+			w := serve(AdminDeleteOrder, "/admin/orders/:id", admin.ID, models.RoleAdmin, http.MethodDelete,
+				fmt.Sprintf("/admin/orders/%d", order.ID), "")
+			wantStatus(t, w, tt.want)
+
+			var orders, items int64
+			db.Model(&models.Order{}).Where("id = ?", order.ID).Count(&orders)
+			db.Model(&models.OrderItem{}).Where("order_id = ?", order.ID).Count(&items)
+			wantLeft := int64(1)
+			if tt.want == http.StatusOK {
+				wantLeft = 0
+			}
+			if orders != wantLeft || items != wantLeft {
+				t.Errorf("%d order(s) and %d item(s) visible, want %d of each", orders, items, wantLeft)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"food-delivery-api/eventbus"
+	"food-delivery-api/models"
+)
+
+// publishOrderPlaced announces a new order on the event bus
+func publishOrderPlaced(order models.Order) {
+	eventbus.Default.Publish(eventbus.OrderPlaced, eventbus.OrderEvent{Order: order, To: models.StatusPlaced})
+}
+
+// publishStatusChange announces an order transition on the event bus, followed
+// by order.delivered when the order has just been delivered
+func publishStatusChange(order models.Order, from, to models.OrderStatus) {
+	event := eventbus.OrderEvent{Order: order, From: from, To: to}
+	eventbus.Default.Publish(eventbus.OrderStatusChanged, event)
+	if to == models.StatusDelivered {
+		eventbus.Default.Publish(eventbus.OrderDelivered, event)
+	}
+}
+
+// PushOrderEvent forwards order events to live SSE subscribers
+func PushOrderEvent(payload interface{}) {
+	if e, ok := payload.(eventbus.OrderEvent); ok {
+		publishTransition(e.Order, e.From, e.To)
+	}
+}
+
+// AwardLoyaltyOnDelivery earns the customer loyalty points for a delivered order
+func AwardLoyaltyOnDelivery(payload interface{}) {
+	if e, ok := payload.(eventbus.OrderEvent); ok {
+		awardLoyaltyPoints(e.Order)
+	}
+}
+
+// AwardReferralOnDelivery pays out a pending referral once the referee's order is delivered
+func AwardReferralOnDelivery(payload interface{}) {
+	if e, ok := payload.(eventbus.OrderEvent); ok {
+		awardReferralBonus(e.Order)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MaintenanceOrderTotalCorrected is logged for each order whose total was repaired
+const MaintenanceOrderTotalCorrected = "ORDER_TOTAL_CORRECTED"
+
+const (
+	orderTotalsBatchSize   = 500
+	maxReportedDivergences = 100 // dry runs list at most this many orders
+)
+
+type orderTotalDivergence struct {
+	OrderID    uint    `json:"order_id"`
+	Stored     float64 `json:"stored_total"`
+	Recomputed float64 `json:"recomputed_total"`
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// AdminRecalculateOrderTotals recomputes every non-cancelled order's total from
+// its item snapshots and fees, and repairs the ones that differ — admin only.
+// Item prices are never touched. With ?dry_run=true nothing is written and the
+// divergent orders are listed instead.
+//
+// @Summary     Recalculate and repair order totals
+// @Tags        admin
+// @Produce     json
+// @Param       dry_run  query  bool  false  "Only report divergent orders"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     500  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/maintenance/recalculate-order-totals [post]
+func AdminRecalculateOrderTotals(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	dryRun := c.Query("dry_run") == "true"
+
+	var checked, corrected int
+	var maxDivergence float64
+	divergent := []orderTotalDivergence{}
+	// Each batch repeats the same queries, which is not an N+1
+	db := config.DB.WithContext(config.WithBatchQueries(c.Request.Context()))
+	var orders []models.Order
+	res := db.Where("status <> ?", models.StatusCancelled).Order("id").
+		FindInBatches(&orders, orderTotalsBatchSize, func(_ *gorm.DB, _ int) error {
+			ids := make([]uint, len(orders))
+			for i, o := range orders {
+				ids[i] = o.ID
+			}
+			// Undelivered items were refunded by a partial delivery, so they don't count
+			var sums []struct {
+				OrderID  uint
+				Subtotal float64
+			}
+			if err := db.Model(&models.OrderItem{}).
+				Select("order_id, SUM(price * quantity) AS subtotal").
+				Where("order_id IN ? AND was_delivered = ?", ids, true).
+				Group("order_id").
+				Scan(&sums).Error; err != nil {
+				return err
+			}
+			subtotals := make(map[uint]float64, len(sums))
+			for _, s := range sums {
+				subtotals[s.OrderID] = s.Subtotal
+			}
+
+			checked += len(orders)
+			var fixes []orderTotalDivergence
+			rates := map[uint]float64{}
+			for _, o := range orders {
+				want := roundCents(subtotals[o.ID] + o.DeliveryFee + o.ServiceFee + o.TipAmount)
+				diff := math.Abs(want - roundCents(o.TotalPrice))
+				if diff < 0.005 {
+					continue
+				}
+				maxDivergence = math.Max(maxDivergence, diff)
+				corrected++
+				fix := orderTotalDivergence{OrderID: o.ID, Stored: o.TotalPrice, Recomputed: want}
+				if len(divergent) < maxReportedDivergences {
+					divergent = append(divergent, fix)
+				}
+				fixes = append(fixes, fix)
+				rates[o.ID] = o.ExchangeRate
+			}
+			if dryRun || len(fixes) == 0 {
+				return nil
+			}
+			return db.Transaction(func(tx *gorm.DB) error {
+				for _, fix := range fixes {
+					if err := tx.Model(&models.Order{}).Where("id = ?", fix.OrderID).Updates(map[string]interface{}{
+						"total_price":      fix.Recomputed,
+						"total_price_base": toBase(fix.Recomputed, rates[fix.OrderID]),
+					}).Error; err != nil {
+						return err
+					}
+					if err := logMaintenance(tx, MaintenanceOrderTotalCorrected, &adminID, gin.H{
+						"order_id":  fix.OrderID,
+						"old_total": fix.Stored,
+						"new_total": fix.Recomputed,
+					}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+	if res.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_recalculate_order_totals",
+			gin.H{"checked": checked, "corrected": corrected})
+		return
+	}
+
+	resp := gin.H{
+		"dry_run":        dryRun,
+		"checked":        checked,
+		"corrected":      corrected,
+		"max_divergence": roundCents(maxDivergence),
+	}
+	if dryRun {
+		resp["divergent_orders"] = divergent
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxPairingSuggestions caps the suggestions returned with a placed order
+const maxPairingSuggestions = 3
+
+type PairingRequest struct {
+	PairsWithItemID uint   `json:"pairs_with_item_id" binding:"required"`
+	PairingNote     string `json:"pairing_note" binding:"max=200"` // ignored when removing
+}
+
+// PairingSuggestion is an item recommended alongside what's already in the cart
+type PairingSuggestion struct {
+	AddItemID    uint    `json:"add_item_id"`
+	AddItemName  string  `json:"add_item_name"`
+	AddItemPrice float64 `json:"add_item_price"`
+	PairingNote  string  `json:"pairing_note"`
+}
+
+// pairKey orders two item IDs the way a MenuItemPairing stores them
+func pairKey(a, b uint) (uint, uint) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// pairingsOf returns the pairings touching any of the given items, oldest first
+func pairingsOf(db *gorm.DB, itemIDs []uint) []models.MenuItemPairing {
+	var pairings []models.MenuItemPairing
+	if len(itemIDs) == 0 {
+		return pairings
+	}
+	db.Where("item_a_id IN ? OR item_b_id IN ?", itemIDs, itemIDs).Order("id").Find(&pairings)
+	return pairings
+}
+
+// pairedItems loads the orderable menu items among ids, keyed by ID
+func pairedItems(db *gorm.DB, ids []uint) map[uint]models.MenuItem {
+	byID := map[uint]models.MenuItem{}
+	if len(ids) == 0 {
+		return byID
+	}
+	var items []models.MenuItem
+	db.Where("id IN ? AND is_available = ? AND eighty_sixed_at IS NULL", ids, true).Find(&items)
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	return byID
+}
+
+// attachPairings fills RecommendedPairings on each menu item with two
+// queries, whatever the number of items. Unavailable and 86'd partners are
+// left out.
+func attachPairings(db *gorm.DB, items []models.MenuItem) {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	pairings := pairingsOf(db, ids)
+	partnerIDs := make([]uint, 0, 2*len(pairings))
+	for _, p := range pairings {
+		partnerIDs = append(partnerIDs, p.ItemAID, p.ItemBID)
+	}
+	partners := pairedItems(db, partnerIDs)
+
+	index := map[uint]int{}
+	for i := range items {
+		index[items[i].ID] = i
+	}
+	link := func(from, to uint, note string) {
+		i, ok := index[from]
+		partner, available := partners[to]
+		if !ok || !available {
+			return
+		}
+		partner.PairingNote = note
+		items[i].RecommendedPairings = append(items[i].RecommendedPairings, partner)
+	}
+	for _, p := range pairings {
+		link(p.ItemAID, p.ItemBID, p.PairingNote)
+		link(p.ItemBID, p.ItemAID, p.PairingNote)
+	}
+}
+
+// pairingSuggestions recommends up to three orderable items that pair with
+// the order's items but aren't in it yet
+func pairingSuggestions(db *gorm.DB, order *models.Order) []PairingSuggestion {
+	inCart := map[uint]bool{}
+	cartIDs := make([]uint, 0, len(order.Items))
+	for _, item := range order.Items {
+		if !inCart[item.MenuItemID] {
+			inCart[item.MenuItemID] = true
+			cartIDs = append(cartIDs, item.MenuItemID)
+		}
+	}
+
+	pairings := pairingsOf(db, cartIDs)
+	var candidateIDs []uint
+	notes := map[uint]string{}
+	for _, p := range pairings {
+		for _, id := range []uint{p.ItemAID, p.ItemBID} {
+			if _, seen := notes[id]; !seen && !inCart[id] {
+				notes[id] = p.PairingNote
+				candidateIDs = append(candidateIDs, id)
+			}
+		}
+	}
+	candidates := pairedItems(db, candidateIDs)
+
+	suggestions := []PairingSuggestion{}
+	for _, id := range candidateIDs {
+		item, ok := candidates[id]
+		if !ok {
+			continue
+		}
+		suggestions = append(suggestions, PairingSuggestion{
+			AddItemID:    item.ID,
+			AddItemName:  item.Name,
+			AddItemPrice: item.Price,
+			PairingNote:  notes[id],
+		})
+		if len(suggestions) == maxPairingSuggestions {
+			break
+		}
+	}
+	return suggestions
+}
+
+// partnerMenuItem loads the other item of a pairing and checks it is on the same menu
+func partnerMenuItem(c *gin.Context, item *models.MenuItem, partnerID uint) (*models.MenuItem, bool) {
+	if partnerID == item.ID {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.an_item_cant_be_paired_with_itself", nil)
+		return nil, false
+	}
+	var partner models.MenuItem
+	if err := requestDB(c).First(&partner, partnerID).Error; err != nil {
+		c.Error(err).SetMeta("errors.menu_item_not_found")
+		c.Abort()
+		return nil, false
+	}
+	if partner.RestaurantID != item.RestaurantID {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.pairing_items_on_same_menu_only", nil)
+		return nil, false
+	}
+	return &partner, true
+}
+
+// AddMenuItemPairing recommends another item of the same menu alongside this one
+//
+// @Summary     Pair a menu item with another
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Param       body  body  PairingRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId}/pairings [post]
+func AddMenuItemPairing(c *gin.Context) {
+	item, ok := ownedMenuItem(c)
+	if !ok {
+		return
+	}
+	var req PairingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	partner, ok := partnerMenuItem(c, item, req.PairsWithItemID)
+	if !ok {
+		return
+	}
+
+	pairing := models.MenuItemPairing{PairingNote: req.PairingNote}
+	pairing.ItemAID, pairing.ItemBID = pairKey(item.ID, partner.ID)
+	if err := requestDB(c).Create(&pairing).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.these_items_are_already_paired", nil)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_pairing", nil)
+		return
+	}
+	invalidateMenuCache(item.RestaurantID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Pairing added",
+		"pairing": pairing,
+	})
+}
+
+// RemoveMenuItemPairing stops recommending two items together
+//
+// @Summary     Remove a menu item pairing
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Param       body  body  PairingRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId}/pairings [delete]
+func RemoveMenuItemPairing(c *gin.Context) {
+	item, ok := ownedMenuItem(c)
+	if !ok {
+		return
+	}
+	var req PairingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	a, b := pairKey(item.ID, req.PairsWithItemID)
+	result := requestDB(c).Where("item_a_id = ? AND item_b_id = ?", a, b).Delete(&models.MenuItemPairing{})
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_remove_pairing", nil)
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.these_items_are_not_paired", nil)
+		return
+	}
+	invalidateMenuCache(item.RestaurantID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pairing removed",
+		"item_id": item.ID,
+	})
+}
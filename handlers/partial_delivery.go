@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// markUndelivered flags the given items of an order as not delivered and
+// takes their line totals off the order total. Returns the flagged items.
+func markUndelivered(tx *gorm.DB, order *models.Order, itemIDs []uint) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	if err := tx.Where("order_id = ?", order.ID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	wanted := map[uint]bool{}
+	for _, id := range itemIDs {
+		wanted[id] = true
+	}
+	var missing []models.OrderItem
+	refund := 0.0
+	for _, item := range items {
+		if wanted[item.ID] {
+			item.WasDelivered = false
+			missing = append(missing, item)
+			refund += item.Price * float64(item.Quantity)
+			delete(wanted, item.ID)
+		}
+	}
+	if len(wanted) > 0 {
+		unknown := make([]uint, 0, len(wanted))
+		for id := range wanted {
+			unknown = append(unknown, id)
+		}
+		return nil, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest, "errors.some_items_are_not_part_of_this_order",
+			gin.H{"unknown_item_ids": unknown})
+	}
+	if len(missing) == len(items) {
+		return nil, apierror.New(http.StatusBadRequest, apierror.ErrBadRequest,
+			"errors.every_item_undelivered", nil)
+	}
+
+	ids := make([]uint, len(missing))
+	for i, item := range missing {
+		ids[i] = item.ID
+	}
+	if err := tx.Model(&models.OrderItem{}).Where("id IN ?", ids).Update("was_delivered", false).Error; err != nil {
+		return nil, err
+	}
+	order.TotalPrice = math.Round((order.TotalPrice-refund)*100) / 100
+	order.TotalPriceBase = toBase(order.TotalPrice, order.ExchangeRate)
+	order.PartialDelivery = true
+	err := tx.Model(order).Updates(map[string]interface{}{
+		"total_price":      order.TotalPrice,
+		"total_price_base": order.TotalPriceBase,
+		"partial_delivery": true,
+	}).Error
+	return missing, err
+}
+
+// notifyAdminsPartialDelivery alerts every admin that an order arrived incomplete
+func notifyAdminsPartialDelivery(order models.Order, missing []models.OrderItem) {
+	names := make([]string, len(missing))
+	for i, item := range missing {
+		names[i] = fmt.Sprintf("%dx %s", item.Quantity, item.Name)
+	}
+	var admins []models.User
+	config.DB.Where("role = ?", models.RoleAdmin).Find(&admins)
+	for _, admin := range admins {
+		err := notify.Default.Send(notify.Message{
+			UserID:  admin.ID,
+			Email:   admin.Email,
+			Phone:   admin.Phone,
+			Channel: notify.ChannelEmail,
+			Title:   fmt.Sprintf("Partial delivery on order #%d", order.ID),
+			Body:    "Not delivered: " + strings.Join(names, ", "),
+
+			EventType:     "partial_delivery",
+			ReferenceID:   order.ID,
+			ReferenceType: "order",
+		})
+		if err != nil {
+			log.Printf("partial delivery: failed to notify admin %d about order %d: %v", admin.ID, order.ID, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxPaymentWebhookBytes = 64 << 10
+
+// maxRejectedPayloadBytes is how much of a callback with a bad signature is
+// kept. Anyone can post one, so it is a clue for debugging, not a copy.
+const maxRejectedPayloadBytes = 256
+
+// PaymentCallback is what the payment gateway posts about one payment
+type PaymentCallback struct {
+	InvoiceNumber string `json:"invoice_number"`
+	Status        string `json:"status"` // paid or failed
+	Reference     string `json:"reference"`
+}
+
+// validPaymentSignature checks a hex HMAC-SHA256 of the raw body
+func validPaymentSignature(body []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(config.PaymentWebhookSecret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// PaymentWebhook receives payment confirmations from the payment gateway. The
+// callback is stored and acknowledged straight away; the order is updated in
+// the background. A callback with a bad signature is rejected, and only its
+// first 256 bytes are stored.
+//
+// @Summary     Payment gateway callback
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       X-Payment-Signature  header  string           true  "Hex HMAC-SHA256 of the body with PAYMENT_WEBHOOK_SECRET"
+// @Param       body                 body    PaymentCallback  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     503  {object}  apierror.ErrorResponse
+// @Router      /webhooks/payment-callback [post]
+func PaymentWebhook(c *gin.Context) {
+	if config.PaymentWebhookSecret == "" {
+		apierror.Respond(c, http.StatusServiceUnavailable, apierror.ErrUnavailable, "errors.payment_callbacks_are_not_configured", nil)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPaymentWebhookBytes))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.failed_to_read_body", nil)
+		return
+	}
+	signature := c.GetHeader("X-Payment-Signature")
+	entry := models.PaymentWebhookLog{
+		Payload:        string(body),
+		Signature:      signature,
+		SignatureValid: validPaymentSignature(body, signature),
+	}
+	if !entry.SignatureValid && len(body) > maxRejectedPayloadBytes {
+		entry.Payload = string(body[:maxRejectedPayloadBytes])
+	}
+	if err := requestDB(c).Create(&entry).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_store_callback", nil)
+		return
+	}
+	if !entry.SignatureValid {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.invalid_signature", nil)
+		return
+	}
+	go processPaymentCallback(entry)
+	c.JSON(http.StatusOK, gin.H{"received": true, "id": entry.ID})
+}
+
+// processPaymentCallback applies a verified callback to its order and records
+// the outcome on the log entry
+func processPaymentCallback(entry models.PaymentWebhookLog) {
+	updates := map[string]interface{}{"processed_at": time.Now()}
+	order, err := applyPaymentCallback(&entry)
+	if order != nil {
+		updates["order_id"] = order.ID
+	}
+	updates["invoice_number"] = entry.InvoiceNumber
+	if err != nil {
+		updates["error"] = err.Error()
+		log.Printf("payment webhook %d: %v", entry.ID, err)
+	}
+	config.DB.Model(&entry).Updates(updates)
+}
+
+func applyPaymentCallback(entry *models.PaymentWebhookLog) (*models.Order, error) {
+	var cb PaymentCallback
+	if err := json.Unmarshal([]byte(entry.Payload), &cb); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	entry.InvoiceNumber = cb.InvoiceNumber
+	if cb.Status != models.PaymentStatusPaid && cb.Status != models.PaymentStatusFailed {
+		return nil, fmt.Errorf("unknown payment status %q", cb.Status)
+	}
+	var order models.Order
+	if err := config.DB.Where("invoice_number = ?", cb.InvoiceNumber).First(&order).Error; err != nil {
+		return nil, fmt.Errorf("no order with invoice number %q", cb.InvoiceNumber)
+	}
+	// A late failure for an earlier attempt mustn't undo a payment
+	if order.PaymentStatus == models.PaymentStatusPaid && cb.Status != models.PaymentStatusPaid {
+		return &order, errors.New("order is already paid")
+	}
+
+	confirmed := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Conditional on the status read above so only one of two racing callbacks applies
+		res := tx.Model(&models.Order{}).Where("id = ? AND payment_status = ?", order.ID, order.PaymentStatus).
+			Updates(map[string]interface{}{
+				"payment_status":    cb.Status,
+				"payment_reference": cb.Reference,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("payment status changed while applying the callback")
+		}
+		if cb.Status != models.PaymentStatusPaid {
+			return nil
+		}
+		// Conditional on PLACED so a restaurant confirm or cancel racing the callback wins
+		res = tx.Model(&models.Order{}).Where("id = ? AND status = ?", order.ID, models.StatusPlaced).
+			Update("status", models.StatusConfirmed)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		confirmed = true
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: models.StatusPlaced,
+			ToStatus:   models.StatusConfirmed,
+			Note:       "[PAYMENT] Confirmed on payment " + cb.Reference,
+		}).Error
+	})
+	if err != nil {
+		return &order, err
+	}
+	if confirmed {
+		publishStatusChange(order, models.StatusPlaced, models.StatusConfirmed)
+	}
+	return &order, nil
+}
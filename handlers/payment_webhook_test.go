@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// usePaymentSecret sets PAYMENT_WEBHOOK_SECRET for the test
+func usePaymentSecret(t *testing.T, secret string) {
+	t.Helper()
+	prev := config.PaymentWebhookSecret
+	config.PaymentWebhookSecret = secret
+	t.Cleanup(func() { config.PaymentWebhookSecret = prev })
+}
+
+func signPayment(body string) string {
+	mac := hmac.New(sha256.New, []byte(config.PaymentWebhookSecret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func postPaymentCallback(body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/payment-callback", strings.NewReader(body))
+	req.Header.Set("X-Payment-Signature", signature)
+	return serveRequest(PaymentWebhook, "/webhooks/payment-callback", 0, "", req)
+}
+
+// unpaidOrder adds a PLACED order from restaurant awaiting payment
+func unpaidOrder(t *testing.T, db *gorm.DB, restaurant models.Restaurant, customer models.User) models.Order {
+	t.Helper()
+	order := models.Order{
+		InvoiceNumber:   fmt.Sprintf("INV-%d", time.Now().UnixNano()),
+		CustomerID:      customer.ID,
+		RestaurantID:    restaurant.ID,
+		Status:          models.StatusPlaced,
+		DeliveryAddress: "1 Low St",
+		TotalPrice:      20,
+	}
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestPaymentWebhookKeepsOnlyTheStartOfForgedCallbacks(t *testing.T) {
+	db := newTestDB(t)
+	usePaymentSecret(t, "gateway-secret")
+	body := `{"invoice_number":"INV-1","status":"paid","padding":"` + strings.Repeat("x", 10000) + `"}`
+
+	w := postPaymentCallback(body, "00ff")
+	wantStatus(t, w, http.StatusUnauthorized)
+
+	var entry models.PaymentWebhookLog
+	if err := db.First(&entry).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entry.SignatureValid || len(entry.Payload) != maxRejectedPayloadBytes || !strings.HasPrefix(body, entry.Payload) {
+		t.Errorf("stored %d bytes, signature_valid %v; want the first %d bytes of a rejected callback",
+			len(entry.Payload), entry.SignatureValid, maxRejectedPayloadBytes)
+	}
+}
+
+func TestPaymentWebhookConfirmsPaidOrder(t *testing.T) {
+	db := newTestDB(t)
+	usePaymentSecret(t, "gateway-secret")
+	restaurant, _ := createRestaurant(t, db)
+	order := unpaidOrder(t, db, restaurant, createUser(t, db, "Asha", models.RoleCustomer))
+	body := fmt.Sprintf(`{"invoice_number":%q,"status":"paid","reference":"pay_1"}`, order.InvoiceNumber)
+
+	entry := models.PaymentWebhookLog{Payload: body, SignatureValid: true}
+	db.Create(&entry)
+	processPaymentCallback(entry)
+
+	var got models.Order
+	db.First(&got, order.ID)
+	if got.PaymentStatus != models.PaymentStatusPaid || got.Status != models.StatusConfirmed || got.PaymentReference != "pay_1" {
+		t.Errorf("order = %s/%s/%q, want paid, CONFIRMED, pay_1", got.PaymentStatus, got.Status, got.PaymentReference)
+	}
+	db.First(&entry, entry.ID)
+	if entry.ProcessedAt == nil || entry.Error != "" || entry.OrderID == nil || *entry.OrderID != order.ID {
+		t.Errorf("log entry = %+v, want processed for the order without error", entry)
+	}
+}
+
+func TestRacingPaymentCallbacksNeverUndoAPayment(t *testing.T) {
+	db := newTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(4)
+	restaurant, _ := createRestaurant(t, db)
+	order := unpaidOrder(t, db, restaurant, createUser(t, db, "Asha", models.RoleCustomer))
+
+	// Hold both callbacks after they read the order, so each sees it unpaid
+	var read sync.WaitGroup
+	read.Add(2)
+	err := db.Callback().Query().After("gorm:query").Register("test:both_read", func(tx *gorm.DB) {
+		if tx.Statement.Table == "orders" {
+			read.Done()
+			read.Wait()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for _, status := range []string{models.PaymentStatusPaid, models.PaymentStatusFailed} {
+		wg.Add(1)
+		go func(status string) {
+			defer wg.Done()
+			applyPaymentCallback(&models.PaymentWebhookLog{
+				Payload: fmt.Sprintf(`{"invoice_number":%q,"status":%q}`, order.InvoiceNumber, status),
+			})
+		}(status)
+	}
+	wg.Wait()
+	db.Callback().Query().Remove("test:both_read")
+
+	var got models.Order
+	db.First(&got, order.ID)
+	var paid int64
+	db.Model(&models.OrderStatusHistory{}).Where("order_id = ? AND to_status = ?", order.ID, models.StatusConfirmed).Count(&paid)
+	if (got.PaymentStatus == models.PaymentStatusPaid) != (paid == 1) {
+		t.Errorf("payment %s with %d confirmation(s); one callback must apply in full", got.PaymentStatus, paid)
+	}
+	if got.PaymentStatus == models.PaymentStatusFailed && got.Status == models.StatusConfirmed {
+		t.Errorf("order confirmed but its payment failed")
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recentPriceChangeWindow is how long the public menu flags a price change
+const recentPriceChangeWindow = 7 * 24 * time.Hour
+
+// markRecentPriceChanges sets PriceChangedRecently on items repriced within the window
+func markRecentPriceChanges(db *gorm.DB, items []models.MenuItem, now time.Time) {
+	if len(items) == 0 {
+		return
+	}
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	var changed []uint
+	db.Model(&models.MenuItemPriceHistory{}).
+		Where("menu_item_id IN ? AND changed_at >= ?", ids, now.Add(-recentPriceChangeWindow)).
+		Distinct().Pluck("menu_item_id", &changed)
+	recent := make(map[uint]bool, len(changed))
+	for _, id := range changed {
+		recent[id] = true
+	}
+	for i := range items {
+		items[i].PriceChangedRecently = recent[items[i].ID]
+	}
+}
+
+func priceHistory(db *gorm.DB, itemID uint) []models.MenuItemPriceHistory {
+	history := []models.MenuItemPriceHistory{}
+	db.Where("menu_item_id = ?", itemID).Order("changed_at desc, id desc").Find(&history)
+	return history
+}
+
+// GetMenuItemPriceHistory lists every price change of one of the owner's menu items, newest first
+//
+// @Summary     Menu item price history
+// @Tags        restaurant
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId}/price-history [get]
+func GetMenuItemPriceHistory(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var item models.MenuItem
+	if err := requestDB(c).First(&item, c.Param("itemId")).Error; err != nil {
+		c.Error(err).SetMeta("errors.menu_item_not_found")
+		c.Abort()
+		return
+	}
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("id = ? AND owner_id = ?", item.RestaurantID, ownerID).First(&restaurant).Error; err != nil {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.you_dont_own_this_menu_item", nil)
+		return
+	}
+	history := priceHistory(requestDB(c), item.ID)
+	c.JSON(http.StatusOK, gin.H{"item_id": item.ID, "name": item.Name, "price": item.Price, "count": len(history), "history": history})
+}
+
+// AdminGetMenuItemPriceHistory lists every price change of any menu item — admin only
+//
+// @Summary     Menu item price history
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Menu item ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/menu-items/{id}/price-history [get]
+func AdminGetMenuItemPriceHistory(c *gin.Context) {
+	var item models.MenuItem
+	if err := requestDB(c).First(&item, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.menu_item_not_found")
+		c.Abort()
+		return
+	}
+	history := priceHistory(requestDB(c), item.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"item_id":       item.ID,
+		"restaurant_id": item.RestaurantID,
+		"name":          item.Name,
+		"price":         item.Price,
+		"count":         len(history),
+		"history":       history,
+	})
+}
@@ -1,31 +1,76 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
-	"food-delivery-api/config"
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
 	"food-delivery-api/models"
+	"food-delivery-api/statemachine"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // ListRestaurants returns all open restaurants (public)
+//
+// @Summary     List restaurants
+// @Tags        public
+// @Produce     json
+// @Param       cuisine  query  string  false  "Filter by cuisine"
+// @Param       search  query  string  false  "Search by name"
+// @Param       open  query  bool  false  "Only restaurants open now (not closed for a holiday)"
+// @Param       featured  query  bool  false  "Only featured open restaurants, best rated first"
+// @Param       min_rating  query  number  false  "Only restaurants rated at least this"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Router      /restaurants [get]
 func ListRestaurants(c *gin.Context) {
-	var restaurants []models.Restaurant
-	query := config.DB.Preload("Owner")
+	query := requestDB(c).Where("is_active = ?", true)
 
 	// Novelty: filter by cuisine or search by name
 	if cuisine := c.Query("cuisine"); cuisine != "" {
 		query = query.Where("cuisine LIKE ?", "%"+cuisine+"%")
 	}
+	listRestaurants(c, query, c.Query("open") == "true")
+}
+
+// listRestaurants applies the search, rating and featured filters shared by
+// the restaurant lists to query and responds with the matches. Open only
+// means open now: not closed by the owner and not closed for a holiday.
+func listRestaurants(c *gin.Context, query *gorm.DB, openOnly bool) {
+	var restaurants []models.Restaurant
+	query = query.Preload("Owner")
 	if search := c.Query("search"); search != "" {
 		query = query.Where("name LIKE ?", "%"+search+"%")
 	}
-	if open := c.Query("open"); open == "true" {
-		query = query.Where("is_open = ?", true)
+	if openOnly {
+		query = query.Where("is_open = ?", true).Scopes(notClosedForHolidayScope(time.Now()))
+	}
+	if s := c.Query("min_rating"); s != "" {
+		minRating, err := strconv.ParseFloat(s, 64)
+		if err != nil || minRating < 0 || minRating > 5 {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_min_rating", nil)
+			return
+		}
+		query = query.Where("rating >= ?", minRating)
+	}
+	if c.Query("featured") == "true" {
+		query = query.Scopes(featuredScope(time.Now())).Where("is_open = ?", true).Order("rating DESC")
 	}
 
 	query.Find(&restaurants)
+	ids := make([]uint, len(restaurants))
+	for i, r := range restaurants {
+		ids[i] = r.ID
+	}
+	closed := closedForHoliday(requestDB(c), ids, time.Now())
+	for i := range restaurants {
+		restaurants[i].IsClosedForHoliday = closed[restaurants[i].ID]
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"count":       len(restaurants),
 		"restaurants": restaurants,
@@ -33,45 +78,128 @@ func ListRestaurants(c *gin.Context) {
 }
 
 // GetRestaurant returns a single restaurant
+//
+// @Summary     Get a restaurant
+// @Tags        public
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Router      /restaurants/{id} [get]
 func GetRestaurant(c *gin.Context) {
 	var restaurant models.Restaurant
-	if err := config.DB.Preload("MenuItems").First(&restaurant, c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+	if err := requestDB(c).Preload("MenuItems").Where("is_active = ?", true).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"restaurant": restaurant})
+	_, restaurant.IsClosedForHoliday = activeClosure(requestDB(c), restaurant.ID, time.Now())
+	c.JSON(http.StatusOK, gin.H{"restaurant": restaurant, "delivery_pricing": deliveryPricing(restaurant)})
 }
 
 // GetMenu returns the menu for a specific restaurant (public)
+//
+// @Summary     Get a restaurant's menu
+// @Tags        public
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Param       category  query  string  false  "Filter by category"
+// @Param       is_veg  query  bool  false  "Only vegetarian items"
+// @Param       exclude_allergens  query  string  false  "Comma-separated allergens to exclude"
+// @Param       max_calories  query  int  false  "Only items with at most this many calories"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Router      /restaurants/{id}/menu [get]
 func GetMenu(c *gin.Context) {
-	restaurantID := c.Param("id")
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.restaurant_not_found", nil)
+		return
+	}
+	category, isVeg := c.Query("category"), c.Query("is_veg")
+	exclude := parseNameList(c.Query("exclude_allergens"))
+	maxCalories := c.Query("max_calories")
+	calorieLimit := 0
+	if maxCalories != "" {
+		if calorieLimit, err = strconv.Atoi(maxCalories); err != nil || calorieLimit < 0 {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_max_calories", nil)
+			return
+		}
+	}
+	key := menuCacheKey(restaurantID, category, isVeg, exclude, maxCalories)
+	if body, ok := cache.Default.Get(key); ok {
+		writeCachedJSON(c, body)
+		return
+	}
+
 	var restaurant models.Restaurant
-	if err := config.DB.First(&restaurant, restaurantID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+	if err := requestDB(c).Where("is_active = ?", true).First(&restaurant, restaurantID).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
 		return
 	}
 
 	var items []models.MenuItem
-	query := config.DB.Where("restaurant_id = ?", restaurantID)
+	query := requestDB(c).Where("restaurant_id = ?", restaurantID)
 
 	// Novelty: filter by category or veg
-	if category := c.Query("category"); category != "" {
+	if category != "" {
 		query = query.Where("category = ?", category)
 	}
-	if isVeg := c.Query("is_veg"); isVeg == "true" {
+	if isVeg == "true" {
 		query = query.Where("is_veg = ?", true)
 	}
+	if len(exclude) > 0 {
+		query = query.Where("id NOT IN (?)", requestDB(c).Table("menu_item_allergens").
+			Select("menu_item_allergens.menu_item_id").
+			Joins("JOIN allergens ON allergens.id = menu_item_allergens.allergen_id").
+			Where("allergens.name IN ?", exclude))
+	}
+	// Items without calorie data can't be shown to be under the limit
+	if maxCalories != "" {
+		query = query.Where("calories IS NOT NULL AND calories <= ?", calorieLimit)
+	}
 	query.Find(&items)
+	attachAllergens(requestDB(c), items)
+	markRecentPriceChanges(requestDB(c), items, time.Now())
+	attachPairings(requestDB(c), items)
+	month := time.Now().Month()
+	for i := range items {
+		items[i].EightySixed = items[i].EightySixedAt != nil
+		items[i].IsInSeason = items[i].InSeason(month)
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"restaurant": restaurant.Name,
-		"count":      len(items),
-		"menu":       items,
+	bundles := menuBundles(requestDB(c), restaurantID, category, isVeg, exclude)
+
+	body, err := json.Marshal(gin.H{
+		"restaurant":       restaurant.Name,
+		"delivery_pricing": deliveryPricing(restaurant),
+		"min_item_count":   restaurant.MinItemCount,
+		"max_item_count":   restaurant.MaxItemCount,
+		"count":            len(items),
+		"menu":             items,
+		"bundles":          bundles,
 	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_encode_menu", nil)
+		return
+	}
+	cache.Default.Set(key, body, menuCacheTTL)
+	writeCachedJSON(c, body)
 }
 
-// GetStateMachineInfo returns the full state machine for informational purposes
+// GetStateMachineInfo returns the full state machine for informational purposes,
+// with each state's display label in the requested locale
+//
+// @Summary     Describe the order state machine
+// @Tags        public
+// @Produce     json
+// @Param       locale  query  string  false  "Locale for state labels (default: Accept-Language, then en)"
+// @Success     200  {object}  map[string]interface{}
+// @Router      /state-machine [get]
 func GetStateMachineInfo(c *gin.Context) {
+	locale := requestLocale(c)
 	info := []gin.H{
 		{"from": "PLACED", "to": "CONFIRMED", "actor": "restaurant"},
 		{"from": "PLACED", "to": "CANCELLED", "actor": "restaurant or customer"},
@@ -81,9 +209,19 @@ func GetStateMachineInfo(c *gin.Context) {
 		{"from": "READY_FOR_PICKUP", "to": "PICKED_UP", "actor": "driver"},
 		{"from": "PICKED_UP", "to": "DELIVERED", "actor": "driver"},
 	}
+	for _, t := range info {
+		t["from_label"] = statemachine.DisplayLabel(models.OrderStatus(t["from"].(string)), locale)
+		t["to_label"] = statemachine.DisplayLabel(models.OrderStatus(t["to"].(string)), locale)
+	}
+	labels := gin.H{}
+	for _, s := range statemachine.States() {
+		labels[string(s)] = statemachine.DisplayLabel(s, locale)
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"state_machine": info,
+		"state_machine":   info,
+		"locale":          locale,
+		"labels":          labels,
 		"terminal_states": []string{"DELIVERED", "CANCELLED"},
-		"description": "Food Delivery Order Lifecycle State Machine",
+		"description":     "Food Delivery Order Lifecycle State Machine",
 	})
 }
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/i18n"
+	"food-delivery-api/statemachine"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	dateLayout      = "2006-01-02"
+)
+
+// parsePagination reads ?page= and ?page_size= with sane defaults and bounds
+func parsePagination(c *gin.Context) (page, pageSize int) {
+	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// parseDateRange reads ?from= and ?to= (YYYY-MM-DD, both inclusive) and returns
+// a half-open [start, end) window. Missing bounds default to the last defaultDays
+// days. maxDays caps the window length; 0 means unlimited.
+func parseDateRange(c *gin.Context, defaultDays, maxDays int) (start, end time.Time, err error) {
+	today := startOfDay(time.Now())
+	to := today
+	if s := c.Query("to"); s != "" {
+		if to, err = time.ParseInLocation(dateLayout, s, time.Local); err != nil {
+			return start, end, errors.New("invalid 'to' date, expected YYYY-MM-DD")
+		}
+	}
+	from := to.AddDate(0, 0, -defaultDays+1)
+	if s := c.Query("from"); s != "" {
+		if from, err = time.ParseInLocation(dateLayout, s, time.Local); err != nil {
+			return start, end, errors.New("invalid 'from' date, expected YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return start, end, errors.New("'to' must be on or after 'from'")
+	}
+	end = to.AddDate(0, 0, 1)
+	if maxDays > 0 && end.Sub(from) > time.Duration(maxDays)*24*time.Hour {
+		return start, end, errors.New("date range cannot exceed " + strconv.Itoa(maxDays) + " days")
+	}
+	return from, end, nil
+}
+
+// startOfDay is local midnight on t's day; "today" and date ranges are in the
+// server's time zone
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// requestLocale is the locale asked for with ?locale=, else the one
+// middleware.I18n matched from Accept-Language
+func requestLocale(c *gin.Context) string {
+	if locale := c.Query("locale"); locale != "" {
+		return statemachine.NormalizeLocale(locale)
+	}
+	return i18n.Locale(c)
+}
+
+// requestDB scopes queries to the request's context, so they are cancelled
+// when the client goes away or the handler timeout fires
+func requestDB(c *gin.Context) *gorm.DB {
+	return config.DB.WithContext(c.Request.Context())
+}
+
+// sqliteTimeLayouts are the forms a timestamp takes as SQLite text
+var sqliteTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// sqlTime scans a timestamp whose column type SQLite has lost, as happens for
+// MIN/MAX aggregates, where the driver returns text instead of a time
+type sqlTime struct {
+	time.Time
+}
+
+func (t *sqlTime) Scan(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.Scan(string(v))
+	case string:
+		for _, layout := range sqliteTimeLayouts {
+			if parsed, err := time.Parse(layout, v); err == nil {
+				t.Time = parsed
+				return nil
+			}
+		}
+		return fmt.Errorf("unrecognised timestamp %q", v)
+	}
+	return fmt.Errorf("cannot scan %T into a timestamp", v)
+}
+
+// Value lets GORM treat sqlTime as a column type
+func (t sqlTime) Value() (driver.Value, error) {
+	return t.Time, nil
+}
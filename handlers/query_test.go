@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestContext returns a gin context for a request whose context the test
+// can cancel
+func requestContext(t *testing.T) (*gin.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/orders", nil).WithContext(ctx)
+	return c, cancel
+}
+
+func TestRequestDBStopsWhenClientGoesAway(t *testing.T) {
+	db := newTestDB(t)
+	createRestaurant(t, db, models.MenuItem{Name: "Paneer", Price: 200})
+
+	c, cancel := requestContext(t)
+	var items []models.MenuItem
+	if err := requestDB(c).Find(&items).Error; err != nil || len(items) != 1 {
+		t.Fatalf("Find before cancelling: %d items, err %v; want 1 item", len(items), err)
+	}
+
+	cancel()
+	items = nil
+	err := requestDB(c).Find(&items).Error
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Find after cancelling: err = %v, want context.Canceled", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("Find after cancelling returned %d items, want none", len(items))
+	}
+}
+
+func TestRequestDBStopsQueryInProgress(t *testing.T) {
+	newTestDB(t)
+	c, cancel := requestContext(t)
+
+	// Returns rows forever; only the cancellation ends the read
+	const endless = "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT i FROM n"
+	done := make(chan error, 1)
+	go func() {
+		var rows []int64
+		done <- requestDB(c).Raw(endless).Scan(&rows).Error
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("query still running 5s after the request was cancelled")
+	}
+}
+
+func TestRestaurantLoadThreshold(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	for _, status := range []models.OrderStatus{models.StatusPlaced, models.StatusPreparing, models.StatusDelivered} {
+		order := unpaidOrder(t, db, restaurant, customer)
+		db.Model(&order).Update("status", status)
+	}
+
+	if rows := restaurantLoad(db, 0); len(rows) != 1 || rows[0].ActiveOrders != 2 {
+		t.Errorf("load = %+v, want one restaurant with 2 active orders", rows)
+	}
+	if rows := restaurantLoad(db, 2); len(rows) != 0 {
+		t.Errorf("load above 2 = %+v, want none", rows)
+	}
+}
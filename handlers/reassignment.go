@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+// How long after pickup a delivery must be in progress before it can be flagged as stalled
+const stalledDeliveryThreshold = 15 * time.Minute
+
+type ReassignmentRequestBody struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RequestReassignment lets a customer flag a stalled delivery for admin review
+//
+// @Summary     Flag a stalled delivery for driver reassignment
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Param       body  body  ReassignmentRequestBody  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/request-reassignment [post]
+func RequestReassignment(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+
+	var req ReassignmentRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.CustomerID != customerID {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
+		return
+	}
+	if order.Status != models.StatusPickedUp || order.DriverID == nil {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.reassign_only_out_for_delivery", gin.H{
+			"current_status": order.Status,
+		})
+		return
+	}
+
+	// Without live GPS we use time since pickup as the stall signal
+	var pickup models.OrderStatusHistory
+	if err := requestDB(c).Where("order_id = ? AND to_status = ?", order.ID, models.StatusPickedUp).
+		Order("created_at desc").First(&pickup).Error; err == nil {
+		if since := time.Since(pickup.CreatedAt); since < stalledDeliveryThreshold {
+			apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.stall_flag_too_early", gin.H{
+				"minutes_remaining": int((stalledDeliveryThreshold - since).Minutes()) + 1,
+			})
+			return
+		}
+	}
+
+	// One active request per order
+	var pending int64
+	requestDB(c).Model(&models.ReassignmentRequest{}).
+		Where("order_id = ? AND status = ?", order.ID, models.ReassignmentPending).
+		Count(&pending)
+	if pending > 0 {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.reassignment_already_pending", nil)
+		return
+	}
+
+	reassignment := models.ReassignmentRequest{
+		OrderID:    order.ID,
+		CustomerID: customerID,
+		DriverID:   *order.DriverID,
+		Reason:     req.Reason,
+		Status:     models.ReassignmentPending,
+	}
+	if err := requestDB(c).Create(&reassignment).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_reassignment_request", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Reassignment requested — an admin will review it shortly", "request": reassignment})
+}
+
+// AdminGetReassignmentRequests lists reassignment requests — admin only
+//
+// @Summary     List driver reassignment requests
+// @Tags        admin
+// @Produce     json
+// @Param       status  query  string  false  "Filter by status"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reassignment-requests [get]
+func AdminGetReassignmentRequests(c *gin.Context) {
+	var requests []models.ReassignmentRequest
+	query := requestDB(c).Preload("Order")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	query.Order("created_at desc").Find(&requests)
+	c.JSON(http.StatusOK, gin.H{"count": len(requests), "requests": requests})
+}
+
+// loadPendingReassignment fetches a reassignment request that is still awaiting review
+func loadPendingReassignment(c *gin.Context) (*models.ReassignmentRequest, bool) {
+	var reassignment models.ReassignmentRequest
+	if err := requestDB(c).First(&reassignment, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.reassignment_request_not_found")
+		c.Abort()
+		return nil, false
+	}
+	if reassignment.Status != models.ReassignmentPending {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.reassignment_request_has_already_been_reviewed", gin.H{
+			"status": reassignment.Status,
+		})
+		return nil, false
+	}
+	return &reassignment, true
+}
+
+// AdminApproveReassignment releases the order back to the pickup pool — admin only
+//
+// @Summary     Approve a reassignment request
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Reassignment request ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reassignment-requests/{id}/approve [put]
+func AdminApproveReassignment(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	reassignment, ok := loadPendingReassignment(c)
+	if !ok {
+		return
+	}
+
+	var order models.Order
+	if err := requestDB(c).First(&order, reassignment.OrderID).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.Status != models.StatusPickedUp || order.DriverID == nil {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.order_is_no_longer_out_for_delivery", gin.H{
+			"current_status": order.Status,
+		})
+		return
+	}
+
+	oldDriverID := *order.DriverID
+	prevStatus := order.Status
+	requestDB(c).Model(&order).Updates(map[string]interface{}{
+		"status":             models.StatusReadyForPickup,
+		"driver_id":          nil,
+		"previous_driver_id": oldDriverID,
+	})
+
+	history := models.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: prevStatus,
+		ToStatus:   models.StatusReadyForPickup,
+		ChangedBy:  adminID,
+		Note:       "[REASSIGNMENT] " + reassignment.Reason,
+	}
+	requestDB(c).Create(&history)
+	publishStatusChange(order, prevStatus, models.StatusReadyForPickup)
+
+	now := time.Now()
+	requestDB(c).Model(reassignment).Updates(map[string]interface{}{
+		"status":      models.ReassignmentApproved,
+		"reviewed_by": adminID,
+		"reviewed_at": now,
+	})
+
+	// Let the old driver know the order was taken off them
+	var driver models.User
+	if err := requestDB(c).First(&driver, oldDriverID).Error; err == nil {
+		err := notify.Default.Send(notify.Message{
+			UserID:  driver.ID,
+			Email:   driver.Email,
+			Phone:   driver.Phone,
+			Channel: notify.ChannelPush,
+			Title:   "Order reassigned",
+			Body:    fmt.Sprintf("Order #%d has been reassigned to another driver.", order.ID),
+
+			EventType:     "order_reassigned",
+			ReferenceID:   order.ID,
+			ReferenceType: "order",
+		})
+		if err != nil {
+			log.Printf("reassignment %d: failed to notify driver %d: %v", reassignment.ID, driver.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Reassignment approved — order is available for pickup again",
+		"order_id":           order.ID,
+		"previous_driver_id": oldDriverID,
+		"status":             models.StatusReadyForPickup,
+	})
+}
+
+// AdminRejectReassignment closes a reassignment request without changing the order — admin only
+//
+// @Summary     Reject a reassignment request
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Reassignment request ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reassignment-requests/{id}/reject [put]
+func AdminRejectReassignment(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	reassignment, ok := loadPendingReassignment(c)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	requestDB(c).Model(reassignment).Updates(map[string]interface{}{
+		"status":      models.ReassignmentRejected,
+		"reviewed_by": adminID,
+		"reviewed_at": now,
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "Reassignment request rejected", "request_id": reassignment.ID})
+}
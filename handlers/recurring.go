@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/features"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxRecurringOrdersPerCustomer = 5
+	recurringCheckInterval        = time.Minute
+	timeOfDayLayout               = "15:04"
+)
+
+type RecurringOrderRequest struct {
+	RestaurantID    uint             `json:"restaurant_id" binding:"required"`
+	DayOfWeek       *int             `json:"day_of_week" binding:"required,min=0,max=6"` // 0 = Sunday
+	TimeOfDay       string           `json:"time_of_day" binding:"required"`             // "HH:MM", server local time
+	Items           []PlaceOrderItem `json:"items" binding:"required,min=1,dive"`
+	DeliveryAddress string           `json:"delivery_address" binding:"required"`
+	Notes           string           `json:"notes"`
+}
+
+// nextRecurringRun returns the first weekday/time slot strictly after `after`
+func nextRecurringRun(dayOfWeek int, timeOfDay string, after time.Time) time.Time {
+	t, _ := time.Parse(timeOfDayLayout, timeOfDay)
+	next := time.Date(after.Year(), after.Month(), after.Day(), t.Hour(), t.Minute(), 0, 0, after.Location())
+	for next.Weekday() != time.Weekday(dayOfWeek) || !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// CreateRecurringOrder schedules an order to be placed every week
+//
+// @Summary     Schedule a weekly recurring order
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       body  body  RecurringOrderRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/recurring-orders [post]
+func CreateRecurringOrder(c *gin.Context) {
+	if !requireFeature(c, models.FeatureRecurringOrders) {
+		return
+	}
+	customerID := middleware.GetUserID(c)
+	var req RecurringOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if _, err := time.Parse(timeOfDayLayout, req.TimeOfDay); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_time_of_day", nil)
+		return
+	}
+
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, req.RestaurantID).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	items := make([]models.RecurringOrderItem, len(req.Items))
+	for i, it := range req.Items {
+		if it.BundleID != 0 {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.bundles_cant_be_part_of_a_recurring_order", nil)
+			return
+		}
+		var menuItem models.MenuItem
+		if err := requestDB(c).Where("id = ? AND restaurant_id = ?", it.MenuItemID, restaurant.ID).First(&menuItem).Error; err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest,
+				"errors.menu_item_id_not_in_restaurant", nil, it.MenuItemID)
+			return
+		}
+		items[i] = models.RecurringOrderItem{MenuItemID: it.MenuItemID, Quantity: it.Quantity}
+	}
+
+	var active int64
+	requestDB(c).Model(&models.RecurringOrder{}).Where("customer_id = ? AND is_active = ?", customerID, true).Count(&active)
+	if active >= maxRecurringOrdersPerCustomer {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict,
+			"errors.you_can_have_at_most_recurring_orders", nil, maxRecurringOrdersPerCustomer)
+		return
+	}
+
+	recurring := models.RecurringOrder{
+		CustomerID:      customerID,
+		RestaurantID:    restaurant.ID,
+		DayOfWeek:       *req.DayOfWeek,
+		TimeOfDay:       req.TimeOfDay,
+		Items:           items,
+		DeliveryAddress: req.DeliveryAddress,
+		Notes:           req.Notes,
+		IsActive:        true,
+		NextRunAt:       nextRecurringRun(*req.DayOfWeek, req.TimeOfDay, time.Now()),
+	}
+	if err := requestDB(c).Create(&recurring).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_recurring_order", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Recurring order scheduled", "recurring_order": recurring})
+}
+
+// GetRecurringOrders lists the customer's active recurring orders
+//
+// @Summary     List my recurring orders
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /customer/recurring-orders [get]
+func GetRecurringOrders(c *gin.Context) {
+	if !requireFeature(c, models.FeatureRecurringOrders) {
+		return
+	}
+	customerID := middleware.GetUserID(c)
+	var list []models.RecurringOrder
+	requestDB(c).Preload("Restaurant").
+		Where("customer_id = ? AND is_active = ?", customerID, true).
+		Order("next_run_at").
+		Find(&list)
+	c.JSON(http.StatusOK, gin.H{"count": len(list), "recurring_orders": list})
+}
+
+// DeleteRecurringOrder deactivates one of the customer's recurring orders
+//
+// @Summary     Stop a recurring order
+// @Tags        customer
+// @Produce     json
+// @Param       id  path  int  true  "Recurring order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/recurring-orders/{id} [delete]
+func DeleteRecurringOrder(c *gin.Context) {
+	if !requireFeature(c, models.FeatureRecurringOrders) {
+		return
+	}
+	customerID := middleware.GetUserID(c)
+	res := requestDB(c).Model(&models.RecurringOrder{}).
+		Where("id = ? AND customer_id = ? AND is_active = ?", c.Param("id"), customerID, true).
+		Update("is_active", false)
+	if res.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.recurring_order_not_found", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Recurring order stopped"})
+}
+
+// StartRecurringOrderWorker places due recurring orders once a minute
+func StartRecurringOrderWorker() {
+	go func() {
+		for range time.Tick(recurringCheckInterval) {
+			runRecurringOrders(time.Now())
+		}
+	}()
+}
+
+func runRecurringOrders(now time.Time) {
+	if !features.IsEnabled(models.FeatureRecurringOrders) {
+		return
+	}
+	var due []models.RecurringOrder
+	config.DB.Where("is_active = ? AND next_run_at <= ?", true, now).Find(&due)
+	for _, r := range due {
+		// Claim the slot by moving next_run_at forward; skip if another run already did
+		next := nextRecurringRun(r.DayOfWeek, r.TimeOfDay, now)
+		res := config.DB.Model(&models.RecurringOrder{}).
+			Where("id = ? AND next_run_at <= ?", r.ID, now).
+			Updates(map[string]interface{}{"next_run_at": next, "last_run_at": now})
+		if res.RowsAffected == 0 {
+			continue
+		}
+
+		req := PlaceOrderRequest{
+			RestaurantID:    r.RestaurantID,
+			DeliveryAddress: r.DeliveryAddress,
+			Notes:           r.Notes,
+		}
+		for _, it := range r.Items {
+			req.Items = append(req.Items, PlaceOrderItem{MenuItemID: it.MenuItemID, Quantity: it.Quantity})
+		}
+
+		entry := models.RecurringOrderLog{RecurringOrderID: r.ID}
+		order, apiErr := placeOrder(config.DB, r.CustomerID, req)
+		if apiErr != nil {
+			entry.Error = apiErr.Error()
+		} else {
+			entry.Success = true
+			entry.OrderID = &order.ID
+		}
+		if err := config.DB.Create(&entry).Error; err != nil {
+			log.Printf("recurring order %d: failed to write log: %v", r.ID, err)
+		}
+
+		var customer models.User
+		if err := config.DB.First(&customer, r.CustomerID).Error; err != nil {
+			continue
+		}
+		msg := notify.Message{UserID: customer.ID, Email: customer.Email, Phone: customer.Phone, Channel: notify.ChannelPush}
+		if apiErr != nil {
+			msg.Title = "Your recurring order couldn't be placed"
+			msg.Body = apiErr.Error()
+			msg.EventType = "recurring_order_failed"
+			msg.ReferenceID, msg.ReferenceType = r.ID, "recurring_order"
+		} else {
+			msg.EventType = "recurring_order_placed"
+			msg.ReferenceID, msg.ReferenceType = order.ID, "order"
+			msg.Title = fmt.Sprintf("Recurring order #%d placed", order.ID)
+			msg.Body = fmt.Sprintf("Your weekly order from %s is on its way to the kitchen.", order.Restaurant.Name)
+		}
+		notify.Default.Send(msg)
+	}
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	referralCodeLength   = 8
+	referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no 0/O or 1/I lookalikes
+)
+
+// newReferralCode returns a random code that no user has yet
+func newReferralCode(db *gorm.DB) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		buf := make([]byte, referralCodeLength)
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for i, b := range buf {
+			buf[i] = referralCodeAlphabet[int(b)%len(referralCodeAlphabet)]
+		}
+		code := string(buf)
+		var taken int64
+		db.Model(&models.User{}).Where("referral_code = ?", code).Count(&taken)
+		if taken == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("could not generate a unique referral code")
+}
+
+// awardReferralBonus pays both sides of a referral when the referred customer's
+// first order is delivered. Runs alongside awardLoyaltyPoints.
+func awardReferralBonus(order models.Order) {
+	referrerBonus := sysconfig.Int(sysconfig.KeyReferralReferrerBonus)
+	refereeBonus := sysconfig.Int(sysconfig.KeyReferralRefereeBonus)
+	var referral models.Referral
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("referee_id = ? AND rewarded_at IS NULL", order.CustomerID).First(&referral).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		// Claim the referral so a concurrent delivery can't pay it twice
+		res := tx.Model(&models.Referral{}).Where("id = ? AND rewarded_at IS NULL", referral.ID).Updates(map[string]interface{}{
+			"rewarded_at":     time.Now(),
+			"order_id":        order.ID,
+			"referrer_points": referrerBonus,
+			"referee_points":  refereeBonus,
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			referral.ID = 0
+			return res.Error
+		}
+		for customerID, points := range map[uint]int{referral.ReferrerID: referrerBonus, referral.RefereeID: refereeBonus} {
+			account, err := loyaltyAccount(tx, customerID)
+			if err != nil {
+				return err
+			}
+			account.Points += points
+			account.LifetimePoints += points
+			account.Tier = tierFor(account.LifetimePoints)
+			if err := tx.Save(&account).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("referral: failed to award bonus for order %d: %v", order.ID, err)
+		return
+	}
+	if referral.ID == 0 {
+		return
+	}
+
+	var referrer, referee models.User
+	config.DB.First(&referrer, referral.ReferrerID)
+	config.DB.First(&referee, referral.RefereeID)
+	notify.Default.Send(notify.Message{
+		UserID:  referrer.ID,
+		Email:   referrer.Email,
+		Phone:   referrer.Phone,
+		Channel: notify.ChannelPush,
+		Title:   "Referral bonus earned!",
+		Body:    fmt.Sprintf("%s received their first order — you earned %d points.", referee.Name, referrerBonus),
+
+		EventType: "referral_bonus",
+	})
+	notify.Default.Send(notify.Message{
+		UserID:  referee.ID,
+		Email:   referee.Email,
+		Phone:   referee.Phone,
+		Channel: notify.ChannelPush,
+		Title:   "Welcome bonus earned!",
+		Body:    fmt.Sprintf("Your first order earned you %d referral points.", refereeBonus),
+
+		EventType: "referral_bonus",
+	})
+}
+
+// GetMyReferrals lists the customers who signed up with the caller's referral code
+//
+// @Summary     My referrals
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /customer/referrals [get]
+func GetMyReferrals(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var me models.User
+	requestDB(c).First(&me, customerID)
+
+	var referrals []models.Referral
+	requestDB(c).Preload("Referee").Where("referrer_id = ?", customerID).Order("created_at DESC").Find(&referrals)
+
+	out := make([]gin.H, 0, len(referrals))
+	earned := 0
+	for _, r := range referrals {
+		var delivered int64
+		requestDB(c).Model(&models.Order{}).Where("customer_id = ? AND status = ?", r.RefereeID, models.StatusDelivered).Count(&delivered)
+		status := "SIGNED_UP"
+		switch {
+		case r.RewardedAt != nil:
+			status = "REWARDED"
+		case delivered > 0:
+			status = "ORDERED"
+		}
+		earned += r.ReferrerPoints
+		out = append(out, gin.H{
+			"user_id":          r.RefereeID,
+			"name":             r.Referee.Name,
+			"joined_at":        r.CreatedAt,
+			"delivered_orders": delivered,
+			"status":           status,
+			"points_earned":    r.ReferrerPoints,
+			"rewarded_at":      r.RewardedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"referral_code": me.ReferralCode,
+		"total":         len(out),
+		"points_earned": earned,
+		"referrals":     out,
+	})
+}
+
+// AdminGetReferralStats summarises referral signups, conversions and bonus points paid
+//
+// @Summary     Referral programme stats
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/referrals/stats [get]
+func AdminGetReferralStats(c *gin.Context) {
+	var stats struct {
+		Total     int64
+		Converted int64
+		Points    int64
+	}
+	requestDB(c).Model(&models.Referral{}).Select(
+		"COUNT(*) AS total, COUNT(rewarded_at) AS converted, COALESCE(SUM(referrer_points + referee_points), 0) AS points",
+	).Scan(&stats)
+
+	conversionRate := 0.0
+	if stats.Total > 0 {
+		conversionRate = float64(stats.Converted) / float64(stats.Total) * 100
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total_referrals":      stats.Total,
+		"converted_referrals":  stats.Converted,
+		"conversion_rate_pct":  math.Round(conversionRate*100) / 100,
+		"total_points_awarded": stats.Points,
+		"referrer_bonus":       sysconfig.Int(sysconfig.KeyReferralReferrerBonus),
+		"referee_bonus":        sysconfig.Int(sysconfig.KeyReferralRefereeBonus),
+	})
+}
+
+// countReferralLink bumps one of a code's funnel counters ("click_count" or
+// "conversion_count"), creating the link the first time the code is seen
+func countReferralLink(db *gorm.DB, code string, userID uint, column string) error {
+	link := models.ReferralLink{Code: code, UserID: userID}
+	switch column {
+	case "click_count":
+		link.ClickCount = 1
+	case "conversion_count":
+		link.ConversionCount = 1
+	default:
+		return fmt.Errorf("unknown referral counter %q", column)
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "code"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			column:       gorm.Expr("referral_links." + column + " + 1"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(&link).Error
+}
+
+// GetReferralLanding returns what a referral landing page shows for a code and
+// counts the visit. Nothing about the referrer is exposed.
+//
+// @Summary     Referral landing page data
+// @Tags        public
+// @Produce     json
+// @Param       code  path  string  true  "Referral code"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Router      /referral/{code} [get]
+func GetReferralLanding(c *gin.Context) {
+	code := strings.ToUpper(strings.TrimSpace(c.Param("code")))
+	var referrer models.User
+	if err := requestDB(c).Select("id").Where("referral_code = ? AND role = ?", code, models.RoleCustomer).
+		First(&referrer).Error; err != nil {
+		c.Error(err).SetMeta("errors.referral_code_not_found")
+		c.Abort()
+		return
+	}
+	if err := countReferralLink(requestDB(c), code, referrer.ID, "click_count"); err != nil {
+		log.Printf("referral: failed to count click for %s: %v", code, err)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":                 code,
+		"message":              sysconfig.Get(sysconfig.KeyReferralLandingMessage),
+		"referee_bonus_points": sysconfig.Int(sysconfig.KeyReferralRefereeBonus),
+		"restaurant_name_hint": nil,
+	})
+}
+
+// AdminGetReferralFunnel reports landing page visits and signups per referral code
+//
+// @Summary     Referral link funnel
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/referrals/funnel [get]
+func AdminGetReferralFunnel(c *gin.Context) {
+	var links []models.ReferralLink
+	requestDB(c).Order("conversion_count DESC, click_count DESC, id").Find(&links)
+
+	conversionRate := func(clicks, conversions int) float64 {
+		if clicks == 0 {
+			return 0
+		}
+		return math.Round(float64(conversions)/float64(clicks)*10000) / 100
+	}
+	var clicks, conversions int
+	out := make([]gin.H, 0, len(links))
+	for _, l := range links {
+		clicks += l.ClickCount
+		conversions += l.ConversionCount
+		out = append(out, gin.H{
+			"code":                l.Code,
+			"user_id":             l.UserID,
+			"clicks":              l.ClickCount,
+			"conversions":         l.ConversionCount,
+			"conversion_rate_pct": conversionRate(l.ClickCount, l.ConversionCount),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total_links":         len(links),
+		"total_clicks":        clicks,
+		"total_conversions":   conversions,
+		"conversion_rate_pct": conversionRate(clicks, conversions),
+		"links":               out,
+	})
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// How far back the price-drift report looks
+const priceDriftWindowDays = 30
+
+type priceDriftRow struct {
+	OrderID        uint    `json:"order_id"`
+	ItemName       string  `json:"item_name"`
+	RestaurantName string  `json:"restaurant_name"`
+	SnapshotPrice  float64 `json:"snapshot_price"`
+	CurrentPrice   float64 `json:"current_price"`
+	DriftPct       float64 `json:"drift_pct"`
+}
+
+// AdminGetPriceDrift lists delivered order items whose snapshot price has drifted from the current menu price — admin only
+//
+// @Summary     Report order items whose price drifted from the menu
+// @Tags        admin
+// @Produce     json
+// @Param       min_drift_pct  query  number  false  "Minimum drift in percent (default 20)"
+// @Param       restaurant_id  query  int  false  "Filter by restaurant"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/price-drift [get]
+func AdminGetPriceDrift(c *gin.Context) {
+	minDrift := 20.0
+	if v := c.Query("min_drift_pct"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_min_drift_pct", nil)
+			return
+		}
+		minDrift = parsed
+	}
+
+	const driftExpr = "ABS(order_items.price - menu_items.price) / menu_items.price * 100"
+	since := time.Now().AddDate(0, 0, -priceDriftWindowDays)
+	query := requestDB(c).Table("order_items").
+		Select("order_items.order_id, order_items.name AS item_name, restaurants.name AS restaurant_name, "+
+			"order_items.price AS snapshot_price, menu_items.price AS current_price, "+driftExpr+" AS drift_pct").
+		Joins("JOIN menu_items ON menu_items.id = order_items.menu_item_id").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("orders.status = ? AND orders.created_at >= ? AND orders.deleted_at IS NULL AND menu_items.price > 0", models.StatusDelivered, since).
+		Where(driftExpr+" > ?", minDrift)
+	if restaurantID := c.Query("restaurant_id"); restaurantID != "" {
+		query = query.Where("orders.restaurant_id = ?", restaurantID)
+	}
+
+	rows := []priceDriftRow{}
+	query.Order("drift_pct desc").Scan(&rows)
+	for i := range rows {
+		rows[i].DriftPct = math.Round(rows[i].DriftPct*100) / 100
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"min_drift_pct": minDrift,
+		"window_days":   priceDriftWindowDays,
+		"count":         len(rows),
+		"items":         rows,
+	})
+}
+
+// reconciliationRow is one delivered order with what it owes the driver.
+// The tree has no driver payouts yet, so no row has one; the fields are there
+// for when those land.
+type reconciliationRow struct {
+	OrderID                uint    `json:"order_id"`
+	InvoiceNumber          string  `json:"invoice_number"`
+	DriverID               *uint   `json:"driver_id"`
+	PaymentMethod          string  `json:"payment_method"`
+	Currency               string  `json:"currency"` // amounts on the row are in this currency
+	ExchangeRate           float64 `json:"exchange_rate"`
+	TotalCollected         float64 `json:"total_collected"`
+	ServiceFeeDeducted     float64 `json:"service_fee_deducted"`
+	DeliveryFeeDueToDriver float64 `json:"delivery_fee_due_to_driver"`
+	TipDueToDriver         float64 `json:"tip_due_to_driver"`
+	PlatformTipIncome      float64 `json:"platform_tip_income"`
+	PayoutRequestID        *uint   `json:"payout_request_id"`
+	PayoutStatus           string  `json:"payout_status"`
+	Unreconciled           bool    `json:"unreconciled"`
+}
+
+// AdminGetReconciliation matches delivered orders against what is owed to drivers — admin only
+//
+// @Summary     Order/payout reconciliation report
+// @Tags        admin
+// @Produce     json
+// @Param       from  query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to    query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/reconciliation [get]
+func AdminGetReconciliation(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	// COD orders count the cash the driver actually took
+	rows := []reconciliationRow{}
+	requestDB(c).Model(&models.Order{}).
+		Select("id AS order_id, invoice_number, driver_id, payment_method, currency, exchange_rate, "+
+			"CASE WHEN payment_method = ? THEN cod_amount_collected ELSE total_price END AS total_collected, "+
+			"service_fee AS service_fee_deducted, delivery_fee AS delivery_fee_due_to_driver, "+
+			"driver_tip_amount AS tip_due_to_driver, platform_tip_income", models.PaymentCOD).
+		Where("status = ? AND created_at >= ? AND created_at < ?", models.StatusDelivered, from, to).
+		Order("id").Scan(&rows)
+
+	var revenue, serviceFees, tipIncome, unreconciledAmount float64
+	unreconciledCount := 0
+	for i := range rows {
+		r := &rows[i]
+		r.PayoutStatus = "NONE"
+		due := r.DeliveryFeeDueToDriver + r.TipDueToDriver
+		r.Unreconciled = due > 0 && r.PayoutRequestID == nil
+		if r.Unreconciled {
+			unreconciledCount++
+			unreconciledAmount += toBase(due, r.ExchangeRate)
+		}
+		revenue += toBase(r.TotalCollected, r.ExchangeRate)
+		serviceFees += toBase(r.ServiceFeeDeducted, r.ExchangeRate)
+		tipIncome += toBase(r.PlatformTipIncome, r.ExchangeRate)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": from.Format(dateLayout),
+		"to":   to.AddDate(0, 0, -1).Format(dateLayout),
+		"summary": gin.H{
+			"base_currency":             sysconfig.Get(sysconfig.KeyBaseCurrency),
+			"total_orders":              len(rows),
+			"total_revenue":             math.Round(revenue*100) / 100,
+			"total_service_fees":        math.Round(serviceFees*100) / 100,
+			"platform_tip_income":       math.Round(tipIncome*100) / 100,
+			"unreconciled_orders":       unreconciledCount,
+			"total_unreconciled_amount": math.Round(unreconciledAmount*100) / 100,
+		},
+		"orders": rows,
+	})
+}
+
+type highVolumeCustomerRow struct {
+	CustomerID            uint   `json:"customer_id"`
+	Name                  string `json:"name"`
+	Email                 string `json:"email"`
+	OrderCount            int    `json:"order_count"`
+	MinutesSinceLastOrder int    `json:"minutes_since_last_order"`
+}
+
+// AdminGetHighVolumeCustomers lists customers who placed more than threshold
+// orders in the last few hours — admin only
+//
+// @Summary     Customers placing unusually many orders
+// @Tags        admin
+// @Produce     json
+// @Param       threshold  query  int  false  "Report customers with more orders than this (default 5)"
+// @Param       hours      query  int  false  "Window in hours, 1 to 168 (default 1)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/high-volume-customers [get]
+func AdminGetHighVolumeCustomers(c *gin.Context) {
+	threshold, err := strconv.Atoi(c.DefaultQuery("threshold", "5"))
+	if err != nil || threshold < 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.threshold_must_be_non_negative_integer", nil)
+		return
+	}
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "1"))
+	if err != nil || hours < 1 || hours > 168 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.hours_must_be_between_1_and_168", nil)
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	rows := []highVolumeCustomerRow{}
+	requestDB(c).Table("orders").
+		Select("orders.customer_id, users.name, users.email, COUNT(*) AS order_count, "+
+			"CAST((julianday('now') - julianday(MAX(orders.created_at))) * 1440 AS INTEGER) AS minutes_since_last_order").
+		Joins("JOIN users ON users.id = orders.customer_id").
+		Where("orders.created_at > ? AND orders.deleted_at IS NULL", since).
+		Group("orders.customer_id, users.name, users.email").
+		Having("COUNT(*) > ?", threshold).
+		Order("order_count DESC").
+		Scan(&rows)
+
+	c.JSON(http.StatusOK, gin.H{
+		"threshold": threshold,
+		"hours":     hours,
+		"count":     len(rows),
+		"customers": rows,
+	})
+}
+
+// AdminGetCancellationReasons counts the reasons customers gave for cancelling
+// orders placed in the date range — admin only. Every reason is listed, even
+// with a zero count.
+//
+// @Summary     Customer cancellation reasons
+// @Tags        admin
+// @Produce     json
+// @Param       from  query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to    query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/cancellation-reasons [get]
+func AdminGetCancellationReasons(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var counts []struct {
+		CancellationReason string
+		Count              int64
+	}
+	requestDB(c).Model(&models.Order{}).
+		Select("cancellation_reason, COUNT(*) AS count").
+		Where("status = ? AND cancellation_reason <> '' AND created_at >= ? AND created_at < ?", models.StatusCancelled, from, to).
+		Group("cancellation_reason").
+		Scan(&counts)
+	byReason := map[string]int64{}
+	var total int64
+	for _, row := range counts {
+		byReason[row.CancellationReason] = row.Count
+		total += row.Count
+	}
+
+	type reasonCount struct {
+		Reason string `json:"reason"`
+		Count  int64  `json:"count"`
+	}
+	reasons := make([]reasonCount, len(models.CancellationReasons))
+	for i, reason := range models.CancellationReasons {
+		reasons[i] = reasonCount{Reason: reason, Count: byReason[reason]}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.Format(dateLayout),
+		"to":      to.AddDate(0, 0, -1).Format(dateLayout),
+		"total":   total,
+		"reasons": reasons,
+	})
+}
+
+// AdminGetETAAccuracy reports how often deliveries rated in the date range
+// arrived within 1.2x their ETA, per restaurant and overall — admin only.
+// on_time_rate is the share customers themselves called on time.
+//
+// @Summary     Delivery ETA accuracy
+// @Tags        admin
+// @Produce     json
+// @Param       restaurant_id  query  int     false  "Only this restaurant"
+// @Param       from           query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to             query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/eta-accuracy [get]
+func AdminGetETAAccuracy(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	type accuracyRow struct {
+		RestaurantID        uint    `json:"restaurant_id"`
+		RestaurantName      string  `json:"restaurant_name"`
+		Ratings             int64   `json:"ratings"`
+		Accurate            int64   `json:"accurate"`
+		OnTime              int64   `json:"on_time"`
+		AvgEstimatedMinutes float64 `json:"avg_estimated_minutes"`
+		AvgActualMinutes    float64 `json:"avg_actual_minutes"`
+		AccuracyRate        float64 `json:"accuracy_rate"`
+		OnTimeRate          float64 `json:"on_time_rate"`
+	}
+	query := requestDB(c).Model(&models.ETARating{}).
+		Select("eta_ratings.restaurant_id, restaurants.name AS restaurant_name, COUNT(*) AS ratings, "+
+			"SUM(CASE WHEN "+etaAccurateSQL+" THEN 1 ELSE 0 END) AS accurate, "+
+			"SUM(CASE WHEN on_time THEN 1 ELSE 0 END) AS on_time, "+
+			"AVG(estimated_minutes) AS avg_estimated_minutes, AVG(actual_minutes) AS avg_actual_minutes").
+		Joins("JOIN restaurants ON restaurants.id = eta_ratings.restaurant_id").
+		Where("eta_ratings.created_at >= ? AND eta_ratings.created_at < ?", from, to)
+	if restaurantID := c.Query("restaurant_id"); restaurantID != "" {
+		query = query.Where("eta_ratings.restaurant_id = ?", restaurantID)
+	}
+	rows := []accuracyRow{}
+	query.Group("eta_ratings.restaurant_id, restaurants.name").Order("eta_ratings.restaurant_id").Scan(&rows)
+
+	var total accuracyRow
+	for i := range rows {
+		row := &rows[i]
+		total.Ratings += row.Ratings
+		total.Accurate += row.Accurate
+		total.OnTime += row.OnTime
+		row.AccuracyRate = math.Round(float64(row.Accurate)/float64(row.Ratings)*1000) / 1000
+		row.OnTimeRate = math.Round(float64(row.OnTime)/float64(row.Ratings)*1000) / 1000
+		row.AvgEstimatedMinutes = math.Round(row.AvgEstimatedMinutes*10) / 10
+		row.AvgActualMinutes = math.Round(row.AvgActualMinutes*10) / 10
+	}
+	overall := gin.H{"ratings": total.Ratings, "accuracy_rate": nil, "on_time_rate": nil}
+	if total.Ratings > 0 {
+		overall["accuracy_rate"] = math.Round(float64(total.Accurate)/float64(total.Ratings)*1000) / 1000
+		overall["on_time_rate"] = math.Round(float64(total.OnTime)/float64(total.Ratings)*1000) / 1000
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":        from.Format(dateLayout),
+		"to":          to.AddDate(0, 0, -1).Format(dateLayout),
+		"overall":     overall,
+		"restaurants": rows,
+	})
+}
@@ -1,30 +1,60 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
-	"food-delivery-api/config"
+	"food-delivery-api/apierror"
+	"food-delivery-api/currency"
+	"food-delivery-api/i18n"
 	"food-delivery-api/middleware"
 	"food-delivery-api/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // ── Restaurant Management ────────────────────────────────────────────────────
 
+const (
+	staleRestaurantMsg = "errors.restaurant_modified_concurrently"
+	staleMenuItemMsg   = "errors.menu_item_modified_concurrently"
+)
+
+// requestVersion reads the version a client last saw from a JSON update body
+func requestVersion(req map[string]interface{}) (int, bool) {
+	n, ok := req["version"].(float64)
+	if !ok || n != float64(int(n)) {
+		return 0, false
+	}
+	return int(n), true
+}
+
 type CreateRestaurantRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Cuisine     string `json:"cuisine"`
 	Address     string `json:"address" binding:"required"`
 	Description string `json:"description"`
+	Currency    string `json:"currency" binding:"omitempty,len=3,uppercase"` // ISO 4217, default USD
 }
 
 // CreateRestaurant lets a restaurant-role user create their restaurant
+//
+// @Summary     Create my restaurant
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  CreateRestaurantRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/ [post]
 func CreateRestaurant(c *gin.Context) {
 	ownerID := middleware.GetUserID(c)
 	var req CreateRestaurantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
 		return
 	}
 
@@ -34,134 +64,432 @@ func CreateRestaurant(c *gin.Context) {
 		Cuisine:     req.Cuisine,
 		Address:     req.Address,
 		Description: req.Description,
+		Currency:    req.Currency,
 		IsOpen:      true,
 	}
-	if err := config.DB.Create(&restaurant).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create restaurant"})
+	if err := requestDB(c).Create(&restaurant).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_restaurant", nil)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"message": "Restaurant created", "restaurant": restaurant})
 }
 
-// GetMyRestaurant fetches the restaurant owned by the logged-in user
+// GetMyRestaurant fetches the restaurant owned or staffed by the logged-in user
+//
+// @Summary     Get my restaurant
+// @Tags        restaurant
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/ [get]
 func GetMyRestaurant(c *gin.Context) {
-	ownerID := middleware.GetUserID(c)
+	userID := middleware.GetUserID(c)
 	var restaurant models.Restaurant
-	if err := config.DB.Preload("MenuItems").Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No restaurant found for your account"})
+	if err := managedRestaurant(requestDB(c).Preload("MenuItems"), userID, &restaurant); err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.no_restaurant_found_for_your_account", nil)
+		return
+	}
+	if !restaurant.IsActive {
+		c.JSON(http.StatusOK, gin.H{"restaurant": restaurant, "message": i18n.T(c, restaurantDeactivatedMsg)})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"restaurant": restaurant})
 }
 
-// UpdateRestaurant updates restaurant details
+// UpdateRestaurant updates restaurant details, including the delivery fee
+// formula and location. The body must carry the version the client last
+// read; if someone else saved since, it gets a 409.
+//
+// @Summary     Update my restaurant
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  object  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/ [put]
 func UpdateRestaurant(c *gin.Context) {
 	ownerID := middleware.GetUserID(c)
 	var restaurant models.Restaurant
-	if err := config.DB.Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
 		return
 	}
 	var req map[string]interface{}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	version, ok := requestVersion(req)
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.version_required", nil)
 		return
 	}
 	// Only allow safe fields
-	allowed := map[string]bool{"name": true, "cuisine": true, "address": true, "description": true, "is_open": true}
+	allowed := map[string]bool{"name": true, "cuisine": true, "address": true, "description": true}
 	update := map[string]interface{}{}
 	for k, v := range req {
 		if allowed[k] {
 			update[k] = v
 		}
 	}
-	config.DB.Model(&restaurant).Updates(update)
+	if v, ok := req["max_orders_per_minute"]; ok {
+		n, isNum := v.(float64)
+		if !isNum || n < 1 || n != float64(int(n)) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_max_orders_per_minute", nil)
+			return
+		}
+		update["max_orders_per_minute"] = int(n)
+	}
+	minItems, maxItems := restaurant.MinItemCount, restaurant.MaxItemCount
+	if v, ok := req["min_item_count"]; ok {
+		n, isNum := v.(float64)
+		if !isNum || n < 1 || n != float64(int(n)) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_min_item_count", nil)
+			return
+		}
+		minItems = int(n)
+		update["min_item_count"] = minItems
+	}
+	if v, ok := req["max_item_count"]; ok {
+		n, isNum := v.(float64)
+		if !isNum || n < 1 || n != float64(int(n)) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_max_item_count", nil)
+			return
+		}
+		maxItems = int(n)
+		update["max_item_count"] = maxItems
+	}
+	if minItems > maxItems {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.min_item_count_above_max", nil)
+		return
+	}
+	if v, ok := req["auto_assign_driver"]; ok {
+		on, isBool := v.(bool)
+		if !isBool {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_auto_assign_driver", nil)
+			return
+		}
+		update["auto_assign_driver"] = on
+	}
+	if v, ok := req["currency"]; ok {
+		code, isString := v.(string)
+		if !isString || !currency.ValidCode(code) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_currency", nil)
+			return
+		}
+		update["currency"] = code
+	}
+	if apiErr := deliveryPricingUpdate(req, update); apiErr != nil {
+		apierror.RespondError(c, apiErr)
+		return
+	}
+	if v, ok := req["manual_override_until"]; ok {
+		until, apiErr := parseOverrideUntil(v)
+		if apiErr != nil {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		update["manual_override_until"] = until
+	}
+	if open, _ := req["is_open"].(bool); open && !restaurant.IsActive {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, restaurantDeactivatedMsg, nil)
+		return
+	}
+	update["version"] = gorm.Expr("version + 1")
+	res := requestDB(c).Model(&restaurant).Where("version = ?", version).Updates(update)
+	if res.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_restaurant", nil)
+		return
+	}
+	if res.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, staleRestaurantMsg, gin.H{"version": restaurant.Version})
+		return
+	}
+	requestDB(c).First(&restaurant, restaurant.ID)
+	invalidateMenuCache(restaurant.ID) // the menu response carries the restaurant name and delivery pricing
+
+	// Opening goes through setRestaurantOpen so the waitlist hears about it
+	if open, ok := req["is_open"].(bool); ok {
+		setRestaurantOpen(&restaurant, open, ownerID, "manual")
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Restaurant updated", "restaurant": restaurant})
 }
 
+// parseOverrideUntil reads manual_override_until from a JSON body; null clears it
+func parseOverrideUntil(v interface{}) (*time.Time, *apierror.Error) {
+	if v == nil {
+		return nil, nil
+	}
+	str, ok := v.(string)
+	if !ok {
+		return nil, apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.manual_override_until_must_be_rfc3339", nil)
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil, apierror.New(http.StatusBadRequest, apierror.ErrValidation, "errors.manual_override_until_must_be_rfc3339", nil)
+	}
+	return &t, nil
+}
+
+type ToggleOpenRequest struct {
+	// While in the future, the operating-hours scheduler leaves is_open alone
+	ManualOverrideUntil *time.Time `json:"manual_override_until"`
+}
+
+// ToggleRestaurantOpen flips the restaurant between open and closed. A
+// deactivated restaurant can close but not reopen.
+//
+// @Summary     Open or close my restaurant
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  ToggleOpenRequest  false  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/toggle-open [put]
+func ToggleRestaurantOpen(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	var req ToggleOpenRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+	// Reopening would also notify the waitlist
+	if !restaurant.IsOpen && !restaurant.IsActive {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, restaurantDeactivatedMsg, nil)
+		return
+	}
+	if req.ManualOverrideUntil != nil {
+		requestDB(c).Model(&restaurant).Update("manual_override_until", req.ManualOverrideUntil)
+	}
+	setRestaurantOpen(&restaurant, !restaurant.IsOpen, ownerID, "manual")
+	c.JSON(http.StatusOK, gin.H{
+		"message":               "Restaurant updated",
+		"is_open":               restaurant.IsOpen,
+		"manual_override_until": restaurant.ManualOverrideUntil,
+	})
+}
+
 // ── Menu Management ─────────────────────────────────────────────────────────
 
 type CreateMenuItemRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Category    string  `json:"category"`
-	IsVeg       bool    `json:"is_veg"`
+	Name          string  `json:"name" binding:"required"`
+	Description   string  `json:"description"`
+	Price         float64 `json:"price" binding:"required,gt=0"`
+	Category      string  `json:"category"`
+	IsVeg         bool    `json:"is_veg"`
+	TrackStock    bool    `json:"track_stock"`
+	StockQuantity int     `json:"stock_quantity" binding:"min=0"`
+	// Per serving, all optional
+	Calories *int     `json:"calories" binding:"omitempty,min=0"`
+	ProteinG *float64 `json:"protein_g" binding:"omitempty,min=0"`
+	CarbsG   *float64 `json:"carbs_g" binding:"omitempty,min=0"`
+	FatG     *float64 `json:"fat_g" binding:"omitempty,min=0"`
 }
 
 // AddMenuItem adds a new item to the restaurant's menu
+//
+// @Summary     Add a menu item
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  CreateMenuItemRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu [post]
 func AddMenuItem(c *gin.Context) {
 	ownerID := middleware.GetUserID(c)
 	var restaurant models.Restaurant
-	if err := config.DB.Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Create a restaurant first before adding menu items"})
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.create_restaurant_before_adding_menu_items")
+		c.Abort()
 		return
 	}
 
 	var req CreateMenuItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
 		return
 	}
 
 	item := models.MenuItem{
-		RestaurantID: restaurant.ID,
-		Name:         req.Name,
-		Description:  req.Description,
-		Price:        req.Price,
-		Category:     req.Category,
-		IsVeg:        req.IsVeg,
-		IsAvailable:  true,
+		RestaurantID:  restaurant.ID,
+		Name:          req.Name,
+		Description:   req.Description,
+		Price:         req.Price,
+		Category:      req.Category,
+		IsVeg:         req.IsVeg,
+		IsAvailable:   true,
+		TrackStock:    req.TrackStock,
+		StockQuantity: req.StockQuantity,
+		Calories:      req.Calories,
+		ProteinG:      req.ProteinG,
+		CarbsG:        req.CarbsG,
+		FatG:          req.FatG,
 	}
-	if err := config.DB.Create(&item).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add menu item"})
+	if err := requestDB(c).Create(&item).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_add_menu_item", nil)
 		return
 	}
+	invalidateMenuCache(restaurant.ID)
 	c.JSON(http.StatusCreated, gin.H{"message": "Menu item added", "item": item})
 }
 
-// UpdateMenuItem updates a menu item (only by the owner)
+// UpdateMenuItem updates a menu item (only by the owner). Like
+// UpdateRestaurant, the body must carry the item's current version.
+//
+// @Summary     Update a menu item
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Param       body  body  object  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId} [put]
 func UpdateMenuItem(c *gin.Context) {
 	ownerID := middleware.GetUserID(c)
 	itemID := c.Param("itemId")
 
 	var item models.MenuItem
-	if err := config.DB.First(&item, itemID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
+	if err := requestDB(c).First(&item, itemID).Error; err != nil {
+		c.Error(err).SetMeta("errors.menu_item_not_found")
+		c.Abort()
 		return
 	}
 
 	// Verify ownership
 	var restaurant models.Restaurant
-	if err := config.DB.Where("id = ? AND owner_id = ?", item.RestaurantID, ownerID).First(&restaurant).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't own this menu item"})
+	if err := requestDB(c).Where("id = ? AND owner_id = ?", item.RestaurantID, ownerID).First(&restaurant).Error; err != nil {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.you_dont_own_this_menu_item", nil)
 		return
 	}
 
 	var req map[string]interface{}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	version, ok := requestVersion(req)
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.version_required", nil)
 		return
 	}
-	config.DB.Model(&item).Updates(req)
+	update := map[string]interface{}{}
+	for k, v := range req {
+		update[k] = v
+	}
+	if v, ok := req["available_months"]; ok {
+		months, err := normalizeAvailableMonths(v)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+		update["available_months"] = months
+	}
+	if apiErr := nutritionUpdate(req, update); apiErr != nil {
+		apierror.RespondError(c, apiErr)
+		return
+	}
+	update["version"] = gorm.Expr("version + 1")
+
+	oldPrice := item.Price
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&item).Where("version = ?", version).Updates(update)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apierror.New(http.StatusConflict, apierror.ErrConflict, staleMenuItemMsg, gin.H{"version": item.Version})
+		}
+		if err := tx.First(&item, item.ID).Error; err != nil {
+			return err
+		}
+		if item.Price == oldPrice {
+			return nil
+		}
+		return tx.Create(&models.MenuItemPriceHistory{
+			MenuItemID: item.ID,
+			OldPrice:   oldPrice,
+			NewPrice:   item.Price,
+			ChangedBy:  ownerID,
+			ChangedAt:  time.Now(),
+		}).Error
+	})
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_menu_item", nil)
+		return
+	}
+	// Making an 86'd item available again counts as restoring it
+	if available, ok := req["is_available"].(bool); ok && available && item.EightySixedAt != nil {
+		if err := restoreEightySixed(requestDB(c), item); err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_menu_item", nil)
+			return
+		}
+		// Re-read into a fresh struct: scanning leaves the old eighty_sixed_at in place of NULL
+		item = models.MenuItem{ID: item.ID}
+		requestDB(c).First(&item)
+	}
+	invalidateMenuCache(item.RestaurantID)
 	c.JSON(http.StatusOK, gin.H{"message": "Menu item updated", "item": item})
 }
 
 // DeleteMenuItem removes a menu item
+//
+// @Summary     Delete a menu item
+// @Tags        restaurant
+// @Produce     json
+// @Param       itemId  path  int  true  "Menu item ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/menu/{itemId} [delete]
 func DeleteMenuItem(c *gin.Context) {
 	ownerID := middleware.GetUserID(c)
 	itemID := c.Param("itemId")
 
 	var item models.MenuItem
-	if err := config.DB.First(&item, itemID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
+	if err := requestDB(c).First(&item, itemID).Error; err != nil {
+		c.Error(err).SetMeta("errors.menu_item_not_found")
+		c.Abort()
 		return
 	}
 	var restaurant models.Restaurant
-	if err := config.DB.Where("id = ? AND owner_id = ?", item.RestaurantID, ownerID).First(&restaurant).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't own this menu item"})
+	if err := requestDB(c).Where("id = ? AND owner_id = ?", item.RestaurantID, ownerID).First(&restaurant).Error; err != nil {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.you_dont_own_this_menu_item", nil)
 		return
 	}
-	config.DB.Delete(&item)
+	requestDB(c).Delete(&item)
+	requestDB(c).Where("item_a_id = ? OR item_b_id = ?", item.ID, item.ID).Delete(&models.MenuItemPairing{})
+	invalidateMenuCache(item.RestaurantID)
 	c.JSON(http.StatusOK, gin.H{"message": "Menu item deleted"})
 }
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MaintenanceRestaurantActivation is logged when an admin deactivates or reactivates a restaurant
+const MaintenanceRestaurantActivation = "RESTAURANT_ACTIVATION"
+
+const restaurantDeactivatedMsg = "errors.restaurant_deactivated"
+
+type DeactivateRestaurantRequest struct {
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+// AdminDeactivateRestaurant closes a restaurant and hides it from customers
+// without deleting anything — admin only
+//
+// @Summary     Deactivate a restaurant
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int                          true   "Restaurant ID"
+// @Param       body  body  DeactivateRestaurantRequest  false  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/deactivate [put]
+func AdminDeactivateRestaurant(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req DeactivateRestaurantRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	if !restaurant.IsActive {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.restaurant_is_already_deactivated", nil)
+		return
+	}
+
+	reason := "deactivated by admin"
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&restaurant).Updates(map[string]interface{}{"is_active": false, "is_open": false}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.RestaurantStatusLog{
+			RestaurantID: restaurant.ID,
+			IsOpen:       false,
+			ChangedBy:    adminID,
+			Reason:       reason,
+		}).Error; err != nil {
+			return err
+		}
+		return logMaintenance(tx, MaintenanceRestaurantActivation, &adminID, gin.H{
+			"restaurant_id": restaurant.ID,
+			"active":        false,
+			"reason":        req.Reason,
+		})
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_deactivate_restaurant", nil)
+		return
+	}
+	invalidateMenuCache(restaurant.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Restaurant deactivated", "restaurant": restaurant})
+}
+
+// AdminReactivateRestaurant makes a deactivated restaurant visible again. It
+// stays closed until its owner or the operating-hours scheduler opens it.
+//
+// @Summary     Reactivate a restaurant
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/reactivate [put]
+func AdminReactivateRestaurant(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	if restaurant.IsActive {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.restaurant_is_already_active", nil)
+		return
+	}
+	var suspension models.SuspensionEvent
+	if openSuspension(requestDB(c), restaurant.ID, &suspension) == nil {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.restaurant_is_suspended_reinstate_it_instead",
+			gin.H{"suspension_event_id": suspension.ID})
+		return
+	}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&restaurant).Update("is_active", true).Error; err != nil {
+			return err
+		}
+		return logMaintenance(tx, MaintenanceRestaurantActivation, &adminID, gin.H{
+			"restaurant_id": restaurant.ID,
+			"active":        true,
+		})
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_reactivate_restaurant", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Restaurant reactivated", "restaurant": restaurant})
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const restaurantComparisonCacheTTL = 10 * time.Minute
+
+// comparisonSorts maps ?sort_by= to the column it ranks by
+var comparisonSorts = map[string]string{
+	"revenue":          "total_revenue",
+	"rating":           "avg_rating",
+	"fulfillment_rate": "fulfillment_rate",
+}
+
+// RestaurantMetrics is one restaurant's performance over a date range.
+// Revenue is delivered orders only, in the base currency. FulfillmentRate is
+// delivered / (total - still placed); rates and averages are null without data.
+type RestaurantMetrics struct {
+	ID              uint     `json:"id"`
+	Name            string   `json:"name"`
+	Cuisine         string   `json:"cuisine"`
+	TotalOrders     int64    `json:"total_orders"`
+	DeliveredOrders int64    `json:"delivered_orders"`
+	CancelledOrders int64    `json:"cancelled_orders"`
+	FulfillmentRate *float64 `json:"fulfillment_rate"`
+	TotalRevenue    float64  `json:"total_revenue"`
+	AvgOrderValue   *float64 `json:"avg_order_value"`
+	AvgRating       *float64 `json:"avg_rating"`
+	AvgPrepMinutes  *float64 `json:"avg_prep_minutes"`
+}
+
+// Per-restaurant order counts and revenue, ratings and CONFIRMED → READY_FOR_PICKUP
+// times, all for orders placed in [@from, @to)
+const restaurantComparisonCTEs = `
+WITH o AS (
+	SELECT restaurant_id,
+		COUNT(*) AS total,
+		SUM(CASE WHEN status = @delivered THEN 1 ELSE 0 END) AS delivered,
+		SUM(CASE WHEN status = @cancelled THEN 1 ELSE 0 END) AS cancelled,
+		SUM(CASE WHEN status = @placed THEN 1 ELSE 0 END) AS placed,
+		SUM(CASE WHEN status = @delivered THEN total_price_base ELSE 0 END) AS revenue
+	FROM orders
+	WHERE created_at >= @from AND created_at < @to AND deleted_at IS NULL
+	GROUP BY restaurant_id
+), r AS (
+	SELECT reviews.restaurant_id, SUM(reviews.restaurant_rating) AS rating_sum, COUNT(*) AS rating_count
+	FROM reviews
+	JOIN orders ON orders.id = reviews.order_id
+	WHERE orders.created_at >= @from AND orders.created_at < @to AND orders.deleted_at IS NULL
+	GROUP BY reviews.restaurant_id
+), p AS (
+	SELECT orders.restaurant_id,
+		SUM((julianday(b.created_at) - julianday(a.created_at)) * 1440) AS prep_sum, COUNT(*) AS prep_count
+	FROM order_status_histories a
+	JOIN order_status_histories b ON b.order_id = a.order_id AND b.to_status = @ready AND b.from_status <> b.to_status
+	JOIN orders ON orders.id = a.order_id
+	WHERE a.to_status = @confirmed AND a.from_status <> a.to_status AND orders.created_at >= @from AND orders.created_at < @to
+		AND orders.deleted_at IS NULL
+	GROUP BY orders.restaurant_id
+)`
+
+const restaurantComparisonFrom = `
+FROM restaurants
+JOIN o ON o.restaurant_id = restaurants.id
+LEFT JOIN r ON r.restaurant_id = restaurants.id
+LEFT JOIN p ON p.restaurant_id = restaurants.id
+WHERE (@cuisine = '' OR LOWER(restaurants.cuisine) = LOWER(@cuisine))`
+
+// restaurantComparison ranks restaurants in one query and works out the
+// platform-wide totals row from the same CTEs
+func restaurantComparison(db *gorm.DB, args map[string]interface{}, sortCol string, limit, offset int) ([]RestaurantMetrics, RestaurantMetrics, int64, error) {
+	rows := []RestaurantMetrics{}
+	err := db.Raw(restaurantComparisonCTEs+`
+SELECT restaurants.id, restaurants.name, restaurants.cuisine,
+	o.total AS total_orders, o.delivered AS delivered_orders, o.cancelled AS cancelled_orders,
+	CASE WHEN o.total - o.placed > 0 THEN ROUND(o.delivered * 1.0 / (o.total - o.placed), 4) END AS fulfillment_rate,
+	ROUND(o.revenue, 2) AS total_revenue,
+	CASE WHEN o.delivered > 0 THEN ROUND(o.revenue / o.delivered, 2) END AS avg_order_value,
+	ROUND(r.rating_sum * 1.0 / r.rating_count, 2) AS avg_rating,
+	ROUND(p.prep_sum / p.prep_count, 1) AS avg_prep_minutes`+
+		restaurantComparisonFrom+fmt.Sprintf(`
+ORDER BY %[1]s IS NULL, %[1]s DESC, restaurants.id
+LIMIT @limit OFFSET @offset`, sortCol), withArgs(args, "limit", limit, "offset", offset)).Scan(&rows).Error
+	if err != nil {
+		return nil, RestaurantMetrics{}, 0, err
+	}
+
+	var totals struct {
+		Restaurants int64
+		RestaurantMetrics
+	}
+	err = db.Raw(restaurantComparisonCTEs+`
+SELECT COUNT(*) AS restaurants,
+	COALESCE(SUM(o.total), 0) AS total_orders, COALESCE(SUM(o.delivered), 0) AS delivered_orders,
+	COALESCE(SUM(o.cancelled), 0) AS cancelled_orders,
+	CASE WHEN SUM(o.total - o.placed) > 0 THEN ROUND(SUM(o.delivered) * 1.0 / SUM(o.total - o.placed), 4) END AS fulfillment_rate,
+	ROUND(COALESCE(SUM(o.revenue), 0), 2) AS total_revenue,
+	CASE WHEN SUM(o.delivered) > 0 THEN ROUND(SUM(o.revenue) / SUM(o.delivered), 2) END AS avg_order_value,
+	ROUND(SUM(r.rating_sum) * 1.0 / SUM(r.rating_count), 2) AS avg_rating,
+	ROUND(SUM(p.prep_sum) / SUM(p.prep_count), 1) AS avg_prep_minutes`+
+		restaurantComparisonFrom, args).Scan(&totals).Error
+	if err != nil {
+		return nil, RestaurantMetrics{}, 0, err
+	}
+	totals.Name = "All restaurants"
+	return rows, totals.RestaurantMetrics, totals.Restaurants, nil
+}
+
+// withArgs copies args and adds key/value pairs
+func withArgs(args map[string]interface{}, kv ...interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(args)+len(kv)/2)
+	for k, v := range args {
+		out[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		out[kv[i].(string)] = kv[i+1]
+	}
+	return out
+}
+
+// AdminGetRestaurantComparison ranks restaurants by revenue, rating or
+// fulfillment rate over a date range, with platform totals to benchmark
+// against — admin only. Cached for 10 minutes per query.
+//
+// @Summary     Compare restaurants
+// @Tags        admin
+// @Produce     json
+// @Param       from       query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to         query  string  false  "End date (YYYY-MM-DD), default today"
+// @Param       sort_by    query  string  false  "revenue (default), rating or fulfillment_rate"
+// @Param       cuisine    query  string  false  "Only this cuisine"
+// @Param       page       query  int     false  "Page number (default 1)"
+// @Param       page_size  query  int     false  "Page size (default 20, max 100)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     500  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/analytics/restaurant-comparison [get]
+func AdminGetRestaurantComparison(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	sortBy := c.DefaultQuery("sort_by", "revenue")
+	sortCol, ok := comparisonSorts[sortBy]
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_comparison_sort_by", nil)
+		return
+	}
+	cuisine := strings.TrimSpace(c.Query("cuisine"))
+	page, pageSize := parsePagination(c)
+
+	key := fmt.Sprintf("restaurant-comparison:%s:%s:%s:%s:%d:%d",
+		from.Format(dateLayout), to.Format(dateLayout), sortBy, strings.ToLower(cuisine), page, pageSize)
+	if body, ok := cache.Default.Get(key); ok {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+
+	args := map[string]interface{}{
+		"from":      from,
+		"to":        to,
+		"cuisine":   cuisine,
+		"placed":    models.StatusPlaced,
+		"confirmed": models.StatusConfirmed,
+		"ready":     models.StatusReadyForPickup,
+		"delivered": models.StatusDelivered,
+		"cancelled": models.StatusCancelled,
+	}
+	// Cached for everyone asking the same question, so not tied to this request
+	rows, totals, total, err := restaurantComparison(config.DB, args, sortCol, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Printf("restaurant comparison: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_build_comparison", nil)
+		return
+	}
+
+	body, err := json.Marshal(gin.H{
+		"from":          from.Format(dateLayout),
+		"to":            to.AddDate(0, 0, -1).Format(dateLayout),
+		"sort_by":       sortBy,
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"totals":        totals,
+		"page":          page,
+		"page_size":     pageSize,
+		"total":         total,
+		"count":         len(rows),
+		"restaurants":   rows,
+		"generated_at":  time.Now(),
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_build_comparison", nil)
+		return
+	}
+	cache.Default.Set(key, body, restaurantComparisonCacheTTL)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -1,86 +1,187 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
-	"food-delivery-api/config"
+	"food-delivery-api/apierror"
 	"food-delivery-api/middleware"
 	"food-delivery-api/models"
 	"food-delivery-api/statemachine"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// GetRestaurantOrders returns all orders for the restaurant owner
+// Restaurant owners can look at up to 90 days of orders at a time
+const restaurantOrderMaxRangeDays = 90
+
+// GetRestaurantOrders returns the restaurant's orders for a date window, paginated
+//
+// @Summary     List my restaurant's orders for a date window
+// @Tags        restaurant
+// @Produce     json
+// @Param       from  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param       to  query  string  false  "End date (YYYY-MM-DD)"
+// @Param       status  query  string  false  "Filter by status"
+// @Param       page  query  int  false  "Page number"
+// @Param       page_size  query  int  false  "Page size"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/orders [get]
 func GetRestaurantOrders(c *gin.Context) {
-	ownerID := middleware.GetUserID(c)
+	userID := middleware.GetUserID(c)
 
 	var restaurant models.Restaurant
-	if err := config.DB.Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No restaurant found for your account"})
+	if err := managedRestaurant(requestDB(c), userID, &restaurant); err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.no_restaurant_found_for_your_account", nil)
 		return
 	}
 
-	var orders []models.Order
-	query := config.DB.Preload("Items.MenuItem").Preload("Customer").Preload("Driver").
-		Where("restaurant_id = ?", restaurant.ID)
+	from, to, err := parseDateRange(c, 30, restaurantOrderMaxRangeDays)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	inWindow := func() *gorm.DB {
+		return requestDB(c).Model(&models.Order{}).
+			Where("restaurant_id = ? AND created_at >= ? AND created_at < ?", restaurant.ID, from, to)
+	}
 
-	// Filter by status
+	// Group counts by status — novelty: dashboard summary over the whole window
+	var statusCounts []struct {
+		Status string
+		Count  int
+	}
+	inWindow().Select("status, COUNT(*) AS count").Group("status").Scan(&statusCounts)
+	summary := map[string]int{}
+	for _, sc := range statusCounts {
+		summary[sc.Status] = sc.Count
+	}
+	var autoCancelled int64
+	inWindow().Where("auto_cancelled = ?", true).Count(&autoCancelled)
+	summary["auto_cancelled"] = int(autoCancelled)
+
+	// Revenue excludes the delivery and service fees, which aren't the restaurant's money
+	var revenue float64
+	inWindow().Where("status = ?", models.StatusDelivered).
+		Select("COALESCE(SUM(total_price - delivery_fee - service_fee - tip_amount), 0)").Scan(&revenue)
+
+	// Top 10 items sold in the window (cancelled orders don't count)
+	var itemsSold []struct {
+		ItemName     string `json:"item_name"`
+		QuantitySold int    `json:"quantity_sold"`
+	}
+	requestDB(c).Table("order_items").
+		Select("order_items.name AS item_name, SUM(order_items.quantity) AS quantity_sold").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.restaurant_id = ? AND orders.created_at >= ? AND orders.created_at < ? AND orders.status <> ? AND orders.deleted_at IS NULL",
+			restaurant.ID, from, to, models.StatusCancelled).
+		Group("order_items.name").
+		Order("quantity_sold desc").
+		Limit(10).
+		Scan(&itemsSold)
+
+	query := inWindow()
 	if status := c.Query("status"); status != "" {
 		query = query.Where("status = ?", status)
 	}
+	var total int64
+	query.Count(&total)
 
-	query.Order("created_at desc").Find(&orders)
-
-	// Group counts by status — novelty: dashboard summary
-	summary := map[string]int{}
-	for _, o := range orders {
-		summary[string(o.Status)]++
+	var orders []models.Order
+	query.Preload("Items.MenuItem").Preload("Customer").Preload("Driver").
+		Order("created_at desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&orders)
+
+	setOrderLinks(c, orders)
+	// Show owners what the platform keeps so they know their net per order
+	views := make([]restaurantOrderView, len(orders))
+	for i, o := range orders {
+		views[i] = restaurantOrderView{
+			Order:              o,
+			ServiceFeeDeducted: o.ServiceFee,
+			NetAmount:          o.Subtotal(),
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"restaurant":    restaurant.Name,
-		"order_summary": summary,
-		"count":         len(orders),
-		"orders":        orders,
+		"restaurant":        restaurant.Name,
+		"from":              from.Format(dateLayout),
+		"to":                to.AddDate(0, 0, -1).Format(dateLayout),
+		"order_summary":     summary,
+		"revenue_in_period": revenue,
+		"items_sold":        itemsSold,
+		"page":              page,
+		"page_size":         pageSize,
+		"total":             total,
+		"count":             len(orders),
+		"orders":            views,
 	})
 }
 
+// restaurantOrderView is an order as its restaurant sees it
+type restaurantOrderView struct {
+	models.Order
+	ServiceFeeDeducted float64 `json:"service_fee_deducted"`
+	NetAmount          float64 `json:"net_amount"`
+}
+
 type UpdateOrderStatusRequest struct {
 	Status models.OrderStatus `json:"status" binding:"required"`
 	Note   string             `json:"note"`
 }
 
 // UpdateOrderStatus handles restaurant's state transitions
+//
+// @Summary     Move an order to its next state
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Param       body  body  UpdateOrderStatusRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/orders/{id}/status [put]
 func UpdateOrderStatus(c *gin.Context) {
-	ownerID := middleware.GetUserID(c)
+	userID := middleware.GetUserID(c)
 	orderID := c.Param("id")
 
 	var restaurant models.Restaurant
-	if err := config.DB.Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No restaurant found for your account"})
+	if err := managedRestaurant(requestDB(c), userID, &restaurant); err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.no_restaurant_found_for_your_account", nil)
 		return
 	}
 
 	var order models.Order
-	if err := config.DB.First(&order, orderID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	if err := requestDB(c).First(&order, orderID).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
 		return
 	}
 	if order.RestaurantID != restaurant.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "This order does not belong to your restaurant"})
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.order_not_from_your_restaurant", nil)
 		return
 	}
 
 	var req UpdateOrderStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
 		return
 	}
 
 	if err := statemachine.CanTransition(order.Status, req.Status, "restaurant"); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"error":             "Invalid state transition",
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrInvalidTransition, "errors.invalid_state_transition", gin.H{
 			"current_status":    order.Status,
 			"requested":         req.Status,
 			"reason":            err.Error(),
@@ -90,26 +191,56 @@ func UpdateOrderStatus(c *gin.Context) {
 	}
 
 	prevStatus := order.Status
-	config.DB.Model(&order).Update("status", req.Status)
-
-	// Auto-set estimated time when preparing
-	if req.Status == models.StatusPreparing {
-		config.DB.Model(&order).Update("estimated_time", 20)
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		update := map[string]interface{}{"status": req.Status}
+		// Auto-set estimated time when preparing
+		if req.Status == models.StatusPreparing {
+			update["estimated_time"] = 20
+		}
+		res := tx.Model(&models.Order{}).Where("id = ? AND status = ?", order.ID, prevStatus).Updates(update)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.order_modified_concurrently", nil)
+		}
+		if req.Status == models.StatusCancelled {
+			if err := restoreStock(tx, order.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: prevStatus,
+			ToStatus:   req.Status,
+			ChangedBy:  userID,
+			Note:       req.Note,
+		}).Error
+	})
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_order_status", nil)
+		return
 	}
-
-	history := models.OrderStatusHistory{
-		OrderID:    order.ID,
-		FromStatus: prevStatus,
-		ToStatus:   req.Status,
-		ChangedBy:  ownerID,
-		Note:       req.Note,
+	publishStatusChange(order, prevStatus, req.Status)
+	switch req.Status {
+	case models.StatusConfirmed:
+		resetAutoCancels(requestDB(c), restaurant.ID)
+	case models.StatusCancelled:
+		invalidateMenuCache(restaurant.ID)
 	}
-	config.DB.Create(&history)
 
+	order.Status = req.Status
 	c.JSON(http.StatusOK, gin.H{
 		"message":         "Order status updated",
 		"order_id":        order.ID,
+		"invoice_number":  order.InvoiceNumber,
 		"previous_status": string(prevStatus),
 		"current_status":  string(req.Status),
+		"links":           orderLinks(c, &order),
 	})
 }
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	restaurantStatsCacheTTL = 5 * time.Minute
+	maxStatsRangeDays       = 366 // for period=custom
+)
+
+type RestaurantStats struct {
+	Period             string    `json:"period"`
+	From               string    `json:"from"`
+	To                 string    `json:"to"`
+	TotalOrders        int64     `json:"total_orders"`
+	Delivered          int64     `json:"delivered"`
+	Cancelled          int64     `json:"cancelled"`
+	TotalRevenue       float64   `json:"total_revenue"` // delivered orders only
+	AvgOrderValue      float64   `json:"avg_order_value"`
+	NewCustomers       int64     `json:"new_customers"` // first order anywhere on the platform was in the period
+	ReturningCustomers int64     `json:"returning_customers"`
+	ItemsSold          int64     `json:"items_sold"`
+	ETARatings         int64     `json:"eta_ratings"`
+	ETAAccuracyRate    *float64  `json:"eta_accuracy_rate"` // share of rated deliveries within 1.2x the ETA; null without ratings
+	SLA                SLAStats  `json:"sla"`
+	CachedAt           time.Time `json:"cached_at"`
+}
+
+// statsPeriod maps ?period= to a [start, end) range in local days
+func statsPeriod(c *gin.Context, now time.Time) (start, end time.Time, err error) {
+	today := startOfDay(now)
+	switch c.DefaultQuery("period", "today") {
+	case "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case "this_week":
+		// Weeks start on Monday
+		offset := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -offset), today.AddDate(0, 0, 1), nil
+	case "this_month":
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.Local), today.AddDate(0, 0, 1), nil
+	case "custom":
+		if c.Query("from") == "" || c.Query("to") == "" {
+			return start, end, fmt.Errorf("period=custom needs both 'from' and 'to'")
+		}
+		return parseDateRange(c, 0, maxStatsRangeDays)
+	}
+	return start, end, fmt.Errorf("period must be one of today, this_week, this_month, custom")
+}
+
+// computeRestaurantStats aggregates a restaurant's orders created in [start, end)
+func computeRestaurantStats(db *gorm.DB, restaurantID uint, start, end time.Time) RestaurantStats {
+	var stats RestaurantStats
+	var orders struct {
+		Total     int64
+		Delivered int64
+		Cancelled int64
+		Revenue   float64
+		Customers int64
+	}
+	db.Model(&models.Order{}).
+		Select("COUNT(*) AS total, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS delivered, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS cancelled, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN total_price ELSE 0 END), 0) AS revenue, "+
+			"COUNT(DISTINCT customer_id) AS customers",
+			models.StatusDelivered, models.StatusCancelled, models.StatusDelivered).
+		Where("restaurant_id = ? AND created_at >= ? AND created_at < ?", restaurantID, start, end).
+		Scan(&orders)
+
+	// Customers whose first order on the whole platform falls in the period
+	firstOrders := db.Model(&models.Order{}).Select("customer_id").
+		Group("customer_id").Having("MIN(created_at) >= ? AND MIN(created_at) < ?", start, end)
+	db.Model(&models.Order{}).Distinct("customer_id").
+		Where("restaurant_id = ? AND created_at >= ? AND created_at < ?", restaurantID, start, end).
+		Where("customer_id IN (?)", firstOrders).
+		Count(&stats.NewCustomers)
+
+	db.Raw(`SELECT COALESCE(SUM(order_items.quantity), 0) FROM order_items
+		JOIN orders ON orders.id = order_items.order_id
+		WHERE orders.restaurant_id = ? AND orders.status = ? AND orders.created_at >= ? AND orders.created_at < ?
+			AND orders.deleted_at IS NULL`,
+		restaurantID, models.StatusDelivered, start, end).Scan(&stats.ItemsSold)
+
+	var eta struct {
+		Accurate int64
+		Count    int64
+	}
+	db.Model(&models.ETARating{}).
+		Select("COALESCE(SUM(CASE WHEN "+etaAccurateSQL+" THEN 1 ELSE 0 END), 0) AS accurate, COUNT(*) AS count").
+		Where("restaurant_id = ? AND created_at >= ? AND created_at < ?", restaurantID, start, end).
+		Scan(&eta)
+	stats.ETARatings = eta.Count
+	if eta.Count > 0 {
+		rate := math.Round(float64(eta.Accurate)/float64(eta.Count)*1000) / 1000
+		stats.ETAAccuracyRate = &rate
+	}
+
+	stats.TotalOrders = orders.Total
+	stats.Delivered = orders.Delivered
+	stats.Cancelled = orders.Cancelled
+	stats.TotalRevenue = math.Round(orders.Revenue*100) / 100
+	if orders.Delivered > 0 {
+		stats.AvgOrderValue = math.Round(orders.Revenue/float64(orders.Delivered)*100) / 100
+	}
+	stats.ReturningCustomers = orders.Customers - stats.NewCustomers
+	stats.SLA = slaStats(db, restaurantID, start, end)
+	return stats
+}
+
+// GetRestaurantStats returns order and revenue KPIs for the caller's restaurant.
+// Results for the named periods are cached for five minutes.
+//
+// @Summary     Order and revenue stats for my restaurant
+// @Tags        restaurant
+// @Produce     json
+// @Param       period  query  string  false  "today (default), this_week, this_month or custom"
+// @Param       from    query  string  false  "Start date for period=custom (YYYY-MM-DD)"
+// @Param       to      query  string  false  "End date for period=custom (YYYY-MM-DD)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/stats [get]
+func GetRestaurantStats(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.no_restaurant_found_for_your_account")
+		c.Abort()
+		return
+	}
+	now := time.Now()
+	start, end, err := statsPeriod(c, now)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	period := c.DefaultQuery("period", "today")
+
+	key := fmt.Sprintf("restaurant-stats:%d:%s:%s", restaurant.ID, period, start.Format(dateLayout))
+	if period != "custom" {
+		if body, ok := cache.Default.Get(key); ok {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+			return
+		}
+	}
+
+	stats := computeRestaurantStats(requestDB(c), restaurant.ID, start, end)
+	stats.Period = period
+	stats.From = start.Format(dateLayout)
+	stats.To = end.AddDate(0, 0, -1).Format(dateLayout)
+	stats.CachedAt = now
+	body, err := json.Marshal(stats)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_build_stats", nil)
+		return
+	}
+	if period != "custom" {
+		cache.Default.Set(key, body, restaurantStatsCacheTTL)
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// raceUpdates sends both bodies to handler at once and returns the two
+// status codes, lowest first
+func raceUpdates(handler gin.HandlerFunc, route, target string, ownerID uint, bodies [2]string) []int {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	codes := make([]int, 2)
+	for i, body := range bodies {
+		wg.Add(1)
+		go func(i int, body string) {
+			defer wg.Done()
+			<-start
+			codes[i] = serve(handler, route, ownerID, models.RoleRestaurant, http.MethodPut, target, body).Code
+		}(i, body)
+	}
+	close(start)
+	wg.Wait()
+	sort.Ints(codes)
+	return codes
+}
+
+func TestConcurrentRestaurantUpdatesConflict(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+
+	codes := raceUpdates(UpdateRestaurant, "/restaurant", "/restaurant", restaurant.OwnerID,
+		[2]string{`{"name":"Tab One","version":1}`, `{"name":"Tab Two","version":1}`})
+	if codes[0] != http.StatusOK || codes[1] != http.StatusConflict {
+		t.Fatalf("status codes = %v, want one 200 and one 409", codes)
+	}
+
+	var saved models.Restaurant
+	db.First(&saved, restaurant.ID)
+	if saved.Version != 2 {
+		t.Errorf("version = %d after one successful update, want 2", saved.Version)
+	}
+	if saved.Name != "Tab One" && saved.Name != "Tab Two" {
+		t.Errorf("name = %q, want the winning tab's", saved.Name)
+	}
+}
+
+func TestStaleRestaurantUpdate(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	update := func(body string) int {
+		return serve(UpdateRestaurant, "/restaurant", restaurant.OwnerID, models.RoleRestaurant, http.MethodPut, "/restaurant", body).Code
+	}
+
+	if code := update(`{"name":"No Version"}`); code != http.StatusBadRequest {
+		t.Errorf("update without version: status = %d, want 400", code)
+	}
+	if code := update(`{"name":"First","version":1}`); code != http.StatusOK {
+		t.Fatalf("first update: status = %d, want 200", code)
+	}
+
+	w := serve(UpdateRestaurant, "/restaurant", restaurant.OwnerID, models.RoleRestaurant, http.MethodPut, "/restaurant", `{"name":"Stale","version":1}`)
+	wantStatus(t, w, http.StatusConflict)
+	var body struct {
+		Details struct {
+			Version int `json:"version"`
+		} `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Details.Version != 2 {
+		t.Errorf("conflict reports version %d, want the current 2", body.Details.Version)
+	}
+
+	// GetMyRestaurant hands out the version to round-trip
+	w = serve(GetMyRestaurant, "/restaurant", restaurant.OwnerID, models.RoleRestaurant, http.MethodGet, "/restaurant", "")
+	wantStatus(t, w, http.StatusOK)
+	var mine struct {
+		Restaurant struct {
+			Version int `json:"version"`
+		} `json:"restaurant"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	if mine.Restaurant.Version != 2 {
+		t.Fatalf("GetMyRestaurant version = %d, want 2; body: %s", mine.Restaurant.Version, w.Body.String())
+	}
+	if code := update(`{"name":"Refreshed","version":2}`); code != http.StatusOK {
+		t.Errorf("update after refreshing: status = %d, want 200", code)
+	}
+}
+
+func TestConcurrentMenuItemUpdatesConflict(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, items := createRestaurant(t, db, models.MenuItem{Name: "Paneer", Price: 200})
+	target := fmt.Sprintf("/menu/%d", items[0].ID)
+
+	codes := raceUpdates(UpdateMenuItem, "/menu/:itemId", target, restaurant.OwnerID,
+		[2]string{`{"price":210,"version":1}`, `{"price":190,"version":1}`})
+	if codes[0] != http.StatusOK || codes[1] != http.StatusConflict {
+		t.Fatalf("status codes = %v, want one 200 and one 409", codes)
+	}
+
+	var history int64
+	db.Model(&models.MenuItemPriceHistory{}).Where("menu_item_id = ?", items[0].ID).Count(&history)
+	if history != 1 {
+		t.Errorf("%d price changes recorded, want only the winner's", history)
+	}
+}
+
+func TestDeactivatedRestaurantCannotReopen(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	db.Model(&restaurant).Updates(map[string]interface{}{"is_active": false, "is_open": false})
+	toggle := func() int {
+		return serve(ToggleRestaurantOpen, "/restaurant/toggle-open", restaurant.OwnerID, models.RoleRestaurant,
+			http.MethodPut, "/restaurant/toggle-open", "").Code
+	}
+
+	if code := toggle(); code != http.StatusForbidden {
+		t.Fatalf("reopening a deactivated restaurant: status %d, want 403", code)
+	}
+	var saved models.Restaurant
+	db.First(&saved, restaurant.ID)
+	var logs int64
+	db.Model(&models.RestaurantStatusLog{}).Where("restaurant_id = ?", restaurant.ID).Count(&logs)
+	if saved.IsOpen || logs != 0 {
+		t.Errorf("is_open = %v with %d status log(s), want it left closed", saved.IsOpen, logs)
+	}
+
+	// Closing is still allowed
+	db.Model(&restaurant).Update("is_open", true)
+	if code := toggle(); code != http.StatusOK {
+		t.Fatalf("closing a deactivated restaurant: status %d, want 200", code)
+	}
+	db.First(&saved, restaurant.ID)
+	if saved.IsOpen {
+		t.Error("restaurant still open after closing it")
+	}
+}
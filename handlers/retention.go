@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dataRetentionInterval = 24 * time.Hour
+	notificationRetention = 90 * 24 * time.Hour
+	// Payment callbacks are kept for disputes; forged ones only briefly
+	paymentCallbackRetention         = 90 * 24 * time.Hour
+	rejectedPaymentCallbackRetention = 7 * 24 * time.Hour
+	// The distance is stored on the order at delivery; the points are for disputes
+	deliveryRouteRetention = 90 * 24 * time.Hour
+)
+
+// StartDataRetentionWorker deletes data that is past its retention period,
+// once a day
+func StartDataRetentionWorker() {
+	go func() {
+		for range time.Tick(dataRetentionInterval) {
+			runDataRetention(time.Now())
+		}
+	}()
+}
+
+func runDataRetention(now time.Time) {
+	logPruned("notification(s) older than 90 days",
+		config.DB.Where("created_at < ?", now.Add(-notificationRetention)).Delete(&models.Notification{}))
+	// A force-logout only counts tokens that could still be valid
+	logPruned("token issue record(s) of expired tokens",
+		config.DB.Where("issued_at < ?", now.Add(-middleware.TokenLifetime)).Delete(&models.TokenIssue{}))
+	logPruned("used or expired login link(s)",
+		config.DB.Where("used_at IS NOT NULL OR expires_at < ?", now).Delete(&models.MagicLinkToken{}))
+	logPruned("used or expired two-factor login challenge(s)",
+		config.DB.Where("used_at IS NOT NULL OR expires_at < ?", now).Delete(&models.MFAChallenge{}))
+	logPruned("payment callback(s) older than 90 days",
+		config.DB.Where("created_at < ?", now.Add(-paymentCallbackRetention)).Delete(&models.PaymentWebhookLog{}))
+	logPruned("payment callback(s) with a bad signature older than 7 days",
+		config.DB.Where("signature_valid = ? AND created_at < ?", false, now.Add(-rejectedPaymentCallbackRetention)).
+			Delete(&models.PaymentWebhookLog{}))
+	logPruned("delivery route(s) not updated in 90 days",
+		config.DB.Where("updated_at < ?", now.Add(-deliveryRouteRetention)).Delete(&models.DeliveryRoute{}))
+}
+
+// logPruned reports the outcome of one retention delete
+func logPruned(what string, res *gorm.DB) {
+	if res.Error != nil {
+		log.Printf("retention: failed to delete %s: %v", what, res.Error)
+	} else if res.RowsAffected > 0 {
+		log.Printf("retention: deleted %d %s", res.RowsAffected, what)
+	}
+}
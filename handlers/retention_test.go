@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"food-delivery-api/geo"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+)
+
+func TestDataRetentionPrunesExpiredTokenIssues(t *testing.T) {
+	db := newTestDB(t)
+	user := createUser(t, db, "Asha", models.RoleCustomer)
+	now := time.Now()
+	db.Create(&[]models.TokenIssue{
+		{UserID: user.ID, IssuedAt: now.Add(-middleware.TokenLifetime - time.Minute)},
+		{UserID: user.ID, IssuedAt: now.Add(-time.Hour)},
+	})
+
+	runDataRetention(now)
+
+	var left []models.TokenIssue
+	db.Find(&left)
+	if len(left) != 1 || !left[0].IssuedAt.After(now.Add(-middleware.TokenLifetime)) {
+		t.Errorf("token issues left = %+v, want only the unexpired one", left)
+	}
+}
+
+func TestDataRetentionPrunesSpentLoginLinks(t *testing.T) {
+	db := newTestDB(t)
+	user := createUser(t, db, "Asha", models.RoleCustomer)
+	now := time.Now()
+	db.Create(&[]models.MagicLinkToken{
+		{UserID: user.ID, TokenHash: "used", ExpiresAt: now.Add(time.Hour), UsedAt: &now},
+		{UserID: user.ID, TokenHash: "expired", ExpiresAt: now.Add(-time.Minute)},
+		{UserID: user.ID, TokenHash: "live", ExpiresAt: now.Add(time.Hour)},
+	})
+
+	runDataRetention(now)
+
+	var left []models.MagicLinkToken
+	db.Find(&left)
+	if len(left) != 1 || left[0].TokenHash != "live" {
+		t.Errorf("login links left = %+v, want only the live one", left)
+	}
+}
+
+func TestDataRetentionPrunesSpentMFAChallenges(t *testing.T) {
+	db := newTestDB(t)
+	user := createUser(t, db, "Asha", models.RoleAdmin)
+	now := time.Now()
+	db.Create(&[]models.MFAChallenge{
+		{UserID: user.ID, TokenHash: "used", ExpiresAt: now.Add(time.Minute), UsedAt: &now},
+		{UserID: user.ID, TokenHash: "expired", ExpiresAt: now.Add(-time.Minute)},
+		{UserID: user.ID, TokenHash: "live", ExpiresAt: now.Add(time.Minute)},
+	})
+
+	runDataRetention(now)
+
+	var left []models.MFAChallenge
+	db.Find(&left)
+	if len(left) != 1 || left[0].TokenHash != "live" {
+		t.Errorf("challenges left = %+v, want only the live one", left)
+	}
+}
+
+func TestDataRetentionPrunesOldDeliveryRoutes(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+	point := geo.Point{Lat: 12.9, Lng: 77.6}
+	if err := appendRoutePoint(db, 1, 2, point, now.Add(-deliveryRouteRetention-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendRoutePoint(db, 2, 2, point, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	runDataRetention(now)
+
+	var left []models.DeliveryRoute
+	db.Find(&left)
+	if len(left) != 1 || left[0].OrderID != 2 {
+		t.Errorf("routes left = %+v, want only the recent one", left)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewRequest struct {
+	RestaurantRating int    `json:"restaurant_rating" binding:"required,min=1,max=5"`
+	DriverRating     *int   `json:"driver_rating" binding:"omitempty,min=1,max=5"`
+	Comment          string `json:"comment"`
+}
+
+// ReviewOrder lets a customer rate the restaurant and driver of a delivered order
+//
+// @Summary     Rate a delivered order
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Param       body  body  ReviewRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/review [post]
+func ReviewOrder(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.CustomerID != customerID {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
+		return
+	}
+	if order.Status != models.StatusDelivered {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.only_delivered_orders_can_be_reviewed", gin.H{
+			"current_status": order.Status,
+		})
+		return
+	}
+
+	var existing int64
+	requestDB(c).Model(&models.Review{}).Where("order_id = ?", order.ID).Count(&existing)
+	if existing > 0 {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.you_have_already_reviewed_this_order", nil)
+		return
+	}
+
+	review := models.Review{
+		OrderID:          order.ID,
+		CustomerID:       customerID,
+		RestaurantID:     order.RestaurantID,
+		DriverID:         order.DriverID,
+		RestaurantRating: req.RestaurantRating,
+		Comment:          req.Comment,
+	}
+	if order.DriverID != nil {
+		review.DriverRating = req.DriverRating
+	}
+	if err := requestDB(c).Create(&review).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_review", nil)
+		return
+	}
+
+	// Keep the restaurant's denormalised rating in step with its reviews
+	var agg struct {
+		Avg   float64
+		Count int
+	}
+	requestDB(c).Model(&models.Review{}).
+		Select("AVG(restaurant_rating) AS avg, COUNT(*) AS count").
+		Where("restaurant_id = ?", order.RestaurantID).
+		Scan(&agg)
+	requestDB(c).Model(&models.Restaurant{}).Where("id = ?", order.RestaurantID).Updates(map[string]interface{}{
+		"rating":       agg.Avg,
+		"review_count": agg.Count,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Thanks for your review!", "review": review})
+}
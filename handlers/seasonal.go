@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const seasonalCheckInterval = time.Hour
+
+// OutOfSeasonItem is a seasonal menu item that can't be sold this month
+type OutOfSeasonItem struct {
+	models.MenuItem
+	RestaurantName string `json:"restaurant_name"`
+}
+
+// normalizeAvailableMonths validates an available_months value from a menu
+// item update and returns it sorted without duplicates. An empty string or
+// null means the item is sold all year.
+func normalizeAvailableMonths(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errors.New("available_months must be a string of comma-separated month numbers, e.g. \"9,10,11\"")
+	}
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	seen := map[int]bool{}
+	months := []int{}
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 || n > 12 {
+			return "", errors.New("available_months must list month numbers from 1 to 12, e.g. \"9,10,11\"")
+		}
+		if !seen[n] {
+			seen[n] = true
+			months = append(months, n)
+		}
+	}
+	sort.Ints(months)
+	parts := make([]string, len(months))
+	for i, n := range months {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// StartSeasonalMenuWorker marks seasonal items unavailable when their season
+// ends: once at startup, then whenever the month changes. Owners make an item
+// available again themselves when its season comes back.
+func StartSeasonalMenuWorker() {
+	go func() {
+		month := time.Now().Month()
+		runSeasonalMenu(month)
+		for now := range time.Tick(seasonalCheckInterval) {
+			if now.Month() != month {
+				month = now.Month()
+				runSeasonalMenu(month)
+			}
+		}
+	}()
+}
+
+func runSeasonalMenu(month time.Month) {
+	var items []models.MenuItem
+	config.DB.Where("available_months <> '' AND is_available = ?", true).Find(&items)
+	ids := []uint{}
+	restaurants := map[uint]bool{}
+	for _, item := range items {
+		if !item.InSeason(month) {
+			ids = append(ids, item.ID)
+			restaurants[item.RestaurantID] = true
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	if err := config.DB.Model(&models.MenuItem{}).Where("id IN ?", ids).Update("is_available", false).Error; err != nil {
+		log.Printf("seasonal: failed to take %d item(s) off the menu: %v", len(ids), err)
+		return
+	}
+	for restaurantID := range restaurants {
+		invalidateMenuCache(restaurantID)
+	}
+	log.Printf("seasonal: %d item(s) out of season in %s marked unavailable", len(ids), month)
+}
+
+// AdminGetOutOfSeasonItems lists seasonal menu items whose months don't
+// include the current one, so they can't be ordered today — admin only
+//
+// @Summary     Menu items out of season
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/menu-items/out-of-season [get]
+func AdminGetOutOfSeasonItems(c *gin.Context) {
+	month := time.Now().Month()
+	var items []OutOfSeasonItem
+	requestDB(c).Model(&models.MenuItem{}).
+		Select("menu_items.*, restaurants.name AS restaurant_name").
+		Joins("JOIN restaurants ON restaurants.id = menu_items.restaurant_id").
+		Where("menu_items.available_months <> ''").
+		Order("menu_items.restaurant_id, menu_items.id").
+		Scan(&items)
+
+	out := []OutOfSeasonItem{}
+	for _, item := range items {
+		if !item.InSeason(month) {
+			out = append(out, item)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"month": int(month),
+		"count": len(out),
+		"items": out,
+	})
+}
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/seed"
+	"food-delivery-api/statemachine"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// MaintenanceSeed is logged each time staging data is seeded
+const MaintenanceSeed = "SEED_DATA"
+
+const (
+	seedRestaurants   = 3
+	seedMenuItems     = 10 // per restaurant
+	seedCustomers     = 5
+	seedDrivers       = 3
+	seedPassword      = "password123" // every seeded account's password
+	seedEmailDomain   = "seed.test"
+	seedMaxOrderLines = 3
+)
+
+// seedOrderStatuses is the status each seeded order ends up in
+var seedOrderStatuses = []models.OrderStatus{
+	models.StatusPlaced, models.StatusConfirmed, models.StatusPreparing, models.StatusReadyForPickup,
+	models.StatusPickedUp, models.StatusDelivered, models.StatusDelivered, models.StatusDelivered,
+	models.StatusCancelled, models.StatusCancelled,
+}
+
+// seedKeptTables survive a reset: schema bookkeeping and reference data that
+// is seeded at startup or tuned by admins. Admin accounts are kept too.
+var seedKeptTables = map[string]bool{
+	"schema_migrations": true,
+	"allergens":         true,
+	"feature_flags":     true,
+	"system_configs":    true,
+}
+
+type SeedRequest struct {
+	Reset bool   `json:"reset"` // empty the tables first
+	Seed  *int64 `json:"seed"`  // reproduce an earlier run; random when omitted
+}
+
+type seedSummary struct {
+	Users       int `json:"users"`
+	Restaurants int `json:"restaurants"`
+	MenuItems   int `json:"menu_items"`
+	Orders      int `json:"orders"`
+}
+
+// AdminSeed fills the database with sample restaurants, users and orders for
+// manual testing — admin only, and refused in release mode. Names come from
+// the seed package's word lists; the response carries the seed, so passing it
+// back reproduces the same data.
+//
+// @Summary     Seed staging test data
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  SeedRequest  false  "Reset first, and/or a seed to reproduce"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Failure     500  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/seed [post]
+func AdminSeed(c *gin.Context) {
+	if gin.Mode() == gin.ReleaseMode {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.seeding_is_disabled_in_release_mode", nil)
+		return
+	}
+	adminID := middleware.GetUserID(c)
+
+	var req SeedRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+			return
+		}
+	}
+	seedValue := seed.NewSeed()
+	if req.Seed != nil {
+		seedValue = *req.Seed
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(seedPassword), config.BCryptCost())
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_hash_password", nil)
+		return
+	}
+
+	var created seedSummary
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if req.Reset {
+			if err := resetSeedTables(tx); err != nil {
+				return err
+			}
+		}
+		created, err = seedData(tx, seed.NewPicker(seedValue), string(hash), time.Now())
+		if err != nil {
+			return err
+		}
+		return logMaintenance(tx, MaintenanceSeed, &adminID, gin.H{"seed": seedValue, "reset": req.Reset, "created": created})
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			apierror.Respond(c, http.StatusConflict, apierror.ErrConflict,
+				"errors.seed_data_exists", nil)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_seed_data", nil)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Seed data created",
+		"seed":     seedValue,
+		"reset":    req.Reset,
+		"password": seedPassword,
+		"created":  created,
+	})
+}
+
+// resetSeedTables empties every table outside seedKeptTables, children before
+// the tables their foreign keys point at
+func resetSeedTables(tx *gorm.DB) error {
+	var tables []string
+	if err := tx.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name").
+		Scan(&tables).Error; err != nil {
+		return err
+	}
+	parents := map[string][]string{}
+	for _, table := range tables {
+		var refs []string
+		if err := tx.Raw(`SELECT DISTINCT "table" FROM pragma_foreign_key_list(?)`, table).Scan(&refs).Error; err != nil {
+			return err
+		}
+		parents[table] = refs
+	}
+
+	// Parents-first order; deleting walks it backwards
+	var order []string
+	visited := map[string]bool{}
+	var visit func(string)
+	visit = func(table string) {
+		if visited[table] {
+			return
+		}
+		visited[table] = true
+		for _, p := range parents[table] {
+			visit(p)
+		}
+		order = append(order, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		table := order[i]
+		if seedKeptTables[table] {
+			continue
+		}
+		stmt := fmt.Sprintf("DELETE FROM %q", table)
+		if table == "users" {
+			stmt += fmt.Sprintf(" WHERE role <> '%s'", models.RoleAdmin)
+		}
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedData(tx *gorm.DB, pick *seed.Picker, passwordHash string, now time.Time) (seedSummary, error) {
+	var created seedSummary
+	newUser := func(role models.UserRole, n int) (models.User, error) {
+		user := models.User{
+			Name:         pick.Pick(seed.FirstNames) + " " + pick.Pick(seed.LastNames),
+			Email:        fmt.Sprintf("%s%d@%s", role, n, seedEmailDomain),
+			PasswordHash: passwordHash,
+			Role:         role,
+			Phone:        fmt.Sprintf("+9198%08d", pick.Intn(100000000)),
+		}
+		if role == models.RoleCustomer {
+			code, err := newReferralCode(tx)
+			if err != nil {
+				return user, err
+			}
+			user.ReferralCode = &code
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return user, err
+		}
+		created.Users++
+		return user, nil
+	}
+
+	restaurants := make([]models.Restaurant, seedRestaurants)
+	menus := make([][]models.MenuItem, seedRestaurants)
+	for i := range restaurants {
+		owner, err := newUser(models.RoleRestaurant, i+1)
+		if err != nil {
+			return created, err
+		}
+		restaurants[i] = models.Restaurant{
+			OwnerID: owner.ID,
+			Name:    "The " + pick.Pick(seed.RestaurantAdjectives) + " " + pick.Pick(seed.RestaurantNouns),
+			Cuisine: pick.Pick(seed.Cuisines),
+			Address: fmt.Sprintf("%d %s", pick.Intn(200)+1, pick.Pick(seed.Streets)),
+			IsOpen:  true,
+		}
+		if err := tx.Create(&restaurants[i]).Error; err != nil {
+			return created, err
+		}
+		created.Restaurants++
+		for _, dish := range pick.PickN(seed.Dishes, seedMenuItems) {
+			menus[i] = append(menus[i], models.MenuItem{
+				RestaurantID: restaurants[i].ID,
+				Name:         dish,
+				Price:        float64(60 + pick.Intn(25)*10),
+				Category:     "Main",
+				IsAvailable:  true,
+			})
+		}
+		if err := tx.Create(&menus[i]).Error; err != nil {
+			return created, err
+		}
+		created.MenuItems += len(menus[i])
+	}
+
+	customers := make([]models.User, seedCustomers)
+	for i := range customers {
+		var err error
+		if customers[i], err = newUser(models.RoleCustomer, i+1); err != nil {
+			return created, err
+		}
+	}
+	drivers := make([]models.User, seedDrivers)
+	for i := range drivers {
+		var err error
+		if drivers[i], err = newUser(models.RoleDriver, i+1); err != nil {
+			return created, err
+		}
+		profile := models.DriverProfile{UserID: drivers[i].ID, MaxConcurrentOrders: models.DefaultMaxConcurrentOrders, IsOnline: true}
+		profile.SetVehicleType(models.VehicleScooter)
+		if err := tx.Create(&profile).Error; err != nil {
+			return created, err
+		}
+	}
+
+	for _, status := range seedOrderStatuses {
+		r := pick.Intn(len(restaurants))
+		customer := customers[pick.Intn(len(customers))]
+		driver := drivers[pick.Intn(len(drivers))]
+		path := seedStatusPath(status, pick)
+
+		// Finished orders happened over the last few days; live ones just now
+		placedAt := now.Add(-time.Duration(5*len(path)+pick.Intn(10)) * time.Minute)
+		if status == models.StatusDelivered || status == models.StatusCancelled {
+			placedAt = now.Add(-time.Duration(24+pick.Intn(72)) * time.Hour)
+		}
+
+		order := models.Order{
+			CustomerID:      customer.ID,
+			RestaurantID:    restaurants[r].ID,
+			Status:          status,
+			DeliveryFee:     restaurants[r].BaseDeliveryFee,
+			PaymentMethod:   models.PaymentPrepaid,
+			PaymentStatus:   models.PaymentStatusPaid,
+			DeliveryAddress: fmt.Sprintf("%d %s", pick.Intn(200)+1, pick.Pick(seed.Streets)),
+			CreatedAt:       placedAt,
+			UpdatedAt:       placedAt.Add(time.Duration(5*(len(path)-1)) * time.Minute),
+		}
+		var subtotal float64
+		for j := 0; j < 1+pick.Intn(seedMaxOrderLines); j++ {
+			item := menus[r][pick.Intn(len(menus[r]))]
+			qty := 1 + pick.Intn(2)
+			subtotal += item.Price * float64(qty)
+			order.Items = append(order.Items, models.OrderItem{MenuItemID: item.ID, Quantity: qty, Price: item.Price, Name: item.Name})
+		}
+		order.EstimatedTime = estimateDeliveryMinutes(restaurants[r], len(order.Items))
+		order.TotalPrice = subtotal + order.DeliveryFee
+		if status == models.StatusCancelled {
+			order.CancellationReason = models.CancelChangedMind
+		}
+		if len(path) > 4 && path[4] == models.StatusPickedUp {
+			order.DriverID = &driver.ID
+		}
+		if err := convertToBase(&order, restaurants[r].Currency); err != nil {
+			return created, err
+		}
+		invoiceNumber, err := nextInvoiceNumber(tx, placedAt)
+		if err != nil {
+			return created, err
+		}
+		order.InvoiceNumber = invoiceNumber
+		if err := tx.Create(&order).Error; err != nil {
+			return created, err
+		}
+
+		actors := map[string]uint{"customer": customer.ID, "restaurant": restaurants[r].OwnerID, "driver": driver.ID}
+		history := []models.OrderStatusHistory{{OrderID: order.ID, ToStatus: models.StatusPlaced, ChangedBy: customer.ID, Note: "Order placed by customer", CreatedAt: placedAt}}
+		for j := 1; j < len(path); j++ {
+			actor := seedActor(path[j-1], path[j])
+			history = append(history, models.OrderStatusHistory{
+				OrderID:    order.ID,
+				FromStatus: path[j-1],
+				ToStatus:   path[j],
+				ChangedBy:  actors[actor],
+				Note:       "Seeded transition by " + actor,
+				CreatedAt:  placedAt.Add(time.Duration(5*j) * time.Minute),
+			})
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return created, err
+		}
+		created.Orders++
+	}
+	return created, nil
+}
+
+// seedStatusPath walks the state machine from PLACED to target along the
+// happy path. Cancelled orders are cancelled from a random cancellable state.
+func seedStatusPath(target models.OrderStatus, pick *seed.Picker) []models.OrderStatus {
+	path := []models.OrderStatus{models.StatusPlaced}
+	cancelAt := -1
+	if target == models.StatusCancelled {
+		cancelAt = pick.Intn(2) // PLACED or CONFIRMED
+	}
+	for current := models.StatusPlaced; current != target; {
+		if len(path)-1 == cancelAt {
+			path = append(path, models.StatusCancelled)
+			break
+		}
+		for _, next := range statemachine.ValidTransitionsFrom(current) {
+			if next != models.StatusCancelled {
+				current = next
+				break
+			}
+		}
+		path = append(path, current)
+	}
+	return path
+}
+
+// seedActor is who makes the from -> to transition, preferring the customer for cancellations
+func seedActor(from, to models.OrderStatus) string {
+	actor := ""
+	for _, t := range statemachine.GetAllTransitions() {
+		if t.From == from && t.To == to && (actor == "" || t.Actor == "customer") {
+			actor = t.Actor
+		}
+	}
+	return actor
+}
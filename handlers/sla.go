@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	slaAlertCheckInterval = 24 * time.Hour
+	slaAlertWindowDays    = 7
+	slaAlertEvent         = "restaurant_sla_below_threshold"
+)
+
+// SLAStats measures delivered orders against their restaurant's SLA. Orders
+// delivered before delivery times were recorded are left out.
+type SLAStats struct {
+	Total                 int      `json:"total"`
+	Met                   int      `json:"met"`
+	Breached              int      `json:"breached"`
+	SLAMetRatePct         *float64 `json:"sla_met_rate_pct"` // null without deliveries
+	AvgFulfillmentMinutes float64  `json:"avg_fulfillment_minutes"`
+	P95FulfillmentMinutes float64  `json:"p95_fulfillment_minutes"`
+}
+
+type SLARequest struct {
+	SLAMinutes int `json:"sla_minutes" binding:"required,min=1,max=1440"`
+}
+
+// slaStats computes SLA stats for orders placed in [from, to), for one
+// restaurant or, with restaurantID 0, all of them
+func slaStats(db *gorm.DB, restaurantID uint, from, to time.Time) SLAStats {
+	var rows []struct {
+		CreatedAt   time.Time
+		DeliveredAt time.Time
+		SLAMet      bool
+	}
+	query := db.Model(&models.Order{}).Select("created_at, delivered_at, sla_met").
+		Where("status = ? AND delivered_at IS NOT NULL AND created_at >= ? AND created_at < ?",
+			models.StatusDelivered, from, to)
+	if restaurantID != 0 {
+		query = query.Where("restaurant_id = ?", restaurantID)
+	}
+	query.Scan(&rows)
+
+	stats := SLAStats{Total: len(rows)}
+	if len(rows) == 0 {
+		return stats
+	}
+	minutes := make([]float64, len(rows))
+	var sum float64
+	for i, r := range rows {
+		minutes[i] = r.DeliveredAt.Sub(r.CreatedAt).Minutes()
+		sum += minutes[i]
+		if r.SLAMet {
+			stats.Met++
+		}
+	}
+	stats.Breached = stats.Total - stats.Met
+	rate := math.Round(float64(stats.Met)/float64(stats.Total)*10000) / 100
+	stats.SLAMetRatePct = &rate
+	stats.AvgFulfillmentMinutes = math.Round(sum/float64(len(minutes))*10) / 10
+	// Nearest rank: the smallest value at least 95% of deliveries don't exceed
+	sort.Float64s(minutes)
+	stats.P95FulfillmentMinutes = math.Round(minutes[int(math.Ceil(0.95*float64(len(minutes))))-1]*10) / 10
+	return stats
+}
+
+// AdminGetSLAReport reports how many orders placed in the date range were
+// delivered within their restaurant's SLA — admin only
+//
+// @Summary     Order fulfilment SLA report
+// @Tags        admin
+// @Produce     json
+// @Param       from           query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to             query  string  false  "End date (YYYY-MM-DD), default today"
+// @Param       restaurant_id  query  int     false  "Only this restaurant"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/sla [get]
+func AdminGetSLAReport(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var restaurantID uint64
+	if s := c.Query("restaurant_id"); s != "" {
+		if restaurantID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_restaurant_id", nil)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":          from.Format(dateLayout),
+		"to":            to.AddDate(0, 0, -1).Format(dateLayout),
+		"restaurant_id": restaurantID,
+		"sla":           slaStats(requestDB(c), uint(restaurantID), from, to),
+	})
+}
+
+// AdminSetRestaurantSLA sets the minutes a restaurant's orders should take
+// from placement to delivery. Orders already delivered keep their result.
+// Admin only.
+//
+// @Summary     Set a restaurant's SLA
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id    path  int         true  "Restaurant ID"
+// @Param       body  body  SLARequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/sla [put]
+func AdminSetRestaurantSLA(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req SLARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	if err := requestDB(c).Model(&restaurant).Update("sla_minutes", req.SLAMinutes).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_update_restaurant", nil)
+		return
+	}
+	log.Printf("sla: admin %d set restaurant %d's SLA to %d minutes", adminID, restaurant.ID, req.SLAMinutes)
+	c.JSON(http.StatusOK, gin.H{"message": "SLA updated", "restaurant_id": restaurant.ID, "sla_minutes": req.SLAMinutes})
+}
+
+// StartSLAAlertWorker emails admins, once a day, about restaurants whose SLA
+// met rate over the last 7 days is below SLA_ALERT_THRESHOLD. A restaurant is
+// reported at most once a week.
+func StartSLAAlertWorker() {
+	go func() {
+		for range time.Tick(slaAlertCheckInterval) {
+			runSLAAlerts(time.Now())
+		}
+	}()
+}
+
+func runSLAAlerts(now time.Time) {
+	threshold := sysconfig.Float(sysconfig.KeySLAAlertThreshold)
+	if threshold <= 0 {
+		return
+	}
+	from := now.AddDate(0, 0, -slaAlertWindowDays)
+	var restaurants []models.Restaurant
+	config.DB.Where("is_active = ?", true).Find(&restaurants)
+	var admins []models.User
+	for _, r := range restaurants {
+		stats := slaStats(config.DB, r.ID, from, now)
+		if stats.SLAMetRatePct == nil || *stats.SLAMetRatePct >= threshold {
+			continue
+		}
+		var recent int64
+		config.DB.Model(&models.Notification{}).
+			Where("event_type = ? AND reference_type = ? AND reference_id = ? AND created_at > ?", slaAlertEvent, "restaurant", r.ID, from).
+			Count(&recent)
+		if recent > 0 {
+			continue
+		}
+		if admins == nil {
+			config.DB.Where("role = ?", models.RoleAdmin).Find(&admins)
+		}
+		for _, admin := range admins {
+			err := notify.Default.Send(notify.Message{
+				UserID:  admin.ID,
+				Email:   admin.Email,
+				Phone:   admin.Phone,
+				Channel: notify.ChannelEmail,
+				Title:   fmt.Sprintf("%s met its SLA on %.1f%% of orders this week", r.Name, *stats.SLAMetRatePct),
+				Body: fmt.Sprintf("%d of %d deliveries took longer than %d minutes (alert threshold %.1f%%).",
+					stats.Breached, stats.Total, r.SLAMinutes, threshold),
+
+				EventType:     slaAlertEvent,
+				ReferenceID:   r.ID,
+				ReferenceType: "restaurant",
+			})
+			if err != nil {
+				log.Printf("sla: failed to alert admin %d about restaurant %d: %v", admin.ID, r.ID, err)
+			}
+		}
+	}
+}
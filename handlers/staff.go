@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const inviteTTL = 72 * time.Hour
+
+// managedRestaurant loads the restaurant the user owns or is staff at
+func managedRestaurant(db *gorm.DB, userID uint, restaurant *models.Restaurant) error {
+	staffOf := config.DB.Model(&models.RestaurantStaff{}).Select("restaurant_id").Where("user_id = ?", userID)
+	return db.Where("owner_id = ? OR id IN (?)", userID, staffOf).First(restaurant).Error
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type InviteStaffRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// InviteStaff sends a staff invitation for the owner's restaurant
+//
+// @Summary     Invite a staff member
+// @Tags        restaurant
+// @Accept      json
+// @Produce     json
+// @Param       body  body  InviteStaffRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /restaurant/staff/invite [post]
+func InviteStaff(c *gin.Context) {
+	ownerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("owner_id = ?", ownerID).First(&restaurant).Error; err != nil {
+		c.Error(err).SetMeta("errors.only_the_restaurant_owner_can_invite_staff")
+		c.Abort()
+		return
+	}
+	var req InviteStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_invite", nil)
+		return
+	}
+	token := hex.EncodeToString(raw)
+
+	invite := models.Invite{
+		RestaurantID: restaurant.ID,
+		InvitedEmail: email,
+		TokenHash:    hashInviteToken(token),
+		Role:         models.StaffRole,
+		ExpiresAt:    time.Now().Add(inviteTTL),
+	}
+	if err := requestDB(c).Create(&invite).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_invite", nil)
+		return
+	}
+
+	// The raw token only ever leaves through the notifier
+	notify.Default.Send(notify.Message{
+		Email:   email,
+		Channel: notify.ChannelEmail,
+		Title:   "You're invited to help run " + restaurant.Name,
+		Body:    "Accept with POST /api/auth/accept-invite and token " + token + " before " + invite.ExpiresAt.Format(time.RFC1123),
+		Private: true,
+	})
+	c.JSON(http.StatusCreated, gin.H{"message": "Invite sent", "invite": invite})
+}
+
+type AcceptInviteRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AcceptInvite turns the logged-in user into staff at the inviting restaurant
+//
+// @Summary     Accept a restaurant staff invite
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       body  body  AcceptInviteRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /auth/accept-invite [post]
+func AcceptInvite(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	var req AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var invite models.Invite
+	if err := requestDB(c).Where("token_hash = ?", hashInviteToken(req.Token)).First(&invite).Error; err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_invite_token", nil)
+		return
+	}
+	if invite.AcceptedAt != nil {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.invite_has_already_been_accepted", nil)
+		return
+	}
+	if time.Now().After(invite.ExpiresAt) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invite_has_expired", nil)
+		return
+	}
+
+	var user models.User
+	if err := requestDB(c).First(&user, userID).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_not_found")
+		c.Abort()
+		return
+	}
+	if !strings.EqualFold(user.Email, invite.InvitedEmail) {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.invite_email_mismatch", nil)
+		return
+	}
+	if user.Role == models.RoleAdmin || user.Role == models.RoleDriver {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.staff_invite_wrong_role", nil)
+		return
+	}
+	var existing int64
+	requestDB(c).Model(&models.Restaurant{}).Where("owner_id = ?", user.ID).Count(&existing)
+	if existing == 0 {
+		requestDB(c).Model(&models.RestaurantStaff{}).Where("user_id = ?", user.ID).Count(&existing)
+	}
+	if existing > 0 {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.you_already_manage_a_restaurant", nil)
+		return
+	}
+
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&invite).Where("accepted_at IS NULL").Update("accepted_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.invite_has_already_been_accepted", nil)
+		}
+		if err := tx.Model(&user).Update("role", models.RoleRestaurant).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.RestaurantStaff{RestaurantID: invite.RestaurantID, UserID: user.ID}).Error
+	})
+	if err != nil {
+		c.Error(err).SetMeta("errors.failed_to_accept_invite")
+		c.Abort()
+		return
+	}
+
+	// The old token still carries the old role
+	token, err := middleware.GenerateToken(&user)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_generate_token", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Invite accepted",
+		"token":         token,
+		"restaurant_id": invite.RestaurantID,
+		"user":          user,
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/statemachine"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dotRenderTimeout bounds one run of Graphviz
+const dotRenderTimeout = 5 * time.Second
+
+// renderedSVGs caches Graphviz output by DOT source, so each label set is
+// rendered once however many locales map to it. svgMu also serialises
+// rendering, so a burst of requests starts at most one dot process.
+var (
+	svgMu        sync.Mutex
+	renderedSVGs = map[string][]byte{}
+)
+
+// resetStateMachineSVGs drops every rendered SVG; call it when labels change
+func resetStateMachineSVGs() {
+	svgMu.Lock()
+	renderedSVGs = map[string][]byte{}
+	svgMu.Unlock()
+}
+
+// renderStateMachineSVG returns the SVG for source, running dot only when
+// it isn't cached. A failed render is not cached.
+func renderStateMachineSVG(ctx context.Context, dot, source string) ([]byte, error) {
+	svgMu.Lock()
+	defer svgMu.Unlock()
+	if svg, ok := renderedSVGs[source]; ok {
+		return svg, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, dotRenderTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, dot, "-Tsvg")
+	cmd.Stdin = strings.NewReader(source)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	svg, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	renderedSVGs[source] = svg
+	return svg, nil
+}
+
+// GetStateMachineDOT returns the order state machine as a Graphviz DOT graph
+// with states named in the requested locale
+//
+// @Summary     State machine as Graphviz DOT
+// @Tags        public
+// @Produce     plain
+// @Param       locale  query  string  false  "Locale for state labels (default: Accept-Language, then en)"
+// @Success     200  {string}  string
+// @Router      /state-machine.dot [get]
+func GetStateMachineDOT(c *gin.Context) {
+	c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(statemachine.DOT(requestLocale(c))))
+}
+
+// GetStateMachineSVG renders the state machine with Graphviz's dot binary,
+// once per set of labels. Servers without Graphviz installed answer 501.
+//
+// @Summary     State machine as SVG
+// @Tags        public
+// @Produce     image/svg+xml
+// @Param       locale  query  string  false  "Locale for state labels (default: Accept-Language, then en)"
+// @Success     200  {string}  string
+// @Failure     501  {object}  apierror.ErrorResponse
+// @Router      /state-machine.svg [get]
+func GetStateMachineSVG(c *gin.Context) {
+	dot, err := exec.LookPath("dot")
+	if err != nil {
+		apierror.Respond(c, http.StatusNotImplemented, apierror.ErrNotImplemented,
+			"errors.graphviz_unavailable", nil)
+		return
+	}
+	svg, err := renderStateMachineSVG(c.Request.Context(), dot, statemachine.DOT(requestLocale(c)))
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_render_state_machine",
+			gin.H{"reason": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "image/svg+xml", svg)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeDot writes a stand-in for Graphviz's dot that logs each run to a file
+// and echoes its input inside an <svg> element. It returns the binary and
+// the log.
+func fakeDot(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	bin := filepath.Join(dir, "dot")
+	script := "#!/bin/sh\necho run >> " + runs + "\necho '<svg>'\ncat\necho '</svg>'\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, runs
+}
+
+func dotRuns(t *testing.T, runs string) int {
+	t.Helper()
+	b, err := os.ReadFile(runs)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(b), "run")
+}
+
+func TestRenderStateMachineSVGRendersEachSourceOnce(t *testing.T) {
+	dot, runs := fakeDot(t)
+	resetStateMachineSVGs()
+	t.Cleanup(resetStateMachineSVGs)
+
+	for i := 0; i < 3; i++ {
+		svg, err := renderStateMachineSVG(context.Background(), dot, "digraph A {}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(svg), "digraph A {}") {
+			t.Fatalf("svg = %q, want the rendered source", svg)
+		}
+	}
+	if n := dotRuns(t, runs); n != 1 {
+		t.Errorf("dot ran %d times for one source, want 1", n)
+	}
+
+	renderStateMachineSVG(context.Background(), dot, "digraph B {}")
+	if n := dotRuns(t, runs); n != 2 {
+		t.Errorf("dot ran %d times for two sources, want 2", n)
+	}
+
+	// Changed labels render afresh
+	resetStateMachineSVGs()
+	renderStateMachineSVG(context.Background(), dot, "digraph A {}")
+	if n := dotRuns(t, runs); n != 3 {
+		t.Errorf("dot ran %d times after a reset, want 3", n)
+	}
+}
+
+func TestRenderStateMachineSVGDoesNotCacheFailures(t *testing.T) {
+	resetStateMachineSVGs()
+	t.Cleanup(resetStateMachineSVGs)
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "dot")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\necho 'syntax error' >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renderStateMachineSVG(context.Background(), bin, "digraph {"); err == nil || err.Error() != "syntax error" {
+		t.Fatalf("err = %v, want dot's stderr", err)
+	}
+
+	dot, runs := fakeDot(t)
+	if _, err := renderStateMachineSVG(context.Background(), dot, "digraph {"); err != nil {
+		t.Fatal(err)
+	}
+	if n := dotRuns(t, runs); n != 1 {
+		t.Errorf("dot ran %d times, want the failed render retried", n)
+	}
+}
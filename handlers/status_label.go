@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/statemachine"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// localePattern accepts language tags like "en", "fr" or "pt-br"
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+type StatusLabelInput struct {
+	Status models.OrderStatus `json:"status" binding:"required"`
+	Locale string             `json:"locale"` // default "en"
+	// Empty removes the label, so the status shows its default name again
+	DisplayLabel string `json:"display_label" binding:"max=50"`
+}
+
+type SetStatusLabelsRequest struct {
+	Labels []StatusLabelInput `json:"labels" binding:"required,min=1,dive"`
+}
+
+// AdminGetStatusLabels lists the configured status labels, and what each
+// status is called in the default locale — admin only
+//
+// @Summary     List order status labels
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/status-labels [get]
+func AdminGetStatusLabels(c *gin.Context) {
+	var rows []models.StatusLabel
+	requestDB(c).Order("locale, status").Find(&rows)
+	effective := gin.H{}
+	for _, s := range statemachine.States() {
+		effective[string(s)] = statemachine.DisplayLabel(s, models.DefaultLocale)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":          len(rows),
+		"labels":         rows,
+		"default_locale": models.DefaultLocale,
+		"effective":      effective,
+	})
+}
+
+// AdminSetStatusLabels sets or clears display labels for order statuses —
+// admin only. Other instances pick the change up within
+// statemachine.LabelRefreshInterval.
+//
+// @Summary     Set order status labels
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  SetStatusLabelsRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/status-labels [put]
+func AdminSetStatusLabels(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req SetStatusLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	for i, in := range req.Labels {
+		if !statemachine.IsState(in.Status) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.unknown_order_status", gin.H{
+				"status":  in.Status,
+				"allowed": statemachine.States(),
+			})
+			return
+		}
+		req.Labels[i].Locale = statemachine.NormalizeLocale(in.Locale)
+		if !localePattern.MatchString(req.Labels[i].Locale) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_locale", gin.H{
+				"locale": in.Locale,
+			})
+			return
+		}
+	}
+
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		for _, in := range req.Labels {
+			if in.DisplayLabel == "" {
+				if err := tx.Where("status = ? AND locale = ?", in.Status, in.Locale).Delete(&models.StatusLabel{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			row := models.StatusLabel{Status: in.Status, Locale: in.Locale, DisplayLabel: in.DisplayLabel, UpdatedBy: &adminID}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "status"}, {Name: "locale"}},
+				DoUpdates: clause.AssignmentColumns([]string{"display_label", "updated_by", "updated_at"}),
+			}).Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = statemachine.RefreshLabels()
+		resetStateMachineSVGs()
+	}
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_status_labels", nil)
+		return
+	}
+
+	var rows []models.StatusLabel
+	requestDB(c).Order("locale, status").Find(&rows)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status labels updated",
+		"count":   len(rows),
+		"labels":  rows,
+	})
+}
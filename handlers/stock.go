@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// restoreStock puts a cancelled order's quantities back on those of its menu
+// items that track stock, undoing the decrement taken when it was placed.
+// Run it in the transaction that cancels the order, and invalidate the
+// restaurant's cached menus once that commits.
+func restoreStock(tx *gorm.DB, orderID uint) error {
+	var items []models.OrderItem
+	if err := tx.Select("menu_item_id", "quantity").Where("order_id = ?", orderID).Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := tx.Model(&models.MenuItem{}).Where("id = ? AND track_stock = ?", item.MenuItemID, true).
+			UpdateColumn("stock_quantity", gorm.Expr("stock_quantity + ?", item.Quantity)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"food-delivery-api/cache"
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+)
+
+func TestPlaceOrderRollsBackStockWhenALineFails(t *testing.T) {
+	db := newTestDB(t)
+	customer := createUser(t, db, "Customer", models.RoleCustomer)
+	restaurant, items := createRestaurant(t, db,
+		models.MenuItem{Name: "Paneer", Price: 200, TrackStock: true, StockQuantity: 5},
+		models.MenuItem{Name: "Naan", Price: 30, TrackStock: true, StockQuantity: 1},
+	)
+
+	_, apiErr := placeOrder(db, customer.ID, PlaceOrderRequest{
+		RestaurantID:    restaurant.ID,
+		DeliveryAddress: "2 Low St",
+		Items: []PlaceOrderItem{
+			{MenuItemID: items[0].ID, Quantity: 2},
+			{MenuItemID: items[1].ID, Quantity: 3},
+		},
+	})
+	if apiErr == nil || apiErr.Status != http.StatusConflict {
+		t.Fatalf("placeOrder error = %v, want a 409 for the short item", apiErr)
+	}
+	if got := stockOf(t, db, items[0].ID); got != 5 {
+		t.Errorf("Paneer stock = %d after the failed order, want 5", got)
+	}
+	if got := stockOf(t, db, items[1].ID); got != 1 {
+		t.Errorf("Naan stock = %d after the failed order, want 1", got)
+	}
+	var orders int64
+	db.Model(&models.Order{}).Count(&orders)
+	if orders != 0 {
+		t.Errorf("%d orders saved, want none", orders)
+	}
+}
+
+func TestCancellingAnOrderRestoresStock(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(t *testing.T, order models.Order, customerID, ownerID uint)
+	}{
+		{"customer", func(t *testing.T, order models.Order, customerID, _ uint) {
+			w := serve(CancelOrder, "/orders/:id/cancel", customerID, models.RoleCustomer,
+				http.MethodPut, fmt.Sprintf("/orders/%d/cancel", order.ID), "")
+			wantStatus(t, w, http.StatusOK)
+		}},
+		{"restaurant", func(t *testing.T, order models.Order, _, ownerID uint) {
+			w := serve(UpdateOrderStatus, "/orders/:id/status", ownerID, models.RoleRestaurant,
+				http.MethodPut, fmt.Sprintf("/orders/%d/status", order.ID), `{"status":"CANCELLED"}`)
+			wantStatus(t, w, http.StatusOK)
+		}},
+		{"admin", func(t *testing.T, order models.Order, _, _ uint) {
+			admin := createUser(t, config.DB, "Admin", models.RoleAdmin)
+			w := serve(AdminForceOrderStatus, "/orders/:id/status", admin.ID, models.RoleAdmin,
+				http.MethodPut, fmt.Sprintf("/orders/%d/status", order.ID), `{"status":"CANCELLED","reason":"test"}`)
+			wantStatus(t, w, http.StatusOK)
+		}},
+		{"auto-cancel", func(t *testing.T, order models.Order, _, _ uint) {
+			runAutoCancel(time.Now().Add(time.Hour))
+			var got models.Order
+			config.DB.First(&got, order.ID)
+			if got.Status != models.StatusCancelled {
+				t.Fatalf("order status = %s after the auto-cancel run, want CANCELLED", got.Status)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			customer := createUser(t, db, "Customer", models.RoleCustomer)
+			restaurant, items := createRestaurant(t, db,
+				models.MenuItem{Name: "Paneer", Price: 200, TrackStock: true, StockQuantity: 5},
+				models.MenuItem{Name: "Naan", Price: 30},
+			)
+			// Cached menus show the stock, so every change has to drop them
+			menuKey := menuCacheKey(uint64(restaurant.ID), "", "", nil, "")
+			cache.Default.Set(menuKey, []byte("{}"), menuCacheTTL)
+			order, apiErr := placeOrder(db, customer.ID, PlaceOrderRequest{
+				RestaurantID:    restaurant.ID,
+				DeliveryAddress: "2 Low St",
+				Items: []PlaceOrderItem{
+					{MenuItemID: items[0].ID, Quantity: 2},
+					{MenuItemID: items[1].ID, Quantity: 4},
+				},
+			})
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			if got := stockOf(t, db, items[0].ID); got != 3 {
+				t.Fatalf("Paneer stock = %d after ordering 2, want 3", got)
+			}
+			if _, ok := cache.Default.Get(menuKey); ok {
+				t.Error("menu still cached after the order took stock")
+			}
+
+			cache.Default.Set(menuKey, []byte("{}"), menuCacheTTL)
+			tt.cancel(t, order, customer.ID, restaurant.OwnerID)
+			if _, ok := cache.Default.Get(menuKey); ok {
+				t.Error("menu still cached after cancelling restored stock")
+			}
+			if got := stockOf(t, db, items[0].ID); got != 5 {
+				t.Errorf("Paneer stock = %d after cancelling, want 5", got)
+			}
+			if got := stockOf(t, db, items[1].ID); got != 0 {
+				t.Errorf("untracked Naan stock = %d, want it left at 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/realtime"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sseHeartbeatInterval   = 30 * time.Second
+	maxAdminSSEConnections = 10
+	loadUpdateInterval     = 10 * time.Second
+)
+
+// adminStreamSlots limits concurrent admin dashboard streams
+var adminStreamSlots = make(chan struct{}, maxAdminSSEConnections)
+
+// publishTransition pushes an order transition to live subscribers
+func publishTransition(order models.Order, from, to models.OrderStatus) {
+	event := realtime.Event{
+		Event:        "order_updated",
+		OrderID:      order.ID,
+		From:         string(from),
+		To:           string(to),
+		RestaurantID: order.RestaurantID,
+		Timestamp:    time.Now(),
+	}
+	var restaurant models.Restaurant
+	if err := config.DB.Select("name").First(&restaurant, order.RestaurantID).Error; err == nil {
+		event.Restaurant = restaurant.Name
+	}
+	// Re-read the driver: the caller's copy may predate the pickup
+	var current models.Order
+	if err := config.DB.Select("driver_id").First(&current, order.ID).Error; err == nil && current.DriverID != nil {
+		var driver models.User
+		if err := config.DB.Select("name").First(&driver, *current.DriverID).Error; err == nil {
+			event.DriverName = anonymiseName(driver.Name)
+		}
+	}
+	realtime.Default.Publish(event)
+}
+
+// periodicEvent is an extra SSE event recomputed on a timer for one stream
+type periodicEvent struct {
+	Name     string
+	Interval time.Duration
+	Build    func() interface{}
+}
+
+// streamEvents writes hub events to the client as SSE until it disconnects
+// or an admin ends the subscription. A non-nil periodic event is also sent
+// immediately and then every Interval.
+func streamEvents(c *gin.Context, sub *realtime.Subscription, periodic *periodicEvent) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var tick <-chan time.Time
+	if periodic != nil {
+		c.SSEvent(periodic.Name, periodic.Build())
+		c.Writer.Flush()
+		ticker := time.NewTicker(periodic.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-tick:
+			c.SSEvent(periodic.Name, periodic.Build())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		case <-sub.Done():
+			return false
+		case e := <-sub.Events:
+			c.SSEvent(e.Event, e)
+			return true
+		case t := <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"time": t})
+			return true
+		}
+	})
+}
+
+// StreamOrder streams live status updates for one of the customer's orders
+//
+// @Summary     Live status updates for my order (SSE)
+// @Tags        customer
+// @Produce     text/event-stream
+// @Param       id  path  int  true  "Order ID"
+// @Success     200  {string}  string  "event stream"
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     429  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/stream [get]
+func StreamOrder(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	if order.CustomerID != customerID {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
+		return
+	}
+
+	limit := sysconfig.Int(sysconfig.KeyMaxSSEConnections)
+	sub, err := realtime.Default.Subscribe(customerID, order.ID, limit)
+	if err != nil {
+		apierror.Respond(c, http.StatusTooManyRequests, apierror.ErrRateLimited,
+			"errors.too_many_sse_connections", gin.H{"limit": limit}, limit)
+		return
+	}
+	defer realtime.Default.Unsubscribe(sub)
+	streamEvents(c, sub, nil)
+}
+
+// AdminDashboardStream streams every order transition for the live ops view, plus a
+// load_update event with per-restaurant active orders every 10 seconds — admin only
+//
+// @Summary     Live feed of all order transitions (SSE)
+// @Tags        admin
+// @Produce     text/event-stream
+// @Success     200  {string}  string  "event stream"
+// @Failure     503  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/dashboard/stream [get]
+func AdminDashboardStream(c *gin.Context) {
+	select {
+	case adminStreamSlots <- struct{}{}:
+		defer func() { <-adminStreamSlots }()
+	default:
+		apierror.Respond(c, http.StatusServiceUnavailable, apierror.ErrUnavailable,
+			"errors.too_many_dashboard_connections", gin.H{"limit": maxAdminSSEConnections})
+		return
+	}
+
+	sub := realtime.Default.SubscribeGlobal(middleware.GetUserID(c))
+	defer realtime.Default.Unsubscribe(sub)
+	streamEvents(c, sub, &periodicEvent{
+		Name:     "load_update",
+		Interval: loadUpdateInterval,
+		Build:    func() interface{} { return gin.H{"restaurants": restaurantLoad(requestDB(c), 0)} },
+	})
+}
+
+// AdminGetSSESubscribers lists every open SSE stream: who holds it, which
+// order it follows (0 for the dashboard feed), since when, and how many
+// events it was sent — admin only
+//
+// @Summary     Open SSE streams
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/sse/subscribers [get]
+func AdminGetSSESubscribers(c *gin.Context) {
+	subscribers := realtime.Default.ListSubscribers()
+	c.JSON(http.StatusOK, gin.H{"count": len(subscribers), "subscribers": subscribers})
+}
+
+// AdminDisconnectSSEUser closes every SSE stream a user has open, e.g. a
+// leaked connection still receiving stale events. The client may reconnect.
+// Admin only.
+//
+// @Summary     Close a user's SSE streams
+// @Tags        admin
+// @Produce     json
+// @Param       userId  path  int  true  "User ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/sse/subscribers/{userId} [delete]
+func AdminDisconnectSSEUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.invalid_user_id", nil)
+		return
+	}
+	closed := realtime.Default.DisconnectUser(uint(userID))
+	log.Printf("sse: admin %d closed %d stream(s) of user %d", middleware.GetUserID(c), closed, userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Streams closed", "user_id": userID, "closed": closed})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Price of each subscription plan — kept here until a payment gateway exists
+var subscriptionPlanPrices = map[models.SubscriptionPlan]float64{
+	models.PlanMonthly: 99.0,
+}
+
+type SubscribeRequest struct {
+	Plan             models.SubscriptionPlan `json:"plan" binding:"required"`
+	PaymentReference string                  `json:"payment_reference"`
+}
+
+// activeSubscription returns the customer's current subscription, if any
+func activeSubscription(db *gorm.DB, customerID uint) (*models.DeliverySubscription, bool) {
+	var sub models.DeliverySubscription
+	err := db.Where("customer_id = ? AND is_active = ? AND expires_at > ?", customerID, true, time.Now()).
+		Order("expires_at desc").
+		First(&sub).Error
+	if err != nil {
+		return nil, false
+	}
+	return &sub, true
+}
+
+// Subscribe signs the customer up for a delivery subscription (auto-approved)
+//
+// @Summary     Subscribe to free delivery
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       body  body  SubscribeRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/subscription/subscribe [post]
+func Subscribe(c *gin.Context) {
+	if !requireFeature(c, models.FeatureDeliverySubscription) {
+		return
+	}
+	customerID := middleware.GetUserID(c)
+
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	price, ok := subscriptionPlanPrices[req.Plan]
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.invalid_plan", nil)
+		return
+	}
+
+	if existing, ok := activeSubscription(requestDB(c), customerID); ok {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.you_already_have_an_active_subscription", gin.H{
+			"subscription": existing,
+		})
+		return
+	}
+
+	now := time.Now()
+	sub := models.DeliverySubscription{
+		CustomerID:       customerID,
+		Plan:             req.Plan,
+		Price:            price,
+		StartedAt:        now,
+		ExpiresAt:        now.AddDate(0, 1, 0),
+		IsActive:         true,
+		PaymentReference: req.PaymentReference,
+	}
+	if err := requestDB(c).Create(&sub).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_subscription", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Subscription activated — delivery fees are now waived", "subscription": sub})
+}
+
+// CancelSubscription deactivates the customer's current subscription
+//
+// @Summary     Cancel my delivery subscription
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/subscription/cancel [delete]
+func CancelSubscription(c *gin.Context) {
+	if !requireFeature(c, models.FeatureDeliverySubscription) {
+		return
+	}
+	customerID := middleware.GetUserID(c)
+
+	sub, ok := activeSubscription(requestDB(c), customerID)
+	if !ok {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.no_active_subscription_found", nil)
+		return
+	}
+	requestDB(c).Model(sub).Update("is_active", false)
+	c.JSON(http.StatusOK, gin.H{"message": "Subscription cancelled", "subscription_id": sub.ID})
+}
+
+// GetMySubscription returns the customer's current plan status
+//
+// @Summary     Get my delivery subscription status
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/subscription [get]
+func GetMySubscription(c *gin.Context) {
+	if !requireFeature(c, models.FeatureDeliverySubscription) {
+		return
+	}
+	customerID := middleware.GetUserID(c)
+
+	sub, ok := activeSubscription(requestDB(c), customerID)
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"active": false, "subscription": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"active": true, "subscription": sub})
+}
+
+// AdminGetSubscriptions lists all subscriptions with revenue totals — admin only
+//
+// @Summary     List delivery subscriptions with revenue
+// @Tags        admin
+// @Produce     json
+// @Param       active  query  bool  false  "Only active subscriptions"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/subscriptions [get]
+func AdminGetSubscriptions(c *gin.Context) {
+	var subs []models.DeliverySubscription
+	query := requestDB(c).Preload("Customer")
+	if active := c.Query("active"); active == "true" {
+		query = query.Where("is_active = ? AND expires_at > ?", true, time.Now())
+	}
+	query.Order("created_at desc").Find(&subs)
+
+	var totalRevenue float64
+	activeCount := 0
+	now := time.Now()
+	for _, s := range subs {
+		totalRevenue += s.Price
+		if s.IsActive && s.ExpiresAt.After(now) {
+			activeCount++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":         len(subs),
+		"active_count":  activeCount,
+		"total_revenue": totalRevenue,
+		"subscriptions": subs,
+	})
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxSupportMessages caps one order's support thread
+const maxSupportMessages = 50
+
+type SupportMessageRequest struct {
+	Message string `json:"message" binding:"required,min=1,max=2000"`
+}
+
+// SupportThread is an order with customer messages no admin has read yet
+type SupportThread struct {
+	OrderID       uint    `json:"order_id"`
+	CustomerID    uint    `json:"customer_id"`
+	CustomerName  string  `json:"customer_name"`
+	OrderStatus   string  `json:"order_status"`
+	UnreadCount   int64   `json:"unread_count"`
+	LastMessageAt sqlTime `json:"last_message_at"`
+}
+
+// supportThread returns an order's messages, oldest first
+func supportThread(db *gorm.DB, orderID uint) []models.SupportMessage {
+	messages := []models.SupportMessage{}
+	db.Where("order_id = ?", orderID).Order("created_at, id").Find(&messages)
+	return messages
+}
+
+// markSupportRead marks the messages the other side sent on an order as
+// read by reader, returning how many were unread
+func markSupportRead(db *gorm.DB, orderID uint, reader models.UserRole) int64 {
+	return db.Model(&models.SupportMessage{}).
+		Where("order_id = ? AND sender_role <> ? AND is_read = ?", orderID, reader, false).
+		Update("is_read", true).RowsAffected
+}
+
+// postSupportMessage adds a message to an order's thread unless the thread is full
+func postSupportMessage(db *gorm.DB, orderID, senderID uint, role models.UserRole, text string) (models.SupportMessage, *apierror.Error) {
+	msg := models.SupportMessage{OrderID: orderID, SenderID: senderID, SenderRole: role, Message: text}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		tx.Model(&models.SupportMessage{}).Where("order_id = ?", orderID).Count(&count)
+		if count >= maxSupportMessages {
+			return apierror.New(http.StatusUnprocessableEntity, apierror.ErrUnprocessable,
+				"errors.support_thread_full", nil, maxSupportMessages)
+		}
+		return tx.Create(&msg).Error
+	})
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return msg, apiErr
+	}
+	if err != nil {
+		return msg, apierror.New(http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_send_message", nil)
+	}
+	return msg, nil
+}
+
+// customerOrder loads one of the caller's orders
+func customerOrder(c *gin.Context) (*models.Order, bool) {
+	var order models.Order
+	if err := requestDB(c).First(&order, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return nil, false
+	}
+	if order.CustomerID != middleware.GetUserID(c) {
+		apierror.Respond(c, http.StatusForbidden, apierror.ErrForbidden, "errors.this_order_does_not_belong_to_you", nil)
+		return nil, false
+	}
+	return &order, true
+}
+
+// SendSupportMessage lets a customer ask support about one of their orders
+//
+// @Summary     Message support about an order
+// @Tags        customer
+// @Accept      json
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Param       body  body  SupportMessageRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/support [post]
+func SendSupportMessage(c *gin.Context) {
+	var req SupportMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	order, ok := customerOrder(c)
+	if !ok {
+		return
+	}
+	msg, apiErr := postSupportMessage(requestDB(c), order.ID, order.CustomerID, models.RoleCustomer, req.Message)
+	if apiErr != nil {
+		apierror.RespondError(c, apiErr)
+		return
+	}
+	cache.Default.Delete(dashboardCacheKey)
+	c.JSON(http.StatusCreated, gin.H{"message": "Message sent to support", "support_message": msg})
+}
+
+// GetSupportThread returns an order's support thread to its customer and
+// marks support's replies as read
+//
+// @Summary     Read an order's support thread
+// @Tags        customer
+// @Produce     json
+// @Param       id  path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/orders/{id}/support [get]
+func GetSupportThread(c *gin.Context) {
+	order, ok := customerOrder(c)
+	if !ok {
+		return
+	}
+	messages := supportThread(requestDB(c), order.ID)
+	markSupportRead(requestDB(c), order.ID, models.RoleCustomer)
+	c.JSON(http.StatusOK, gin.H{
+		"order_id": order.ID,
+		"count":    len(messages),
+		"limit":    maxSupportMessages,
+		"messages": messages,
+	})
+}
+
+// AdminGetSupportThreads lists orders whose customers are waiting on a
+// reply, most recent message first — admin only
+//
+// @Summary     Support threads with unread messages
+// @Tags        admin
+// @Produce     json
+// @Param       page       query  int  false  "Page number (default 1)"
+// @Param       page_size  query  int  false  "Page size (default 20, max 100)"
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/support/threads [get]
+func AdminGetSupportThreads(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+	unread := func() *gorm.DB {
+		return requestDB(c).Table("support_messages").
+			Where("support_messages.sender_role = ? AND support_messages.is_read = ?", models.RoleCustomer, false)
+	}
+
+	var total int64
+	unread().Distinct("order_id").Count(&total)
+
+	threads := []SupportThread{}
+	unread().
+		Select("support_messages.order_id, orders.customer_id, users.name AS customer_name, orders.status AS order_status, " +
+			"COUNT(*) AS unread_count, MAX(support_messages.created_at) AS last_message_at").
+		Joins("JOIN orders ON orders.id = support_messages.order_id").
+		Joins("JOIN users ON users.id = orders.customer_id").
+		Group("support_messages.order_id, orders.customer_id, users.name, orders.status").
+		Order("last_message_at DESC, support_messages.order_id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&threads)
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"count":     len(threads),
+		"threads":   threads,
+	})
+}
+
+// AdminGetSupportThread returns an order's full support thread and marks the
+// customer's messages as read — admin only
+//
+// @Summary     Read an order's support thread
+// @Tags        admin
+// @Produce     json
+// @Param       orderId  path  int  true  "Order ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/support/threads/{orderId} [get]
+func AdminGetSupportThread(c *gin.Context) {
+	var order models.Order
+	if err := requestDB(c).Preload("Customer").First(&order, c.Param("orderId")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	messages := supportThread(requestDB(c), order.ID)
+	if markSupportRead(requestDB(c), order.ID, models.RoleAdmin) > 0 {
+		cache.Default.Delete(dashboardCacheKey)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"order_id":      order.ID,
+		"order_status":  order.Status,
+		"customer_id":   order.CustomerID,
+		"customer_name": order.Customer.Name,
+		"count":         len(messages),
+		"limit":         maxSupportMessages,
+		"messages":      messages,
+	})
+}
+
+// AdminReplySupportThread answers a customer in an order's support thread
+// and notifies them — admin only
+//
+// @Summary     Reply in an order's support thread
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       orderId  path  int  true  "Order ID"
+// @Param       body  body  SupportMessageRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     422  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/support/threads/{orderId}/reply [post]
+func AdminReplySupportThread(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req SupportMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var order models.Order
+	if err := requestDB(c).Preload("Customer").First(&order, c.Param("orderId")).Error; err != nil {
+		c.Error(err).SetMeta("errors.order_not_found")
+		c.Abort()
+		return
+	}
+	msg, apiErr := postSupportMessage(requestDB(c), order.ID, adminID, models.RoleAdmin, req.Message)
+	if apiErr != nil {
+		apierror.RespondError(c, apiErr)
+		return
+	}
+
+	err := notify.Default.Send(notify.Message{
+		UserID:  order.CustomerID,
+		Email:   order.Customer.Email,
+		Phone:   order.Customer.Phone,
+		Channel: notify.ChannelPush,
+		Title:   fmt.Sprintf("Support replied about order #%d", order.ID),
+		Body:    req.Message,
+
+		EventType:     "support_reply",
+		ReferenceID:   order.ID,
+		ReferenceType: "order",
+	})
+	if err != nil {
+		log.Printf("support: failed to notify customer %d about order %d: %v", order.CustomerID, order.ID, err)
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Reply sent", "support_message": msg})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MaintenanceRestaurantReinstated is logged when an admin lifts an automatic suspension
+const MaintenanceRestaurantReinstated = "RESTAURANT_REINSTATED"
+
+// openSuspension finds the restaurant's suspension that hasn't been lifted yet
+func openSuspension(db *gorm.DB, restaurantID uint, event *models.SuspensionEvent) error {
+	return db.Where("restaurant_id = ? AND reinstated_at IS NULL", restaurantID).
+		Order("suspended_at DESC").First(event).Error
+}
+
+// recordAutoCancel counts an auto-cancelled order (or a customer giving up
+// waiting for confirmation) against its restaurant and suspends the restaurant
+// once AUTO_SUSPEND_THRESHOLD cancels in a row is reached
+func recordAutoCancel(restaurantID uint, now time.Time) {
+	threshold := sysconfig.Int(sysconfig.KeyAutoSuspendThreshold)
+	var restaurant models.Restaurant
+	suspended := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Restaurant{}).Where("id = ?", restaurantID).
+			Update("consecutive_auto_cancels", gorm.Expr("consecutive_auto_cancels + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&restaurant, restaurantID).Error; err != nil {
+			return err
+		}
+		if threshold <= 0 || restaurant.ConsecutiveAutoCancels < threshold || !restaurant.IsActive {
+			return nil
+		}
+		reason := fmt.Sprintf("%d orders in a row were auto-cancelled", restaurant.ConsecutiveAutoCancels)
+		if err := tx.Model(&restaurant).Updates(map[string]interface{}{"is_active": false, "is_open": false}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.RestaurantStatusLog{
+			RestaurantID: restaurant.ID,
+			IsOpen:       false,
+			Reason:       "suspended: " + reason,
+		}).Error; err != nil {
+			return err
+		}
+		suspended = true
+		return tx.Create(&models.SuspensionEvent{
+			RestaurantID:    restaurant.ID,
+			Reason:          reason,
+			AutoCancelCount: restaurant.ConsecutiveAutoCancels,
+			SuspendedAt:     now,
+		}).Error
+	})
+	if err != nil {
+		log.Printf("auto-suspend: restaurant %d: %v", restaurantID, err)
+		return
+	}
+	if !suspended {
+		return
+	}
+	log.Printf("auto-suspend: restaurant %d suspended after %d auto-cancels", restaurantID, restaurant.ConsecutiveAutoCancels)
+	invalidateMenuCache(restaurant.ID)
+	sendSuspensionNotice(restaurant.ID, "restaurant_suspended", "Your restaurant has been suspended",
+		fmt.Sprintf("%d orders in a row were cancelled because they weren't confirmed in time. Contact support to be reinstated.",
+			restaurant.ConsecutiveAutoCancels))
+}
+
+// resetAutoCancels clears a restaurant's auto-cancel streak after it confirms an order
+func resetAutoCancels(db *gorm.DB, restaurantID uint) {
+	db.Model(&models.Restaurant{}).Where("id = ? AND consecutive_auto_cancels > 0", restaurantID).
+		Update("consecutive_auto_cancels", 0)
+}
+
+func sendSuspensionNotice(restaurantID uint, eventType, title, body string) {
+	var restaurant models.Restaurant
+	if err := config.DB.Preload("Owner").First(&restaurant, restaurantID).Error; err != nil {
+		return
+	}
+	notify.Default.Send(notify.Message{
+		UserID:  restaurant.Owner.ID,
+		Email:   restaurant.Owner.Email,
+		Phone:   restaurant.Owner.Phone,
+		Channel: notify.ChannelEmail,
+		Title:   title,
+		Body:    body,
+
+		EventType:     eventType,
+		ReferenceID:   restaurant.ID,
+		ReferenceType: "restaurant",
+	})
+}
+
+// AdminReinstateRestaurant lifts an automatic suspension and resets the
+// restaurant's auto-cancel streak — admin only. The restaurant stays closed
+// until its owner or the operating-hours scheduler opens it.
+//
+// @Summary     Reinstate a suspended restaurant
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/reinstate [put]
+func AdminReinstateRestaurant(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	var event models.SuspensionEvent
+	if err := openSuspension(requestDB(c), restaurant.ID, &event); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.restaurant_is_not_suspended", nil)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_load_suspension", nil)
+		return
+	}
+
+	now := time.Now()
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&event).Updates(map[string]interface{}{"reinstated_at": now, "reinstated_by": adminID}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&restaurant).Updates(map[string]interface{}{"is_active": true, "consecutive_auto_cancels": 0}).Error; err != nil {
+			return err
+		}
+		return logMaintenance(tx, MaintenanceRestaurantReinstated, &adminID, gin.H{
+			"restaurant_id":       restaurant.ID,
+			"suspension_event_id": event.ID,
+		})
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_reinstate_restaurant", nil)
+		return
+	}
+	sendSuspensionNotice(restaurant.ID, "restaurant_reinstated", "Your restaurant has been reinstated",
+		"Your restaurant is active again. Open it to start taking orders.")
+	c.JSON(http.StatusOK, gin.H{"message": "Restaurant reinstated", "restaurant": restaurant, "suspension": event})
+}
+
+// AdminGetSuspendedRestaurants lists restaurants whose automatic suspension
+// hasn't been lifted, most recent first — admin only
+//
+// @Summary     List suspended restaurants
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/restaurants/suspended [get]
+func AdminGetSuspendedRestaurants(c *gin.Context) {
+	suspensions := []models.SuspensionEvent{}
+	requestDB(c).Preload("Restaurant").
+		Where("reinstated_at IS NULL").
+		Order("suspended_at DESC").
+		Find(&suspensions)
+	c.JSON(http.StatusOK, gin.H{"count": len(suspensions), "suspensions": suspensions})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceFeeRequest struct {
+	Percent *float64 `json:"percent" binding:"required,min=0,max=100"`
+}
+
+// AdminSetServiceFeePercent sets the platform's cut of each order subtotal — admin only
+//
+// @Summary     Set the platform service fee percent
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  ServiceFeeRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/config/service-fee-percent [put]
+func AdminSetServiceFeePercent(c *gin.Context) {
+	adminID := middleware.GetUserID(c)
+	var req ServiceFeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	value := strconv.FormatFloat(*req.Percent, 'f', -1, 64)
+	if err := sysconfig.Set(sysconfig.KeyServiceFeePercent, value, &adminID); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_config", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Service fee updated", "service_fee_percent": *req.Percent})
+}
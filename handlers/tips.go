@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// splitTip divides a tip between the driver and the platform by the current
+// PLATFORM_TIP_SHARE_PCT. Callers store both halves on the order, so later
+// changes to the setting leave it alone.
+func splitTip(tip float64) (driverTip, platformIncome float64) {
+	pct := math.Min(math.Max(sysconfig.Float(sysconfig.KeyPlatformTipSharePct), 0), 100)
+	driverTip = math.Round(tip*(1-pct/100)*100) / 100
+	return driverTip, math.Round((tip-driverTip)*100) / 100
+}
+
+// GetDriverEarnings totals the delivery fees and tips a driver earned on
+// orders delivered in the date range, in the base currency. Tips count the
+// driver's share only.
+//
+// @Summary     My earnings
+// @Tags        driver
+// @Produce     json
+// @Param       from  query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to    query  string  false  "End date (YYYY-MM-DD), default today"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /driver/earnings [get]
+func GetDriverEarnings(c *gin.Context) {
+	driverID := middleware.GetUserID(c)
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var orders []models.Order
+	requestDB(c).Where("driver_id = ? AND status = ? AND delivered_at >= ? AND delivered_at < ?",
+		driverID, models.StatusDelivered, from, to).
+		Order("delivered_at").Find(&orders)
+
+	var deliveryFees, tips float64
+	out := make([]gin.H, 0, len(orders))
+	for _, o := range orders {
+		fee := toBase(o.DeliveryFee, o.ExchangeRate)
+		tip := toBase(o.DriverTipAmount, o.ExchangeRate)
+		deliveryFees += fee
+		tips += tip
+		out = append(out, gin.H{
+			"order_id":     o.ID,
+			"delivered_at": o.DeliveredAt,
+			"delivery_fee": fee,
+			"tip":          tip,
+			"total":        roundCents(fee + tip),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":          from.Format(dateLayout),
+		"to":            to.AddDate(0, 0, -1).Format(dateLayout),
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"deliveries":    len(out),
+		"delivery_fees": roundCents(deliveryFees),
+		"tips":          roundCents(tips),
+		"total":         roundCents(deliveryFees + tips),
+		"orders":        out,
+	})
+}
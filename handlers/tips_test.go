@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"food-delivery-api/models"
+)
+
+func TestDriverEarningsFollowDeliveryTime(t *testing.T) {
+	db := newTestDB(t)
+	restaurant, _ := createRestaurant(t, db)
+	customer := createUser(t, db, "Asha", models.RoleCustomer)
+	driver := createUser(t, db, "Dev", models.RoleDriver)
+	now := time.Now()
+	for i, deliveredAt := range []time.Time{now.AddDate(0, 0, -2), now.AddDate(0, 0, -40)} {
+		order := models.Order{
+			InvoiceNumber:   fmt.Sprintf("INV-EARN-%d", i),
+			CustomerID:      customer.ID,
+			RestaurantID:    restaurant.ID,
+			DriverID:        &driver.ID,
+			Status:          models.StatusDelivered,
+			DeliveryAddress: "1 Low St",
+			DeliveryFee:     10,
+			DriverTipAmount: 2,
+			ExchangeRate:    1,
+			DeliveredAt:     &deliveredAt,
+		}
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A later write, e.g. COD remittance, mustn't move the old delivery into range
+	db.Model(&models.Order{}).Where("invoice_number = ?", "INV-EARN-1").Update("cod_remitted_at", now)
+
+	w := serve(GetDriverEarnings, "/driver/earnings", driver.ID, models.RoleDriver, http.MethodGet, "/driver/earnings", "")
+	wantStatus(t, w, http.StatusOK)
+	var body struct {
+		Deliveries int     `json:"deliveries"`
+		Total      float64 `json:"total"`
+		Orders     []struct {
+			DeliveredAt time.Time `json:"delivered_at"`
+		} `json:"orders"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Deliveries != 1 || body.Total != 12 {
+		t.Fatalf("earnings = %d deliveries, %.2f total; want the one delivered 2 days ago worth 12", body.Deliveries, body.Total)
+	}
+	if got := body.Orders[0].DeliveredAt; got.Sub(now.AddDate(0, 0, -2)).Abs() > time.Second {
+		t.Errorf("delivered_at = %v, want the delivery time", got)
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/cache"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	topItemsCacheTTL     = 15 * time.Minute
+	defaultTopItemsLimit = 20
+	maxTopItemsLimit     = 100
+)
+
+// ItemProfitability is one menu item's sales on delivered orders. Revenue is
+// in the base currency and leaves out items the driver reported undelivered.
+// PrevRevenue and GrowthPct are only set when comparing to the previous
+// period; GrowthPct stays null when the item sold nothing then.
+type ItemProfitability struct {
+	MenuItemID        uint     `json:"menu_item_id"`
+	ItemName          string   `json:"item_name"` // snapshot name on the latest order
+	RestaurantID      uint     `json:"restaurant_id"`
+	RestaurantName    string   `json:"restaurant_name"`
+	TotalQuantitySold int64    `json:"total_quantity_sold"`
+	TotalRevenue      float64  `json:"total_revenue"`
+	AvgUnitPrice      float64  `json:"avg_unit_price"`
+	OrderCount        int64    `json:"order_count"`
+	PrevRevenue       *float64 `json:"prev_revenue,omitempty"`
+	GrowthPct         *float64 `json:"growth_pct,omitempty"`
+}
+
+// itemSales aggregates delivered order items per menu item for orders placed in [from, to)
+func itemSales(db *gorm.DB, from, to time.Time) *gorm.DB {
+	return db.Table("order_items").
+		Select("order_items.menu_item_id, "+
+			"(SELECT latest.name FROM order_items latest WHERE latest.menu_item_id = order_items.menu_item_id ORDER BY latest.id DESC LIMIT 1) AS item_name, "+
+			"orders.restaurant_id, restaurants.name AS restaurant_name, "+
+			"SUM(order_items.quantity) AS total_quantity_sold, "+
+			"ROUND(SUM(order_items.price * order_items.quantity * orders.exchange_rate), 2) AS total_revenue, "+
+			"ROUND(SUM(order_items.price * order_items.quantity * orders.exchange_rate) / SUM(order_items.quantity), 2) AS avg_unit_price, "+
+			"COUNT(DISTINCT order_items.order_id) AS order_count").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("orders.status = ? AND orders.created_at >= ? AND orders.created_at < ?", models.StatusDelivered, from, to).
+		Where("order_items.was_delivered = ? AND orders.deleted_at IS NULL AND order_items.deleted_at IS NULL", true).
+		Group("order_items.menu_item_id, orders.restaurant_id, restaurants.name")
+}
+
+// addPreviousPeriod fills PrevRevenue and GrowthPct on rows from the sales in [from, to)
+func addPreviousPeriod(db *gorm.DB, rows []ItemProfitability, from, to time.Time) {
+	if len(rows) == 0 {
+		return
+	}
+	ids := make([]uint, len(rows))
+	for i, r := range rows {
+		ids[i] = r.MenuItemID
+	}
+	var prev []ItemProfitability
+	itemSales(db, from, to).Where("order_items.menu_item_id IN ?", ids).Scan(&prev)
+	byItem := make(map[uint]float64, len(prev))
+	for _, p := range prev {
+		byItem[p.MenuItemID] = p.TotalRevenue
+	}
+	for i := range rows {
+		revenue := byItem[rows[i].MenuItemID]
+		rows[i].PrevRevenue = &revenue
+		if revenue > 0 {
+			growth := math.Round((rows[i].TotalRevenue-revenue)/revenue*10000) / 100
+			rows[i].GrowthPct = &growth
+		}
+	}
+}
+
+// AdminGetTopItems ranks menu items across all restaurants by the revenue
+// their delivered orders brought in, with the lowest earners alongside for
+// comparison — admin only. Cached for 15 minutes.
+//
+// @Summary     Most and least profitable menu items
+// @Tags        admin
+// @Produce     json
+// @Param       from                        query  string  false  "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param       to                          query  string  false  "End date (YYYY-MM-DD), default today"
+// @Param       limit                       query  int     false  "Items in each list, 1 to 100 (default 20)"
+// @Param       compared_to_previous_period query  bool    false  "Add prev_revenue and growth_pct from the equally long period before from"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/reports/top-items [get]
+func AdminGetTopItems(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30, 0)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTopItemsLimit)))
+	if err != nil || limit < 1 || limit > maxTopItemsLimit {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.limit_must_be_between_1_and", nil, maxTopItemsLimit)
+		return
+	}
+	compare := c.Query("compared_to_previous_period") == "true"
+
+	key := fmt.Sprintf("top-items:%s:%s:%d:%t", from.Format(dateLayout), to.Format(dateLayout), limit, compare)
+	if body, ok := cache.Default.Get(key); ok {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+
+	top := []ItemProfitability{}
+	itemSales(requestDB(c), from, to).
+		Order("total_revenue DESC, order_items.menu_item_id").Limit(limit).Scan(&top)
+	bottom := []ItemProfitability{}
+	itemSales(requestDB(c), from, to).
+		Having("SUM(order_items.price * order_items.quantity * orders.exchange_rate) > 0").
+		Order("total_revenue, order_items.menu_item_id").Limit(limit).Scan(&bottom)
+
+	response := gin.H{
+		"from":          from.Format(dateLayout),
+		"to":            to.AddDate(0, 0, -1).Format(dateLayout),
+		"limit":         limit,
+		"base_currency": sysconfig.Get(sysconfig.KeyBaseCurrency),
+		"top_items":     top,
+		"bottom_items":  bottom,
+		"generated_at":  time.Now(),
+	}
+	if compare {
+		prevFrom := from.Add(-to.Sub(from))
+		addPreviousPeriod(requestDB(c), top, prevFrom, from)
+		addPreviousPeriod(requestDB(c), bottom, prevFrom, from)
+		response["previous_from"] = prevFrom.Format(dateLayout)
+		response["previous_to"] = from.AddDate(0, 0, -1).Format(dateLayout)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_build_report", nil)
+		return
+	}
+	cache.Default.Set(key, body, topItemsCacheTTL)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer         = "Food Delivery API"
+	mfaChallengeTTL    = 5 * time.Minute
+	mfaMaxAttempts     = 5 // wrong codes before the mfa_token stops working
+	mfaTokenSize       = 32
+	mfaInvalidTokenMsg = "errors.mfa_token_invalid"
+	totpPeriod         = 30 // seconds per code
+)
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type TOTPLoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,len=6,numeric"`
+}
+
+func hashMFAToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpStep returns the time step code belongs to, allowing one step of clock
+// drift either way like totp.Validate
+func totpStep(code, secret string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+	for _, step := range []int64{current - 1, current, current + 1} {
+		want, err := totp.GenerateCode(secret, time.Unix(step*totpPeriod, 0))
+		if err == nil && subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// claimTOTPStep records step as the user's last accepted code, failing if that
+// step or a later one was already used, so each code works only once
+func claimTOTPStep(db *gorm.DB, userID uint, step int64) (bool, error) {
+	res := db.Model(&models.User{}).Where("id = ? AND totp_last_step < ?", userID, step).
+		UpdateColumn("totp_last_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+// startMFAChallenge responds to a correct password on a TOTP-protected account
+// with an mfa_token for POST /auth/totp instead of a JWT
+func startMFAChallenge(c *gin.Context, user models.User) {
+	buf := make([]byte, mfaTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_start_mfa_challenge", nil)
+		return
+	}
+	token := hex.EncodeToString(buf)
+	challenge := models.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: hashMFAToken(token),
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	}
+	if err := requestDB(c).Create(&challenge).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_start_mfa_challenge", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"mfa_required":       true,
+		"mfa_token":          token,
+		"expires_in_seconds": int(mfaChallengeTTL.Seconds()),
+	})
+}
+
+// SetupTOTP creates a new TOTP secret for the caller. It only takes effect once
+// confirmed with POST /profile/totp/verify-setup.
+//
+// @Summary     Start TOTP two-factor setup
+// @Tags        auth
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Failure     403  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /profile/totp/setup [post]
+func SetupTOTP(c *gin.Context) {
+	var user models.User
+	if err := requestDB(c).First(&user, middleware.GetUserID(c)).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_not_found")
+		c.Abort()
+		return
+	}
+	if user.TOTPEnabled {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.two_factor_authentication_is_already_enabled", nil)
+		return
+	}
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: totpIssuer, AccountName: user.Email})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_totp_secret", nil)
+		return
+	}
+	if err := requestDB(c).Model(&user).Update("totp_secret", key.Secret()).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_save_totp_secret", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Add this account to your authenticator app, then confirm with a code",
+		"secret":           key.Secret(),
+		"provisioning_uri": key.URL(),
+	})
+}
+
+// VerifyTOTPSetup enables two-factor login once the caller proves their
+// authenticator app produces valid codes
+//
+// @Summary     Confirm TOTP setup
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       body  body  TOTPCodeRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /profile/totp/verify-setup [post]
+func VerifyTOTPSetup(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	var user models.User
+	if err := requestDB(c).First(&user, middleware.GetUserID(c)).Error; err != nil {
+		c.Error(err).SetMeta("errors.user_not_found")
+		c.Abort()
+		return
+	}
+	if user.TOTPEnabled {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.two_factor_authentication_is_already_enabled", nil)
+		return
+	}
+	if user.TOTPSecret == "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.totp_setup_not_started", nil)
+		return
+	}
+	step, ok := totpStep(req.Code, user.TOTPSecret, time.Now())
+	if !ok {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.invalid_code", nil)
+		return
+	}
+	// The confirming code counts as used, so it can't also finish a login
+	if err := requestDB(c).Model(&user).Updates(map[string]interface{}{"totp_enabled": true, "totp_last_step": step}).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_enable_two_factor_authentication", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "totp_enabled": true})
+}
+
+// LoginTOTP completes a two-factor login: it exchanges the mfa_token from
+// Login and a current TOTP code for a JWT
+//
+// @Summary     Complete a two-factor login
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       body  body  TOTPLoginRequest  true  "Request body"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     401  {object}  apierror.ErrorResponse
+// @Router      /auth/totp [post]
+func LoginTOTP(c *gin.Context) {
+	var req TOTPLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+
+	var challenge models.MFAChallenge
+	if err := requestDB(c).Where("token_hash = ?", hashMFAToken(req.MFAToken)).First(&challenge).Error; err != nil ||
+		challenge.UsedAt != nil || challenge.Attempts >= mfaMaxAttempts || time.Now().After(challenge.ExpiresAt) {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, mfaInvalidTokenMsg, nil)
+		return
+	}
+	var user models.User
+	if err := requestDB(c).First(&user, challenge.UserID).Error; err != nil || !user.TOTPEnabled {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, mfaInvalidTokenMsg, nil)
+		return
+	}
+
+	step, ok := totpStep(req.Code, user.TOTPSecret, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		requestDB(c).Model(&challenge).UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+		msg := "errors.invalid_code"
+		if ok {
+			msg = "errors.totp_code_already_used"
+		}
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, msg,
+			gin.H{"attempts_left": mfaMaxAttempts - challenge.Attempts - 1})
+		return
+	}
+	// Claim the challenge so the same mfa_token can't be used twice, and the
+	// code's time step so the same code can't finish another login
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.MFAChallenge{}).
+			Where("id = ? AND used_at IS NULL AND attempts < ?", challenge.ID, mfaMaxAttempts).Update("used_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apierror.New(http.StatusUnauthorized, apierror.ErrUnauthorized, mfaInvalidTokenMsg, nil)
+		}
+		claimed, err := claimTOTPStep(tx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return apierror.New(http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.totp_code_already_used", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		apierror.Respond(c, http.StatusUnauthorized, apierror.ErrUnauthorized, mfaInvalidTokenMsg, nil)
+		return
+	}
+	respondLogin(c, &user)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+// totpAdmin sets up and confirms two-factor login for a new admin through the
+// handlers, returning the admin and their secret
+func totpAdmin(t *testing.T, db *gorm.DB) (models.User, string) {
+	t.Helper()
+	admin := createUser(t, db, "Admin", models.RoleAdmin)
+	w := serve(SetupTOTP, "/profile/totp/setup", admin.ID, models.RoleAdmin, http.MethodPost, "/profile/totp/setup", "")
+	wantStatus(t, w, http.StatusOK)
+	var setup struct{ Secret string }
+	json.Unmarshal(w.Body.Bytes(), &setup)
+	code, err := totp.GenerateCode(setup.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = serve(VerifyTOTPSetup, "/profile/totp/verify-setup", admin.ID, models.RoleAdmin, http.MethodPost,
+		"/profile/totp/verify-setup", fmt.Sprintf(`{"code":%q}`, code))
+	wantStatus(t, w, http.StatusOK)
+	return admin, setup.Secret
+}
+
+// mfaChallenge stores a live challenge for user and returns its mfa_token
+func mfaChallenge(t *testing.T, db *gorm.DB, user models.User, token string) string {
+	t.Helper()
+	challenge := models.MFAChallenge{UserID: user.ID, TokenHash: hashMFAToken(token), ExpiresAt: time.Now().Add(mfaChallengeTTL)}
+	if err := db.Create(&challenge).Error; err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func loginTOTP(token, code string) (int, apierror.ErrorResponse) {
+	w := serve(LoginTOTP, "/auth/totp", 0, "", http.MethodPost, "/auth/totp",
+		fmt.Sprintf(`{"mfa_token":%q,"code":%q}`, token, code))
+	var body apierror.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body
+}
+
+func TestTOTPLoginWithNextCode(t *testing.T) {
+	db := newTestDB(t)
+	admin, secret := totpAdmin(t, db)
+	code, _ := totp.GenerateCode(secret, time.Now().Add(totpPeriod*time.Second))
+
+	if status, body := loginTOTP(mfaChallenge(t, db, admin, "tok1"), code); status != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %+v", status, body)
+	}
+	// The mfa_token is spent
+	if status, _ := loginTOTP("tok1", code); status != http.StatusUnauthorized {
+		t.Errorf("reused mfa_token: status = %d, want 401", status)
+	}
+}
+
+func TestTOTPCodeCannotBeReplayed(t *testing.T) {
+	db := newTestDB(t)
+	admin, secret := totpAdmin(t, db)
+
+	// The code that confirmed setup is already used
+	db.First(&admin, admin.ID)
+	setupCode, _ := totp.GenerateCode(secret, time.Unix(admin.TOTPLastStep*totpPeriod, 0))
+	status, body := loginTOTP(mfaChallenge(t, db, admin, "tok1"), setupCode)
+	if status != http.StatusUnauthorized || body.Message != "This code has already been used; wait for the next one" {
+		t.Fatalf("setup code: got %d %q, want 401 already used", status, body.Message)
+	}
+
+	next, _ := totp.GenerateCode(secret, time.Now().Add(totpPeriod*time.Second))
+	if status, body := loginTOTP(mfaChallenge(t, db, admin, "tok2"), next); status != http.StatusOK {
+		t.Fatalf("next code: status = %d, want 200; body: %+v", status, body)
+	}
+	if status, _ := loginTOTP(mfaChallenge(t, db, admin, "tok3"), next); status != http.StatusUnauthorized {
+		t.Errorf("replayed code: status = %d, want 401", status)
+	}
+}
+
+func TestTOTPWrongCodesSpendTheChallenge(t *testing.T) {
+	db := newTestDB(t)
+	admin, secret := totpAdmin(t, db)
+	token := mfaChallenge(t, db, admin, "tok1")
+
+	for i := 1; i <= mfaMaxAttempts; i++ {
+		status, body := loginTOTP(token, "000000")
+		if status != http.StatusUnauthorized || body.Details["attempts_left"] != float64(mfaMaxAttempts-i) {
+			t.Fatalf("attempt %d: got %d %+v, want 401 with %d attempts left", i, status, body, mfaMaxAttempts-i)
+		}
+	}
+	next, _ := totp.GenerateCode(secret, time.Now().Add(totpPeriod*time.Second))
+	if status, _ := loginTOTP(token, next); status != http.StatusUnauthorized {
+		t.Errorf("right code after %d wrong ones: status = %d, want 401", mfaMaxAttempts, status)
+	}
+}
+
+func TestTOTPStepAllowsOneStepOfDrift(t *testing.T) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: totpIssuer, AccountName: "a@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	current := now.Unix() / totpPeriod
+	for drift, want := range map[int64]bool{-2: false, -1: true, 0: true, 1: true, 2: false} {
+		code, _ := totp.GenerateCode(key.Secret(), time.Unix((current+drift)*totpPeriod, 0))
+		step, ok := totpStep(code, key.Secret(), now)
+		if ok != want || (ok && step != current+drift) {
+			t.Errorf("drift %d: got step %d ok %v, want ok %v", drift, step, ok, want)
+		}
+	}
+}
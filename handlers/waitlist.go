@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/middleware"
+	"food-delivery-api/models"
+	"food-delivery-api/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notifyWaitlist tells every active waitlisted customer that the restaurant has opened
+func notifyWaitlist(restaurant models.Restaurant) {
+	var entries []models.RestaurantWaitlist
+	config.DB.Where("restaurant_id = ? AND notified_at IS NULL", restaurant.ID).Find(&entries)
+	for _, entry := range entries {
+		var customer models.User
+		if err := config.DB.First(&customer, entry.CustomerID).Error; err != nil {
+			continue
+		}
+		err := notify.Default.Send(notify.Message{
+			UserID:  customer.ID,
+			Email:   customer.Email,
+			Phone:   customer.Phone,
+			Channel: notify.ChannelPush,
+			Title:   restaurant.Name + " is open!",
+			Body:    restaurant.Name + " is now accepting orders.",
+
+			EventType:     "restaurant_open",
+			ReferenceID:   restaurant.ID,
+			ReferenceType: "restaurant",
+		})
+		if err != nil {
+			log.Printf("waitlist %d: failed to notify customer %d: %v", entry.ID, customer.ID, err)
+			continue
+		}
+		config.DB.Model(&entry).Update("notified_at", time.Now())
+	}
+}
+
+// setRestaurantOpen flips is_open, records the change in the status log and
+// notifies the waitlist when the restaurant opens. changedBy 0 is the scheduler.
+func setRestaurantOpen(restaurant *models.Restaurant, open bool, changedBy uint, reason string) {
+	wasOpen := restaurant.IsOpen
+	config.DB.Model(restaurant).Update("is_open", open)
+	if open == wasOpen {
+		return
+	}
+	config.DB.Create(&models.RestaurantStatusLog{
+		RestaurantID: restaurant.ID,
+		IsOpen:       open,
+		ChangedBy:    changedBy,
+		Reason:       reason,
+	})
+	if open {
+		go notifyWaitlist(*restaurant)
+	}
+}
+
+// JoinWaitlist adds the customer to a closed restaurant's waitlist
+//
+// @Summary     Join a closed restaurant's waitlist
+// @Tags        customer
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/restaurants/{id}/waitlist [post]
+func JoinWaitlist(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var restaurant models.Restaurant
+	if err := requestDB(c).Where("is_active = ?", true).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	if restaurant.IsOpen {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.restaurant_is_open_you_can_order_now", nil)
+		return
+	}
+
+	var entry models.RestaurantWaitlist
+	err := requestDB(c).Where("customer_id = ? AND restaurant_id = ? AND notified_at IS NULL", customerID, restaurant.ID).
+		First(&entry).Error
+	if err == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "You are already on the waitlist", "entry": entry})
+		return
+	}
+	entry = models.RestaurantWaitlist{CustomerID: customerID, RestaurantID: restaurant.ID}
+	if err := requestDB(c).Create(&entry).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_join_waitlist", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "We'll let you know when " + restaurant.Name + " opens", "entry": entry})
+}
+
+// LeaveWaitlist removes the customer from a restaurant's waitlist
+//
+// @Summary     Leave a restaurant's waitlist
+// @Tags        customer
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /customer/restaurants/{id}/waitlist [delete]
+func LeaveWaitlist(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	res := requestDB(c).Where("customer_id = ? AND restaurant_id = ? AND notified_at IS NULL", customerID, c.Param("id")).
+		Delete(&models.RestaurantWaitlist{})
+	if res.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.ErrNotFound, "errors.you_are_not_on_this_restaurants_waitlist", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from waitlist"})
+}
+
+// GetMyWaitlist lists the customer's active waitlist entries
+//
+// @Summary     List my waitlist entries
+// @Tags        customer
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /customer/waitlist [get]
+func GetMyWaitlist(c *gin.Context) {
+	customerID := middleware.GetUserID(c)
+	var entries []models.RestaurantWaitlist
+	requestDB(c).Preload("Restaurant").
+		Where("customer_id = ? AND notified_at IS NULL", customerID).
+		Order("created_at desc").
+		Find(&entries)
+	c.JSON(http.StatusOK, gin.H{"count": len(entries), "waitlist": entries})
+}
+
+// AdminGetRestaurantWaitlist shows how many customers are waiting on a restaurant — admin only
+//
+// @Summary     Waitlist size for a restaurant
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Restaurant ID"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/restaurants/{id}/waitlist [get]
+func AdminGetRestaurantWaitlist(c *gin.Context) {
+	var restaurant models.Restaurant
+	if err := requestDB(c).First(&restaurant, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.restaurant_not_found")
+		c.Abort()
+		return
+	}
+	var active, notified int64
+	requestDB(c).Model(&models.RestaurantWaitlist{}).Where("restaurant_id = ? AND notified_at IS NULL", restaurant.ID).Count(&active)
+	requestDB(c).Model(&models.RestaurantWaitlist{}).Where("restaurant_id = ? AND notified_at IS NOT NULL", restaurant.ID).Count(&notified)
+	c.JSON(http.StatusOK, gin.H{
+		"restaurant_id":  restaurant.ID,
+		"restaurant":     restaurant.Name,
+		"is_open":        restaurant.IsOpen,
+		"waitlist_size":  active,
+		"notified_total": notified,
+	})
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+	"food-delivery-api/eventbus"
+	"food-delivery-api/models"
+	"food-delivery-api/webhook"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Secret string   `json:"secret" binding:"required,min=16"`
+	Events []string `json:"events"` // empty subscribes to every event
+}
+
+var webhookEvents = map[string]bool{
+	eventbus.OrderPlaced:        true,
+	eventbus.OrderStatusChanged: true,
+	eventbus.ItemEightySixed:    true,
+}
+
+// webhookPayload is the JSON body posted to webhooks
+type webhookPayload struct {
+	Event         string             `json:"event"`
+	OrderID       uint               `json:"order_id"`
+	InvoiceNumber string             `json:"invoice_number"`
+	RestaurantID  uint               `json:"restaurant_id"`
+	From          models.OrderStatus `json:"from,omitempty"`
+	To            models.OrderStatus `json:"to"`
+	Timestamp     time.Time          `json:"timestamp"`
+}
+
+// itemWebhookPayload is the JSON body posted to webhooks for menu item events
+type itemWebhookPayload struct {
+	Event        string    `json:"event"`
+	ItemID       uint      `json:"item_id"`
+	RestaurantID uint      `json:"restaurant_id"`
+	Name         string    `json:"name"`
+	Reason       string    `json:"reason,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// webhookWants reports whether the webhook is subscribed to event
+func webhookWants(hook models.Webhook, event string) bool {
+	if hook.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(hook.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// DispatchOrderWebhooks queues an order event for every active webhook subscribed to it
+func DispatchOrderWebhooks(payload interface{}) {
+	e, ok := payload.(eventbus.OrderEvent)
+	if !ok {
+		return
+	}
+	event := eventbus.OrderStatusChanged
+	if e.From == "" {
+		event = eventbus.OrderPlaced
+	}
+	body, err := json.Marshal(webhookPayload{
+		Event:         event,
+		OrderID:       e.Order.ID,
+		InvoiceNumber: e.Order.InvoiceNumber,
+		RestaurantID:  e.Order.RestaurantID,
+		From:          e.From,
+		To:            e.To,
+		Timestamp:     time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	queueWebhooks(event, body)
+}
+
+// DispatchItemWebhooks queues an 86'd item for every active webhook subscribed to it
+func DispatchItemWebhooks(payload interface{}) {
+	e, ok := payload.(eventbus.ItemEightySixedEvent)
+	if !ok {
+		return
+	}
+	body, err := json.Marshal(itemWebhookPayload{
+		Event:        eventbus.ItemEightySixed,
+		ItemID:       e.Item.ID,
+		RestaurantID: e.Item.RestaurantID,
+		Name:         e.Item.Name,
+		Reason:       e.Reason,
+		Timestamp:    e.At,
+	})
+	if err != nil {
+		return
+	}
+	queueWebhooks(eventbus.ItemEightySixed, body)
+}
+
+// queueWebhooks records a pending delivery of body to every active webhook
+// subscribed to event and starts sending them
+func queueWebhooks(event string, body []byte) {
+	var hooks []models.Webhook
+	config.DB.Where("is_active = ?", true).Find(&hooks)
+	for _, hook := range hooks {
+		if !webhookWants(hook, event) {
+			continue
+		}
+		entry := models.WebhookDeliveryLog{WebhookID: hook.ID, Event: event, Payload: string(body), Status: models.DeliveryPending}
+		if err := config.DB.Create(&entry).Error; err != nil {
+			log.Printf("webhook %d: failed to queue %s: %v", hook.ID, event, err)
+			continue
+		}
+		go deliverWebhook(hook, entry)
+	}
+}
+
+// ResumeWebhookDeliveries sends deliveries still pending from the last run,
+// which stopped before their round of attempts finished
+func ResumeWebhookDeliveries() {
+	entries, err := pendingDeliveries(config.DB)
+	if err != nil {
+		log.Printf("webhooks: failed to load pending deliveries: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		go deliverWebhook(entry.Webhook, entry)
+	}
+	if len(entries) > 0 {
+		log.Printf("webhooks: resumed %d pending deliveries", len(entries))
+	}
+}
+
+// pendingDeliveries loads pending deliveries to active webhooks, oldest first
+func pendingDeliveries(db *gorm.DB) ([]models.WebhookDeliveryLog, error) {
+	var entries []models.WebhookDeliveryLog
+	err := db.Preload("Webhook").
+		Joins("JOIN webhooks ON webhooks.id = webhook_delivery_logs.webhook_id AND webhooks.is_active = ?", true).
+		Where("webhook_delivery_logs.status = ?", models.DeliveryPending).
+		Order("webhook_delivery_logs.id").
+		Find(&entries).Error
+	return entries, err
+}
+
+// deliverWebhook runs one round of attempts and records its outcome
+func deliverWebhook(hook models.Webhook, entry models.WebhookDeliveryLog) {
+	attempts, err := webhook.Deliver(hook.URL, hook.Secret, entry.Payload)
+	updates := map[string]interface{}{"attempts": attempts}
+	if err != nil {
+		updates["status"] = models.DeliveryFailed
+		updates["last_error"] = err.Error()
+		log.Printf("webhook %d: delivery %d failed after %d attempts: %v", hook.ID, entry.ID, attempts, err)
+	} else {
+		updates["status"] = models.DeliveryDelivered
+		updates["last_error"] = ""
+		updates["delivered_at"] = time.Now()
+	}
+	config.DB.Model(&entry).Updates(updates)
+}
+
+// AdminCreateWebhook registers a URL to receive order and menu events — admin only
+//
+// @Summary     Register a webhook
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       body  body  CreateWebhookRequest  true  "Request body"
+// @Success     201  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/webhooks [post]
+func AdminCreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, err.Error(), nil)
+		return
+	}
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.url_must_be_http_or_https", nil)
+		return
+	}
+	for _, e := range req.Events {
+		if !webhookEvents[e] {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrBadRequest, "errors.unknown_event",
+				gin.H{"valid_events": []string{eventbus.OrderPlaced, eventbus.OrderStatusChanged, eventbus.ItemEightySixed}}, e)
+			return
+		}
+	}
+	hook := models.Webhook{URL: req.URL, Secret: req.Secret, Events: strings.Join(req.Events, ","), IsActive: true}
+	if err := requestDB(c).Create(&hook).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_create_webhook", nil)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Webhook registered", "webhook": hook})
+}
+
+// AdminGetWebhooks lists registered webhooks — admin only
+//
+// @Summary     List webhooks
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/webhooks [get]
+func AdminGetWebhooks(c *gin.Context) {
+	hooks := []models.Webhook{}
+	requestDB(c).Order("id").Find(&hooks)
+	c.JSON(http.StatusOK, gin.H{"count": len(hooks), "webhooks": hooks})
+}
+
+// AdminGetFailedDeliveries lists deliveries whose last round of attempts all
+// failed, newest first — admin only
+//
+// @Summary     List failed webhook deliveries
+// @Tags        admin
+// @Produce     json
+// @Param       webhook_id  query  int     false  "Only this webhook"
+// @Param       since       query  string  false  "Created on or after this date (YYYY-MM-DD)"
+// @Param       page        query  int     false  "Page number (default 1)"
+// @Param       page_size   query  int     false  "Page size (default 20, max 100)"
+// @Success     200  {object}  map[string]interface{}
+// @Failure     400  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/webhooks/failed-deliveries [get]
+func AdminGetFailedDeliveries(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+	query := requestDB(c).Model(&models.WebhookDeliveryLog{}).Where("status = ?", models.DeliveryFailed)
+	if id := c.Query("webhook_id"); id != "" {
+		query = query.Where("webhook_id = ?", id)
+	}
+	if s := c.Query("since"); s != "" {
+		since, err := time.ParseInLocation(dateLayout, s, time.Local)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "errors.since_must_be_yyyy_mm_dd", nil)
+			return
+		}
+		query = query.Where("created_at >= ?", since)
+	}
+	var total int64
+	query.Count(&total)
+	deliveries := []models.WebhookDeliveryLog{}
+	query.Order("created_at desc, id desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries)
+	c.JSON(http.StatusOK, gin.H{
+		"page":       page,
+		"page_size":  pageSize,
+		"total":      total,
+		"count":      len(deliveries),
+		"deliveries": deliveries,
+	})
+}
+
+// AdminReplayDelivery starts a fresh round of attempts for a failed delivery,
+// signed again with the webhook's current secret — admin only. The outcome is
+// written to the delivery log.
+//
+// @Summary     Replay a failed webhook delivery
+// @Tags        admin
+// @Produce     json
+// @Param       id  path  int  true  "Delivery ID"
+// @Success     202  {object}  map[string]interface{}
+// @Failure     404  {object}  apierror.ErrorResponse
+// @Failure     409  {object}  apierror.ErrorResponse
+// @Security    BearerAuth
+// @Router      /admin/webhooks/deliveries/{id}/replay [post]
+func AdminReplayDelivery(c *gin.Context) {
+	var entry models.WebhookDeliveryLog
+	if err := requestDB(c).First(&entry, c.Param("id")).Error; err != nil {
+		c.Error(err).SetMeta("errors.delivery_not_found")
+		c.Abort()
+		return
+	}
+	var hook models.Webhook
+	if err := requestDB(c).First(&hook, entry.WebhookID).Error; err != nil {
+		c.Error(err).SetMeta("errors.webhook_not_found")
+		c.Abort()
+		return
+	}
+	// Conditional on failed so two replays can't run at once
+	res := requestDB(c).Model(&models.WebhookDeliveryLog{}).
+		Where("id = ? AND status = ?", entry.ID, models.DeliveryFailed).
+		Updates(map[string]interface{}{
+			"status":   models.DeliveryPending,
+			"attempts": 0,
+			"replays":  entry.Replays + 1,
+		})
+	if res.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.failed_to_replay_delivery", nil)
+		return
+	}
+	if res.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusConflict, apierror.ErrConflict, "errors.only_failed_deliveries_can_be_replayed",
+			gin.H{"status": entry.Status})
+		return
+	}
+	go deliverWebhook(hook, entry)
+	c.JSON(http.StatusAccepted, gin.H{"message": "Replay started", "delivery_id": entry.ID})
+}
+
+// AdminGetWebhookStats summarises every delivery — admin only
+//
+// @Summary     Webhook delivery stats
+// @Tags        admin
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Security    BearerAuth
+// @Router      /admin/webhooks/stats [get]
+func AdminGetWebhookStats(c *gin.Context) {
+	var row struct {
+		Total     int64
+		Delivered int64
+		Failed    int64
+		Pending   int64
+		AvgRetry  float64
+	}
+	requestDB(c).Model(&models.WebhookDeliveryLog{}).
+		Select("COUNT(*) AS total, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS delivered, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS failed, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS pending, "+
+			"COALESCE(AVG(CASE WHEN status <> ? THEN attempts - 1 END), 0) AS avg_retry",
+			models.DeliveryDelivered, models.DeliveryFailed, models.DeliveryPending, models.DeliveryPending).
+		Scan(&row)
+
+	// Pending deliveries haven't finished a round, so they count towards neither rate
+	var successRate float64
+	if finished := row.Delivered + row.Failed; finished > 0 {
+		successRate = float64(row.Delivered) * 100 / float64(finished)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total_deliveries":    row.Total,
+		"delivered":           row.Delivered,
+		"failed":              row.Failed,
+		"pending":             row.Pending,
+		"success_rate_pct":    math.Round(successRate*100) / 100,
+		"average_retry_count": math.Round(row.AvgRetry*100) / 100,
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"food-delivery-api/eventbus"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm"
+)
+
+// webhookReceiver starts a server that accepts every delivery and hands its
+// body to the test
+func webhookReceiver(t *testing.T) (*httptest.Server, chan []byte) {
+	t.Helper()
+	bodies := make(chan []byte, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	t.Cleanup(srv.Close)
+	return srv, bodies
+}
+
+// waitForStatus polls a delivery until it reaches status, so its goroutine
+// is done with the database before the test ends
+func waitForStatus(t *testing.T, db *gorm.DB, id uint, status string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var entry models.WebhookDeliveryLog
+		db.First(&entry, id)
+		if entry.Status == status {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery %d is %q, want %q", id, entry.Status, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEightySixMenuItemDispatchesWebhook(t *testing.T) {
+	db := newTestDB(t)
+	bus := eventbus.Default
+	eventbus.Default = eventbus.New()
+	defer func() { eventbus.Default = bus }()
+	eventbus.Default.Subscribe(eventbus.ItemEightySixed, DispatchItemWebhooks)
+
+	srv, bodies := webhookReceiver(t)
+	db.Create(&models.Webhook{URL: srv.URL, Secret: "0123456789abcdef", Events: eventbus.ItemEightySixed, IsActive: true})
+	db.Create(&models.Webhook{URL: srv.URL, Secret: "0123456789abcdef", Events: eventbus.OrderPlaced, IsActive: true})
+	restaurant, items := createRestaurant(t, db, models.MenuItem{Name: "Paneer", Price: 200})
+
+	w := serve(EightySixMenuItem, "/restaurant/menu/:itemId/eighty-six", restaurant.OwnerID, models.RoleRestaurant,
+		http.MethodPut, fmt.Sprintf("/restaurant/menu/%d/eighty-six", items[0].ID), `{"reason":"out of paneer"}`)
+	wantStatus(t, w, http.StatusOK)
+
+	var got itemWebhookPayload
+	select {
+	case body := <-bodies:
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery received")
+	}
+	if got.Event != eventbus.ItemEightySixed || got.ItemID != items[0].ID || got.RestaurantID != restaurant.ID ||
+		got.Name != "Paneer" || got.Reason != "out of paneer" {
+		t.Errorf("payload = %+v", got)
+	}
+
+	var entries []models.WebhookDeliveryLog
+	db.Find(&entries)
+	if len(entries) != 1 {
+		t.Fatalf("%d deliveries queued, want 1 for the subscribed webhook", len(entries))
+	}
+	waitForStatus(t, db, entries[0].ID, models.DeliveryDelivered)
+}
+
+func TestResumeWebhookDeliveriesSendsPendingOnes(t *testing.T) {
+	db := newTestDB(t)
+	srv, bodies := webhookReceiver(t)
+	active := models.Webhook{URL: srv.URL, Secret: "0123456789abcdef", IsActive: true}
+	inactive := models.Webhook{URL: srv.URL, Secret: "0123456789abcdef", IsActive: true}
+	db.Create(&active)
+	db.Create(&inactive)
+	db.Model(&inactive).Update("is_active", false)
+
+	pending := models.WebhookDeliveryLog{WebhookID: active.ID, Event: eventbus.OrderPlaced, Payload: `{"order_id":1}`, Status: models.DeliveryPending}
+	failed := models.WebhookDeliveryLog{WebhookID: active.ID, Event: eventbus.OrderPlaced, Payload: `{"order_id":2}`, Status: models.DeliveryFailed}
+	paused := models.WebhookDeliveryLog{WebhookID: inactive.ID, Event: eventbus.OrderPlaced, Payload: `{"order_id":3}`, Status: models.DeliveryPending}
+	for _, entry := range []*models.WebhookDeliveryLog{&pending, &failed, &paused} {
+		if err := db.Create(entry).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ResumeWebhookDeliveries()
+
+	select {
+	case body := <-bodies:
+		if string(body) != pending.Payload {
+			t.Errorf("resent %s, want %s", body, pending.Payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending delivery not resent")
+	}
+	waitForStatus(t, db, pending.ID, models.DeliveryDelivered)
+	select {
+	case body := <-bodies:
+		t.Errorf("unexpected delivery %s", body)
+	default:
+	}
+	var entry models.WebhookDeliveryLog
+	db.First(&entry, paused.ID)
+	if entry.Status != models.DeliveryPending {
+		t.Errorf("delivery to an inactive webhook is %q, want it left pending", entry.Status)
+	}
+}
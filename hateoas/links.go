@@ -0,0 +1,64 @@
+// Package hateoas works out the requests a caller can make next on a
+// resource, so clients follow links instead of hard-coding the workflow.
+package hateoas
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"food-delivery-api/models"
+	"food-delivery-api/statemachine"
+)
+
+// Link is a request the caller can make next
+type Link = models.Link
+
+// orderRels names the action that moves an order into each status
+var orderRels = map[models.OrderStatus]string{
+	models.StatusConfirmed:      "confirm",
+	models.StatusPreparing:      "start_preparing",
+	models.StatusReadyForPickup: "mark_ready",
+	models.StatusPickedUp:       "pickup",
+	models.StatusDelivered:      "deliver",
+	models.StatusCancelled:      "cancel",
+}
+
+// OrderLinks lists the status changes role may make to order, one link per
+// next status the state machine allows from the current one. Admins get every
+// next status through the override endpoint. Hrefs are relative unless
+// baseURL is set.
+func OrderLinks(order *models.Order, role models.UserRole, baseURL string) []Link {
+	base := strings.TrimRight(baseURL, "/")
+	links := []Link{}
+	for _, next := range statemachine.ValidTransitionsFrom(order.Status) {
+		var path string
+		switch role {
+		case models.RoleAdmin:
+			path = fmt.Sprintf("/api/admin/orders/%d/status", order.ID)
+		case models.RoleCustomer, models.RoleRestaurant, models.RoleDriver:
+			if statemachine.CanTransition(order.Status, next, string(role)) != nil {
+				continue
+			}
+			path = orderActionPath(order.ID, role, next)
+		default:
+			continue
+		}
+		links = append(links, Link{Rel: orderRels[next], Href: base + path, Method: http.MethodPut})
+	}
+	return links
+}
+
+// orderActionPath is the endpoint role calls to move an order into next
+func orderActionPath(orderID uint, role models.UserRole, next models.OrderStatus) string {
+	switch {
+	case role == models.RoleCustomer:
+		return fmt.Sprintf("/api/customer/orders/%d/cancel", orderID)
+	case role == models.RoleDriver && next == models.StatusPickedUp:
+		return fmt.Sprintf("/api/driver/orders/%d/pickup", orderID)
+	case role == models.RoleDriver:
+		return fmt.Sprintf("/api/driver/orders/%d/deliver", orderID)
+	default:
+		return fmt.Sprintf("/api/restaurant/orders/%d/status", orderID)
+	}
+}
@@ -0,0 +1,76 @@
+package hateoas
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"food-delivery-api/models"
+)
+
+func TestOrderLinks(t *testing.T) {
+	put := func(rel, href string) Link { return Link{Rel: rel, Href: href, Method: http.MethodPut} }
+	const (
+		admin    = "/api/admin/orders/7/status"
+		owner    = "/api/restaurant/orders/7/status"
+		cancel   = "/api/customer/orders/7/cancel"
+		pickup   = "/api/driver/orders/7/pickup"
+		delivery = "/api/driver/orders/7/deliver"
+	)
+	for _, tc := range []struct {
+		status models.OrderStatus
+		role   models.UserRole
+		want   []Link
+	}{
+		{models.StatusPlaced, models.RoleCustomer, []Link{put("cancel", cancel)}},
+		{models.StatusPlaced, models.RoleRestaurant, []Link{put("confirm", owner), put("cancel", owner)}},
+		{models.StatusPlaced, models.RoleDriver, []Link{}},
+		{models.StatusPlaced, models.RoleAdmin, []Link{put("confirm", admin), put("cancel", admin)}},
+
+		{models.StatusConfirmed, models.RoleCustomer, []Link{put("cancel", cancel)}},
+		{models.StatusConfirmed, models.RoleRestaurant, []Link{put("start_preparing", owner), put("cancel", owner)}},
+		{models.StatusConfirmed, models.RoleDriver, []Link{}},
+		{models.StatusConfirmed, models.RoleAdmin, []Link{put("start_preparing", admin), put("cancel", admin)}},
+
+		{models.StatusPreparing, models.RoleCustomer, []Link{}},
+		{models.StatusPreparing, models.RoleRestaurant, []Link{put("mark_ready", owner)}},
+		{models.StatusPreparing, models.RoleDriver, []Link{}},
+		{models.StatusPreparing, models.RoleAdmin, []Link{put("mark_ready", admin)}},
+
+		{models.StatusReadyForPickup, models.RoleCustomer, []Link{}},
+		{models.StatusReadyForPickup, models.RoleRestaurant, []Link{}},
+		{models.StatusReadyForPickup, models.RoleDriver, []Link{put("pickup", pickup)}},
+		{models.StatusReadyForPickup, models.RoleAdmin, []Link{put("pickup", admin)}},
+
+		{models.StatusPickedUp, models.RoleCustomer, []Link{}},
+		{models.StatusPickedUp, models.RoleRestaurant, []Link{}},
+		{models.StatusPickedUp, models.RoleDriver, []Link{put("deliver", delivery)}},
+		{models.StatusPickedUp, models.RoleAdmin, []Link{put("deliver", admin)}},
+
+		{models.StatusDelivered, models.RoleCustomer, []Link{}},
+		{models.StatusDelivered, models.RoleRestaurant, []Link{}},
+		{models.StatusDelivered, models.RoleDriver, []Link{}},
+		{models.StatusDelivered, models.RoleAdmin, []Link{}},
+
+		{models.StatusCancelled, models.RoleCustomer, []Link{}},
+		{models.StatusCancelled, models.RoleRestaurant, []Link{}},
+		{models.StatusCancelled, models.RoleDriver, []Link{}},
+		{models.StatusCancelled, models.RoleAdmin, []Link{}},
+
+		{models.StatusPlaced, models.UserRole("support"), []Link{}},
+	} {
+		order := &models.Order{ID: 7, Status: tc.status}
+		if got := OrderLinks(order, tc.role, ""); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s as %s: links = %+v, want %+v", tc.status, tc.role, got, tc.want)
+		}
+	}
+}
+
+func TestOrderLinksBaseURL(t *testing.T) {
+	order := &models.Order{ID: 7, Status: models.StatusPickedUp}
+	got := OrderLinks(order, models.RoleDriver, "https://api.example.com/")
+	want := []Link{{Rel: "deliver", Href: "https://api.example.com/api/driver/orders/7/deliver", Method: http.MethodPut}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("links = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+// Package health checks the dependencies the API needs to serve traffic.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"food-delivery-api/geo"
+	"food-delivery-api/notify"
+
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+)
+
+// Timeout bounds a whole Run; a checker still going by then is reported as failed
+const Timeout = 3 * time.Second
+
+// Checker reports whether one dependency is usable
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// DBChecker runs a trivial query against the database
+type DBChecker struct {
+	DB *gorm.DB
+}
+
+func (d DBChecker) Check(ctx context.Context) error {
+	return d.DB.WithContext(ctx).Exec("SELECT 1").Error
+}
+
+// NotifierChecker probes the notifier without sending anything. Notifiers
+// that can't be probed are assumed healthy.
+type NotifierChecker struct {
+	Notifier notify.Notifier
+}
+
+func (n NotifierChecker) Check(ctx context.Context) error {
+	if p, ok := n.Notifier.(notify.Prober); ok {
+		return p.Probe(ctx)
+	}
+	return nil
+}
+
+// GeocoderChecker probes the geocoding provider. Geocoders that can't be
+// probed, e.g. none configured, are assumed healthy.
+type GeocoderChecker struct {
+	Geocoder geo.Geocoder
+}
+
+func (g GeocoderChecker) Check(ctx context.Context) error {
+	if p, ok := g.Geocoder.(geo.Prober); ok {
+		return p.Probe(ctx)
+	}
+	return nil
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = map[string]Checker{}
+)
+
+// Register adds a dependency to every Run under the given name
+func Register(name string, c Checker) {
+	mu.Lock()
+	checkers[name] = c
+	mu.Unlock()
+}
+
+// Run checks every registered dependency in parallel. Each result is "ok" or
+// "degraded: <error>"; healthy is false when any check failed.
+func Run(ctx context.Context) (results map[string]string, healthy bool) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	mu.RLock()
+	defer mu.RUnlock()
+	results = make(map[string]string, len(checkers))
+	healthy = true
+	var resultsMu sync.Mutex
+	var g errgroup.Group
+	for name, c := range checkers {
+		g.Go(func() error {
+			err := check(ctx, c)
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
+			if err != nil {
+				results[name] = "degraded: " + err.Error()
+				healthy = false
+			} else {
+				results[name] = "ok"
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return results, healthy
+}
+
+// check stops waiting at the deadline even if the checker ignores ctx
+func check(ctx context.Context, c Checker) error {
+	done := make(chan error, 1)
+	go func() { done <- c.Check(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
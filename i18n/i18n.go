@@ -0,0 +1,180 @@
+// Package i18n translates the messages the API shows to users. Each locale
+// has a YAML catalogue named after it (en.yaml, es.yaml) mapping message keys
+// to text; nested keys are joined with dots, e.g. errors.order_not_found.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is used when a request asks for no locale we have a catalogue for
+const DefaultLocale = "en"
+
+// LocaleKey is the gin context key holding the request's locale
+const LocaleKey = "locale"
+
+//go:embed *.yaml
+var catalogues embed.FS
+
+// Default translates with the catalogues built into the binary
+var Default = mustLoad(catalogues)
+
+// Translator looks up message keys in per-locale catalogues
+type Translator struct {
+	messages map[string]map[string]string // locale -> key -> message
+}
+
+// Load reads every <locale>.yaml file at the root of fsys
+func Load(fsys fs.FS) (*Translator, error) {
+	files, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	t := &Translator{messages: map[string]map[string]string{}}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var tree map[string]interface{}
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		messages := map[string]string{}
+		if err := flatten("", tree, messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		t.messages[strings.ToLower(strings.TrimSuffix(path.Base(file), ".yaml"))] = messages
+	}
+	if _, ok := t.messages[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("no catalogue for the default locale %q", DefaultLocale)
+	}
+	return t, nil
+}
+
+func mustLoad(fsys fs.FS) *Translator {
+	t, err := Load(fsys)
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	return t
+}
+
+func flatten(prefix string, tree map[string]interface{}, out map[string]string) error {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case string:
+			out[key] = v
+		case map[string]interface{}:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: expected a string or a map", key)
+		}
+	}
+	return nil
+}
+
+// Locales lists the locales with a catalogue, sorted
+func (t *Translator) Locales() []string {
+	locales := make([]string, 0, len(t.messages))
+	for locale := range t.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// T returns the message for key in locale, formatted with args as by
+// fmt.Sprintf. A key the locale lacks falls back to the locale's language
+// (es for es-mx), then to the default locale. A key no catalogue has is
+// returned as is, so text that was never a key, like a validator's message,
+// passes through unchanged.
+func (t *Translator) T(locale, key string, args ...interface{}) string {
+	message, ok := t.lookup(locale, key)
+	if !ok {
+		message = key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+func (t *Translator) lookup(locale, key string) (string, bool) {
+	locale = strings.ToLower(locale)
+	lang, _, _ := strings.Cut(locale, "-")
+	for _, l := range []string{locale, lang, DefaultLocale} {
+		if message, ok := t.messages[l][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Match picks the locale to answer an Accept-Language header with: the
+// highest weighted language we have a catalogue for, matched exactly or by
+// its language alone, else the default locale
+func (t *Translator) Match(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, w := range tags {
+		if _, ok := t.messages[w.tag]; ok {
+			return w.tag
+		}
+		if lang, _, _ := strings.Cut(w.tag, "-"); lang != w.tag {
+			if _, ok := t.messages[lang]; ok {
+				return lang
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Locale returns the locale middleware.I18n resolved for the request
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(LocaleKey); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T translates key into the request's locale with the Default translator
+func T(c *gin.Context, key string, args ...interface{}) string {
+	return Default.T(Locale(c), key, args...)
+}
@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func testTranslator(t *testing.T) *Translator {
+	t.Helper()
+	tr, err := Load(fstest.MapFS{
+		"en.yaml": {Data: []byte("errors:\n  not_found: \"Order not found\"\n  too_few: \"At least %d items\"\n  english_only: \"Only in English\"\n")},
+		"es.yaml": {Data: []byte("errors:\n  not_found: \"Pedido no encontrado\"\n  too_few: \"Al menos %d artículos\"\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+func TestT(t *testing.T) {
+	tr := testTranslator(t)
+	tests := []struct {
+		locale, key string
+		args        []interface{}
+		want        string
+	}{
+		{"es", "errors.not_found", nil, "Pedido no encontrado"},
+		{"en", "errors.not_found", nil, "Order not found"},
+		{"es-MX", "errors.not_found", nil, "Pedido no encontrado"},
+		{"es", "errors.too_few", []interface{}{3}, "Al menos 3 artículos"},
+		{"es", "errors.english_only", nil, "Only in English"},
+		{"fr", "errors.not_found", nil, "Order not found"},
+		{"es", "name is required", nil, "name is required"},
+	}
+	for _, tt := range tests {
+		if got := tr.T(tt.locale, tt.key, tt.args...); got != tt.want {
+			t.Errorf("T(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tr := testTranslator(t)
+	tests := []struct {
+		header, want string
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"ES", "es"},
+		{"es-MX,es;q=0.9", "es"},
+		{"es_AR", "es"},
+		{"fr-FR, es;q=0.8, en;q=0.5", "es"},
+		{"en;q=0.4, es;q=0.9", "es"},
+		{"es;q=0, en", "en"},
+		{"de, fr", "en"},
+		{"*", "en"},
+	}
+	for _, tt := range tests {
+		if got := tr.Match(tt.header); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLoadNeedsDefaultLocale(t *testing.T) {
+	_, err := Load(fstest.MapFS{"es.yaml": {Data: []byte("errors:\n  x: \"y\"\n")}})
+	if err == nil {
+		t.Error("Load without en.yaml succeeded, want an error")
+	}
+}
+
+var verb = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// The shipped catalogues must translate the same keys with the same
+// formatting verbs, or a Spanish message would print %!d(MISSING)
+func TestCataloguesMatch(t *testing.T) {
+	en, es := Default.messages["en"], Default.messages["es"]
+	if len(en) == 0 {
+		t.Fatal("English catalogue is empty")
+	}
+	for key, message := range en {
+		translated, ok := es[key]
+		if !ok {
+			t.Errorf("%s has no Spanish translation", key)
+			continue
+		}
+		if a, b := verb.FindAllString(message, -1), verb.FindAllString(translated, -1); !sameStrings(a, b) {
+			t.Errorf("%s: English verbs %v, Spanish %v", key, a, b)
+		}
+	}
+	for key := range es {
+		if _, ok := en[key]; !ok {
+			t.Errorf("%s is only in the Spanish catalogue", key)
+		}
+	}
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
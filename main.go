@@ -5,12 +5,32 @@ import (
 	"net/http"
 	"os"
 
+	"food-delivery-api/cache"
 	"food-delivery-api/config"
+	"food-delivery-api/currency"
+	_ "food-delivery-api/docs" // generated by `make swagger`
+	"food-delivery-api/eventbus"
+	"food-delivery-api/features"
+	"food-delivery-api/geo"
+	"food-delivery-api/handlers"
+	"food-delivery-api/health"
+	"food-delivery-api/middleware"
+	"food-delivery-api/notify"
 	"food-delivery-api/routes"
+	"food-delivery-api/statemachine"
+	"food-delivery-api/sysconfig"
 
 	"github.com/gin-gonic/gin"
 )
 
+// @title                      Food Delivery Order Management API
+// @version                    1.0.0
+// @description                Order lifecycle API for customers, restaurants, drivers and admins.
+// @BasePath                   /api
+// @securityDefinitions.apikey BearerAuth
+// @in                         header
+// @name                       Authorization
+// @description                JWT as "Bearer <token>"
 func main() {
 	// Set Gin mode
 	mode := os.Getenv("GIN_MODE")
@@ -18,17 +38,70 @@ func main() {
 		gin.SetMode(gin.DebugMode)
 	}
 
+	// Resolve the bcrypt cost now so a bad BCRYPT_COST is reported at startup
+	config.BCryptCost()
+
 	// Initialize database
 	config.InitDB()
+	if config.CurrencyRatesFile != "" {
+		rates, err := currency.LoadFixed(config.CurrencyRatesFile)
+		if err != nil {
+			log.Fatal("Invalid CURRENCY_RATES_FILE: ", err)
+		}
+		currency.Default = rates
+	}
+	responseCache, err := cache.New(config.CacheBackend)
+	if err != nil {
+		log.Fatal("Invalid CACHE_BACKEND: ", err)
+	}
+	cache.Default = responseCache
+	if config.GeocoderURL != "" {
+		geo.Default = geo.Nominatim{BaseURL: config.GeocoderURL}
+	}
+	sysconfig.StartRefresher()
+	features.StartRefresher()
+	statemachine.StartLabelRefresher()
+	handlers.StartAutoCancelWorker()
+	handlers.StartRecurringOrderWorker()
+	handlers.StartOperatingHoursScheduler()
+	handlers.StartDriverIdleWorker()
+	handlers.StartFeaturedExpiryWorker()
+	handlers.StartDataRetentionWorker()
+	handlers.StartDocumentExpiryWorker()
+	handlers.StartSeasonalMenuWorker()
+	handlers.StartSLAAlertWorker()
+
+	// Side effects of order and menu events; subscribers run in order, before the publishing request returns
+	eventbus.Default.Subscribe(eventbus.OrderPlaced, handlers.PushOrderEvent)
+	eventbus.Default.Subscribe(eventbus.OrderStatusChanged, handlers.PushOrderEvent)
+	eventbus.Default.Subscribe(eventbus.OrderStatusChanged, handlers.InvalidateDashboardOnTerminal)
+	eventbus.Default.Subscribe(eventbus.OrderDelivered, handlers.AwardLoyaltyOnDelivery)
+	eventbus.Default.Subscribe(eventbus.OrderDelivered, handlers.AwardReferralOnDelivery)
+	eventbus.Default.Subscribe(eventbus.OrderPlaced, handlers.DispatchOrderWebhooks)
+	eventbus.Default.Subscribe(eventbus.OrderStatusChanged, handlers.DispatchOrderWebhooks)
+	eventbus.Default.Subscribe(eventbus.ItemEightySixed, handlers.DispatchItemWebhooks)
+	eventbus.Default.Subscribe(eventbus.AlertDriverOutOfZone, handlers.NotifyAdminsDriverOutOfZone)
+	eventbus.Default.Subscribe(eventbus.OrderStatusChanged, handlers.AutoAssignOnReady)
+	eventbus.Default.Subscribe(eventbus.AlertNoDriverAvailable, handlers.NotifyNoDriverAvailable)
+	// Orders left waiting for a driver by the last run; after the subscribers, which assignments publish to
+	handlers.ResumeAutoAssign()
+	// Webhook deliveries the last run queued but never finished sending
+	handlers.ResumeWebhookDeliveries()
 
-	// Create Gin router with default middleware (logger + recovery)
-	r := gin.Default()
+	// Create Gin router: request IDs, the caller's locale, logging, and
+	// structured errors for panics and anything handlers report with c.Error
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.I18n(), middleware.QueryTrace(), gin.Logger(), middleware.ErrorHandler())
+	if err := r.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 
 	// CORS middleware for frontend integration
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
@@ -36,22 +109,46 @@ func main() {
 		c.Next()
 	})
 
-	// Health check endpoint
+	// During maintenance only admins get through; the rest see a 503
+	r.Use(middleware.MaintenanceMode("/api/status", "/api/auth/login", "/api/auth/totp", "/api/.well-known/jwks.json", "/api/docs/*any"))
+
+	// Health checks: /health and /readyz check every dependency, /livez only the process
+	health.Register("db", health.DBChecker{DB: config.DB})
+	health.Register("notifier", health.NotifierChecker{Notifier: notify.Default})
+	health.Register("geocoder", health.GeocoderChecker{Geocoder: geo.Default})
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
+		checks, healthy := health.Run(c.Request.Context())
+		status, code := "healthy", http.StatusOK
+		if !healthy {
+			status, code = "unhealthy", http.StatusServiceUnavailable
+		}
+		c.JSON(code, gin.H{
+			"status":  status,
 			"service": "Food Delivery Order Management API",
 			"version": "1.0.0",
+			"checks":  checks,
 		})
 	})
+	r.GET("/livez", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "alive"})
+	})
+	r.GET("/readyz", func(c *gin.Context) {
+		checks, healthy := health.Run(c.Request.Context())
+		code := http.StatusOK
+		if !healthy {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, checks)
+	})
 
 	// Welcome
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
-			"message": "🍔 Welcome to the Food Delivery Order Management API",
-			"docs":    "/api/state-machine",
-			"health":  "/health",
-			"roles":   []string{"customer", "restaurant", "driver", "admin"},
+			"message":              "🍔 Welcome to the Food Delivery Order Management API",
+			"docs":                 "/api/docs/index.html",
+			"health":               "/health",
+			"roles":                []string{"customer", "restaurant", "driver", "admin"},
+			"featured_restaurants": handlers.FeaturedPreview(config.DB.WithContext(c.Request.Context())),
 		})
 	})
 
@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"food-delivery-api/apierror"
 	"food-delivery-api/config"
 	"food-delivery-api/models"
 
@@ -19,19 +22,71 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// TokenLifetime is how long an issued JWT stays valid
+const TokenLifetime = 24 * time.Hour
+
 // GenerateToken creates a signed JWT for a given user
 func GenerateToken(user *models.User) (string, error) {
+	now := time.Now()
 	claims := Claims{
 		UserID: user.ID,
 		Email:  user.Email,
 		Role:   user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenLifetime)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(config.JWTSecret)
+	var signed string
+	var err error
+	if config.JWTPrivateKey != nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = config.JWTKeyID
+		signed, err = token.SignedString(config.JWTPrivateKey)
+	} else {
+		signed, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(config.JWTSecret)
+	}
+	if err != nil {
+		return "", err
+	}
+	// Only used to size a force-logout; auth never reads it
+	if err := config.DB.Create(&models.TokenIssue{UserID: user.ID, IssuedAt: now}).Error; err != nil {
+		return "", err
+	}
+	return signed, nil
+}
+
+// signingKey picks the key to verify a token with from its alg header. Once
+// RS256 is configured, HS256 tokens are only accepted until
+// JWT_HS256_ACCEPT_UNTIL.
+func signingKey(t *jwt.Token) (interface{}, error) {
+	switch t.Method {
+	case jwt.SigningMethodRS256:
+		if config.JWTPublicKey == nil {
+			return nil, errors.New("RS256 is not configured")
+		}
+		return config.JWTPublicKey, nil
+	case jwt.SigningMethodHS256:
+		if !config.AcceptHS256(time.Now()) {
+			return nil, errors.New("HS256 tokens are no longer accepted")
+		}
+		return config.JWTSecret, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+}
+
+// parseToken verifies a bearer token and returns its claims
+func parseToken(tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, signingKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
 }
 
 // AuthRequired validates the JWT and injects claims into context
@@ -39,18 +94,24 @@ func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required (Bearer <token>)"})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.authorization_header_required", nil)
 			return
 		}
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
-			return config.JWTSecret, nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+		claims, err := parseToken(tokenStr)
+		if err != nil {
+			apierror.Abort(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.invalid_or_expired_token", nil)
+			return
+		}
+		// Tokens die with their account (e.g. after an admin merge)
+		var user models.User
+		if err := config.DB.WithContext(c.Request.Context()).Select("id", "tokens_valid_from").First(&user, claims.UserID).Error; err != nil {
+			apierror.Abort(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.account_no_longer_exists", nil)
+			return
+		}
+		// ...and with an admin force-logout
+		if user.TokensValidFrom != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*user.TokensValidFrom)) {
+			apierror.Abort(c, http.StatusUnauthorized, apierror.ErrUnauthorized, "errors.session_has_been_invalidated", nil)
 			return
 		}
 		c.Set("userID", claims.UserID)
@@ -65,8 +126,7 @@ func RoleRequired(roles ...models.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		roleVal, exists := c.Get("role")
 		if !exists {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Role not found in context"})
-			c.Abort()
+			apierror.Abort(c, http.StatusForbidden, apierror.ErrForbidden, "errors.role_not_found_in_context", nil)
 			return
 		}
 		callerRole := models.UserRole(roleVal.(string))
@@ -76,10 +136,7 @@ func RoleRequired(roles ...models.UserRole) gin.HandlerFunc {
 				return
 			}
 		}
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Access denied. Required role(s): " + rolesString(roles),
-		})
-		c.Abort()
+		apierror.Abort(c, http.StatusForbidden, apierror.ErrForbidden, "errors.access_denied_role", nil, rolesString(roles))
 	}
 }
 
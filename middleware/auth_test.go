@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useRS256 configures a fresh RSA key pair, with HS256 accepted until the
+// given time, and restores the previous keys when the test ends
+func useRS256(t *testing.T, hs256Until time.Time) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub, kid, until := config.JWTPrivateKey, config.JWTPublicKey, config.JWTKeyID, config.JWTHS256AcceptUntil
+	t.Cleanup(func() {
+		config.JWTPrivateKey, config.JWTPublicKey, config.JWTKeyID, config.JWTHS256AcceptUntil = priv, pub, kid, until
+	})
+	config.JWTPrivateKey, config.JWTPublicKey, config.JWTKeyID, config.JWTHS256AcceptUntil = key, &key.PublicKey, "test", hs256Until
+}
+
+func hs256Token(t *testing.T, role models.UserRole) string {
+	t.Helper()
+	now := time.Now()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: 1,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenLifetime)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}).SignedString(config.JWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestParseTokenHS256AfterSwitchingToRS256(t *testing.T) {
+	tests := []struct {
+		name       string
+		hs256Until time.Time
+		wantValid  bool
+	}{
+		{"no transition window", time.Time{}, false},
+		{"inside transition window", time.Now().Add(time.Hour), true},
+		{"after transition window", time.Now().Add(-time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := hs256Token(t, models.RoleAdmin)
+			useRS256(t, tt.hs256Until)
+			claims, err := parseToken(token)
+			if valid := err == nil; valid != tt.wantValid {
+				t.Fatalf("parseToken error = %v, want valid %v", err, tt.wantValid)
+			}
+			if tt.wantValid && claims.Role != models.RoleAdmin {
+				t.Errorf("role = %q, want admin", claims.Role)
+			}
+		})
+	}
+}
+
+func TestParseTokenAcceptsHS256WithoutRS256(t *testing.T) {
+	if config.JWTPublicKey != nil {
+		t.Skip("RS256 keys are configured")
+	}
+	if _, err := parseToken(hs256Token(t, models.RoleCustomer)); err != nil {
+		t.Errorf("parseToken = %v, want HS256 accepted", err)
+	}
+}
+
+func TestParseTokenAcceptsRS256(t *testing.T) {
+	useRS256(t, time.Time{})
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, Claims{UserID: 1, Role: models.RoleDriver, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}).SignedString(config.JWTPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := parseToken(token)
+	if err != nil || claims.Role != models.RoleDriver {
+		t.Errorf("parseToken = %+v, %v; want a driver token", claims, err)
+	}
+}
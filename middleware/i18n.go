@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"food-delivery-api/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// I18n picks the locale error messages are translated into from the
+// Accept-Language header and stores it in the context under i18n.LocaleKey,
+// echoing it back in Content-Language
+func I18n() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Default.Match(c.GetHeader("Accept-Language"))
+		c.Set(i18n.LocaleKey, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"food-delivery-api/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPAllowlist only lets through clients whose IP falls in one of the given
+// CIDR blocks. An empty list allows everyone (dev mode) with a warning at
+// startup. A malformed block stops the server, so a typo can't quietly
+// open the routes it guards.
+func IPAllowlist(cidrs []string) gin.HandlerFunc {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Fatalf("IP allowlist: invalid CIDR %q: %v", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	if len(networks) == 0 {
+		log.Println("⚠️  No IP allowlist configured — admin routes are reachable from any IP")
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.ClientIP())
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				c.Next()
+				return
+			}
+		}
+		apierror.Abort(c, http.StatusForbidden, apierror.ErrForbidden, "errors.ip_not_allowed", nil)
+	}
+}
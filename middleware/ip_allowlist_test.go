@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIPAllowlist(t *testing.T) {
+	tests := []struct {
+		name         string
+		cidrs        []string
+		remoteAddr   string
+		forwardedFor string
+		wantStatus   int
+	}{
+		{"loopback allowed", []string{"127.0.0.1/8"}, "127.0.0.1:40000", "", http.StatusOK},
+		{"other loopback address allowed", []string{"127.0.0.1/8"}, "127.5.6.7:40000", "", http.StatusOK},
+		{"second block allowed", []string{"127.0.0.1/8", " 10.0.0.0/8"}, "10.1.2.3:40000", "", http.StatusOK},
+		{"foreign IP blocked", []string{"127.0.0.1/8", "10.0.0.0/8"}, "203.0.113.9:40000", "", http.StatusForbidden},
+		{"spoofed X-Forwarded-For blocked", []string{"127.0.0.1/8"}, "203.0.113.9:40000", "127.0.0.1", http.StatusForbidden},
+		{"empty list allows everyone", nil, "203.0.113.9:40000", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := r.SetTrustedProxies(nil); err != nil {
+				t.Fatal(err)
+			}
+			r.Use(IPAllowlist(tt.cidrs))
+			r.GET("/admin/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/ping", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
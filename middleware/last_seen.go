@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lastSeenResolution limits last_seen_at writes to one per driver per interval
+const lastSeenResolution = 30 * time.Second
+
+// DriverLastSeen stamps last_seen_at on the driver's profile for every
+// authenticated request a driver makes. Runs after AuthRequired.
+func DriverLastSeen() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetRole(c) == models.RoleDriver {
+			now := time.Now()
+			db := config.DB.WithContext(c.Request.Context())
+			res := db.Model(&models.DriverProfile{}).
+				Where("user_id = ? AND (last_seen_at IS NULL OR last_seen_at < ?)", GetUserID(c), now.Add(-lastSeenResolution)).
+				UpdateColumn("last_seen_at", now)
+			if res.Error == nil && res.RowsAffected == 0 {
+				// No profile yet; create one so the driver is tracked from the first call
+				profile := models.DriverProfile{UserID: GetUserID(c), MaxConcurrentOrders: models.DefaultMaxConcurrentOrders, IsOnline: true}
+				db.Where("user_id = ?", profile.UserID).Attrs(models.DriverProfile{LastSeenAt: &now}).FirstOrCreate(&profile)
+			}
+		}
+		c.Next()
+	}
+}
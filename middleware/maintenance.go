@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/models"
+	"food-delivery-api/sysconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMaintenanceRetry is how long clients are told to wait when no end
+// time was set
+const defaultMaintenanceRetry = 30 * time.Minute
+
+// MaintenanceEnd is when maintenance is expected to end: MAINTENANCE_END if
+// it is still ahead, otherwise 30 minutes from now
+func MaintenanceEnd(now time.Time) time.Time {
+	if end, err := time.Parse(time.RFC3339, sysconfig.Get(sysconfig.KeyMaintenanceEnd)); err == nil && end.After(now) {
+		return end
+	}
+	return now.Add(defaultMaintenanceRetry)
+}
+
+// MaintenanceMode answers every /api request with a 503 while MAINTENANCE_MODE
+// is on, with Retry-After and X-Maintenance-End headers. Admins get through:
+// admin routes are never blocked, and neither is any request carrying an admin
+// token. The exempt routes, given as full paths (e.g. "/api/auth/login"), stay
+// open so admins can log in and clients can check the status.
+func MaintenanceMode(exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		skip[path] = true
+	}
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !sysconfig.Bool(sysconfig.KeyMaintenanceMode) ||
+			!strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/api/admin/") ||
+			skip[c.FullPath()] || bearerRole(c) == models.RoleAdmin {
+			c.Next()
+			return
+		}
+		now := time.Now()
+		end := MaintenanceEnd(now)
+		retryAfter := int(end.Sub(now).Seconds())
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.Header("X-Maintenance-End", end.UTC().Format(time.RFC3339))
+		apierror.Abort(c, http.StatusServiceUnavailable, apierror.ErrUnavailable, "errors.service_under_maintenance", gin.H{
+			"retry_after": retryAfter,
+			"message":     sysconfig.Get(sysconfig.KeyMaintenanceMessage),
+		})
+	}
+}
+
+// bearerRole is the role in the request's token, or "" without a valid one.
+// Unlike AuthRequired it doesn't look the account up, so it stays usable while
+// the database is being migrated.
+func bearerRole(c *gin.Context) models.UserRole {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return ""
+	}
+	claims, err := parseToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		return ""
+	}
+	return claims.Role
+}
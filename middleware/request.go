@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID tags every request with an ID (honouring an incoming X-Request-ID)
+// so error responses and logs can be correlated
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Set(apierror.RequestIDKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// QueryTrace lets config.LazyLoadChecker watch the request's queries for N+1
+// patterns; handlers must query through the request context (requestDB)
+func QueryTrace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := config.WithQueryTrace(c.Request.Context(), c.GetString(apierror.RequestIDKey), c.HandlerName())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// ErrorHandler writes the response for errors handlers report with c.Error
+// and turns panics into a structured 500 instead of a dropped connection.
+// An *apierror.Error is rendered as is; sentinels listed in
+// apierror.KnownErrors get their mapped status and code; anything else is a
+// 500. A string set with SetMeta overrides the message. Handlers that already
+// wrote a response are left alone.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic recovered (request %s): %v\n%s", c.GetString(apierror.RequestIDKey), r, debug.Stack())
+				if apiErr, ok := r.(*apierror.Error); ok {
+					apierror.RespondError(c, apiErr)
+					c.Abort()
+					return
+				}
+				apierror.Abort(c, http.StatusInternalServerError, apierror.ErrInternal, "errors.internal_server_error", nil)
+			}
+		}()
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+		var apiErr *apierror.Error
+		if errors.As(last.Err, &apiErr) {
+			apierror.RespondError(c, apiErr)
+			return
+		}
+		message, _ := last.Meta.(string)
+		if known, ok := apierror.Lookup(last.Err); ok {
+			if message == "" {
+				message = known.Message
+			}
+			apierror.Respond(c, known.Status, known.Code, message, nil)
+			return
+		}
+		log.Printf("request %s failed: %v", c.GetString(apierror.RequestIDKey), last.Err)
+		if message == "" {
+			message = "Internal server error"
+		}
+		apierror.Respond(c, http.StatusInternalServerError, apierror.ErrInternal, message, nil)
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"food-delivery-api/apierror"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// serveWithErrorHandler runs handler behind ErrorHandler and decodes the
+// error response
+func serveWithErrorHandler(t *testing.T, handler gin.HandlerFunc) (int, apierror.ErrorResponse) {
+	t.Helper()
+	r := gin.New()
+	r.Use(RequestID(), ErrorHandler())
+	r.GET("/", handler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var body apierror.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", w.Body.String(), err)
+	}
+	return w.Code, body
+}
+
+func TestErrorHandlerMapsKnownErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		meta        string
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{"record not found", gorm.ErrRecordNotFound, "", http.StatusNotFound, apierror.ErrNotFound, "Resource not found"},
+		{"record not found with message", gorm.ErrRecordNotFound, "errors.order_not_found", http.StatusNotFound, apierror.ErrNotFound, "Order not found"},
+		{"wrapped duplicate key", fmt.Errorf("create user: %w", gorm.ErrDuplicatedKey), "", http.StatusConflict, apierror.ErrConflict, "Resource already exists"},
+		{"deadline exceeded", context.DeadlineExceeded, "", http.StatusServiceUnavailable, apierror.ErrTimeout, "Request timed out"},
+		{"api error", apierror.New(http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "errors.order_not_found", nil), "", http.StatusUnprocessableEntity, apierror.ErrUnprocessable, "Order not found"},
+		{"unknown error", errors.New("disk on fire"), "", http.StatusInternalServerError, apierror.ErrInternal, "Internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := serveWithErrorHandler(t, func(c *gin.Context) {
+				e := c.Error(tt.err)
+				if tt.meta != "" {
+					e.SetMeta(tt.meta)
+				}
+				c.Abort()
+			})
+			if status != tt.wantStatus || body.Code != tt.wantCode || body.Message != tt.wantMessage {
+				t.Errorf("got %d %s %q, want %d %s %q", status, body.Code, body.Message, tt.wantStatus, tt.wantCode, tt.wantMessage)
+			}
+			if body.RequestID == "" {
+				t.Error("response has no request_id")
+			}
+		})
+	}
+}
+
+func TestErrorHandlerRecoversPanics(t *testing.T) {
+	status, body := serveWithErrorHandler(t, func(c *gin.Context) {
+		panic("nil map")
+	})
+	if status != http.StatusInternalServerError || body.Code != apierror.ErrInternal {
+		t.Errorf("plain panic: got %d %s, want 500 %s", status, body.Code, apierror.ErrInternal)
+	}
+
+	status, body = serveWithErrorHandler(t, func(c *gin.Context) {
+		panic(apierror.New(http.StatusConflict, apierror.ErrConflict, "errors.resource_already_exists", nil))
+	})
+	if status != http.StatusConflict || body.Code != apierror.ErrConflict {
+		t.Errorf("apierror panic: got %d %s, want 409 %s", status, body.Code, apierror.ErrConflict)
+	}
+}
+
+func TestErrorHandlerLeavesWrittenResponsesAlone(t *testing.T) {
+	status, body := serveWithErrorHandler(t, func(c *gin.Context) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ErrValidation, "name is required", nil)
+		c.Error(gorm.ErrRecordNotFound)
+	})
+	if status != http.StatusBadRequest || body.Message != "name is required" {
+		t.Errorf("got %d %q, want the handler's own 400", status, body.Message)
+	}
+}
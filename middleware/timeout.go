@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"food-delivery-api/apierror"
+	"food-delivery-api/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeoutWriter buffers the handler's response so nothing reaches the client
+// until we know whether the handler beat the deadline
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status != 0
+}
+
+// Flush is a no-op: the response is only sent once the handler finishes
+func (w *timeoutWriter) Flush() {}
+
+// Timeout gives each request a deadline of d. Handlers see it through
+// c.Request.Context(), so database queries started with DB.WithContext are
+// cancelled when it passes. If the handler hasn't finished by then the client
+// gets a 503 and whatever the handler writes afterwards is discarded.
+// Server-sent event streams (routes ending in /stream) are long-lived by
+// design and are left alone, as are the exempt routes, given as full paths
+// (e.g. "/api/admin/export/menus"), which stream their response as they go.
+func Timeout(d time.Duration, exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		skip[path] = true
+	}
+	return func(c *gin.Context) {
+		if strings.HasSuffix(c.FullPath(), "/stream") || skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		w := c.Writer
+		tw := &timeoutWriter{ResponseWriter: w, header: w.Header().Clone()}
+		c.Writer = tw
+
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					panicked <- r
+				}
+			}()
+			c.Next()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			c.Writer = w
+			copyHeader(w.Header(), tw.header)
+			if tw.status != 0 {
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+			}
+		case r := <-panicked:
+			// Let ErrorHandler render the 500 as usual
+			c.Writer = w
+			panic(r)
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+			writeTimeout(c, w, d)
+			// The handler still holds c, so wait for it to notice the
+			// cancelled context before gin reuses the context
+			select {
+			case <-done:
+			case r := <-panicked:
+				log.Printf("panic after timeout (request %s): %v", c.GetString(apierror.RequestIDKey), r)
+			}
+			c.Writer = w
+		}
+	}
+}
+
+func copyHeader(dst, src http.Header) {
+	for k := range dst {
+		if _, ok := src[k]; !ok {
+			dst.Del(k)
+		}
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// writeTimeout sends the 503 straight to the client with a Content-Length, so
+// the response is complete even while the handler is still winding down
+func writeTimeout(c *gin.Context, w gin.ResponseWriter, d time.Duration) {
+	log.Printf("request %s timed out after %s: %s %s", c.GetString(apierror.RequestIDKey), d, c.Request.Method, c.Request.URL.Path)
+	body, _ := json.Marshal(apierror.ErrorResponse{
+		Code:      apierror.ErrUnavailable,
+		Message:   i18n.T(c, "errors.request_timed_out"),
+		RequestID: c.GetString(apierror.RequestIDKey),
+	})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write(body)
+	w.Flush()
+}
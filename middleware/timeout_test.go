@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"food-delivery-api/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func timeoutRouter(d time.Duration, route string, handler gin.HandlerFunc) *gin.Engine {
+	r := gin.New()
+	r.Use(Timeout(d))
+	r.GET(route, handler)
+	return r
+}
+
+func TestTimeoutAbortsSlowHandler(t *testing.T) {
+	handlerErr := make(chan error, 1)
+	r := timeoutRouter(20*time.Millisecond, "/slow", func(c *gin.Context) {
+		select {
+		case <-time.After(2 * time.Second):
+			handlerErr <- nil
+		case <-c.Request.Context().Done():
+			handlerErr <- c.Request.Context().Err()
+		}
+		c.JSON(http.StatusOK, gin.H{"late": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", w.Code)
+	}
+	var body apierror.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", w.Body.String(), err)
+	}
+	if body.Code != apierror.ErrUnavailable {
+		t.Errorf("code = %q, want %q", body.Code, apierror.ErrUnavailable)
+	}
+	if err := <-handlerErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("handler's context error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestTimeoutPassesFastResponseThrough(t *testing.T) {
+	r := timeoutRouter(time.Second, "/fast", func(c *gin.Context) {
+		c.Header("X-Handler", "fast")
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", w.Code)
+	}
+	if got := w.Header().Get("X-Handler"); got != "fast" {
+		t.Errorf("X-Handler = %q, want the handler's header", got)
+	}
+	if w.Body.String() != `{"ok":true}` {
+		t.Errorf("body = %q, want the handler's body", w.Body.String())
+	}
+}
+
+func TestTimeoutLeavesStreamsAlone(t *testing.T) {
+	r := timeoutRouter(10*time.Millisecond, "/orders/stream", func(c *gin.Context) {
+		time.Sleep(50 * time.Millisecond)
+		if err := c.Request.Context().Err(); err != nil {
+			t.Errorf("stream context error = %v, want none", err)
+		}
+		c.String(http.StatusOK, "data")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders/stream", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "data" {
+		t.Errorf("got %d %q, want the stream's 200 response", w.Code, w.Body.String())
+	}
+}
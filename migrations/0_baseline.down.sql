@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS `loyalty_accounts`;
+DROP TABLE IF EXISTS `restaurant_staffs`;
+DROP TABLE IF EXISTS `invites`;
+DROP TABLE IF EXISTS `system_configs`;
+DROP TABLE IF EXISTS `maintenance_logs`;
+DROP TABLE IF EXISTS `restaurant_waitlists`;
+DROP TABLE IF EXISTS `reviews`;
+DROP TABLE IF EXISTS `reassignment_requests`;
+DROP TABLE IF EXISTS `dietary_preferences`;
+DROP TABLE IF EXISTS `menu_item_allergens`;
+DROP TABLE IF EXISTS `allergens`;
+DROP TABLE IF EXISTS `broadcast_logs`;
+DROP TABLE IF EXISTS `driver_profiles`;
+DROP TABLE IF EXISTS `delivery_subscriptions`;
+DROP TABLE IF EXISTS `order_status_histories`;
+DROP TABLE IF EXISTS `order_items`;
+DROP TABLE IF EXISTS `orders`;
+DROP TABLE IF EXISTS `menu_items`;
+DROP TABLE IF EXISTS `restaurants`;
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,244 @@
+-- Schema as of the switch from AutoMigrate to versioned migrations.
+-- Databases created by AutoMigrate already match this and are marked as applied.
+
+CREATE TABLE `users` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `email` text NOT NULL,
+    `password_hash` text NOT NULL,
+    `role` text NOT NULL DEFAULT "customer",
+    `phone` text,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+
+CREATE TABLE `restaurants` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `owner_id` integer NOT NULL,
+    `name` text NOT NULL,
+    `cuisine` text,
+    `address` text,
+    `description` text,
+    `is_open` numeric DEFAULT true,
+    `rating` real DEFAULT 0,
+    `review_count` integer DEFAULT 0,
+    `max_orders_per_minute` integer DEFAULT 10,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_restaurants_owner` FOREIGN KEY (`owner_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE `menu_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `restaurant_id` integer NOT NULL,
+    `name` text NOT NULL,
+    `description` text,
+    `price` real NOT NULL,
+    `category` text,
+    `is_available` numeric DEFAULT true,
+    `is_veg` numeric DEFAULT false,
+    `track_stock` numeric DEFAULT false,
+    `stock_quantity` integer DEFAULT 0,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_restaurants_menu_items` FOREIGN KEY (`restaurant_id`) REFERENCES `restaurants`(`id`)
+);
+
+CREATE TABLE `orders` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `customer_id` integer NOT NULL,
+    `restaurant_id` integer NOT NULL,
+    `driver_id` integer,
+    `previous_driver_id` integer,
+    `status` text NOT NULL DEFAULT "PLACED",
+    `total_price` real,
+    `delivery_fee` real,
+    `service_fee` real,
+    `loyalty_points_earned` integer DEFAULT 0,
+    `auto_cancelled` numeric DEFAULT false,
+    `auto_cancel_reason` text,
+    `auto_cancel_warned_at` datetime,
+    `subscription_applied` numeric,
+    `delivery_address` text NOT NULL,
+    `notes` text,
+    `estimated_time` integer,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_orders_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_orders_customer` FOREIGN KEY (`customer_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_orders_restaurant` FOREIGN KEY (`restaurant_id`) REFERENCES `restaurants`(`id`)
+);
+CREATE INDEX `idx_orders_auto_cancelled` ON `orders`(`auto_cancelled`);
+
+CREATE TABLE `order_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `order_id` integer NOT NULL,
+    `menu_item_id` integer NOT NULL,
+    `quantity` integer NOT NULL,
+    `price` real NOT NULL,
+    `name` text,
+    CONSTRAINT `fk_order_items_menu_item` FOREIGN KEY (`menu_item_id`) REFERENCES `menu_items`(`id`),
+    CONSTRAINT `fk_orders_items` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`)
+);
+
+CREATE TABLE `order_status_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `order_id` integer NOT NULL,
+    `from_status` text,
+    `to_status` text NOT NULL,
+    `changed_by` integer,
+    `note` text,
+    `created_at` datetime,
+    CONSTRAINT `fk_orders_status_history` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`)
+);
+
+CREATE TABLE `delivery_subscriptions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `customer_id` integer NOT NULL,
+    `plan` text NOT NULL DEFAULT "monthly",
+    `price` real,
+    `started_at` datetime,
+    `expires_at` datetime,
+    `is_active` numeric DEFAULT true,
+    `payment_reference` text,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_delivery_subscriptions_customer` FOREIGN KEY (`customer_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX `idx_delivery_subscriptions_customer_id` ON `delivery_subscriptions`(`customer_id`);
+
+CREATE TABLE `driver_profiles` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `vehicle_type` text,
+    `max_concurrent_orders` integer NOT NULL DEFAULT 1,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_driver_profiles_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE UNIQUE INDEX `idx_driver_profiles_user_id` ON `driver_profiles`(`user_id`);
+
+CREATE TABLE `broadcast_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `admin_id` integer NOT NULL,
+    `target_role` text NOT NULL,
+    `channel` text,
+    `title` text NOT NULL,
+    `body` text,
+    `recipient_count` integer,
+    `success_count` integer,
+    `errors` integer,
+    `sent_at` datetime,
+    `created_at` datetime
+);
+
+CREATE TABLE `allergens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL
+);
+CREATE UNIQUE INDEX `idx_allergens_name` ON `allergens`(`name`);
+
+CREATE TABLE `menu_item_allergens` (
+    `menu_item_id` integer,
+    `allergen_id` integer,
+    PRIMARY KEY (`menu_item_id`,`allergen_id`)
+);
+
+CREATE TABLE `dietary_preferences` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `customer_id` integer NOT NULL,
+    `allergens` text
+);
+CREATE UNIQUE INDEX `idx_dietary_preferences_customer_id` ON `dietary_preferences`(`customer_id`);
+
+CREATE TABLE `reassignment_requests` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `order_id` integer NOT NULL,
+    `customer_id` integer NOT NULL,
+    `driver_id` integer NOT NULL,
+    `reason` text,
+    `status` text NOT NULL DEFAULT "PENDING",
+    `reviewed_by` integer,
+    `reviewed_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_reassignment_requests_order` FOREIGN KEY (`order_id`) REFERENCES `orders`(`id`)
+);
+CREATE INDEX `idx_reassignment_requests_order_id` ON `reassignment_requests`(`order_id`);
+
+CREATE TABLE `reviews` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `order_id` integer NOT NULL,
+    `customer_id` integer NOT NULL,
+    `restaurant_id` integer NOT NULL,
+    `driver_id` integer,
+    `restaurant_rating` integer NOT NULL,
+    `driver_rating` integer,
+    `comment` text,
+    `created_at` datetime
+);
+CREATE INDEX `idx_reviews_driver_id` ON `reviews`(`driver_id`);
+CREATE INDEX `idx_reviews_restaurant_id` ON `reviews`(`restaurant_id`);
+CREATE UNIQUE INDEX `idx_reviews_order_id` ON `reviews`(`order_id`);
+
+CREATE TABLE `restaurant_waitlists` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `customer_id` integer NOT NULL,
+    `restaurant_id` integer NOT NULL,
+    `notified_at` datetime,
+    `created_at` datetime,
+    CONSTRAINT `fk_restaurant_waitlists_restaurant` FOREIGN KEY (`restaurant_id`) REFERENCES `restaurants`(`id`)
+);
+CREATE INDEX `idx_restaurant_waitlists_restaurant_id` ON `restaurant_waitlists`(`restaurant_id`);
+CREATE INDEX `idx_restaurant_waitlists_customer_id` ON `restaurant_waitlists`(`customer_id`);
+
+CREATE TABLE `maintenance_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `action` text NOT NULL,
+    `performed_by` integer,
+    `details` text,
+    `created_at` datetime
+);
+CREATE INDEX `idx_maintenance_logs_action` ON `maintenance_logs`(`action`);
+
+CREATE TABLE `system_configs` (
+    `key` text,
+    `value` text NOT NULL,
+    `updated_by` integer,
+    `updated_at` datetime,
+    PRIMARY KEY (`key`)
+);
+
+CREATE TABLE `invites` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `restaurant_id` integer NOT NULL,
+    `invited_email` text NOT NULL,
+    `token_hash` text NOT NULL,
+    `role` text NOT NULL DEFAULT "staff",
+    `expires_at` datetime,
+    `accepted_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_invites_token_hash` ON `invites`(`token_hash`);
+CREATE INDEX `idx_invites_invited_email` ON `invites`(`invited_email`);
+CREATE INDEX `idx_invites_restaurant_id` ON `invites`(`restaurant_id`);
+
+CREATE TABLE `restaurant_staffs` (
+    `restaurant_id` integer,
+    `user_id` integer,
+    `created_at` datetime,
+    PRIMARY KEY (`restaurant_id`,`user_id`)
+);
+CREATE UNIQUE INDEX `idx_restaurant_staffs_user_id` ON `restaurant_staffs`(`user_id`);
+
+CREATE TABLE `loyalty_accounts` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `customer_id` integer NOT NULL,
+    `points` integer DEFAULT 0,
+    `lifetime_points` integer DEFAULT 0,
+    `tier` text NOT NULL DEFAULT "bronze",
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_loyalty_accounts_customer_id` ON `loyalty_accounts`(`customer_id`);
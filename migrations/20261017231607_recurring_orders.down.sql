@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `recurring_order_logs`;
+DROP TABLE IF EXISTS `recurring_orders`;
@@ -0,0 +1,28 @@
+CREATE TABLE `recurring_orders` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `customer_id` integer NOT NULL,
+    `restaurant_id` integer NOT NULL,
+    `day_of_week` integer,
+    `time_of_day` text,
+    `items` text,
+    `delivery_address` text NOT NULL,
+    `notes` text,
+    `is_active` numeric DEFAULT true,
+    `next_run_at` datetime,
+    `last_run_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_recurring_orders_restaurant` FOREIGN KEY (`restaurant_id`) REFERENCES `restaurants`(`id`)
+);
+CREATE INDEX `idx_recurring_orders_next_run_at` ON `recurring_orders`(`next_run_at`);
+CREATE INDEX `idx_recurring_orders_is_active` ON `recurring_orders`(`is_active`);
+CREATE INDEX `idx_recurring_orders_customer_id` ON `recurring_orders`(`customer_id`);
+CREATE TABLE `recurring_order_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `recurring_order_id` integer NOT NULL,
+    `order_id` integer,
+    `success` numeric,
+    `error` text,
+    `created_at` datetime
+);
+CREATE INDEX `idx_recurring_order_logs_recurring_order_id` ON `recurring_order_logs`(`recurring_order_id`);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `restaurant_status_logs`;
+DROP TABLE IF EXISTS `operating_hours`;
+ALTER TABLE `restaurants` DROP COLUMN `manual_override_until`;
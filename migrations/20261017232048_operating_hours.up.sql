@@ -0,0 +1,20 @@
+ALTER TABLE `restaurants` ADD `manual_override_until` datetime;
+CREATE TABLE `operating_hours` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `restaurant_id` integer NOT NULL,
+    `day_of_week` integer NOT NULL,
+    `opens_at` text NOT NULL,
+    `closes_at` text NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_operating_hours_day` ON `operating_hours`(`restaurant_id`,`day_of_week`);
+CREATE TABLE `restaurant_status_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `restaurant_id` integer NOT NULL,
+    `is_open` numeric,
+    `changed_by` integer,
+    `reason` text,
+    `created_at` datetime
+);
+CREATE INDEX `idx_restaurant_status_logs_restaurant_id` ON `restaurant_status_logs`(`restaurant_id`);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `referrals`;
+DROP INDEX IF EXISTS `idx_users_referred_by_id`;
+DROP INDEX IF EXISTS `idx_users_referral_code`;
+ALTER TABLE `users` DROP COLUMN `referred_by_id`;
+ALTER TABLE `users` DROP COLUMN `referral_code`;
@@ -0,0 +1,20 @@
+ALTER TABLE `users` ADD `referral_code` text;
+ALTER TABLE `users` ADD `referred_by_id` integer;
+CREATE UNIQUE INDEX `idx_users_referral_code` ON `users`(`referral_code`);
+CREATE INDEX `idx_users_referred_by_id` ON `users`(`referred_by_id`);
+CREATE TABLE `referrals` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `referrer_id` integer NOT NULL,
+    `referee_id` integer NOT NULL,
+    `order_id` integer,
+    `referrer_points` integer,
+    `referee_points` integer,
+    `rewarded_at` datetime,
+    `created_at` datetime,
+    CONSTRAINT `fk_referrals_referee` FOREIGN KEY (`referee_id`) REFERENCES `users`(`id`)
+);
+CREATE UNIQUE INDEX `idx_referrals_referee_id` ON `referrals`(`referee_id`);
+CREATE INDEX `idx_referrals_referrer_id` ON `referrals`(`referrer_id`);
+
+-- Existing customers get a code now, new ones get one at registration
+UPDATE `users` SET `referral_code` = upper(hex(randomblob(4))) WHERE `role` = 'customer' AND `referral_code` IS NULL;
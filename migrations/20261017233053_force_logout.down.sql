@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `token_issues`;
+ALTER TABLE `users` DROP COLUMN `tokens_valid_from`;
@@ -0,0 +1,7 @@
+ALTER TABLE `users` ADD `tokens_valid_from` datetime;
+CREATE TABLE `token_issues` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `issued_at` datetime NOT NULL
+);
+CREATE INDEX `idx_token_issues_user_id` ON `token_issues`(`user_id`);
@@ -0,0 +1,5 @@
+ALTER TABLE `orders` DROP COLUMN `cod_remitted_at`;
+ALTER TABLE `orders` DROP COLUMN `cod_variance`;
+ALTER TABLE `orders` DROP COLUMN `cod_amount_collected`;
+ALTER TABLE `orders` DROP COLUMN `cod_collected`;
+ALTER TABLE `orders` DROP COLUMN `payment_method`;
@@ -0,0 +1,5 @@
+ALTER TABLE `orders` ADD `payment_method` text NOT NULL DEFAULT "prepaid";
+ALTER TABLE `orders` ADD `cod_collected` numeric DEFAULT false;
+ALTER TABLE `orders` ADD `cod_amount_collected` real;
+ALTER TABLE `orders` ADD `cod_variance` real;
+ALTER TABLE `orders` ADD `cod_remitted_at` datetime;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `menu_item_eighty_sixes`;
+ALTER TABLE `menu_items` DROP COLUMN `eighty_six_reason`;
+ALTER TABLE `menu_items` DROP COLUMN `eighty_sixed_at`;
@@ -0,0 +1,13 @@
+ALTER TABLE `menu_items` ADD `eighty_sixed_at` datetime;
+ALTER TABLE `menu_items` ADD `eighty_six_reason` text;
+CREATE TABLE `menu_item_eighty_sixes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `menu_item_id` integer NOT NULL,
+    `restaurant_id` integer NOT NULL,
+    `reason` text,
+    `eighty_sixed_at` datetime NOT NULL,
+    `restored_at` datetime
+);
+CREATE INDEX `idx_menu_item_eighty_sixes_eighty_sixed_at` ON `menu_item_eighty_sixes`(`eighty_sixed_at`);
+CREATE INDEX `idx_menu_item_eighty_sixes_restaurant_id` ON `menu_item_eighty_sixes`(`restaurant_id`);
+CREATE INDEX `idx_menu_item_eighty_sixes_menu_item_id` ON `menu_item_eighty_sixes`(`menu_item_id`);
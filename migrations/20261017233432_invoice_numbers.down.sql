@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `invoice_sequences`;
+DROP INDEX IF EXISTS `idx_orders_invoice_number`;
+ALTER TABLE `orders` DROP COLUMN `invoice_number`;
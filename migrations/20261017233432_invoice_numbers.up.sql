@@ -0,0 +1,16 @@
+ALTER TABLE `orders` ADD `invoice_number` text;
+
+-- Number existing orders in id order within each calendar year
+UPDATE `orders` SET `invoice_number` = 'INV-' || substr(`created_at`, 1, 4) || '-' || printf('%05d', (
+    SELECT COUNT(*) FROM `orders` AS `o2`
+    WHERE substr(`o2`.`created_at`, 1, 4) = substr(`orders`.`created_at`, 1, 4) AND `o2`.`id` <= `orders`.`id`
+));
+
+CREATE UNIQUE INDEX `idx_orders_invoice_number` ON `orders`(`invoice_number`);
+CREATE TABLE `invoice_sequences` (
+    `year` integer,
+    `last_seq` integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (`year`)
+);
+INSERT INTO `invoice_sequences` (`year`, `last_seq`)
+SELECT CAST(substr(`created_at`, 1, 4) AS integer), COUNT(*) FROM `orders` GROUP BY substr(`created_at`, 1, 4);
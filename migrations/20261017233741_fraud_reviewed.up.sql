@@ -0,0 +1 @@
+ALTER TABLE `orders` ADD `fraud_reviewed` numeric DEFAULT false;
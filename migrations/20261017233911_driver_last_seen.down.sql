@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS `idx_driver_profiles_is_online`;
+ALTER TABLE `driver_profiles` DROP COLUMN `last_seen_at`;
+ALTER TABLE `driver_profiles` DROP COLUMN `is_online`;
@@ -0,0 +1,3 @@
+ALTER TABLE `driver_profiles` ADD `is_online` numeric NOT NULL DEFAULT true;
+ALTER TABLE `driver_profiles` ADD `last_seen_at` datetime;
+CREATE INDEX `idx_driver_profiles_is_online` ON `driver_profiles`(`is_online`);
@@ -0,0 +1,2 @@
+ALTER TABLE `order_items` DROP COLUMN `was_delivered`;
+ALTER TABLE `orders` DROP COLUMN `partial_delivery`;
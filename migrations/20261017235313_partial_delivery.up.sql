@@ -0,0 +1,2 @@
+ALTER TABLE `orders` ADD `partial_delivery` numeric DEFAULT false;
+ALTER TABLE `order_items` ADD `was_delivered` numeric NOT NULL DEFAULT true;
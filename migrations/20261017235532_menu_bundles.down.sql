@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `menu_bundle_items`;
+DROP TABLE IF EXISTS `menu_bundles`;
+ALTER TABLE `order_items` DROP COLUMN `bundle_id`;
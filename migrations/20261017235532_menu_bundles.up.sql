@@ -0,0 +1,21 @@
+ALTER TABLE `order_items` ADD `bundle_id` integer;
+CREATE TABLE `menu_bundles` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `restaurant_id` integer NOT NULL,
+    `name` text NOT NULL,
+    `description` text,
+    `bundle_price` real NOT NULL,
+    `is_available` numeric DEFAULT true,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX `idx_menu_bundles_restaurant_id` ON `menu_bundles`(`restaurant_id`);
+CREATE TABLE `menu_bundle_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `bundle_id` integer NOT NULL,
+    `menu_item_id` integer NOT NULL,
+    `quantity` integer NOT NULL DEFAULT 1,
+    CONSTRAINT `fk_menu_bundle_items_menu_item` FOREIGN KEY (`menu_item_id`) REFERENCES `menu_items`(`id`),
+    CONSTRAINT `fk_menu_bundles_items` FOREIGN KEY (`bundle_id`) REFERENCES `menu_bundles`(`id`)
+);
+CREATE INDEX `idx_menu_bundle_items_bundle_id` ON `menu_bundle_items`(`bundle_id`);
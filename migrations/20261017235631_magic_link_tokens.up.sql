@@ -0,0 +1,10 @@
+CREATE TABLE `magic_link_tokens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `token_hash` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_magic_link_tokens_token_hash` ON `magic_link_tokens`(`token_hash`);
+CREATE INDEX `idx_magic_link_tokens_user_id` ON `magic_link_tokens`(`user_id`);
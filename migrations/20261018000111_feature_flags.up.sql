@@ -0,0 +1,10 @@
+CREATE TABLE `feature_flags` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `enabled` numeric NOT NULL DEFAULT false,
+    `description` text,
+    `updated_by` integer,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_feature_flags_name` ON `feature_flags`(`name`);
@@ -0,0 +1,11 @@
+CREATE TABLE `referral_links` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `code` text NOT NULL,
+    `user_id` integer NOT NULL,
+    `click_count` integer NOT NULL DEFAULT 0,
+    `conversion_count` integer NOT NULL DEFAULT 0,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX `idx_referral_links_user_id` ON `referral_links`(`user_id`);
+CREATE UNIQUE INDEX `idx_referral_links_code` ON `referral_links`(`code`);
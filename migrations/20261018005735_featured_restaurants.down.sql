@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS `idx_restaurants_is_featured`;
+ALTER TABLE `restaurants` DROP COLUMN `featured_until`;
+ALTER TABLE `restaurants` DROP COLUMN `is_featured`;
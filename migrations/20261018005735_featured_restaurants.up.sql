@@ -0,0 +1,3 @@
+ALTER TABLE `restaurants` ADD `is_featured` numeric DEFAULT false;
+ALTER TABLE `restaurants` ADD `featured_until` datetime;
+CREATE INDEX `idx_restaurants_is_featured` ON `restaurants`(`is_featured`);
@@ -0,0 +1,13 @@
+ALTER TABLE `users` ADD `totp_secret` text;
+ALTER TABLE `users` ADD `totp_enabled` numeric DEFAULT false;
+CREATE TABLE `mfa_challenges` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `token_hash` text NOT NULL,
+    `attempts` integer NOT NULL DEFAULT 0,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_mfa_challenges_token_hash` ON `mfa_challenges`(`token_hash`);
+CREATE INDEX `idx_mfa_challenges_user_id` ON `mfa_challenges`(`user_id`);
@@ -0,0 +1,15 @@
+CREATE TABLE `driver_documents` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `driver_id` integer NOT NULL,
+    `document_type` text NOT NULL,
+    `url` text NOT NULL,
+    `status` text NOT NULL DEFAULT "pending",
+    `admin_note` text,
+    `reviewed_by` integer,
+    `reviewed_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_driver_documents_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX `idx_driver_documents_status` ON `driver_documents`(`status`);
+CREATE INDEX `idx_driver_documents_driver_id` ON `driver_documents`(`driver_id`);
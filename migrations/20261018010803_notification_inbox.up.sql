@@ -0,0 +1,15 @@
+CREATE TABLE `notifications` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `title` text NOT NULL,
+    `body` text,
+    `event_type` text,
+    `reference_id` integer,
+    `reference_type` text,
+    `is_read` numeric NOT NULL DEFAULT false,
+    `created_at` datetime
+);
+CREATE INDEX `idx_notifications_created_at` ON `notifications`(`created_at`);
+CREATE INDEX `idx_notifications_is_read` ON `notifications`(`is_read`);
+CREATE INDEX `idx_notifications_event_type` ON `notifications`(`event_type`);
+CREATE INDEX `idx_notifications_user_id` ON `notifications`(`user_id`);
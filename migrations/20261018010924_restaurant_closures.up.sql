@@ -0,0 +1,12 @@
+CREATE TABLE `restaurant_closures` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `restaurant_id` integer NOT NULL,
+    `starts_at` text NOT NULL,
+    `ends_at` text NOT NULL,
+    `reason` text,
+    `created_by` integer,
+    `created_at` datetime
+);
+CREATE INDEX `idx_restaurant_closures_ends_at` ON `restaurant_closures`(`ends_at`);
+CREATE INDEX `idx_restaurant_closures_starts_at` ON `restaurant_closures`(`starts_at`);
+CREATE INDEX `idx_restaurant_closures_restaurant_id` ON `restaurant_closures`(`restaurant_id`);
@@ -0,0 +1 @@
+ALTER TABLE `orders` DROP COLUMN `eta_recalculated_at`;
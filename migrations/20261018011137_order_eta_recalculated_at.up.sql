@@ -0,0 +1 @@
+ALTER TABLE `orders` ADD `eta_recalculated_at` datetime;
@@ -0,0 +1,4 @@
+ALTER TABLE `orders` DROP COLUMN `total_price_base`;
+ALTER TABLE `orders` DROP COLUMN `exchange_rate`;
+ALTER TABLE `orders` DROP COLUMN `currency`;
+ALTER TABLE `restaurants` DROP COLUMN `currency`;
@@ -0,0 +1,6 @@
+ALTER TABLE `restaurants` ADD `currency` text NOT NULL DEFAULT "USD";
+ALTER TABLE `orders` ADD `currency` text NOT NULL DEFAULT "USD";
+ALTER TABLE `orders` ADD `exchange_rate` real NOT NULL DEFAULT 1;
+ALTER TABLE `orders` ADD `total_price_base` real;
+-- Orders placed before this migration were all in the base currency
+UPDATE `orders` SET `total_price_base` = `total_price`;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_restaurants_is_active`;
+ALTER TABLE `restaurants` DROP COLUMN `is_active`;
@@ -0,0 +1,2 @@
+ALTER TABLE `restaurants` ADD `is_active` numeric NOT NULL DEFAULT true;
+CREATE INDEX `idx_restaurants_is_active` ON `restaurants`(`is_active`);
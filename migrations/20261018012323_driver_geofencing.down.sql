@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `geofence_violations`;
+DROP TABLE IF EXISTS `driver_locations`;
+DROP TABLE IF EXISTS `delivery_zones`;
+ALTER TABLE `driver_profiles` DROP COLUMN `zone_id`;
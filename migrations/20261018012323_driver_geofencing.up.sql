@@ -0,0 +1,30 @@
+ALTER TABLE `driver_profiles` ADD `zone_id` integer;
+CREATE TABLE `delivery_zones` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `polygon` text NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_delivery_zones_name` ON `delivery_zones`(`name`);
+CREATE TABLE `driver_locations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `driver_id` integer NOT NULL,
+    `order_id` integer NOT NULL,
+    `lat` real,
+    `lng` real,
+    `created_at` datetime
+);
+CREATE INDEX `idx_driver_locations_order_id` ON `driver_locations`(`order_id`);
+CREATE INDEX `idx_driver_locations_driver_id` ON `driver_locations`(`driver_id`);
+CREATE TABLE `geofence_violations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `driver_id` integer NOT NULL,
+    `order_id` integer NOT NULL,
+    `zone_id` integer NOT NULL,
+    `lat` real,
+    `lng` real,
+    `created_at` datetime
+);
+CREATE INDEX `idx_geofence_violations_order_id` ON `geofence_violations`(`order_id`);
+CREATE INDEX `idx_geofence_violations_driver_id` ON `geofence_violations`(`driver_id`);
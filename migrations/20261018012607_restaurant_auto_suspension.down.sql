@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `suspension_events`;
+ALTER TABLE `restaurants` DROP COLUMN `consecutive_auto_cancels`;
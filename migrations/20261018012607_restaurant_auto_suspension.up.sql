@@ -0,0 +1,12 @@
+ALTER TABLE `restaurants` ADD `consecutive_auto_cancels` integer NOT NULL DEFAULT 0;
+CREATE TABLE `suspension_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `restaurant_id` integer NOT NULL,
+    `reason` text,
+    `auto_cancel_count` integer,
+    `suspended_at` datetime,
+    `reinstated_at` datetime,
+    `reinstated_by` integer,
+    CONSTRAINT `fk_suspension_events_restaurant` FOREIGN KEY (`restaurant_id`) REFERENCES `restaurants`(`id`)
+);
+CREATE INDEX `idx_suspension_events_restaurant_id` ON `suspension_events`(`restaurant_id`);
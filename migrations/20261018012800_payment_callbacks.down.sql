@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `payment_webhook_logs`;
+ALTER TABLE `orders` DROP COLUMN `payment_reference`;
+ALTER TABLE `orders` DROP COLUMN `payment_status`;
@@ -0,0 +1,15 @@
+ALTER TABLE `orders` ADD `payment_status` text NOT NULL DEFAULT "pending";
+ALTER TABLE `orders` ADD `payment_reference` text;
+CREATE TABLE `payment_webhook_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `payload` text NOT NULL,
+    `signature` text,
+    `signature_valid` numeric,
+    `invoice_number` text,
+    `order_id` integer,
+    `processed_at` datetime,
+    `error` text,
+    `created_at` datetime
+);
+CREATE INDEX `idx_payment_webhook_logs_order_id` ON `payment_webhook_logs`(`order_id`);
+CREATE INDEX `idx_payment_webhook_logs_invoice_number` ON `payment_webhook_logs`(`invoice_number`);
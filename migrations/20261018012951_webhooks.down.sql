@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `webhook_delivery_logs`;
+DROP TABLE IF EXISTS `webhooks`;
@@ -0,0 +1,24 @@
+CREATE TABLE `webhooks` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `url` text NOT NULL,
+    `secret` text NOT NULL,
+    `events` text,
+    `is_active` numeric NOT NULL DEFAULT true,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE TABLE `webhook_delivery_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `webhook_id` integer NOT NULL,
+    `event` text,
+    `payload` text NOT NULL,
+    `status` text NOT NULL DEFAULT "pending",
+    `attempts` integer,
+    `replays` integer,
+    `last_error` text,
+    `delivered_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX `idx_webhook_delivery_logs_status` ON `webhook_delivery_logs`(`status`);
+CREATE INDEX `idx_webhook_delivery_logs_webhook_id` ON `webhook_delivery_logs`(`webhook_id`);
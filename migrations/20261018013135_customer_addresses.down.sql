@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `customer_addresses`;
+ALTER TABLE `orders` DROP COLUMN `delivery_lng`;
+ALTER TABLE `orders` DROP COLUMN `delivery_lat`;
@@ -0,0 +1,14 @@
+ALTER TABLE `orders` ADD `delivery_lat` real;
+ALTER TABLE `orders` ADD `delivery_lng` real;
+CREATE TABLE `customer_addresses` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `customer_id` integer NOT NULL,
+    `label` text,
+    `address` text NOT NULL,
+    `latitude` real,
+    `longitude` real,
+    `geocoded_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX `idx_customer_addresses_customer_id` ON `customer_addresses`(`customer_id`);
@@ -0,0 +1,10 @@
+CREATE TABLE `menu_item_price_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `menu_item_id` integer NOT NULL,
+    `old_price` real,
+    `new_price` real,
+    `changed_by` integer,
+    `changed_at` datetime
+);
+CREATE INDEX `idx_menu_item_price_histories_changed_at` ON `menu_item_price_histories`(`changed_at`);
+CREATE INDEX `idx_menu_item_price_histories_menu_item_id` ON `menu_item_price_histories`(`menu_item_id`);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS `idx_orders_cancellation_reason`;
+ALTER TABLE `orders` DROP COLUMN `cancellation_note`;
+ALTER TABLE `orders` DROP COLUMN `cancellation_reason`;
@@ -0,0 +1,3 @@
+ALTER TABLE `orders` ADD `cancellation_reason` text;
+ALTER TABLE `orders` ADD `cancellation_note` text;
+CREATE INDEX `idx_orders_cancellation_reason` ON `orders`(`cancellation_reason`);
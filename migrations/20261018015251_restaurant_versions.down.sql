@@ -0,0 +1,2 @@
+ALTER TABLE `menu_items` DROP COLUMN `version`;
+ALTER TABLE `restaurants` DROP COLUMN `version`;
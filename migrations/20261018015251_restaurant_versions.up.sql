@@ -0,0 +1,2 @@
+ALTER TABLE `restaurants` ADD `version` integer NOT NULL DEFAULT 1;
+ALTER TABLE `menu_items` ADD `version` integer NOT NULL DEFAULT 1;
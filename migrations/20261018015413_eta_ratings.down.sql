@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `eta_ratings`;
+ALTER TABLE `restaurants` DROP COLUMN `eta_rating_count`;
+ALTER TABLE `restaurants` DROP COLUMN `eta_accuracy_rate`;
@@ -0,0 +1,14 @@
+ALTER TABLE `restaurants` ADD `eta_accuracy_rate` real NOT NULL DEFAULT 1;
+ALTER TABLE `restaurants` ADD `eta_rating_count` integer NOT NULL DEFAULT 0;
+CREATE TABLE `eta_ratings` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `order_id` integer NOT NULL,
+    `customer_id` integer NOT NULL,
+    `restaurant_id` integer NOT NULL,
+    `estimated_minutes` integer NOT NULL,
+    `actual_minutes` integer NOT NULL,
+    `on_time` numeric,
+    `created_at` datetime
+);
+CREATE INDEX `idx_eta_ratings_restaurant_id` ON `eta_ratings`(`restaurant_id`);
+CREATE UNIQUE INDEX `idx_eta_ratings_order_id` ON `eta_ratings`(`order_id`);
@@ -0,0 +1,16 @@
+CREATE TABLE `driver_locations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `driver_id` integer NOT NULL,
+    `order_id` integer NOT NULL,
+    `lat` real,
+    `lng` real,
+    `created_at` datetime
+);
+CREATE INDEX `idx_driver_locations_order_id` ON `driver_locations`(`order_id`);
+CREATE INDEX `idx_driver_locations_driver_id` ON `driver_locations`(`driver_id`);
+INSERT INTO `driver_locations` (`driver_id`, `order_id`, `lat`, `lng`, `created_at`)
+SELECT r.`driver_id`, r.`order_id`, json_extract(p.value, '$.lat'), json_extract(p.value, '$.lng'), json_extract(p.value, '$.ts')
+FROM `delivery_routes` r, json_each(r.`coordinates`) p
+ORDER BY r.`order_id`, p.key;
+DROP TABLE IF EXISTS `delivery_routes`;
+ALTER TABLE `orders` DROP COLUMN `route_distance_km`;
@@ -0,0 +1,18 @@
+ALTER TABLE `orders` ADD `route_distance_km` real;
+CREATE TABLE `delivery_routes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `order_id` integer NOT NULL,
+    `driver_id` integer NOT NULL,
+    `coordinates` text NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX `idx_delivery_routes_driver_id` ON `delivery_routes`(`driver_id`);
+CREATE UNIQUE INDEX `idx_delivery_routes_order_id` ON `delivery_routes`(`order_id`);
+INSERT INTO `delivery_routes` (`order_id`, `driver_id`, `coordinates`, `created_at`, `updated_at`)
+SELECT `order_id`, MAX(`driver_id`),
+    json_group_array(json_object('lat', `lat`, 'lng', `lng`, 'ts', strftime('%Y-%m-%dT%H:%M:%fZ', `created_at`))),
+    MIN(`created_at`), MAX(`created_at`)
+FROM (SELECT * FROM `driver_locations` ORDER BY `order_id`, `id`)
+GROUP BY `order_id`;
+DROP TABLE `driver_locations`;
@@ -0,0 +1,9 @@
+CREATE TABLE `menu_item_pairings` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `item_a_id` integer NOT NULL,
+    `item_b_id` integer NOT NULL,
+    `pairing_note` text,
+    `created_at` datetime
+);
+CREATE INDEX `idx_menu_item_pairings_item_b_id` ON `menu_item_pairings`(`item_b_id`);
+CREATE UNIQUE INDEX `idx_menu_item_pairings_pair` ON `menu_item_pairings`(`item_a_id`,`item_b_id`);
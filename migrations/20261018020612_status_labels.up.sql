@@ -0,0 +1,10 @@
+CREATE TABLE `status_labels` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `status` text NOT NULL,
+    `locale` text NOT NULL DEFAULT "en",
+    `display_label` text NOT NULL,
+    `updated_by` integer,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_status_labels_status_locale` ON `status_labels`(`status`,`locale`);
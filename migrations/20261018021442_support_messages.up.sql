@@ -0,0 +1,11 @@
+CREATE TABLE `support_messages` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `order_id` integer NOT NULL,
+    `sender_id` integer NOT NULL,
+    `sender_role` text NOT NULL,
+    `message` text NOT NULL,
+    `is_read` numeric NOT NULL DEFAULT false,
+    `created_at` datetime
+);
+CREATE INDEX `idx_support_messages_is_read` ON `support_messages`(`is_read`);
+CREATE INDEX `idx_support_messages_order_id` ON `support_messages`(`order_id`);
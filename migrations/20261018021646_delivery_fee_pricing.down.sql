@@ -0,0 +1,6 @@
+ALTER TABLE `orders` DROP COLUMN `distance_km`;
+ALTER TABLE `restaurants` DROP COLUMN `free_delivery_above`;
+ALTER TABLE `restaurants` DROP COLUMN `price_per_km`;
+ALTER TABLE `restaurants` DROP COLUMN `base_delivery_fee`;
+ALTER TABLE `restaurants` DROP COLUMN `longitude`;
+ALTER TABLE `restaurants` DROP COLUMN `latitude`;
@@ -0,0 +1,6 @@
+ALTER TABLE `restaurants` ADD `latitude` real;
+ALTER TABLE `restaurants` ADD `longitude` real;
+ALTER TABLE `restaurants` ADD `base_delivery_fee` real NOT NULL DEFAULT 40;
+ALTER TABLE `restaurants` ADD `price_per_km` real NOT NULL DEFAULT 0;
+ALTER TABLE `restaurants` ADD `free_delivery_above` real NOT NULL DEFAULT 0;
+ALTER TABLE `orders` ADD `distance_km` real;
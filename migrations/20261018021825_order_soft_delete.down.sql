@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS `idx_order_items_deleted_at`;
+ALTER TABLE `order_items` DROP COLUMN `deleted_at`;
+DROP INDEX IF EXISTS `idx_orders_deleted_at`;
+ALTER TABLE `orders` DROP COLUMN `deleted_at`;
@@ -0,0 +1,4 @@
+ALTER TABLE `orders` ADD `deleted_at` datetime;
+CREATE INDEX `idx_orders_deleted_at` ON `orders`(`deleted_at`);
+ALTER TABLE `order_items` ADD `deleted_at` datetime;
+CREATE INDEX `idx_order_items_deleted_at` ON `order_items`(`deleted_at`);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS `idx_driver_documents_expires_at`;
+ALTER TABLE `driver_documents` DROP COLUMN `expired_alert_sent`;
+ALTER TABLE `driver_documents` DROP COLUMN `renews_id`;
+ALTER TABLE `driver_documents` DROP COLUMN `expires_at`;
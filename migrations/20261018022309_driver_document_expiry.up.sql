@@ -0,0 +1,4 @@
+ALTER TABLE `driver_documents` ADD `expires_at` datetime;
+ALTER TABLE `driver_documents` ADD `renews_id` integer;
+ALTER TABLE `driver_documents` ADD `expired_alert_sent` numeric NOT NULL DEFAULT false;
+CREATE INDEX `idx_driver_documents_expires_at` ON `driver_documents`(`expires_at`);
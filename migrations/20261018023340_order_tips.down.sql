@@ -0,0 +1,3 @@
+ALTER TABLE `orders` DROP COLUMN `platform_tip_income`;
+ALTER TABLE `orders` DROP COLUMN `driver_tip_amount`;
+ALTER TABLE `orders` DROP COLUMN `tip_amount`;
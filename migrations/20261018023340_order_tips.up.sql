@@ -0,0 +1,3 @@
+ALTER TABLE `orders` ADD `tip_amount` real NOT NULL DEFAULT 0;
+ALTER TABLE `orders` ADD `driver_tip_amount` real NOT NULL DEFAULT 0;
+ALTER TABLE `orders` ADD `platform_tip_income` real NOT NULL DEFAULT 0;
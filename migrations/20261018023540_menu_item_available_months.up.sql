@@ -0,0 +1 @@
+ALTER TABLE `menu_items` ADD `available_months` text;
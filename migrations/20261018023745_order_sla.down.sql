@@ -0,0 +1,3 @@
+ALTER TABLE `orders` DROP COLUMN `sla_met`;
+ALTER TABLE `orders` DROP COLUMN `delivered_at`;
+ALTER TABLE `restaurants` DROP COLUMN `sla_minutes`;
@@ -0,0 +1,3 @@
+ALTER TABLE `restaurants` ADD `sla_minutes` integer NOT NULL DEFAULT 60;
+ALTER TABLE `orders` ADD `delivered_at` datetime;
+ALTER TABLE `orders` ADD `sla_met` numeric NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_users_email_lower`;
+ALTER TABLE `users` DROP COLUMN `duplicate_email`;
@@ -0,0 +1,7 @@
+-- Emails are unique regardless of case. Accounts that already share an
+-- email are flagged, all but the oldest in each group, and left out of the
+-- index until an admin merges them (POST /api/admin/users/deduplicate).
+ALTER TABLE `users` ADD `duplicate_email` numeric NOT NULL DEFAULT false;
+UPDATE `users` SET `duplicate_email` = true
+    WHERE `id` NOT IN (SELECT MIN(`id`) FROM `users` GROUP BY LOWER(`email`));
+CREATE UNIQUE INDEX `idx_users_email_lower` ON `users` (LOWER(`email`)) WHERE `duplicate_email` = false;
@@ -0,0 +1,2 @@
+ALTER TABLE `orders` DROP COLUMN `auto_assigned_at`;
+ALTER TABLE `restaurants` DROP COLUMN `auto_assign_driver`;
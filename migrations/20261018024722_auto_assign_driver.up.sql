@@ -0,0 +1,2 @@
+ALTER TABLE `restaurants` ADD `auto_assign_driver` numeric NOT NULL DEFAULT false;
+ALTER TABLE `orders` ADD `auto_assigned_at` datetime;
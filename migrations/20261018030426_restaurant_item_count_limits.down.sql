@@ -0,0 +1,2 @@
+ALTER TABLE `restaurants` DROP COLUMN `max_item_count`;
+ALTER TABLE `restaurants` DROP COLUMN `min_item_count`;
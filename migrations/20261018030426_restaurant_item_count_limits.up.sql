@@ -0,0 +1,2 @@
+ALTER TABLE `restaurants` ADD `min_item_count` integer NOT NULL DEFAULT 1;
+ALTER TABLE `restaurants` ADD `max_item_count` integer NOT NULL DEFAULT 50;
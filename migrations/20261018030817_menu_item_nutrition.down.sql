@@ -0,0 +1,5 @@
+ALTER TABLE `dietary_preferences` DROP COLUMN `diets`;
+ALTER TABLE `menu_items` DROP COLUMN `fat_g`;
+ALTER TABLE `menu_items` DROP COLUMN `carbs_g`;
+ALTER TABLE `menu_items` DROP COLUMN `protein_g`;
+ALTER TABLE `menu_items` DROP COLUMN `calories`;
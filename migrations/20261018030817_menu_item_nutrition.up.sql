@@ -0,0 +1,5 @@
+ALTER TABLE `menu_items` ADD `calories` integer;
+ALTER TABLE `menu_items` ADD `protein_g` real;
+ALTER TABLE `menu_items` ADD `carbs_g` real;
+ALTER TABLE `menu_items` ADD `fat_g` real;
+ALTER TABLE `dietary_preferences` ADD `diets` text;
@@ -0,0 +1 @@
+-- The backfilled times are indistinguishable from real ones; nothing to undo
@@ -0,0 +1,7 @@
+-- Orders delivered before delivered_at existed take it from their history,
+-- or failing that from their last update
+UPDATE `orders` SET `delivered_at` = (
+    SELECT MAX(`created_at`) FROM `order_status_histories`
+    WHERE `order_status_histories`.`order_id` = `orders`.`id` AND `to_status` = 'DELIVERED'
+) WHERE `status` = 'DELIVERED' AND `delivered_at` IS NULL;
+UPDATE `orders` SET `delivered_at` = `updated_at` WHERE `status` = 'DELIVERED' AND `delivered_at` IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE `users` ADD `totp_last_step` integer NOT NULL DEFAULT 0;
@@ -0,0 +1,45 @@
+-- Text defaults added since the baseline were written with double quotes,
+-- which SQLite only reads as strings when no column has that name. SQLite
+-- can't change a default in place, so each column is copied into a new one
+-- with a single-quoted default that then takes its name.
+-- orders.payment_method
+ALTER TABLE `orders` ADD `payment_method_requoted` text NOT NULL DEFAULT 'prepaid';
+UPDATE `orders` SET `payment_method_requoted` = `payment_method`;
+ALTER TABLE `orders` DROP COLUMN `payment_method`;
+ALTER TABLE `orders` RENAME COLUMN `payment_method_requoted` TO `payment_method`;
+-- driver_documents.status
+DROP INDEX `idx_driver_documents_status`;
+ALTER TABLE `driver_documents` ADD `status_requoted` text NOT NULL DEFAULT 'pending';
+UPDATE `driver_documents` SET `status_requoted` = `status`;
+ALTER TABLE `driver_documents` DROP COLUMN `status`;
+ALTER TABLE `driver_documents` RENAME COLUMN `status_requoted` TO `status`;
+CREATE INDEX `idx_driver_documents_status` ON `driver_documents`(`status`);
+-- restaurants.currency
+ALTER TABLE `restaurants` ADD `currency_requoted` text NOT NULL DEFAULT 'USD';
+UPDATE `restaurants` SET `currency_requoted` = `currency`;
+ALTER TABLE `restaurants` DROP COLUMN `currency`;
+ALTER TABLE `restaurants` RENAME COLUMN `currency_requoted` TO `currency`;
+-- orders.currency
+ALTER TABLE `orders` ADD `currency_requoted` text NOT NULL DEFAULT 'USD';
+UPDATE `orders` SET `currency_requoted` = `currency`;
+ALTER TABLE `orders` DROP COLUMN `currency`;
+ALTER TABLE `orders` RENAME COLUMN `currency_requoted` TO `currency`;
+-- orders.payment_status
+ALTER TABLE `orders` ADD `payment_status_requoted` text NOT NULL DEFAULT 'pending';
+UPDATE `orders` SET `payment_status_requoted` = `payment_status`;
+ALTER TABLE `orders` DROP COLUMN `payment_status`;
+ALTER TABLE `orders` RENAME COLUMN `payment_status_requoted` TO `payment_status`;
+-- webhook_delivery_logs.status
+DROP INDEX `idx_webhook_delivery_logs_status`;
+ALTER TABLE `webhook_delivery_logs` ADD `status_requoted` text NOT NULL DEFAULT 'pending';
+UPDATE `webhook_delivery_logs` SET `status_requoted` = `status`;
+ALTER TABLE `webhook_delivery_logs` DROP COLUMN `status`;
+ALTER TABLE `webhook_delivery_logs` RENAME COLUMN `status_requoted` TO `status`;
+CREATE INDEX `idx_webhook_delivery_logs_status` ON `webhook_delivery_logs`(`status`);
+-- status_labels.locale
+DROP INDEX `idx_status_labels_status_locale`;
+ALTER TABLE `status_labels` ADD `locale_requoted` text NOT NULL DEFAULT 'en';
+UPDATE `status_labels` SET `locale_requoted` = `locale`;
+ALTER TABLE `status_labels` DROP COLUMN `locale`;
+ALTER TABLE `status_labels` RENAME COLUMN `locale_requoted` TO `locale`;
+CREATE UNIQUE INDEX `idx_status_labels_status_locale` ON `status_labels`(`status`,`locale`);
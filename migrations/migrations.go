@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned SQL schema migrations.
+//
+// Files are named <version>.up.sql / <version>.down.sql, where version is
+// YYYYMMDDHHMMSS_name (or 0_baseline for the initial schema). Every schema
+// change ships as a new up/down pair; applied files are never edited.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
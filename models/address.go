@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CustomerAddress is a saved delivery address. Latitude and Longitude are only
+// meaningful once GeocodedAt is set.
+type CustomerAddress struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	CustomerID uint       `json:"customer_id" gorm:"not null;index"`
+	Label      string     `json:"label"` // e.g. "Home"
+	Address    string     `json:"address" gorm:"not null"`
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	GeocodedAt *time.Time `json:"geocoded_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
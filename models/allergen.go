@@ -0,0 +1,31 @@
+package models
+
+// Allergen is one of the fixed allergens a menu item can be tagged with
+type Allergen struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"uniqueIndex;not null"`
+}
+
+// AllergenNames is the canonical allergen list, seeded on startup
+var AllergenNames = []string{"nuts", "dairy", "gluten", "shellfish", "eggs", "soy", "sesame"}
+
+// MenuItemAllergen links a menu item to an allergen it contains
+type MenuItemAllergen struct {
+	MenuItemID uint `json:"menu_item_id" gorm:"primaryKey"`
+	AllergenID uint `json:"allergen_id" gorm:"primaryKey"`
+}
+
+// Diets a customer can follow; orders of calorie-conscious customers come with
+// a nutritional summary
+const DietCalorieConscious = "calorie_conscious"
+
+// DietNames is every diet a customer can set
+var DietNames = []string{DietCalorieConscious}
+
+// DietaryPreference stores a customer's saved allergies so orders can be checked automatically
+type DietaryPreference struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	CustomerID uint   `json:"customer_id" gorm:"uniqueIndex;not null"`
+	Allergens  string `json:"-"` // comma-separated allergen names
+	Diets      string `json:"-"` // comma-separated diet names
+}
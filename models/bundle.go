@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// MenuBundle is a meal deal: a fixed set of menu items sold at one discounted price
+type MenuBundle struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	RestaurantID uint             `json:"restaurant_id" gorm:"not null;index"`
+	Name         string           `json:"name" gorm:"not null"`
+	Description  string           `json:"description"`
+	BundlePrice  float64          `json:"bundle_price" gorm:"not null"`
+	IsAvailable  bool             `json:"is_available" gorm:"default:true"` // also needs every member item available
+	Items        []MenuBundleItem `json:"items" gorm:"foreignKey:BundleID"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// MenuBundleItem is one member of a bundle
+type MenuBundleItem struct {
+	ID         uint     `json:"id" gorm:"primaryKey"`
+	BundleID   uint     `json:"bundle_id" gorm:"not null;index"`
+	MenuItemID uint     `json:"menu_item_id" gorm:"not null"`
+	MenuItem   MenuItem `json:"menu_item,omitempty" gorm:"foreignKey:MenuItemID"`
+	Quantity   int      `json:"quantity" gorm:"not null;default:1"`
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+
+	"food-delivery-api/geo"
+)
+
+// Vehicle types a driver can register with
+const (
+	VehicleBicycle = "bicycle"
+	VehicleScooter = "scooter"
+	VehicleCar     = "car"
+)
+
+// Concurrent delivery caps — cars can carry more orders at once
+const (
+	DefaultMaxConcurrentOrders = 1
+	CarMaxConcurrentOrders     = 3
+)
+
+// DriverProfile holds driver-specific settings that don't belong on User
+type DriverProfile struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`
+	UserID              uint       `json:"user_id" gorm:"uniqueIndex;not null"`
+	User                User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	VehicleType         string     `json:"vehicle_type"`
+	MaxConcurrentOrders int        `json:"max_concurrent_orders" gorm:"not null;default:1"`
+	IsOnline            bool       `json:"is_online" gorm:"not null;default:true;index"` // taking new orders; cleared after DRIVER_IDLE_OFFLINE_MINUTES without activity
+	LastSeenAt          *time.Time `json:"last_seen_at"`                                 // last authenticated API call
+	ZoneID              *uint      `json:"zone_id"`                                      // DeliveryZone the driver should stay in while delivering
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// SetVehicleType records the vehicle and raises the default cap for cars.
+// An explicitly configured cap (anything other than the default) is left alone.
+func (p *DriverProfile) SetVehicleType(vehicleType string) {
+	p.VehicleType = vehicleType
+	if vehicleType == VehicleCar && p.MaxConcurrentOrders <= DefaultMaxConcurrentOrders {
+		p.MaxConcurrentOrders = CarMaxConcurrentOrders
+	}
+}
+
+// Driver document types. License and insurance must be approved before a
+// driver can go online.
+const (
+	DocumentLicense   = "license"
+	DocumentInsurance = "insurance"
+	DocumentIdentity  = "identity"
+)
+
+// RequiredDriverDocuments lists the document types a driver needs approved to go online
+var RequiredDriverDocuments = []string{DocumentLicense, DocumentInsurance}
+
+// Driver document review states. An approved document becomes expired once
+// its expires_at passes.
+const (
+	DocumentPending  = "pending"
+	DocumentApproved = "approved"
+	DocumentRejected = "rejected"
+	DocumentExpired  = "expired"
+)
+
+// DriverDocument is an onboarding document a driver submitted by URL. Each new
+// submission of a type supersedes the earlier ones, except a renewal, which
+// only supersedes the document it renews once it is approved.
+type DriverDocument struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	DriverID     uint       `json:"driver_id" gorm:"not null;index"`
+	Driver       User       `json:"driver,omitempty" gorm:"foreignKey:DriverID"`
+	DocumentType string     `json:"document_type" gorm:"not null"`
+	URL          string     `json:"url" gorm:"not null"`
+	Status       string     `json:"status" gorm:"not null;default:'pending';index"`
+	AdminNote    string     `json:"admin_note"`
+	ReviewedBy   *uint      `json:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	ExpiresAt    *time.Time `json:"expires_at" gorm:"index"`
+	RenewsID     *uint      `json:"renews_id"` // the document this one renews
+	// ExpiredAlertSent is set once the driver and admins were warned of the expiry
+	ExpiredAlertSent bool      `json:"expired_alert_sent" gorm:"not null;default:false"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// DeliveryZone is an area drivers are assigned to, given by its boundary
+type DeliveryZone struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	Name      string      `json:"name" gorm:"not null;uniqueIndex"`
+	Polygon   []geo.Point `json:"polygon" gorm:"serializer:json;not null"` // vertices in order, at least 3
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// RoutePoint is one position report from a driver during a delivery
+type RoutePoint struct {
+	geo.Point
+	TS time.Time `json:"ts"`
+}
+
+// DeliveryRoute is the path a driver took with an order, as the position
+// reports arrived. Reports are only appended.
+type DeliveryRoute struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	OrderID     uint         `json:"order_id" gorm:"uniqueIndex;not null"`
+	DriverID    uint         `json:"driver_id" gorm:"not null;index"`
+	Coordinates []RoutePoint `json:"coordinates" gorm:"serializer:json;not null"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Path is the route as a polyline
+func (r DeliveryRoute) Path() []geo.Point {
+	path := make([]geo.Point, len(r.Coordinates))
+	for i, p := range r.Coordinates {
+		path[i] = p.Point
+	}
+	return path
+}
+
+// GeofenceViolation records a location report from outside the driver's zone
+// while they were carrying an order
+type GeofenceViolation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DriverID  uint      `json:"driver_id" gorm:"not null;index"`
+	OrderID   uint      `json:"order_id" gorm:"not null;index"`
+	ZoneID    uint      `json:"zone_id" gorm:"not null"`
+	Lat       float64   `json:"lat"`
+	Lng       float64   `json:"lng"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Feature flag names
+const (
+	FeatureLoyalty              = "loyalty"
+	FeatureSurgePricing         = "surge_pricing"
+	FeatureMagicLink            = "magic_link"
+	FeatureGroupOrdering        = "group_ordering"
+	FeatureDeliverySubscription = "delivery_subscription"
+	FeatureRecurringOrders      = "recurring_orders"
+)
+
+// FeatureFlag switches a feature on or off at runtime
+type FeatureFlag struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Enabled     bool      `json:"enabled" gorm:"not null;default:false"`
+	Description string    `json:"description"`
+	UpdatedBy   *uint     `json:"updated_by"` // nil until an admin changes it
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DefaultFeatureFlags are seeded on startup. Features that already exist start
+// enabled; ones not built yet start disabled.
+var DefaultFeatureFlags = []FeatureFlag{
+	{Name: FeatureLoyalty, Enabled: true, Description: "Loyalty points, tiers and tier perks"},
+	{Name: FeatureSurgePricing, Enabled: false, Description: "Demand-based delivery fee multiplier"},
+	{Name: FeatureMagicLink, Enabled: true, Description: "Password-less email login for customers"},
+	{Name: FeatureGroupOrdering, Enabled: false, Description: "Several customers adding to one order"},
+	{Name: FeatureDeliverySubscription, Enabled: true, Description: "Monthly free-delivery subscription"},
+	{Name: FeatureRecurringOrders, Enabled: true, Description: "Weekly scheduled repeat orders"},
+}
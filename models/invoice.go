@@ -0,0 +1,7 @@
+package models
+
+// InvoiceSequence hands out invoice numbers one year at a time
+type InvoiceSequence struct {
+	Year    int `json:"year" gorm:"primaryKey;autoIncrement:false"`
+	LastSeq int `json:"last_seq" gorm:"not null;default:0"`
+}
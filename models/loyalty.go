@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// LoyaltyTier is derived from a customer's lifetime points
+type LoyaltyTier string
+
+const (
+	TierBronze LoyaltyTier = "bronze"
+	TierSilver LoyaltyTier = "silver"
+	TierGold   LoyaltyTier = "gold"
+)
+
+// LoyaltyAccount holds a customer's points; Points can be spent, LifetimePoints only grows
+type LoyaltyAccount struct {
+	ID             uint        `json:"id" gorm:"primaryKey"`
+	CustomerID     uint        `json:"customer_id" gorm:"uniqueIndex;not null"`
+	Points         int         `json:"points" gorm:"default:0"`
+	LifetimePoints int         `json:"lifetime_points" gorm:"default:0"`
+	Tier           LoyaltyTier `json:"tier" gorm:"not null;default:'bronze'"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
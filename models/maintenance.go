@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// MaintenanceLog records admin and system actions that rewrite data outside the normal flows
+type MaintenanceLog struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Action      string    `json:"action" gorm:"not null;index"`
+	PerformedBy *uint     `json:"performed_by"` // nil when a background job did it
+	Details     string    `json:"details"`      // JSON-encoded summary of what changed
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// BroadcastLog records an admin broadcast and how its delivery went
+type BroadcastLog struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	AdminID        uint       `json:"admin_id" gorm:"not null"`
+	TargetRole     UserRole   `json:"target_role" gorm:"not null"`
+	Channel        string     `json:"channel"`
+	Title          string     `json:"title" gorm:"not null"`
+	Body           string     `json:"body"`
+	RecipientCount int        `json:"recipient_count"`
+	SuccessCount   int        `json:"success_count"`
+	Errors         int        `json:"errors"`
+	SentAt         *time.Time `json:"sent_at"` // set once every send has been attempted
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Notification is a message kept in a user's in-app inbox
+type Notification struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	Title         string    `json:"title" gorm:"not null"`
+	Body          string    `json:"body"`
+	EventType     string    `json:"event_type" gorm:"index"`
+	ReferenceID   uint      `json:"reference_id"`
+	ReferenceType string    `json:"reference_type"` // what reference_id points at, e.g. "order"
+	IsRead        bool      `json:"is_read" gorm:"not null;default:false;index"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// OperatingHours is a restaurant's opening window for one day of the week (server local time).
+// A window whose ClosesAt is earlier than OpensAt runs past midnight.
+type OperatingHours struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;uniqueIndex:idx_operating_hours_day"`
+	DayOfWeek    int       `json:"day_of_week" gorm:"not null;uniqueIndex:idx_operating_hours_day"` // 0 = Sunday
+	OpensAt      string    `json:"opens_at" gorm:"not null"`                                        // "HH:MM"
+	ClosesAt     string    `json:"closes_at" gorm:"not null"`                                       // "HH:MM"
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RestaurantStatusLog records every open/close of a restaurant; ChangedBy 0 is the scheduler
+type RestaurantStatusLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;index"`
+	IsOpen       bool      `json:"is_open"`
+	ChangedBy    uint      `json:"changed_by"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SuspensionEvent records a restaurant being deactivated automatically for
+// letting too many orders in a row auto-cancel. It is open until an admin
+// reinstates the restaurant.
+type SuspensionEvent struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	RestaurantID    uint       `json:"restaurant_id" gorm:"not null;index"`
+	Restaurant      Restaurant `json:"restaurant,omitempty" gorm:"foreignKey:RestaurantID"`
+	Reason          string     `json:"reason"`
+	AutoCancelCount int        `json:"auto_cancel_count"`
+	SuspendedAt     time.Time  `json:"suspended_at"`
+	ReinstatedAt    *time.Time `json:"reinstated_at"`
+	ReinstatedBy    *uint      `json:"reinstated_by,omitempty"`
+}
+
+// RestaurantClosure is a stretch of whole days (both ends inclusive, server
+// local dates) when a restaurant takes no orders, e.g. a holiday
+type RestaurantClosure struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;index"`
+	StartsAt     string    `json:"starts_at" gorm:"not null;index"` // "YYYY-MM-DD"
+	EndsAt       string    `json:"ends_at" gorm:"not null;index"`   // "YYYY-MM-DD"
+	Reason       string    `json:"reason"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -1,56 +1,142 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"food-delivery-api/geo"
+
+	"gorm.io/gorm"
+)
 
 // OrderStatus represents all possible states of a food delivery order
 type OrderStatus string
 
 const (
-	StatusPlaced          OrderStatus = "PLACED"
-	StatusConfirmed       OrderStatus = "CONFIRMED"
-	StatusPreparing       OrderStatus = "PREPARING"
-	StatusReadyForPickup  OrderStatus = "READY_FOR_PICKUP"
-	StatusPickedUp        OrderStatus = "PICKED_UP"
-	StatusDelivered       OrderStatus = "DELIVERED"
-	StatusCancelled       OrderStatus = "CANCELLED"
+	StatusPlaced         OrderStatus = "PLACED"
+	StatusConfirmed      OrderStatus = "CONFIRMED"
+	StatusPreparing      OrderStatus = "PREPARING"
+	StatusReadyForPickup OrderStatus = "READY_FOR_PICKUP"
+	StatusPickedUp       OrderStatus = "PICKED_UP"
+	StatusDelivered      OrderStatus = "DELIVERED"
+	StatusCancelled      OrderStatus = "CANCELLED"
+)
+
+// Payment methods an order can be placed with
+const (
+	PaymentPrepaid = "prepaid"
+	PaymentCOD     = "cod" // cash collected by the driver on delivery
+)
+
+// Reasons a customer can give for cancelling an order
+const (
+	CancelChangedMind  = "changed_mind"
+	CancelWaitTooLong  = "wait_too_long"
+	CancelWrongItems   = "wrong_items"
+	CancelWrongAddress = "wrong_address"
+	CancelOther        = "other"
+)
+
+// CancellationReasons lists every reason, in the order reports show them
+var CancellationReasons = []string{CancelChangedMind, CancelWaitTooLong, CancelWrongItems, CancelWrongAddress, CancelOther}
+
+// Payment statuses reported by the payment gateway's callbacks
+const (
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusFailed  = "failed"
 )
 
 type Order struct {
-	ID              uint         `json:"id" gorm:"primaryKey"`
-	CustomerID      uint         `json:"customer_id" gorm:"not null"`
-	Customer        User         `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
-	RestaurantID    uint         `json:"restaurant_id" gorm:"not null"`
-	Restaurant      Restaurant   `json:"restaurant,omitempty" gorm:"foreignKey:RestaurantID"`
-	DriverID        *uint        `json:"driver_id"`
-	Driver          *User        `json:"driver,omitempty" gorm:"foreignKey:DriverID"`
-	Status          OrderStatus  `json:"status" gorm:"not null;default:'PLACED'"`
-	TotalPrice      float64      `json:"total_price"`
-	DeliveryAddress string       `json:"delivery_address" gorm:"not null"`
-	Notes           string       `json:"notes"`
-	EstimatedTime   int          `json:"estimated_time_minutes"` // novelty: ETA in minutes
-	Items           []OrderItem  `json:"items,omitempty" gorm:"foreignKey:OrderID"`
-	StatusHistory   []OrderStatusHistory `json:"status_history,omitempty" gorm:"foreignKey:OrderID"`
-	CreatedAt       time.Time    `json:"created_at"`
-	UpdatedAt       time.Time    `json:"updated_at"`
+	ID                  uint                 `json:"id" gorm:"primaryKey"`
+	InvoiceNumber       string               `json:"invoice_number" gorm:"uniqueIndex"` // INV-<year>-<seq>, allocated at placement
+	CustomerID          uint                 `json:"customer_id" gorm:"not null"`
+	Customer            User                 `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	RestaurantID        uint                 `json:"restaurant_id" gorm:"not null"`
+	Restaurant          Restaurant           `json:"restaurant,omitempty" gorm:"foreignKey:RestaurantID"`
+	DriverID            *uint                `json:"driver_id"`
+	Driver              *User                `json:"driver,omitempty" gorm:"foreignKey:DriverID"`
+	PreviousDriverID    *uint                `json:"previous_driver_id"` // set when the order is reassigned
+	Status              OrderStatus          `json:"status" gorm:"not null;default:'PLACED'"`
+	TotalPrice          float64              `json:"total_price"`                             // in Currency
+	Currency            string               `json:"currency" gorm:"not null;default:'USD'"`  // the restaurant's, when the order was placed
+	ExchangeRate        float64              `json:"exchange_rate" gorm:"not null;default:1"` // base currency per unit of Currency, snapshot at placement
+	TotalPriceBase      float64              `json:"total_price_base"`                        // TotalPrice in the platform's BASE_CURRENCY
+	DeliveryFee         float64              `json:"delivery_fee"`
+	ServiceFee          float64              `json:"service_fee"`                                   // platform cut, a percentage of the subtotal
+	TipAmount           float64              `json:"tip_amount" gorm:"not null;default:0"`          // added by the customer at checkout
+	DriverTipAmount     float64              `json:"driver_tip_amount" gorm:"not null;default:0"`   // the driver's share of the tip, frozen on delivery
+	PlatformTipIncome   float64              `json:"platform_tip_income" gorm:"not null;default:0"` // the rest of the tip, per PLATFORM_TIP_SHARE_PCT
+	LoyaltyPointsEarned int                  `json:"loyalty_points_earned" gorm:"default:0"`
+	AutoCancelled       bool                 `json:"auto_cancelled" gorm:"default:false;index"` // cancelled by the worker; customer is owed a refund
+	AutoCancelReason    string               `json:"auto_cancel_reason,omitempty"`
+	AutoCancelWarnedAt  *time.Time           `json:"-"`
+	CancellationReason  string               `json:"cancellation_reason,omitempty" gorm:"index"` // given by the customer when cancelling
+	CancellationNote    string               `json:"cancellation_note,omitempty"`
+	SubscriptionApplied bool                 `json:"subscription_applied"`                // delivery fee waived by subscription
+	FraudReviewed       bool                 `json:"fraud_reviewed" gorm:"default:false"` // an admin has looked at it; hidden from the suspicious-orders report
+	PaymentMethod       string               `json:"payment_method" gorm:"not null;default:'prepaid'"`
+	PaymentStatus       string               `json:"payment_status" gorm:"not null;default:'pending'"`
+	PaymentReference    string               `json:"payment_reference"` // the gateway's ID for the payment
+	CODCollected        bool                 `json:"cod_collected" gorm:"default:false"`
+	CODAmountCollected  float64              `json:"cod_amount_collected"`
+	CODVariance         float64              `json:"cod_variance"`                          // collected minus expected; non-zero means a mismatch
+	CODRemittedAt       *time.Time           `json:"cod_remitted_at"`                       // driver handed the cash to the platform
+	PartialDelivery     bool                 `json:"partial_delivery" gorm:"default:false"` // some items never reached the customer
+	DeliveryAddress     string               `json:"delivery_address" gorm:"not null"`
+	DeliveryLat         *float64             `json:"delivery_lat"` // from the saved address, when it was geocoded
+	DeliveryLng         *float64             `json:"delivery_lng"`
+	DeliveryCoords      *geo.Point           `json:"delivery_coords,omitempty" gorm:"-"` // filled for drivers' maps
+	Links               []Link               `json:"links,omitempty" gorm:"-"`           // actions open to the caller; filled by hateoas.OrderLinks
+	DistanceKm          *float64             `json:"distance_km"`                        // straight line from the restaurant, when both ends have coordinates
+	RouteDistanceKm     *float64             `json:"route_distance_km"`                  // length of the driver's reported route, set on delivery
+	DeliveredAt         *time.Time           `json:"delivered_at"`
+	SLAMet              bool                 `json:"sla_met" gorm:"not null;default:false"` // delivered within the restaurant's SLA minutes
+	AutoAssignedAt      *time.Time           `json:"auto_assigned_at"`                      // set when the system gave the order to a driver; the driver may unclaim it for 2 minutes
+	Notes               string               `json:"notes"`
+	EstimatedTime       int                  `json:"estimated_time_minutes"` // novelty: ETA in minutes
+	ETARecalculatedAt   *time.Time           `json:"eta_recalculated_at"`    // last admin recalculation
+	Items               []OrderItem          `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+	StatusHistory       []OrderStatusHistory `json:"status_history,omitempty" gorm:"foreignKey:OrderID"`
+	CreatedAt           time.Time            `json:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt       `json:"deleted_at" gorm:"index"` // soft-deleted by an admin; hidden from every scoped query
+}
+
+// Link points a client at an action it may take next
+type Link struct {
+	Rel    string `json:"rel"`
+	Href   string `json:"href"`
+	Method string `json:"method"`
+}
+
+// Subtotal is what the items cost: the total less fees and tip
+func (o Order) Subtotal() float64 {
+	return o.TotalPrice - o.DeliveryFee - o.ServiceFee - o.TipAmount
 }
 
+// OrderItem is one line of an order. GET /customer/orders?q= searches Name with
+// LIKE; add an index on order_items.name (or full-text search) when moving to a
+// production database.
 type OrderItem struct {
-	ID         uint     `json:"id" gorm:"primaryKey"`
-	OrderID    uint     `json:"order_id" gorm:"not null"`
-	MenuItemID uint     `json:"menu_item_id" gorm:"not null"`
-	MenuItem   MenuItem `json:"menu_item,omitempty" gorm:"foreignKey:MenuItemID"`
-	Quantity   int      `json:"quantity" gorm:"not null"`
-	Price      float64  `json:"price" gorm:"not null"` // snapshot price at time of order
-	Name       string   `json:"name"`                  // snapshot name
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	OrderID      uint           `json:"order_id" gorm:"not null"`
+	MenuItemID   uint           `json:"menu_item_id" gorm:"not null"`
+	MenuItem     MenuItem       `json:"menu_item,omitempty" gorm:"foreignKey:MenuItemID"`
+	BundleID     *uint          `json:"bundle_id,omitempty"` // set when the item came in a bundle
+	Quantity     int            `json:"quantity" gorm:"not null"`
+	Price        float64        `json:"price" gorm:"not null"`                      // snapshot price at time of order
+	Name         string         `json:"name"`                                       // snapshot name
+	WasDelivered bool           `json:"was_delivered" gorm:"not null;default:true"` // false when the driver reported it missing
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`                    // soft-deleted along with its order
 }
 
 // OrderStatusHistory tracks every status change — audit trail novelty
 type OrderStatusHistory struct {
-	ID        uint        `json:"id" gorm:"primaryKey"`
-	OrderID   uint        `json:"order_id" gorm:"not null"`
+	ID         uint        `json:"id" gorm:"primaryKey"`
+	OrderID    uint        `json:"order_id" gorm:"not null"`
 	FromStatus OrderStatus `json:"from_status"`
-	ToStatus  OrderStatus `json:"to_status" gorm:"not null"`
-	ChangedBy uint        `json:"changed_by"` // user ID who triggered the transition
-	Note      string      `json:"note"`
-	CreatedAt time.Time   `json:"created_at"`
+	ToStatus   OrderStatus `json:"to_status" gorm:"not null"`
+	ChangedBy  uint        `json:"changed_by"` // user ID who triggered the transition
+	Note       string      `json:"note"`
+	CreatedAt  time.Time   `json:"created_at"`
 }
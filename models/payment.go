@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PaymentWebhookLog keeps every payment gateway callback as received, for debugging
+type PaymentWebhookLog struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Payload        string     `json:"payload" gorm:"not null"` // raw request body; only its start when the signature is bad
+	Signature      string     `json:"signature"`
+	SignatureValid bool       `json:"signature_valid"`
+	InvoiceNumber  string     `json:"invoice_number" gorm:"index"`
+	OrderID        *uint      `json:"order_id" gorm:"index"`
+	ProcessedAt    *time.Time `json:"processed_at"`
+	Error          string     `json:"error,omitempty"` // why processing didn't update the order
+	CreatedAt      time.Time  `json:"created_at"`
+}
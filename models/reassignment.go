@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ReassignmentStatus tracks the admin review of a reassignment request
+type ReassignmentStatus string
+
+const (
+	ReassignmentPending  ReassignmentStatus = "PENDING"
+	ReassignmentApproved ReassignmentStatus = "APPROVED"
+	ReassignmentRejected ReassignmentStatus = "REJECTED"
+)
+
+// ReassignmentRequest is a customer's flag that their delivery has stalled
+type ReassignmentRequest struct {
+	ID         uint               `json:"id" gorm:"primaryKey"`
+	OrderID    uint               `json:"order_id" gorm:"not null;index"`
+	Order      Order              `json:"order,omitempty" gorm:"foreignKey:OrderID"`
+	CustomerID uint               `json:"customer_id" gorm:"not null"`
+	DriverID   uint               `json:"driver_id" gorm:"not null"` // driver at the time of the request
+	Reason     string             `json:"reason"`
+	Status     ReassignmentStatus `json:"status" gorm:"not null;default:'PENDING'"`
+	ReviewedBy *uint              `json:"reviewed_by"`
+	ReviewedAt *time.Time         `json:"reviewed_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// RecurringOrderItem is one line of a recurring order's template
+type RecurringOrderItem struct {
+	MenuItemID uint `json:"menu_item_id"`
+	Quantity   int  `json:"quantity"`
+}
+
+// RecurringOrder places the same order every week at a fixed day and time (server local time)
+type RecurringOrder struct {
+	ID              uint                 `json:"id" gorm:"primaryKey"`
+	CustomerID      uint                 `json:"customer_id" gorm:"not null;index"`
+	RestaurantID    uint                 `json:"restaurant_id" gorm:"not null"`
+	Restaurant      Restaurant           `json:"restaurant,omitempty" gorm:"foreignKey:RestaurantID"`
+	DayOfWeek       int                  `json:"day_of_week"` // 0 = Sunday
+	TimeOfDay       string               `json:"time_of_day"` // "HH:MM"
+	Items           []RecurringOrderItem `json:"items" gorm:"serializer:json"`
+	DeliveryAddress string               `json:"delivery_address" gorm:"not null"`
+	Notes           string               `json:"notes"`
+	IsActive        bool                 `json:"is_active" gorm:"default:true;index"`
+	NextRunAt       time.Time            `json:"next_run_at" gorm:"index"`
+	LastRunAt       *time.Time           `json:"last_run_at"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// RecurringOrderLog records each attempt to place a recurring order
+type RecurringOrderLog struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	RecurringOrderID uint      `json:"recurring_order_id" gorm:"not null;index"`
+	OrderID          *uint     `json:"order_id"` // nil when the attempt failed
+	Success          bool      `json:"success"`
+	Error            string    `json:"error,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Referral links a customer who signed up with a referral code to the customer who shared it.
+// RewardedAt is set once the referee's first order is delivered and both bonuses are paid.
+type Referral struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	ReferrerID     uint       `json:"referrer_id" gorm:"not null;index"`
+	RefereeID      uint       `json:"referee_id" gorm:"not null;uniqueIndex"`
+	Referee        User       `json:"referee,omitempty" gorm:"foreignKey:RefereeID"`
+	OrderID        *uint      `json:"order_id"`
+	ReferrerPoints int        `json:"referrer_points"`
+	RefereePoints  int        `json:"referee_points"`
+	RewardedAt     *time.Time `json:"rewarded_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ReferralLink tracks how a customer's referral code performs: landing page
+// visits and the signups that used the code
+type ReferralLink struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	Code            string    `json:"code" gorm:"not null;uniqueIndex"`
+	UserID          uint      `json:"user_id" gorm:"not null;index"`
+	ClickCount      int       `json:"click_count" gorm:"not null;default:0"`
+	ConversionCount int       `json:"conversion_count" gorm:"not null;default:0"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
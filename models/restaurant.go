@@ -1,31 +1,116 @@
 package models
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Restaurant struct {
-	ID          uint       `json:"id" gorm:"primaryKey"`
-	OwnerID     uint       `json:"owner_id" gorm:"not null"`
-	Owner       User       `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
-	Name        string     `json:"name" gorm:"not null"`
-	Cuisine     string     `json:"cuisine"`
-	Address     string     `json:"address"`
-	Description string     `json:"description"`
-	IsOpen      bool       `json:"is_open" gorm:"default:true"`
-	Rating      float64    `json:"rating" gorm:"default:0"`
-	MenuItems   []MenuItem `json:"menu_items,omitempty" gorm:"foreignKey:RestaurantID"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID                     uint       `json:"id" gorm:"primaryKey"`
+	OwnerID                uint       `json:"owner_id" gorm:"not null"`
+	Owner                  User       `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
+	Name                   string     `json:"name" gorm:"not null"`
+	Cuisine                string     `json:"cuisine"`
+	Address                string     `json:"address"`
+	Latitude               *float64   `json:"latitude"` // set by the owner; distance-based delivery fees need it
+	Longitude              *float64   `json:"longitude"`
+	Description            string     `json:"description"`
+	IsOpen                 bool       `json:"is_open" gorm:"default:true"`
+	IsActive               bool       `json:"is_active" gorm:"not null;default:true;index"`       // false hides the restaurant from customers; set by admins
+	ConsecutiveAutoCancels int        `json:"consecutive_auto_cancels" gorm:"not null;default:0"` // reset when the restaurant confirms an order
+	Rating                 float64    `json:"rating" gorm:"default:0"`
+	ReviewCount            int        `json:"review_count" gorm:"default:0"`
+	ETAAccuracyRate        float64    `json:"eta_accuracy_rate" gorm:"not null;default:1"` // share of rated deliveries within 1.2x the ETA
+	ETARatingCount         int        `json:"eta_rating_count" gorm:"not null;default:0"`
+	MaxOrdersPerMinute     int        `json:"max_orders_per_minute" gorm:"default:10"`
+	MinItemCount           int        `json:"min_item_count" gorm:"not null;default:1"` // item lines an order needs
+	MaxItemCount           int        `json:"max_item_count" gorm:"not null;default:50"`
+	SLAMinutes             int        `json:"sla_minutes" gorm:"not null;default:60"`           // target from placement to delivery; set by admins
+	AutoAssignDriver       bool       `json:"auto_assign_driver" gorm:"not null;default:false"` // give ready orders to the nearest free driver instead of waiting for a pickup
+	Currency               string     `json:"currency" gorm:"not null;default:'USD'"`           // ISO 4217; menu prices and orders are in it
+	BaseDeliveryFee        float64    `json:"base_delivery_fee" gorm:"not null;default:40"`
+	PricePerKm             float64    `json:"price_per_km" gorm:"not null;default:0"`        // added per km from the restaurant to the delivery point
+	FreeDeliveryAbove      float64    `json:"free_delivery_above" gorm:"not null;default:0"` // subtotal from which delivery is free; 0 never
+	ManualOverrideUntil    *time.Time `json:"manual_override_until"`                         // scheduler leaves is_open alone until then
+	IsFeatured             bool       `json:"is_featured" gorm:"default:false;index"`
+	FeaturedUntil          *time.Time `json:"featured_until"`
+	FeaturedEndsInHours    *float64   `json:"featured_ends_in_hours,omitempty" gorm:"-"` // filled for admin listings
+	IsClosedForHoliday     bool       `json:"is_closed_for_holiday" gorm:"-"`            // a closure covers today
+	MenuItems              []MenuItem `json:"menu_items,omitempty" gorm:"foreignKey:RestaurantID"`
+	Version                int        `json:"version" gorm:"not null;default:1"` // bumped by every owner edit; stale edits are rejected
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
 }
 
 type MenuItem struct {
-	ID           uint       `json:"id" gorm:"primaryKey"`
-	RestaurantID uint       `json:"restaurant_id" gorm:"not null"`
-	Name         string     `json:"name" gorm:"not null"`
-	Description  string     `json:"description"`
-	Price        float64    `json:"price" gorm:"not null"`
-	Category     string     `json:"category"`
-	IsAvailable  bool       `json:"is_available" gorm:"default:true"`
-	IsVeg        bool       `json:"is_veg" gorm:"default:false"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	ID                   uint       `json:"id" gorm:"primaryKey"`
+	RestaurantID         uint       `json:"restaurant_id" gorm:"not null"`
+	Name                 string     `json:"name" gorm:"not null"`
+	Description          string     `json:"description"`
+	Price                float64    `json:"price" gorm:"not null"`
+	Category             string     `json:"category"`
+	IsAvailable          bool       `json:"is_available" gorm:"default:true"`
+	IsVeg                bool       `json:"is_veg" gorm:"default:false"`
+	TrackStock           bool       `json:"track_stock" gorm:"default:false"` // when false, stock_quantity is ignored
+	StockQuantity        int        `json:"stock_quantity" gorm:"default:0"`
+	Calories             *int       `json:"calories,omitempty"` // per serving, like the macros; null until the restaurant gives them
+	ProteinG             *float64   `json:"protein_g,omitempty"`
+	CarbsG               *float64   `json:"carbs_g,omitempty"`
+	FatG                 *float64   `json:"fat_g,omitempty"`
+	AvailableMonths      string     `json:"available_months"`         // comma-separated month numbers, e.g. "9,10,11"; empty means all year
+	IsInSeason           bool       `json:"is_in_season" gorm:"-"`    // filled when listing the public menu
+	Allergens            []string   `json:"allergens" gorm:"-"`       // filled from menu_item_allergens when listing
+	EightySixedAt        *time.Time `json:"eightysixed_at,omitempty"` // 86'd: out mid-service until restored
+	EightySixReason      string     `json:"eightysix_reason,omitempty"`
+	EightySixed          bool       `json:"eightysixed" gorm:"-"`                    // filled when listing the public menu
+	PriceChangedRecently bool       `json:"price_changed_recently" gorm:"-"`         // filled when listing the public menu
+	Version              int        `json:"version" gorm:"not null;default:1"`       // bumped by every owner edit; stale edits are rejected
+	RecommendedPairings  []MenuItem `json:"recommended_pairings,omitempty" gorm:"-"` // filled when listing the public menu
+	PairingNote          string     `json:"pairing_note,omitempty" gorm:"-"`         // set on items listed as a recommended pairing
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// InSeason reports whether the item may be sold in month
+func (m MenuItem) InSeason(month time.Month) bool {
+	if m.AvailableMonths == "" {
+		return true
+	}
+	for _, s := range strings.Split(m.AvailableMonths, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && time.Month(n) == month {
+			return true
+		}
+	}
+	return false
+}
+
+// MenuItemPairing recommends two menu items of the same restaurant together.
+// The pair is unordered, so it is always stored with ItemAID < ItemBID.
+type MenuItemPairing struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ItemAID     uint      `json:"item_a_id" gorm:"not null;uniqueIndex:idx_menu_item_pairings_pair"`
+	ItemBID     uint      `json:"item_b_id" gorm:"not null;uniqueIndex:idx_menu_item_pairings_pair;index"`
+	PairingNote string    `json:"pairing_note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MenuItemEightySix records each time an item was 86'd, kept after the item is restored
+type MenuItemEightySix struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	MenuItemID    uint       `json:"menu_item_id" gorm:"not null;index"`
+	RestaurantID  uint       `json:"restaurant_id" gorm:"not null;index"`
+	Reason        string     `json:"reason"`
+	EightySixedAt time.Time  `json:"eightysixed_at" gorm:"not null;index"`
+	RestoredAt    *time.Time `json:"restored_at"`
+}
+
+// MenuItemPriceHistory records one change to a menu item's price
+type MenuItemPriceHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	MenuItemID uint      `json:"menu_item_id" gorm:"not null;index"`
+	OldPrice   float64   `json:"old_price"`
+	NewPrice   float64   `json:"new_price"`
+	ChangedBy  uint      `json:"changed_by"`
+	ChangedAt  time.Time `json:"changed_at" gorm:"index"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Review is a customer's rating of a delivered order — one per order
+type Review struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	OrderID          uint      `json:"order_id" gorm:"uniqueIndex;not null"`
+	CustomerID       uint      `json:"customer_id" gorm:"not null"`
+	RestaurantID     uint      `json:"restaurant_id" gorm:"not null;index"`
+	DriverID         *uint     `json:"driver_id" gorm:"index"`
+	RestaurantRating int       `json:"restaurant_rating" gorm:"not null"` // 1-5
+	DriverRating     *int      `json:"driver_rating"`                     // 1-5, optional
+	Comment          string    `json:"comment"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ETARating is a customer's verdict on how accurate a delivered order's ETA was
+type ETARating struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	OrderID          uint      `json:"order_id" gorm:"uniqueIndex;not null"`
+	CustomerID       uint      `json:"customer_id" gorm:"not null"`
+	RestaurantID     uint      `json:"restaurant_id" gorm:"not null;index"`
+	EstimatedMinutes int       `json:"estimated_minutes" gorm:"not null"` // the ETA quoted at placement
+	ActualMinutes    int       `json:"actual_minutes" gorm:"not null"`
+	OnTime           bool      `json:"on_time"` // as the customer felt it
+	CreatedAt        time.Time `json:"created_at"`
+}
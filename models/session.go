@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// TokenIssue records each JWT handed out so a force-logout can report how many
+// sessions it ended. It is never consulted when authenticating requests.
+type TokenIssue struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	UserID   uint      `json:"user_id" gorm:"not null;index"`
+	IssuedAt time.Time `json:"issued_at" gorm:"not null"`
+}
+
+// MagicLinkToken is a single-use password-less login link. Only the SHA-256
+// hash of the token is stored; the plain token goes out by email.
+type MagicLinkToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAChallenge is the short-lived second step of a login for accounts with TOTP
+// enabled. Only the SHA-256 hash of the mfa_token is stored.
+type MFAChallenge struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"` // wrong codes entered so far
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
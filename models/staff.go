@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// StaffRole is the only role an invite can grant today
+const StaffRole = "staff"
+
+// Invite is a pending invitation for someone to help run a restaurant
+type Invite struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	RestaurantID uint       `json:"restaurant_id" gorm:"not null;index"`
+	InvitedEmail string     `json:"invited_email" gorm:"not null;index"`
+	TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"` // sha256 of the token sent to the invitee
+	Role         string     `json:"role" gorm:"not null;default:'staff'"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	AcceptedAt   *time.Time `json:"accepted_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RestaurantStaff lets a non-owner manage a restaurant's orders
+type RestaurantStaff struct {
+	RestaurantID uint      `json:"restaurant_id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"primaryKey;uniqueIndex"`
+	CreatedAt    time.Time `json:"created_at"`
+}
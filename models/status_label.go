@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DefaultLocale is the locale status labels fall back to
+const DefaultLocale = "en"
+
+// StatusLabel renames an order status for display, per locale, so a
+// white-label deployment can use its own terms. The canonical status value
+// never changes.
+type StatusLabel struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	Status       OrderStatus `json:"status" gorm:"not null;uniqueIndex:idx_status_labels_status_locale"`
+	Locale       string      `json:"locale" gorm:"not null;default:en;uniqueIndex:idx_status_labels_status_locale"`
+	DisplayLabel string      `json:"display_label" gorm:"not null"`
+	UpdatedBy    *uint       `json:"updated_by"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// SubscriptionPlan identifies a delivery subscription tier
+type SubscriptionPlan string
+
+const (
+	PlanMonthly SubscriptionPlan = "monthly"
+)
+
+// DeliverySubscription waives delivery fees for a customer while active.
+// There is no payment gateway yet, so subscriptions are auto-approved on sign-up;
+// PaymentReference is reserved for a future gateway integration.
+type DeliverySubscription struct {
+	ID               uint             `json:"id" gorm:"primaryKey"`
+	CustomerID       uint             `json:"customer_id" gorm:"not null;index"`
+	Customer         User             `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	Plan             SubscriptionPlan `json:"plan" gorm:"not null;default:'monthly'"`
+	Price            float64          `json:"price"`
+	StartedAt        time.Time        `json:"started_at"`
+	ExpiresAt        time.Time        `json:"expires_at"`
+	IsActive         bool             `json:"is_active" gorm:"default:true"`
+	PaymentReference string           `json:"payment_reference"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// SupportMessage is one message in an order's support thread between its
+// customer and the admins
+type SupportMessage struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	OrderID    uint      `json:"order_id" gorm:"not null;index"`
+	SenderID   uint      `json:"sender_id" gorm:"not null"`
+	SenderRole UserRole  `json:"sender_role" gorm:"not null"` // customer or admin
+	Message    string    `json:"message" gorm:"not null"`
+	IsRead     bool      `json:"is_read" gorm:"not null;default:false;index"` // read by the other side
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SystemConfig is an admin-editable platform setting stored as a string
+type SystemConfig struct {
+	Key       string    `json:"key" gorm:"primaryKey"`
+	Value     string    `json:"value" gorm:"not null"`
+	UpdatedBy *uint     `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
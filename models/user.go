@@ -15,12 +15,19 @@ const (
 )
 
 type User struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	Name         string    `json:"name" gorm:"not null"`
-	Email        string    `json:"email" gorm:"uniqueIndex;not null"`
-	PasswordHash string    `json:"-" gorm:"not null"`
-	Role         UserRole  `json:"role" gorm:"not null;default:'customer'"`
-	Phone        string    `json:"phone"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Name            string     `json:"name" gorm:"not null"`
+	Email           string     `json:"email" gorm:"uniqueIndex;not null"`
+	PasswordHash    string     `json:"-" gorm:"not null"`
+	Role            UserRole   `json:"role" gorm:"not null;default:'customer'"`
+	Phone           string     `json:"phone"`
+	ReferralCode    *string    `json:"referral_code,omitempty" gorm:"uniqueIndex;size:8"` // customers only
+	ReferredByID    *uint      `json:"referred_by_id,omitempty" gorm:"index"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	TokensValidFrom *time.Time `json:"-"`                                             // tokens issued before this are rejected (admin force-logout)
+	TOTPSecret      string     `json:"-"`                                             // base32; set by TOTP setup, used once TOTPEnabled
+	TOTPEnabled     bool       `json:"totp_enabled" gorm:"default:false"`             // login needs a TOTP code (admins only)
+	TOTPLastStep    int64      `json:"-" gorm:"not null;default:0"`                   // 30-second time step of the last accepted code; older or equal steps are replays
+	DuplicateEmail  bool       `json:"-" gorm:"not null;default:false"`               // registered twice under one email before emails were lowercased; exempt from the case-insensitive unique index until merged
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// RestaurantWaitlist is a customer waiting to hear when a closed restaurant reopens.
+// An entry is active until NotifiedAt is set.
+type RestaurantWaitlist struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	CustomerID   uint       `json:"customer_id" gorm:"not null;index"`
+	RestaurantID uint       `json:"restaurant_id" gorm:"not null;index"`
+	Restaurant   Restaurant `json:"restaurant,omitempty" gorm:"foreignKey:RestaurantID"`
+	NotifiedAt   *time.Time `json:"notified_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
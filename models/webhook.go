@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // every attempt of the last round failed
+)
+
+// Webhook is an external URL that receives order events, registered by an admin
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	URL       string    `json:"url" gorm:"not null"`
+	Secret    string    `json:"-" gorm:"not null"` // signs each payload
+	Events    string    `json:"events"`            // comma-separated event names, empty for all
+	IsActive  bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryLog is one payload sent to one webhook, with the outcome of
+// its latest round of attempts
+type WebhookDeliveryLog struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	WebhookID   uint       `json:"webhook_id" gorm:"not null;index"`
+	Webhook     Webhook    `json:"-" gorm:"foreignKey:WebhookID"`
+	Event       string     `json:"event"`
+	Payload     string     `json:"payload" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;default:'pending';index"`
+	Attempts    int        `json:"attempts"` // in the latest round; a replay starts again from 0
+	Replays     int        `json:"replays"`
+	LastError   string     `json:"last_error,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"log"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+)
+
+// Delivery channels a notification can be sent through
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// Message is a single notification addressed to one user
+type Message struct {
+	UserID  uint
+	Email   string
+	Phone   string
+	Channel string
+	Title   string
+	Body    string
+
+	// Optional context shown in the user's inbox
+	EventType     string
+	ReferenceID   uint
+	ReferenceType string
+	// Private messages, e.g. ones carrying login links, are never stored
+	Private bool
+}
+
+// Notifier delivers messages to users. Swap Default for a real
+// email/SMS/push provider when one is integrated.
+type Notifier interface {
+	Send(msg Message) error
+}
+
+// Prober is implemented by notifiers that can check their provider is
+// reachable without sending a message
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+// LogNotifier writes notifications to the server log instead of sending them
+type LogNotifier struct{}
+
+func (LogNotifier) Send(msg Message) error {
+	log.Printf("📣 [%s] to user %d <%s>: %s — %s", msg.Channel, msg.UserID, msg.Email, msg.Title, msg.Body)
+	return nil
+}
+
+func (LogNotifier) Probe(context.Context) error { return nil }
+
+// Inbox stores each message addressed to a user as an in-app notification,
+// then hands it to Next for delivery
+type Inbox struct {
+	Next Notifier
+}
+
+func (n Inbox) Send(msg Message) error {
+	if msg.UserID != 0 && !msg.Private {
+		eventType := msg.EventType
+		if eventType == "" {
+			eventType = "general"
+		}
+		err := config.DB.Create(&models.Notification{
+			UserID:        msg.UserID,
+			Title:         msg.Title,
+			Body:          msg.Body,
+			EventType:     eventType,
+			ReferenceID:   msg.ReferenceID,
+			ReferenceType: msg.ReferenceType,
+		}).Error
+		if err != nil {
+			log.Printf("failed to store notification for user %d: %v", msg.UserID, err)
+		}
+	}
+	return n.Next.Send(msg)
+}
+
+func (n Inbox) Probe(ctx context.Context) error {
+	if p, ok := n.Next.(Prober); ok {
+		return p.Probe(ctx)
+	}
+	return nil
+}
+
+// Default is the notifier used across the application
+var Default Notifier = Inbox{Next: LogNotifier{}}
+
+// ValidChannel reports whether a channel name is supported
+func ValidChannel(channel string) bool {
+	switch channel {
+	case ChannelEmail, ChannelSMS, ChannelPush:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package pos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"food-delivery-api/models"
+)
+
+// GenericFields are the menu item fields a FieldMap can fill
+var GenericFields = []string{"name", "description", "price", "category", "is_veg"}
+
+// GenericImporter reads an array of objects from any system. FieldMap says
+// which source key holds each menu item field, e.g. {"name": "title",
+// "price": "cost"}; unmapped fields are read from keys of the same name.
+type GenericImporter struct {
+	FieldMap map[string]string
+}
+
+func (g GenericImporter) key(field string) string {
+	if k, ok := g.FieldMap[field]; ok && k != "" {
+		return k
+	}
+	return field
+}
+
+func (g GenericImporter) Import(rawJSON []byte) ([]models.MenuItem, error) {
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(rawJSON, &rows); err != nil {
+		return nil, fmt.Errorf("generic import needs an array of objects: %w", err)
+	}
+	items := make([]models.MenuItem, len(rows))
+	for i, row := range rows {
+		items[i] = models.MenuItem{
+			Name:        stringValue(row[g.key("name")]),
+			Description: stringValue(row[g.key("description")]),
+			Category:    stringValue(row[g.key("category")]),
+			Price:       floatValue(row[g.key("price")]),
+			IsVeg:       boolValue(row[g.key("is_veg")]),
+		}
+	}
+	return items, nil
+}
+
+func stringValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// floatValue accepts numbers and numeric strings; anything else is 0 and
+// fails validation
+func floatValue(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+func boolValue(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	case float64:
+		return v != 0
+	}
+	return false
+}
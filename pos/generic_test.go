@@ -0,0 +1,34 @@
+package pos
+
+import (
+	"reflect"
+	"testing"
+
+	"food-delivery-api/models"
+)
+
+func TestGenericImporterMapsFields(t *testing.T) {
+	g := GenericImporter{FieldMap: map[string]string{"name": "title", "price": "cost", "is_veg": ""}}
+	items, err := g.Import([]byte(`[
+		{"title": "Dal", "cost": "4.5", "category": "Mains", "is_veg": "true", "description": "Lentils"},
+		{"title": 42, "cost": 3, "is_veg": 1},
+		{"name": "Ignored", "cost": "free", "is_veg": false}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.MenuItem{
+		{Name: "Dal", Description: "Lentils", Category: "Mains", Price: 4.5, IsVeg: true},
+		{Name: "42", Price: 3, IsVeg: true},
+		{},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items = %+v, want %+v", items, want)
+	}
+}
+
+func TestGenericImporterNeedsAnArray(t *testing.T) {
+	if _, err := (GenericImporter{}).Import([]byte(`{"name": "Dal"}`)); err == nil {
+		t.Error("want an error for a single object")
+	}
+}
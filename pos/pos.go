@@ -0,0 +1,40 @@
+// Package pos converts menus exported by point-of-sale systems into menu items.
+package pos
+
+import (
+	"fmt"
+	"sort"
+
+	"food-delivery-api/models"
+)
+
+// Importer turns one POS export format into menu items. Items come back
+// unvalidated and without a restaurant; the caller checks and saves them.
+type Importer interface {
+	Import(rawJSON []byte) ([]models.MenuItem, error)
+}
+
+// importers builds a fresh importer for each supported format
+var importers = map[string]func() Importer{
+	"square":  func() Importer { return &SquareImporter{} },
+	"generic": func() Importer { return &GenericImporter{} },
+}
+
+// GetImporter returns an importer for the named format
+func GetImporter(format string) (Importer, error) {
+	newImporter, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported POS format %q (supported: %v)", format, Formats())
+	}
+	return newImporter(), nil
+}
+
+// Formats lists the supported format names
+func Formats() []string {
+	names := make([]string, 0, len(importers))
+	for name := range importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
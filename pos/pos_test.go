@@ -0,0 +1,27 @@
+package pos
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetImporter(t *testing.T) {
+	for _, format := range Formats() {
+		if _, err := GetImporter(format); err != nil {
+			t.Errorf("GetImporter(%q): %v", format, err)
+		}
+	}
+	_, err := GetImporter("toast")
+	if err == nil || !strings.Contains(err.Error(), "[generic square]") {
+		t.Errorf("unknown format: err = %v, want one listing the supported formats", err)
+	}
+}
+
+func TestGetImporterReturnsFreshImporters(t *testing.T) {
+	a, _ := GetImporter("generic")
+	a.(*GenericImporter).FieldMap = map[string]string{"name": "title"}
+	b, _ := GetImporter("generic")
+	if b.(*GenericImporter).FieldMap != nil {
+		t.Error("field map from one import leaked into the next")
+	}
+}
@@ -0,0 +1,81 @@
+package pos
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"food-delivery-api/models"
+)
+
+// squareObject is the part of a Square catalog object we read
+type squareObject struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	ItemData *struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		CategoryID  string         `json:"category_id"`
+		Variations  []squareObject `json:"variations"`
+	} `json:"item_data"`
+	ItemVariationData *struct {
+		Name       string `json:"name"`
+		PriceMoney *struct {
+			Amount int64 `json:"amount"` // smallest currency unit
+		} `json:"price_money"`
+	} `json:"item_variation_data"`
+	CategoryData *struct {
+		Name string `json:"name"`
+	} `json:"category_data"`
+}
+
+// SquareImporter reads a Square catalog export: {"objects": [...]} or a bare
+// array of catalog objects. Each ITEM becomes one menu item per variation;
+// with several variations the variation name is appended ("Latte (Large)").
+// CATEGORY objects supply category names.
+type SquareImporter struct{}
+
+func (SquareImporter) Import(rawJSON []byte) ([]models.MenuItem, error) {
+	var objects []squareObject
+	if err := json.Unmarshal(rawJSON, &objects); err != nil {
+		var export struct {
+			Objects []squareObject `json:"objects"`
+		}
+		if err := json.Unmarshal(rawJSON, &export); err != nil {
+			return nil, fmt.Errorf("not a Square catalog export: %w", err)
+		}
+		objects = export.Objects
+	}
+
+	categories := map[string]string{}
+	for _, obj := range objects {
+		if obj.Type == "CATEGORY" && obj.CategoryData != nil {
+			categories[obj.ID] = obj.CategoryData.Name
+		}
+	}
+
+	var items []models.MenuItem
+	for _, obj := range objects {
+		if obj.Type != "ITEM" || obj.ItemData == nil {
+			continue
+		}
+		data := obj.ItemData
+		if len(data.Variations) == 0 {
+			// Nothing to price it by; let validation report it
+			items = append(items, models.MenuItem{Name: data.Name, Description: data.Description, Category: categories[data.CategoryID]})
+			continue
+		}
+		for _, v := range data.Variations {
+			item := models.MenuItem{Name: data.Name, Description: data.Description, Category: categories[data.CategoryID]}
+			if vd := v.ItemVariationData; vd != nil {
+				if len(data.Variations) > 1 && vd.Name != "" {
+					item.Name = fmt.Sprintf("%s (%s)", data.Name, vd.Name)
+				}
+				if vd.PriceMoney != nil {
+					item.Price = float64(vd.PriceMoney.Amount) / 100
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
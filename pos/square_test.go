@@ -0,0 +1,48 @@
+package pos
+
+import (
+	"reflect"
+	"testing"
+
+	"food-delivery-api/models"
+)
+
+const squareCatalog = `[
+	{"type": "CATEGORY", "id": "C1", "category_data": {"name": "Drinks"}},
+	{"type": "ITEM", "id": "I1", "item_data": {"name": "Latte", "description": "Milky", "category_id": "C1", "variations": [
+		{"type": "ITEM_VARIATION", "item_variation_data": {"name": "Small", "price_money": {"amount": 350}}},
+		{"type": "ITEM_VARIATION", "item_variation_data": {"name": "Large", "price_money": {"amount": 425}}}
+	]}},
+	{"type": "ITEM", "id": "I2", "item_data": {"name": "Scone", "variations": [
+		{"type": "ITEM_VARIATION", "item_variation_data": {"name": "Regular", "price_money": {"amount": 275}}}
+	]}},
+	{"type": "ITEM", "id": "I3", "item_data": {"name": "Mystery"}},
+	{"type": "DISCOUNT", "id": "D1"}
+]`
+
+func TestSquareImporter(t *testing.T) {
+	want := []models.MenuItem{
+		{Name: "Latte (Small)", Description: "Milky", Category: "Drinks", Price: 3.50},
+		{Name: "Latte (Large)", Description: "Milky", Category: "Drinks", Price: 4.25},
+		{Name: "Scone", Price: 2.75},
+		{Name: "Mystery"},
+	}
+	for name, payload := range map[string]string{
+		"bare array":  squareCatalog,
+		"export file": `{"objects": ` + squareCatalog + `}`,
+	} {
+		items, err := SquareImporter{}.Import([]byte(payload))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(items, want) {
+			t.Errorf("%s: items = %+v, want %+v", name, items, want)
+		}
+	}
+}
+
+func TestSquareImporterRejectsOtherJSON(t *testing.T) {
+	if _, err := (SquareImporter{}).Import([]byte(`"latte"`)); err == nil {
+		t.Error("want an error for a payload that isn't a catalog")
+	}
+}
@@ -0,0 +1,84 @@
+// Package ratelimit keeps per-key token buckets in memory.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket refills at perMinute tokens per minute and holds at most perMinute tokens
+type Bucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+	rejected   []time.Time // rejections kept for the last hour
+}
+
+// Stats is a snapshot of a bucket
+type Stats struct {
+	Capacity         int     `json:"capacity"`
+	Tokens           float64 `json:"tokens"`
+	FillPercent      float64 `json:"fill_percent"`
+	RejectedLastHour int     `json:"rejected_last_hour"`
+}
+
+var restaurants sync.Map // restaurant ID → *Bucket
+
+func bucketFor(restaurantID uint, perMinute int) *Bucket {
+	b, _ := restaurants.LoadOrStore(restaurantID, &Bucket{tokens: float64(perMinute), lastRefill: time.Now()})
+	return b.(*Bucket)
+}
+
+// refill tops the bucket up for the time elapsed since the last call; callers hold b.mu
+func (b *Bucket) refill(perMinute int, now time.Time) {
+	elapsed := now.Sub(b.lastRefill).Minutes()
+	b.tokens = math.Min(float64(perMinute), b.tokens+elapsed*float64(perMinute))
+	b.lastRefill = now
+
+	cutoff := now.Add(-time.Hour)
+	i := 0
+	for i < len(b.rejected) && b.rejected[i].Before(cutoff) {
+		i++
+	}
+	b.rejected = b.rejected[i:]
+}
+
+// AllowOrder takes one token from the restaurant's bucket. When the bucket is empty it
+// returns false and how long until the next token is available.
+func AllowOrder(restaurantID uint, perMinute int) (bool, time.Duration) {
+	if perMinute <= 0 {
+		return true, 0
+	}
+	b := bucketFor(restaurantID, perMinute)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.refill(perMinute, now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	b.rejected = append(b.rejected, now)
+	wait := time.Duration((1 - b.tokens) / float64(perMinute) * float64(time.Minute))
+	return false, wait
+}
+
+// RestaurantStats reports the current fill level of a restaurant's bucket
+func RestaurantStats(restaurantID uint, perMinute int) Stats {
+	b := bucketFor(restaurantID, perMinute)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(perMinute, time.Now())
+	stats := Stats{
+		Capacity:         perMinute,
+		Tokens:           math.Round(b.tokens*100) / 100,
+		RejectedLastHour: len(b.rejected),
+	}
+	if perMinute > 0 {
+		stats.FillPercent = math.Round(b.tokens/float64(perMinute)*10000) / 100
+	}
+	return stats
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// window remembers when each recent request for a key was allowed
+type window struct {
+	mu      sync.Mutex
+	hits    []time.Time
+	per     time.Duration
+	removed bool // swept out of windows; callers holding it must look again
+}
+
+var windows sync.Map // key → *window
+
+// windowSweepInterval is the least time between two sweeps for idle windows
+const windowSweepInterval = time.Minute
+
+var lastWindowSweep atomic.Int64 // unix nanoseconds
+
+// Allow admits at most limit calls per key in any sliding period of length per.
+// When the limit is reached it returns false and how long until the oldest
+// call falls out of the window. Keys with no call left in their window are
+// forgotten now and then, so callers can use unbounded keys such as emails.
+func Allow(key string, limit int, per time.Duration) (bool, time.Duration) {
+	now := time.Now()
+	sweepWindows(now)
+	for {
+		v, _ := windows.LoadOrStore(key, &window{per: per})
+		w := v.(*window)
+		w.mu.Lock()
+		if w.removed {
+			w.mu.Unlock()
+			continue
+		}
+		ok, wait := w.allow(limit, per, now)
+		w.mu.Unlock()
+		return ok, wait
+	}
+}
+
+// allow records a call at now if the window has room; callers hold w.mu
+func (w *window) allow(limit int, per time.Duration, now time.Time) (bool, time.Duration) {
+	w.per = per
+	cutoff := now.Add(-per)
+	i := 0
+	for i < len(w.hits) && !w.hits[i].After(cutoff) {
+		i++
+	}
+	w.hits = w.hits[i:]
+	if len(w.hits) >= limit {
+		return false, w.hits[0].Sub(cutoff)
+	}
+	w.hits = append(w.hits, now)
+	return true, 0
+}
+
+// sweepWindows drops every window whose calls have all aged out, at most once
+// per windowSweepInterval
+func sweepWindows(now time.Time) {
+	last := lastWindowSweep.Load()
+	if now.UnixNano()-last < int64(windowSweepInterval) || !lastWindowSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	windows.Range(func(k, v interface{}) bool {
+		w := v.(*window)
+		w.mu.Lock()
+		if len(w.hits) == 0 || !w.hits[len(w.hits)-1].Add(w.per).After(now) {
+			w.removed = true
+			windows.Delete(k)
+		}
+		w.mu.Unlock()
+		return true
+	})
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func countWindows() int {
+	n := 0
+	windows.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func TestAllowLimitsPerWindow(t *testing.T) {
+	key := t.Name()
+	for i := 0; i < 3; i++ {
+		if ok, _ := Allow(key, 3, time.Hour); !ok {
+			t.Fatalf("call %d rejected, want the first 3 allowed", i+1)
+		}
+	}
+	ok, wait := Allow(key, 3, time.Hour)
+	if ok || wait <= 0 || wait > time.Hour {
+		t.Errorf("4th call = %v, wait %v; want rejected with a wait of at most an hour", ok, wait)
+	}
+	if ok, _ := Allow(key+"-other", 3, time.Hour); !ok {
+		t.Error("another key shares the window")
+	}
+}
+
+func TestSweepDropsIdleWindows(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		Allow(fmt.Sprintf("%s-%d", t.Name(), i), 1, time.Millisecond)
+	}
+	Allow(t.Name()+"-busy", 1, time.Hour)
+	before := countWindows()
+
+	lastWindowSweep.Store(0)
+	sweepWindows(time.Now().Add(time.Second))
+
+	if got := countWindows(); got > before-100 {
+		t.Errorf("%d windows after sweeping, want the 100 idle ones of %d gone", got, before)
+	}
+	if ok, _ := Allow(t.Name()+"-busy", 1, time.Hour); ok {
+		t.Error("sweep forgot a window that still has a call in it")
+	}
+}
+
+func TestAllowDuringSweepKeepsEveryCall(t *testing.T) {
+	key := t.Name()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if ok, _ := Allow(key, 10, time.Hour); ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			lastWindowSweep.Store(0)
+			sweepWindows(time.Now())
+		}()
+	}
+	wg.Wait()
+	if allowed != 10 {
+		t.Errorf("%d calls allowed, want 10 whatever the sweeps did", allowed)
+	}
+}
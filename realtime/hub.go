@@ -0,0 +1,212 @@
+// Package realtime fans order transition events out to Server-Sent Events subscribers.
+package realtime
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is a single order transition pushed to SSE subscribers
+type Event struct {
+	Event           string    `json:"event"`
+	OrderID         uint      `json:"order_id"`
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	RestaurantID    uint      `json:"-"`
+	Restaurant      string    `json:"restaurant"`
+	DriverName      string    `json:"driver_name,omitempty"`
+	ETAMinutes      int       `json:"eta_minutes,omitempty"`      // set on eta_updated events
+	DeliveryAddress string    `json:"delivery_address,omitempty"` // set on address_updated events
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// Buffered so a slow client doesn't block publishers; events beyond this are dropped for that client
+const subscriberBuffer = 16
+
+// ErrTooManySubscriptions is returned by Subscribe when the user already has
+// the maximum number of open streams
+var ErrTooManySubscriptions = errors.New("too many open streams for this user")
+
+// SubscriberInfo describes one open stream. OrderID is 0 for global subscribers.
+type SubscriberInfo struct {
+	UserID       uint      `json:"user_id"`
+	OrderID      uint      `json:"order_id"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	MessagesSent int64     `json:"messages_sent"` // events handed to the stream; heartbeats aren't counted
+}
+
+// Subscription is one open stream. Events is never closed, so publishing
+// can't race with a disconnect; Done is closed when DisconnectUser ends the
+// stream. The subscriber still calls Unsubscribe when it stops reading.
+type Subscription struct {
+	Events      chan Event
+	done        chan struct{}
+	userID      uint
+	orderID     uint
+	connectedAt time.Time
+	sent        atomic.Int64
+}
+
+// Done is closed once an admin disconnects the stream
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func newSubscription(userID, orderID uint) *Subscription {
+	return &Subscription{
+		Events:      make(chan Event, subscriberBuffer),
+		done:        make(chan struct{}),
+		userID:      userID,
+		orderID:     orderID,
+		connectedAt: time.Now(),
+	}
+}
+
+// Hub routes events to per-order subscribers and to global subscribers that see every order
+type Hub struct {
+	mu      sync.RWMutex
+	byOrder map[uint]map[*Subscription]struct{}
+	global  map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		byOrder: map[uint]map[*Subscription]struct{}{},
+		global:  map[*Subscription]struct{}{},
+	}
+}
+
+// Default is the hub shared by the whole application
+var Default = NewHub()
+
+// Subscribe opens a stream of events for one order on behalf of userID.
+// With maxPerUser above 0 it fails once the user has that many streams open.
+func (h *Hub) Subscribe(userID, orderID uint, maxPerUser int) (*Subscription, error) {
+	sub := newSubscription(userID, orderID)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if maxPerUser > 0 && h.countLocked(userID) >= maxPerUser {
+		return nil, ErrTooManySubscriptions
+	}
+	if h.byOrder[orderID] == nil {
+		h.byOrder[orderID] = map[*Subscription]struct{}{}
+	}
+	h.byOrder[orderID][sub] = struct{}{}
+	return sub, nil
+}
+
+// SubscribeGlobal opens a stream of events for every order
+func (h *Hub) SubscribeGlobal(userID uint) *Subscription {
+	sub := newSubscription(userID, 0)
+	h.mu.Lock()
+	h.global[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *Hub) countLocked(userID uint) int {
+	n := 0
+	for sub := range h.global {
+		if sub.userID == userID {
+			n++
+		}
+	}
+	for _, subs := range h.byOrder {
+		for sub := range subs {
+			if sub.userID == userID {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// ListSubscribers describes every open stream, oldest first
+func (h *Hub) ListSubscribers() []SubscriberInfo {
+	h.mu.RLock()
+	out := []SubscriberInfo{}
+	add := func(sub *Subscription) {
+		out = append(out, SubscriberInfo{
+			UserID:       sub.userID,
+			OrderID:      sub.orderID,
+			ConnectedAt:  sub.connectedAt,
+			MessagesSent: sub.sent.Load(),
+		})
+	}
+	for sub := range h.global {
+		add(sub)
+	}
+	for _, subs := range h.byOrder {
+		for sub := range subs {
+			add(sub)
+		}
+	}
+	h.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ConnectedAt.Before(out[j].ConnectedAt) })
+	return out
+}
+
+// DisconnectUser ends every stream the user has open and returns how many
+// there were. Their handlers see Done closed and end the response.
+func (h *Hub) DisconnectUser(userID uint) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for sub := range h.global {
+		if sub.userID == userID {
+			delete(h.global, sub)
+			close(sub.done)
+			n++
+		}
+	}
+	for orderID, subs := range h.byOrder {
+		for sub := range subs {
+			if sub.userID == userID {
+				delete(subs, sub)
+				close(sub.done)
+				n++
+			}
+		}
+		if len(subs) == 0 {
+			delete(h.byOrder, orderID)
+		}
+	}
+	return n
+}
+
+// Unsubscribe removes a stream from the hub. It does nothing for one
+// DisconnectUser already removed.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.global, sub)
+	if subs, ok := h.byOrder[sub.orderID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.byOrder, sub.orderID)
+		}
+	}
+}
+
+// Publish delivers an event to the order's subscribers and all global subscribers
+func (h *Hub) Publish(e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.byOrder[e.OrderID] {
+		send(sub, e)
+	}
+	for sub := range h.global {
+		send(sub, e)
+	}
+}
+
+func send(sub *Subscription, e Event) {
+	select {
+	case sub.Events <- e:
+		sub.sent.Add(1)
+	default:
+	}
+}
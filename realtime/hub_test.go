@@ -0,0 +1,146 @@
+package realtime
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribeCapHoldsUnderConcurrency(t *testing.T) {
+	h := NewHub()
+	const limit = 3
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	opened, refused := 0, 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(orderID uint) {
+			defer wg.Done()
+			_, err := h.Subscribe(7, orderID, limit)
+			mu.Lock()
+			defer mu.Unlock()
+			switch err {
+			case nil:
+				opened++
+			case ErrTooManySubscriptions:
+				refused++
+			default:
+				t.Errorf("Subscribe: %v", err)
+			}
+		}(uint(i % 5))
+	}
+	wg.Wait()
+	if opened != limit || refused != 50-limit {
+		t.Errorf("%d opened, %d refused; want %d and %d", opened, refused, limit, 50-limit)
+	}
+	if n := len(h.ListSubscribers()); n != limit {
+		t.Errorf("%d streams listed, want %d", n, limit)
+	}
+	// Other users have their own allowance
+	if _, err := h.Subscribe(8, 1, limit); err != nil {
+		t.Errorf("another user: %v", err)
+	}
+}
+
+func TestDisconnectUserEndsOnlyTheirStreams(t *testing.T) {
+	h := NewHub()
+	order, _ := h.Subscribe(7, 1, 0)
+	global := h.SubscribeGlobal(7)
+	other, _ := h.Subscribe(8, 1, 0)
+
+	if n := h.DisconnectUser(7); n != 2 {
+		t.Fatalf("DisconnectUser closed %d streams, want 2", n)
+	}
+	for _, sub := range []*Subscription{order, global} {
+		select {
+		case <-sub.Done():
+		default:
+			t.Error("disconnected stream's Done still open")
+		}
+	}
+	select {
+	case <-other.Done():
+		t.Error("another user's stream was ended")
+	default:
+	}
+
+	h.Publish(Event{Event: "status_changed", OrderID: 1})
+	if len(other.Events) != 1 || len(order.Events) != 0 || len(global.Events) != 0 {
+		t.Errorf("buffered events: other %d, order %d, global %d; want 1, 0, 0",
+			len(other.Events), len(order.Events), len(global.Events))
+	}
+}
+
+func TestUnsubscribeAfterDisconnectUser(t *testing.T) {
+	h := NewHub()
+	sub, _ := h.Subscribe(7, 1, 0)
+	global := h.SubscribeGlobal(7)
+	h.DisconnectUser(7)
+
+	// The stream handlers' deferred Unsubscribe runs after the disconnect
+	h.Unsubscribe(sub)
+	h.Unsubscribe(global)
+	if n := h.DisconnectUser(7); n != 0 {
+		t.Errorf("second DisconnectUser closed %d streams, want 0", n)
+	}
+	if len(h.byOrder) != 0 || len(h.global) != 0 {
+		t.Errorf("hub not empty: %d orders, %d global", len(h.byOrder), len(h.global))
+	}
+}
+
+func TestPublishConcurrentWithDisconnect(t *testing.T) {
+	h := NewHub()
+	stop := make(chan struct{})
+	var publishers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					h.Publish(Event{Event: "status_changed", OrderID: 1})
+				}
+			}
+		}()
+	}
+
+	// Readers behave like streamEvents: drain until Done, then Unsubscribe
+	var readers sync.WaitGroup
+	for round := 0; round < 100; round++ {
+		for i := 0; i < 3; i++ {
+			sub, err := h.Subscribe(7, 1, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				defer h.Unsubscribe(sub)
+				for {
+					select {
+					case <-sub.Done():
+						return
+					case <-sub.Events:
+					}
+				}
+			}()
+		}
+		h.DisconnectUser(7)
+	}
+
+	done := make(chan struct{})
+	go func() { readers.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("readers still running after every stream was disconnected")
+	}
+	close(stop)
+	publishers.Wait()
+	if n := len(h.ListSubscribers()); n != 0 {
+		t.Errorf("%d streams left open", n)
+	}
+}
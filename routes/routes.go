@@ -1,83 +1,282 @@
 package routes
 
 import (
+	"food-delivery-api/config"
 	"food-delivery-api/handlers"
 	"food-delivery-api/middleware"
 	"food-delivery-api/models"
 
 	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func SetupRoutes(r *gin.Engine) {
 	// ── Public routes ──────────────────────────────────────────────
 	public := r.Group("/api")
+	public.Use(middleware.Timeout(config.HandlerTimeout("public")))
 	{
 		// Auth
 		public.POST("/auth/register", handlers.Register)
 		public.POST("/auth/login", handlers.Login)
+		public.POST("/auth/magic-link", handlers.RequestMagicLink)
+		public.POST("/auth/magic-link/verify", handlers.VerifyMagicLink)
+		public.POST("/auth/totp", handlers.LoginTOTP)
+		public.GET("/.well-known/jwks.json", handlers.GetJWKS)
+		public.GET("/status", handlers.GetStatus)
 
 		// Restaurants & menus (no auth needed)
 		public.GET("/restaurants", handlers.ListRestaurants)
 		public.GET("/restaurants/:id", handlers.GetRestaurant)
 		public.GET("/restaurants/:id/menu", handlers.GetMenu)
+		public.GET("/cuisines", handlers.ListCuisines)
+		public.GET("/cuisines/:name/restaurants", handlers.ListCuisineRestaurants)
 
 		// State machine info (great for docs/Postman)
 		public.GET("/state-machine", handlers.GetStateMachineInfo)
+		public.GET("/state-machine.dot", handlers.GetStateMachineDOT)
+		public.GET("/state-machine.svg", handlers.GetStateMachineSVG)
+
+		// Leaderboards
+		public.GET("/leaderboard/drivers", handlers.GetPublicDriverLeaderboard)
+		public.GET("/leaderboard/restaurants", handlers.GetRestaurantLeaderboard)
+
+		// Payment gateway callbacks, authenticated by signature
+		public.POST("/webhooks/payment-callback", handlers.PaymentWebhook)
+
+		// Referral landing pages
+		public.GET("/referral/:code", handlers.GetReferralLanding)
+
+		// Swagger UI + OpenAPI spec (regenerate with `make swagger`)
+		public.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
 	// ── Authenticated routes ───────────────────────────────────────
 	auth := r.Group("/api")
-	auth.Use(middleware.AuthRequired())
+	auth.Use(middleware.Timeout(config.HandlerTimeout("auth")), middleware.AuthRequired(), middleware.DriverLastSeen())
 	{
 		auth.GET("/profile", handlers.GetProfile)
+		auth.POST("/auth/accept-invite", handlers.AcceptInvite)
+		auth.GET("/profile/notifications", handlers.GetMyNotifications)
+		auth.GET("/profile/notifications/count", handlers.GetUnreadNotificationCount)
+		auth.PUT("/profile/notifications/read-all", handlers.MarkAllNotificationsRead)
+		auth.PUT("/profile/notifications/:id/read", handlers.MarkNotificationRead)
+		auth.POST("/profile/totp/setup", middleware.RoleRequired(models.RoleAdmin), handlers.SetupTOTP)
+		auth.POST("/profile/totp/verify-setup", middleware.RoleRequired(models.RoleAdmin), handlers.VerifyTOTPSetup)
 	}
 
 	// ── Customer routes ────────────────────────────────────────────
 	customer := r.Group("/api/customer")
-	customer.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleCustomer))
+	customer.Use(middleware.Timeout(config.HandlerTimeout("customer")), middleware.AuthRequired(), middleware.RoleRequired(models.RoleCustomer))
 	{
 		customer.POST("/orders", handlers.PlaceOrder)
 		customer.GET("/orders", handlers.GetMyOrders)
 		customer.GET("/orders/:id", handlers.GetOrderDetail)
+		customer.POST("/orders/:id/support", handlers.SendSupportMessage)
+		customer.GET("/orders/:id/support", handlers.GetSupportThread)
 		customer.PUT("/orders/:id/cancel", handlers.CancelOrder)
+		customer.PUT("/orders/:id/delivery-address", handlers.ChangeDeliveryAddress)
+		customer.POST("/orders/:id/request-reassignment", handlers.RequestReassignment)
+		customer.POST("/orders/:id/review", handlers.ReviewOrder)
+		customer.POST("/orders/:id/rate-eta", handlers.RateOrderETA)
+		customer.GET("/orders/:id/stream", handlers.StreamOrder)
+
+		// Spending analytics
+		customer.GET("/analytics/spending", handlers.GetSpendingAnalytics)
+		customer.GET("/analytics/favorite-items", handlers.GetFavoriteItems)
+
+		// Saved delivery addresses
+		customer.POST("/addresses", handlers.CreateAddress)
+		customer.GET("/addresses", handlers.GetAddresses)
+		customer.PUT("/addresses/:id/regeocode", handlers.RegeocodeAddress)
+		customer.DELETE("/addresses/:id", handlers.DeleteAddress)
+
+		// Recurring weekly orders
+		customer.POST("/recurring-orders", handlers.CreateRecurringOrder)
+		customer.GET("/recurring-orders", handlers.GetRecurringOrders)
+		customer.DELETE("/recurring-orders/:id", handlers.DeleteRecurringOrder)
+		customer.GET("/referrals", handlers.GetMyReferrals)
+
+		// Loyalty
+		customer.GET("/loyalty/tier", handlers.GetLoyaltyTier)
+
+		// Delivery subscription
+		customer.GET("/subscription", handlers.GetMySubscription)
+		customer.POST("/subscription/subscribe", handlers.Subscribe)
+		customer.DELETE("/subscription/cancel", handlers.CancelSubscription)
+
+		// Waitlist for closed restaurants
+		customer.GET("/waitlist", handlers.GetMyWaitlist)
+		customer.POST("/restaurants/:id/waitlist", handlers.JoinWaitlist)
+		customer.DELETE("/restaurants/:id/waitlist", handlers.LeaveWaitlist)
+
+		// Dietary preferences (saved allergies)
+		customer.GET("/dietary-preferences", handlers.GetDietaryPreferences)
+		customer.PUT("/dietary-preferences", handlers.UpdateDietaryPreferences)
 	}
 
 	// ── Restaurant owner routes ────────────────────────────────────
 	restaurant := r.Group("/api/restaurant")
-	restaurant.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleRestaurant))
+	restaurant.Use(middleware.Timeout(config.HandlerTimeout("restaurant")), middleware.AuthRequired(), middleware.RoleRequired(models.RoleRestaurant))
 	{
 		// Restaurant management
 		restaurant.POST("/", handlers.CreateRestaurant)
 		restaurant.GET("/", handlers.GetMyRestaurant)
 		restaurant.PUT("/", handlers.UpdateRestaurant)
+		restaurant.PUT("/toggle-open", handlers.ToggleRestaurantOpen)
+		restaurant.GET("/operating-hours", handlers.GetOperatingHours)
+		restaurant.PUT("/operating-hours", handlers.SetOperatingHours)
+		restaurant.GET("/closures", handlers.GetClosures)
+		restaurant.POST("/closures", handlers.CreateClosure)
+		restaurant.DELETE("/closures/:id", handlers.DeleteClosure)
+		restaurant.POST("/staff/invite", handlers.InviteStaff)
 
 		// Menu management
 		restaurant.POST("/menu", handlers.AddMenuItem)
 		restaurant.PUT("/menu/:itemId", handlers.UpdateMenuItem)
 		restaurant.DELETE("/menu/:itemId", handlers.DeleteMenuItem)
+		restaurant.GET("/menu/:itemId/price-history", handlers.GetMenuItemPriceHistory)
+		restaurant.POST("/menu/import-pos", handlers.ImportPOSMenu)
+		restaurant.POST("/menu/:itemId/allergens", handlers.AddMenuItemAllergens)
+		restaurant.DELETE("/menu/:itemId/allergens", handlers.RemoveMenuItemAllergens)
+		restaurant.POST("/menu/:itemId/pairings", handlers.AddMenuItemPairing)
+		restaurant.DELETE("/menu/:itemId/pairings", handlers.RemoveMenuItemPairing)
+		restaurant.PUT("/menu/:itemId/eighty-six", handlers.EightySixMenuItem)
+		restaurant.PUT("/menu/:itemId/restore", handlers.RestoreMenuItem)
+		restaurant.PUT("/menu/availability", handlers.SetMenuAvailability)
+
+		// Meal-deal bundles
+		restaurant.POST("/bundles", handlers.CreateBundle)
+		restaurant.GET("/bundles", handlers.GetBundles)
+		restaurant.PUT("/bundles/:bundleId", handlers.UpdateBundle)
+		restaurant.DELETE("/bundles/:bundleId", handlers.DeleteBundle)
 
 		// Order management
 		restaurant.GET("/orders", handlers.GetRestaurantOrders)
 		restaurant.PUT("/orders/:id/status", handlers.UpdateOrderStatus)
+
+		// Analytics
+		restaurant.GET("/analytics/heatmap", handlers.GetRestaurantHeatmap)
+		restaurant.GET("/stats", handlers.GetRestaurantStats)
 	}
 
 	// ── Driver routes ──────────────────────────────────────────────
 	driver := r.Group("/api/driver")
-	driver.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleDriver))
+	driver.Use(middleware.Timeout(config.HandlerTimeout("driver")), middleware.AuthRequired(), middleware.RoleRequired(models.RoleDriver), middleware.DriverLastSeen())
 	{
 		driver.GET("/orders/available", handlers.GetAvailableOrders)
 		driver.GET("/orders/my-deliveries", handlers.GetMyDeliveries)
 		driver.PUT("/orders/:id/pickup", handlers.PickupOrder)
 		driver.PUT("/orders/:id/deliver", handlers.DeliverOrder)
+		driver.PUT("/orders/:id/unclaim", handlers.UnclaimOrder)
+		driver.PUT("/orders/:id/location", handlers.UpdateDeliveryLocation)
+		driver.GET("/cod-pending", handlers.GetCODPending)
+		driver.GET("/earnings", handlers.GetDriverEarnings)
+		driver.PUT("/availability", handlers.SetDriverAvailability)
+		driver.GET("/profile", handlers.GetDriverProfile)
+		driver.PUT("/profile", handlers.UpdateDriverProfile)
+		driver.POST("/documents", handlers.SubmitDriverDocument)
+		driver.GET("/documents", handlers.GetMyDriverDocuments)
+		driver.PUT("/documents/:id", handlers.RenewDriverDocument)
 	}
 
 	// ── Admin routes ───────────────────────────────────────────────
 	admin := r.Group("/api/admin")
-	admin.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleAdmin))
+	admin.Use(middleware.IPAllowlist(config.AdminAllowedCIDRs()), middleware.Timeout(config.HandlerTimeout("admin"), "/api/admin/export/menus"), middleware.AuthRequired(), middleware.RoleRequired(models.RoleAdmin))
 	{
 		admin.GET("/orders", handlers.AdminGetAllOrders)
+		admin.GET("/dashboard/stream", handlers.AdminDashboardStream)
+		admin.GET("/sse/subscribers", handlers.AdminGetSSESubscribers)
+		admin.DELETE("/sse/subscribers/:userId", handlers.AdminDisconnectSSEUser)
+		admin.GET("/live/restaurant-load", handlers.AdminGetRestaurantLoad)
+		admin.GET("/dashboard/metrics", handlers.AdminGetDashboardMetrics)
+		admin.GET("/scheduler/status", handlers.AdminGetSchedulerStatus)
 		admin.PUT("/orders/:id/status", handlers.AdminForceOrderStatus)
+		admin.DELETE("/orders/:id", handlers.AdminDeleteOrder)
+		admin.PUT("/orders/:id/restore", handlers.AdminRestoreOrder)
+		admin.PUT("/orders/:id/mark-reviewed", handlers.AdminMarkOrderReviewed)
+		admin.POST("/orders/:id/recalculate-eta", handlers.AdminRecalculateETA)
+		admin.PUT("/orders/:id/delivery-address", handlers.AdminChangeDeliveryAddress)
+		admin.GET("/orders/:id/route", handlers.AdminGetOrderRoute)
+		admin.GET("/fraud/suspicious-orders", handlers.AdminGetSuspiciousOrders)
+		admin.GET("/support/threads", handlers.AdminGetSupportThreads)
+		admin.GET("/support/threads/:orderId", handlers.AdminGetSupportThread)
+		admin.POST("/support/threads/:orderId/reply", handlers.AdminReplySupportThread)
 		admin.GET("/users", handlers.AdminGetAllUsers)
+		admin.POST("/users/merge", handlers.AdminMergeUsers)
+		admin.GET("/users/duplicate-emails", handlers.AdminGetDuplicateEmails)
+		admin.POST("/users/deduplicate", handlers.AdminDeduplicateUsers)
+		admin.POST("/users/:id/force-logout", handlers.AdminForceLogout)
+		admin.POST("/maintenance/recalculate-order-totals", handlers.AdminRecalculateOrderTotals)
+		admin.GET("/db/active-queries", handlers.AdminGetActiveQueries)
+		admin.DELETE("/db/active-queries/:uuid", handlers.AdminCancelQuery)
+		if gin.Mode() != gin.ReleaseMode {
+			admin.POST("/seed", handlers.AdminSeed)
+		}
+
+		// Reports
+		admin.GET("/reports/price-drift", handlers.AdminGetPriceDrift)
+		admin.GET("/reports/auto-cancellations", handlers.AdminGetAutoCancellations)
+		admin.GET("/reports/cod-collections", handlers.AdminGetCODCollections)
+		admin.GET("/reports/reconciliation", handlers.AdminGetReconciliation)
+		admin.GET("/reports/high-volume-customers", handlers.AdminGetHighVolumeCustomers)
+		admin.GET("/reports/cancellation-reasons", handlers.AdminGetCancellationReasons)
+		admin.GET("/reports/eta-accuracy", handlers.AdminGetETAAccuracy)
+		admin.GET("/reports/customer-ltv", handlers.AdminGetCustomerLTV)
+		admin.GET("/reports/churn", handlers.AdminGetChurnedCustomers)
+		admin.GET("/reports/top-items", handlers.AdminGetTopItems)
+		admin.GET("/reports/sla", handlers.AdminGetSLAReport)
+		admin.GET("/export/menus", handlers.AdminExportMenus)
+		admin.GET("/menu-items/out-of-season", handlers.AdminGetOutOfSeasonItems)
+		admin.GET("/menu-items/:id/price-history", handlers.AdminGetMenuItemPriceHistory)
+		admin.POST("/import/menus", handlers.AdminImportMenus)
+		admin.GET("/referrals/stats", handlers.AdminGetReferralStats)
+		admin.GET("/referrals/funnel", handlers.AdminGetReferralFunnel)
+
+		// Platform config
+		admin.PUT("/config/service-fee-percent", handlers.AdminSetServiceFeePercent)
+		admin.PUT("/config/maintenance", handlers.AdminSetMaintenance)
+		admin.GET("/features", handlers.AdminGetFeatures)
+		admin.PUT("/features", handlers.AdminSetFeature)
+		admin.GET("/status-labels", handlers.AdminGetStatusLabels)
+		admin.PUT("/status-labels", handlers.AdminSetStatusLabels)
 		admin.GET("/restaurants", handlers.AdminGetAllRestaurants)
+		admin.PUT("/restaurants/:id/feature", handlers.AdminFeatureRestaurant)
+		admin.PUT("/restaurants/:id/sla", handlers.AdminSetRestaurantSLA)
+		admin.DELETE("/restaurants/:id/feature", handlers.AdminUnfeatureRestaurant)
+		admin.PUT("/restaurants/:id/deactivate", handlers.AdminDeactivateRestaurant)
+		admin.PUT("/restaurants/:id/reactivate", handlers.AdminReactivateRestaurant)
+		admin.PUT("/restaurants/:id/reinstate", handlers.AdminReinstateRestaurant)
+		admin.GET("/restaurants/suspended", handlers.AdminGetSuspendedRestaurants)
+		admin.GET("/restaurants/:id/waitlist", handlers.AdminGetRestaurantWaitlist)
+		admin.GET("/restaurants/:id/rate-stats", handlers.AdminGetRestaurantRateStats)
+		admin.GET("/subscriptions", handlers.AdminGetSubscriptions)
+		admin.PUT("/drivers/:id/profile", handlers.AdminUpdateDriverProfile)
+		admin.GET("/drivers/overloaded", handlers.AdminGetOverloadedDrivers)
+		admin.GET("/drivers/stale", handlers.AdminGetStaleDrivers)
+		admin.GET("/drivers/expiring-documents", handlers.AdminGetExpiringDocuments)
+		admin.POST("/zones", handlers.AdminCreateZone)
+		admin.GET("/zones", handlers.AdminGetZones)
+		admin.GET("/geofence-violations", handlers.AdminGetGeofenceViolations)
+		admin.POST("/webhooks", handlers.AdminCreateWebhook)
+		admin.GET("/webhooks", handlers.AdminGetWebhooks)
+		admin.GET("/webhooks/failed-deliveries", handlers.AdminGetFailedDeliveries)
+		admin.POST("/webhooks/deliveries/:id/replay", handlers.AdminReplayDelivery)
+		admin.GET("/webhooks/stats", handlers.AdminGetWebhookStats)
+		admin.GET("/documents/pending", handlers.AdminGetPendingDocuments)
+		admin.PUT("/documents/:id/review", handlers.AdminReviewDocument)
+		admin.PUT("/drivers/:id/cod-remitted", handlers.AdminMarkCODRemitted)
+		admin.POST("/notifications/broadcast", handlers.AdminBroadcast)
+		admin.GET("/notifications/broadcast-history", handlers.AdminGetBroadcastHistory)
+		admin.GET("/reassignment-requests", handlers.AdminGetReassignmentRequests)
+		admin.PUT("/reassignment-requests/:id/approve", handlers.AdminApproveReassignment)
+		admin.PUT("/reassignment-requests/:id/reject", handlers.AdminRejectReassignment)
+		admin.GET("/leaderboard/drivers", handlers.AdminGetDriverLeaderboard)
+		admin.GET("/analytics/heatmap", handlers.AdminGetHeatmap)
+		admin.DELETE("/analytics/heatmap/cache", handlers.AdminInvalidateHeatmap)
+		admin.GET("/analytics/eighty-six", handlers.AdminGetEightySixStats)
+		admin.GET("/analytics/restaurant-comparison", handlers.AdminGetRestaurantComparison)
+		admin.GET("/analytics/driver-distance", handlers.AdminGetDriverDistance)
+		admin.GET("/analytics/cuisines", handlers.AdminGetCuisineAnalytics)
 	}
 }
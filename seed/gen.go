@@ -0,0 +1,69 @@
+//go:build ignore
+
+// gen turns words.txt into words.go; run it with `go generate ./seed`
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	f, err := os.Open("words.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var sections []string
+	words := map[string][]string{}
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = strings.Trim(line, "[]")
+			sections = append(sections, current)
+		case current == "":
+			log.Fatalf("word %q appears before any [section]", line)
+		default:
+			words[current] = append(words[current], line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go from words.txt; DO NOT EDIT.\n\npackage seed\n")
+	for _, section := range sections {
+		fmt.Fprintf(&buf, "\nvar %s = []string{\n", identifier(section))
+		for _, w := range words[section] {
+			fmt.Fprintf(&buf, "%q,\n", w)
+		}
+		buf.WriteString("}\n")
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("words.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// identifier turns a section name like first_names into FirstNames
+func identifier(section string) string {
+	parts := strings.Split(section, "_")
+	for i, p := range parts {
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,50 @@
+// Package seed supplies the names used for staging seed data
+package seed
+
+//go:generate go run gen.go
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	mathrand "math/rand"
+)
+
+// Picker draws from the word lists. The same seed always yields the same
+// sequence, so a seeded dataset can be reproduced exactly.
+type Picker struct {
+	r *mathrand.Rand
+}
+
+// NewPicker returns a Picker for seed
+func NewPicker(seed int64) *Picker {
+	return &Picker{r: mathrand.New(mathrand.NewSource(seed))}
+}
+
+// NewSeed draws a fresh seed from crypto/rand
+func NewSeed() int64 {
+	var buf [8]byte
+	rand.Read(buf[:])
+	return int64(binary.BigEndian.Uint64(buf[:]) >> 1)
+}
+
+// Pick returns one word from words
+func (p *Picker) Pick(words []string) string {
+	return words[p.r.Intn(len(words))]
+}
+
+// PickN returns n distinct words from words (all of them if n is larger)
+func (p *Picker) PickN(words []string, n int) []string {
+	if n > len(words) {
+		n = len(words)
+	}
+	picked := make([]string, n)
+	for i, j := range p.r.Perm(len(words))[:n] {
+		picked[i] = words[j]
+	}
+	return picked
+}
+
+// Intn returns a number in [0, n)
+func (p *Picker) Intn(n int) int {
+	return p.r.Intn(n)
+}
@@ -0,0 +1,104 @@
+// Code generated by gen.go from words.txt; DO NOT EDIT.
+
+package seed
+
+var FirstNames = []string{
+	"Aarav",
+	"Ananya",
+	"Arjun",
+	"Diya",
+	"Farah",
+	"Ishaan",
+	"Kabir",
+	"Leela",
+	"Meera",
+	"Nikhil",
+	"Priya",
+	"Rohan",
+	"Sana",
+	"Tara",
+	"Vikram",
+	"Zoya",
+}
+
+var LastNames = []string{
+	"Bose",
+	"Chopra",
+	"Desai",
+	"Iyer",
+	"Kapoor",
+	"Menon",
+	"Nair",
+	"Pillai",
+	"Rao",
+	"Shah",
+	"Verma",
+}
+
+var RestaurantAdjectives = []string{
+	"Golden",
+	"Spicy",
+	"Little",
+	"Royal",
+	"Green",
+	"Urban",
+	"Rustic",
+	"Coastal",
+}
+
+var RestaurantNouns = []string{
+	"Spoon",
+	"Tandoor",
+	"Kitchen",
+	"Bowl",
+	"Leaf",
+	"Table",
+	"Pantry",
+	"Hearth",
+}
+
+var Cuisines = []string{
+	"Indian",
+	"Chinese",
+	"Italian",
+	"Thai",
+	"Mexican",
+	"Mediterranean",
+}
+
+var Dishes = []string{
+	"Paneer Tikka",
+	"Butter Chicken",
+	"Dal Makhani",
+	"Chole Bhature",
+	"Masala Dosa",
+	"Veg Biryani",
+	"Chicken Biryani",
+	"Hakka Noodles",
+	"Veg Manchurian",
+	"Kung Pao Chicken",
+	"Margherita Pizza",
+	"Penne Arrabbiata",
+	"Mushroom Risotto",
+	"Pad Thai",
+	"Green Curry",
+	"Tom Yum Soup",
+	"Bean Burrito",
+	"Chicken Quesadilla",
+	"Falafel Wrap",
+	"Hummus Platter",
+	"Garlic Naan",
+	"Jeera Rice",
+	"Gulab Jamun",
+	"Mango Lassi",
+	"Tiramisu",
+}
+
+var Streets = []string{
+	"MG Road",
+	"Park Street",
+	"Church Street",
+	"Brigade Road",
+	"Linking Road",
+	"Residency Road",
+}
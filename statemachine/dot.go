@@ -0,0 +1,62 @@
+package statemachine
+
+import (
+	"fmt"
+	"strings"
+
+	"food-delivery-api/models"
+)
+
+// InitialState is the status every order starts in
+const InitialState = models.StatusPlaced
+
+// TerminalStates are the statuses no transition leaves, in definition order
+func TerminalStates() []models.OrderStatus {
+	var terminal []models.OrderStatus
+	seen := map[models.OrderStatus]bool{}
+	for _, t := range validTransitions {
+		if !seen[t.To] && len(ValidTransitionsFrom(t.To)) == 0 {
+			terminal = append(terminal, t.To)
+			seen[t.To] = true
+		}
+	}
+	return terminal
+}
+
+// DOT renders the state machine as a Graphviz digraph, naming each state by
+// its display label in locale. Transitions between the same two states share
+// one edge labelled with every actor allowed to make it.
+func DOT(locale string) string {
+	type edge struct{ from, to models.OrderStatus }
+	var edges []edge
+	actors := map[edge][]string{}
+	for _, t := range validTransitions {
+		e := edge{t.From, t.To}
+		if _, ok := actors[e]; !ok {
+			edges = append(edges, e)
+		}
+		actors[e] = append(actors[e], t.Actor)
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph OrderStateMachine {\n")
+	b.WriteString("\trankdir=LR;\n")
+	terminal := map[models.OrderStatus]bool{}
+	for _, s := range TerminalStates() {
+		terminal[s] = true
+	}
+	for _, s := range States() {
+		shape := "box"
+		if s == InitialState {
+			shape = "circle"
+		} else if terminal[s] {
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(&b, "\t%s [shape=%s, label=%q];\n", s, shape, DisplayLabel(s, locale))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "\t%s -> %s [label=%q];\n", e.from, e.to, strings.Join(actors[e], " or "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package statemachine
+
+import (
+	"strings"
+	"testing"
+
+	"food-delivery-api/models"
+)
+
+// useLabels replaces the label cache for the test
+func useLabels(t *testing.T, l map[labelKey]string) {
+	t.Helper()
+	labelsMu.Lock()
+	prev := labels
+	labels = l
+	labelsMu.Unlock()
+	t.Cleanup(func() {
+		labelsMu.Lock()
+		labels = prev
+		labelsMu.Unlock()
+	})
+}
+
+func TestDOTShapes(t *testing.T) {
+	useLabels(t, map[labelKey]string{})
+	dot := DOT("en")
+	for _, want := range []string{
+		"digraph OrderStateMachine {\n",
+		"\tPLACED [shape=circle, label=\"Placed\"];\n",
+		"\tPREPARING [shape=box, label=\"Preparing\"];\n",
+		"\tDELIVERED [shape=doublecircle, label=\"Delivered\"];\n",
+		"\tCANCELLED [shape=doublecircle, label=\"Cancelled\"];\n",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT missing %q:\n%s", want, dot)
+		}
+	}
+	if !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("DOT doesn't close the graph:\n%s", dot)
+	}
+}
+
+func TestDOTMergesEdgesByActor(t *testing.T) {
+	useLabels(t, map[labelKey]string{})
+	dot := DOT("en")
+	tests := []struct {
+		edge, label string
+	}{
+		{"PLACED -> CANCELLED", "restaurant or customer"},
+		{"READY_FOR_PICKUP -> PICKED_UP", "driver or system"},
+		{"PICKED_UP -> DELIVERED", "driver"},
+	}
+	for _, tt := range tests {
+		line := "\t" + tt.edge + " [label=\"" + tt.label + "\"];\n"
+		if n := strings.Count(dot, "\t"+tt.edge+" "); n != 1 {
+			t.Errorf("%s drawn %d times, want once", tt.edge, n)
+		}
+		if !strings.Contains(dot, line) {
+			t.Errorf("DOT missing %q:\n%s", line, dot)
+		}
+	}
+	wantEdges := 0
+	seen := map[[2]models.OrderStatus]bool{}
+	for _, tr := range validTransitions {
+		if !seen[[2]models.OrderStatus{tr.From, tr.To}] {
+			seen[[2]models.OrderStatus{tr.From, tr.To}] = true
+			wantEdges++
+		}
+	}
+	if n := strings.Count(dot, " -> "); n != wantEdges {
+		t.Errorf("%d edges, want %d", n, wantEdges)
+	}
+}
+
+func TestDOTUsesLocaleLabels(t *testing.T) {
+	useLabels(t, map[labelKey]string{
+		{models.StatusPlaced, "es"}:    "Realizado",
+		{models.StatusDelivered, "en"}: "Handed over",
+	})
+	tests := []struct {
+		locale, placed, delivered string
+	}{
+		{"es", "Realizado", "Handed over"},
+		{"es-MX", "Realizado", "Handed over"},
+		{"fr", "Placed", "Handed over"},
+	}
+	for _, tt := range tests {
+		dot := DOT(tt.locale)
+		for _, want := range []string{
+			"PLACED [shape=circle, label=\"" + tt.placed + "\"]",
+			"DELIVERED [shape=doublecircle, label=\"" + tt.delivered + "\"]",
+		} {
+			if !strings.Contains(dot, want) {
+				t.Errorf("DOT(%q) missing %q", tt.locale, want)
+			}
+		}
+	}
+}
+
+func TestDOTQuotesLabels(t *testing.T) {
+	useLabels(t, map[labelKey]string{{models.StatusPlaced, "en"}: `Say "hi"`})
+	if dot := DOT("en"); !strings.Contains(dot, `label="Say \"hi\""`) {
+		t.Errorf("label not escaped:\n%s", dot)
+	}
+}
@@ -0,0 +1,118 @@
+package statemachine
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+)
+
+// LabelRefreshInterval is how often the label cache is reloaded from the database
+const LabelRefreshInterval = 60 * time.Second
+
+type labelKey struct {
+	status models.OrderStatus
+	locale string
+}
+
+var (
+	labelsMu sync.RWMutex
+	labels   = map[labelKey]string{}
+)
+
+// States lists every status in the order the transitions first mention it
+func States() []models.OrderStatus {
+	var states []models.OrderStatus
+	seen := map[models.OrderStatus]bool{}
+	for _, t := range validTransitions {
+		for _, s := range []models.OrderStatus{t.From, t.To} {
+			if !seen[s] {
+				states = append(states, s)
+				seen[s] = true
+			}
+		}
+	}
+	return states
+}
+
+// IsState reports whether status is part of the state machine
+func IsState(status models.OrderStatus) bool {
+	for _, s := range States() {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeLocale lower-cases a locale tag, treating empty as the default locale
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" {
+		return models.DefaultLocale
+	}
+	return locale
+}
+
+// RefreshLabels reloads every stored label into the cache
+func RefreshLabels() error {
+	var rows []models.StatusLabel
+	if err := config.DB.Find(&rows).Error; err != nil {
+		return err
+	}
+	fresh := make(map[labelKey]string, len(rows))
+	for _, row := range rows {
+		fresh[labelKey{row.Status, row.Locale}] = row.DisplayLabel
+	}
+	labelsMu.Lock()
+	labels = fresh
+	labelsMu.Unlock()
+	return nil
+}
+
+// StartLabelRefresher loads the label cache and keeps it fresh in the background
+func StartLabelRefresher() {
+	if err := RefreshLabels(); err != nil {
+		log.Printf("statemachine: initial label load failed: %v", err)
+	}
+	go func() {
+		for range time.Tick(LabelRefreshInterval) {
+			if err := RefreshLabels(); err != nil {
+				log.Printf("statemachine: label refresh failed: %v", err)
+			}
+		}
+	}()
+}
+
+// DisplayLabel is the human-readable name of status in locale. It falls back
+// to the locale's language ("pt" for "pt-br"), then to the default locale,
+// then to the status itself in sentence case ("Ready for pickup").
+func DisplayLabel(status models.OrderStatus, locale string) string {
+	locale = NormalizeLocale(locale)
+	candidates := []string{locale}
+	if lang, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, lang)
+	}
+	candidates = append(candidates, models.DefaultLocale)
+
+	labelsMu.RLock()
+	defer labelsMu.RUnlock()
+	for _, l := range candidates {
+		if label, ok := labels[labelKey{status, l}]; ok {
+			return label
+		}
+	}
+	return DefaultLabel(status)
+}
+
+// DefaultLabel is status in sentence case, used when no label is configured
+func DefaultLabel(status models.OrderStatus) string {
+	s := strings.ToLower(strings.ReplaceAll(string(status), "_", " "))
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
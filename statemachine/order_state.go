@@ -27,6 +27,8 @@ var validTransitions = []Transition{
 	{From: models.StatusPreparing, To: models.StatusReadyForPickup, Actor: "restaurant"},
 	// Driver picks up the order
 	{From: models.StatusReadyForPickup, To: models.StatusPickedUp, Actor: "driver"},
+	// System assigns it to the nearest driver when the restaurant auto-assigns
+	{From: models.StatusReadyForPickup, To: models.StatusPickedUp, Actor: "system"},
 	// Driver delivers the order
 	{From: models.StatusPickedUp, To: models.StatusDelivered, Actor: "driver"},
 }
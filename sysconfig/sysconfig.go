@@ -0,0 +1,148 @@
+// Package sysconfig serves SystemConfig values from an in-memory cache that
+// is reloaded from the database periodically.
+package sysconfig
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"food-delivery-api/config"
+	"food-delivery-api/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// Keys understood by the platform
+const (
+	KeyServiceFeePercent      = "SERVICE_FEE_PERCENT"
+	KeyLoyaltySilverThreshold = "LOYALTY_SILVER_THRESHOLD"
+	KeyLoyaltyGoldThreshold   = "LOYALTY_GOLD_THRESHOLD"
+	KeyAutoCancelMinutes      = "AUTO_CANCEL_MINUTES"
+	KeyReferralReferrerBonus  = "REFERRAL_REFERRER_BONUS"
+	KeyReferralRefereeBonus   = "REFERRAL_REFEREE_BONUS"
+	KeyDriverIdleMinutes      = "DRIVER_IDLE_OFFLINE_MINUTES"
+	KeyReferralLandingMessage = "REFERRAL_LANDING_MESSAGE"
+	KeyMaxOrdersPerHour       = "MAX_ORDERS_PER_HOUR"
+	KeyBaseCurrency           = "BASE_CURRENCY"
+	KeyAutoSuspendThreshold   = "AUTO_SUSPEND_THRESHOLD"
+	KeyPlatformTipSharePct    = "PLATFORM_TIP_SHARE_PCT"
+	KeySLAAlertThreshold      = "SLA_ALERT_THRESHOLD"
+	KeyMaxSSEConnections      = "MAX_SSE_CONNECTIONS_PER_USER"
+	KeyMaintenanceMode        = "MAINTENANCE_MODE"
+	KeyMaintenanceMessage     = "MAINTENANCE_MESSAGE"
+	KeyMaintenanceEnd         = "MAINTENANCE_END"
+)
+
+// RefreshInterval is how often the cache is reloaded from the database
+const RefreshInterval = 60 * time.Second
+
+// defaults apply until an admin stores a value
+var defaults = map[string]string{
+	KeyServiceFeePercent:      "0",
+	KeyLoyaltySilverThreshold: "500",
+	KeyLoyaltyGoldThreshold:   "2000",
+	KeyAutoCancelMinutes:      "10",
+	KeyReferralReferrerBonus:  "100",
+	KeyReferralRefereeBonus:   "50",
+	KeyDriverIdleMinutes:      "30",
+	KeyMaxOrdersPerHour:       "10",  // per customer, 0 disables
+	KeyBaseCurrency:           "USD", // admin revenue reports are in this currency
+	KeyAutoSuspendThreshold:   "5",   // consecutive auto-cancels, 0 disables
+	KeyPlatformTipSharePct:    "0",   // percent of each tip the platform keeps
+	KeySLAAlertThreshold:      "80",  // weekly SLA met rate percent below which admins are alerted, 0 disables
+	KeyMaxSSEConnections:      "3",   // open order streams per customer, 0 disables
+	KeyMaintenanceMode:        "false",
+	KeyMaintenanceEnd:         "", // RFC3339; empty when the end isn't known
+	KeyReferralLandingMessage: "Sign up with this code and earn bonus loyalty points on your first delivered order.",
+}
+
+var (
+	mu     sync.RWMutex
+	values = map[string]string{}
+)
+
+// Refresh reloads every stored value into the cache
+func Refresh() error {
+	var rows []models.SystemConfig
+	if err := config.DB.Find(&rows).Error; err != nil {
+		return err
+	}
+	fresh := make(map[string]string, len(rows))
+	for _, row := range rows {
+		fresh[row.Key] = row.Value
+	}
+	mu.Lock()
+	values = fresh
+	mu.Unlock()
+	return nil
+}
+
+// StartRefresher loads the cache and keeps it fresh in the background
+func StartRefresher() {
+	if err := Refresh(); err != nil {
+		log.Printf("sysconfig: initial load failed: %v", err)
+	}
+	go func() {
+		for range time.Tick(RefreshInterval) {
+			if err := Refresh(); err != nil {
+				log.Printf("sysconfig: refresh failed: %v", err)
+			}
+		}
+	}()
+}
+
+// Get returns the cached value for key, or its default
+func Get(key string) string {
+	mu.RLock()
+	v, ok := values[key]
+	mu.RUnlock()
+	if ok {
+		return v
+	}
+	return defaults[key]
+}
+
+// Float returns the value for key parsed as a float, falling back to the default
+func Float(key string) float64 {
+	if f, err := strconv.ParseFloat(Get(key), 64); err == nil {
+		return f
+	}
+	f, _ := strconv.ParseFloat(defaults[key], 64)
+	return f
+}
+
+// Int returns the value for key parsed as an int, falling back to the default
+func Int(key string) int {
+	if n, err := strconv.Atoi(Get(key)); err == nil {
+		return n
+	}
+	n, _ := strconv.Atoi(defaults[key])
+	return n
+}
+
+// Bool returns the value for key parsed as a bool, falling back to the default
+func Bool(key string) bool {
+	if b, err := strconv.ParseBool(Get(key)); err == nil {
+		return b
+	}
+	b, _ := strconv.ParseBool(defaults[key])
+	return b
+}
+
+// Set stores a value and updates this process's cache immediately
+func Set(key, value string, updatedBy *uint) error {
+	row := models.SystemConfig{Key: key, Value: value, UpdatedBy: updatedBy}
+	err := config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	values[key] = value
+	mu.Unlock()
+	return nil
+}
@@ -0,0 +1,82 @@
+// Package webhook posts signed event payloads to subscriber URLs.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxAttempts is one round of delivery; after it the delivery has failed
+	// permanently until it is replayed
+	MaxAttempts = 10
+
+	// SignatureHeader carries the hex HMAC-SHA256 of the body
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Backoff before retry n (1-based) is BaseDelay * 2^(n-1), capped at MaxDelay
+var (
+	BaseDelay = time.Second
+	MaxDelay  = 5 * time.Minute
+)
+
+// Client sends every delivery
+var Client = &http.Client{Timeout: 10 * time.Second}
+
+// Sign is the signature a receiver should expect for payload
+func Sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts payload to url until it gets a 2xx response, backing off
+// exponentially between attempts. It returns how many attempts it made and,
+// when all MaxAttempts failed, the last error.
+func Deliver(url, secret, payload string) (int, error) {
+	signature := Sign(secret, payload)
+	var err error
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		if err = post(url, signature, payload); err == nil {
+			return attempt, nil
+		}
+		if attempt < MaxAttempts {
+			time.Sleep(backoff(attempt))
+		}
+	}
+	return MaxAttempts, err
+}
+
+func backoff(attempt int) time.Duration {
+	d := BaseDelay << (attempt - 1)
+	if d > MaxDelay || d <= 0 {
+		return MaxDelay
+	}
+	return d
+}
+
+func post(url, signature, payload string) error {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+	resp, err := Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded %d", url, resp.StatusCode)
+	}
+	return nil
+}